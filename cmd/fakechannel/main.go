@@ -0,0 +1,62 @@
+// Package main 模拟支付渠道，向本地服务推送已签名的代收回调，用于离线联调回调接口。
+//
+// 示例:
+//
+//	go run ./cmd/fakechannel -url http://127.0.0.1:8080 -channel FAKE -secret s3cret -schedule 12 -amount 20000
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"loan/internal/channel"
+)
+
+func main() {
+	baseURL := flag.String("url", "http://127.0.0.1:8080", "loan service base url")
+	code := flag.String("channel", "FAKE", "payment channel code (loan_payment_channels.code)")
+	secret := flag.String("secret", "", "channel callback secret")
+	scheduleID := flag.Uint64("schedule", 0, "repayment schedule id")
+	amount := flag.Int("amount", 0, "paid amount in cents")
+	payRef := flag.String("payref", "", "channel transaction id, default generated; reuse it to test duplicate callbacks")
+	orderNo := flag.String("order", "", "merchant order no, optional")
+	status := flag.String("status", channel.CollectStatusSuccess, "SUCCESS or FAILED")
+	repeat := flag.Int("repeat", 1, "send the same callback n times to test idempotency")
+	flag.Parse()
+
+	if *secret == "" || *scheduleID == 0 {
+		fmt.Fprintln(os.Stderr, "-secret and -schedule are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *payRef == "" {
+		*payRef = fmt.Sprintf("FAKE%d", time.Now().UnixNano())
+	}
+
+	fake := channel.NewFakeChannel(*code, *secret)
+	n := &channel.CollectNotification{
+		OrderNo:    *orderNo,
+		PayRef:     *payRef,
+		ScheduleID: *scheduleID,
+		Amount:     *amount,
+		PayMethod:  "BANK_TRANSFER",
+		Status:     *status,
+		PaidAt:     time.Now().Unix(),
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	for i := 0; i < *repeat; i++ {
+		resp, err := fake.SendCollect(client, *baseURL, n)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "send callback failed: %v\n", err)
+			os.Exit(1)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		fmt.Printf("[%d] %s %d %s\n", i+1, fake.CollectURL(*baseURL), resp.StatusCode, body)
+	}
+}
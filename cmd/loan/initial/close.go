@@ -10,6 +10,7 @@ import (
 
 	"loan/internal/config"
 	"loan/internal/database"
+	"loan/internal/job"
 )
 
 // Close releasing resources after service exit
@@ -21,6 +22,11 @@ func Close(servers []app.IServer) []app.Close {
 		closes = append(closes, s.Stop)
 	}
 
+	// close background jobs
+	closes = append(closes, func() error {
+		return job.Close()
+	})
+
 	// close database
 	closes = append(closes, func() error {
 		return database.CloseDB()
@@ -14,6 +14,7 @@ import (
 	"loan/configs"
 	"loan/internal/config"
 	"loan/internal/database"
+	"loan/internal/job"
 )
 
 var (
@@ -75,6 +76,12 @@ func InitApp() {
 	if cfg.App.CacheType != "" {
 		logger.Infof("[%s] was initialized", cfg.App.CacheType)
 	}
+
+	// initializing background jobs
+	if err = job.Init(); err != nil {
+		panic("init job error: " + err.Error())
+	}
+	logger.Info("[job] was initialized")
 }
 
 func initConfig() {
@@ -0,0 +1,192 @@
+// Package main 命令行渠道结算对账：读取渠道结算文件，与数据库中的放款单/回款流水比对并输出对账报告。
+//
+// 示例:
+//
+//	go run ./cmd/reconcile -c configs/loan.yml -channel BANK_A -file settle_20260101.csv -from 2026-01-01 -to 2026-01-01
+//	go run ./cmd/reconcile -channel BANK_A -file settle.csv -from 2026-01-01 -to 2026-01-07 -format csv -out report.csv
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"loan/configs"
+	"loan/internal/config"
+	"loan/internal/dao"
+	"loan/internal/database"
+	"loan/internal/reconcile"
+)
+
+func main() {
+	configFile := flag.String("c", "", "configuration file, default configs/loan.yml")
+	code := flag.String("channel", "", "payment channel code (loan_payment_channels.code)")
+	filePath := flag.String("file", "", "channel settlement file")
+	dateFrom := flag.String("from", "", "start date yyyy-mm-dd")
+	dateTo := flag.String("to", "", "end date yyyy-mm-dd, inclusive, default same as -from")
+	format := flag.String("format", "text", "report format: text, json or csv")
+	outPath := flag.String("out", "", "write report to file instead of stdout")
+	flag.Parse()
+
+	if *code == "" || *filePath == "" || *dateFrom == "" {
+		fmt.Fprintln(os.Stderr, "-channel, -file and -from are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *dateTo == "" {
+		*dateTo = *dateFrom
+	}
+	from, to, err := reconcile.ParseWindow(*dateFrom, *dateTo)
+	if err != nil {
+		exitf("%v", err)
+	}
+
+	if *configFile == "" {
+		*configFile = configs.Location("loan.yml")
+	}
+	if err = config.Init(*configFile); err != nil {
+		exitf("init config error: %v", err)
+	}
+	database.InitDB()
+	defer func() { _ = database.CloseDB() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// 命令行一次性运行，不使用缓存
+	db := database.GetDB()
+	ch, err := dao.NewLoanPaymentChannelsDao(db, nil).GetByCode(ctx, *code)
+	if err != nil {
+		exitf("get channel %s error: %v", *code, err)
+	}
+	ledger := &reconcile.Ledger{
+		DisbursementDao: dao.NewLoanDisbursementsDao(db, nil),
+		TransactionDao:  dao.NewLoanRepaymentTransactionsDao(db, nil),
+	}
+
+	f, err := os.Open(*filePath)
+	if err != nil {
+		exitf("open settlement file error: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	report, err := ledger.Run(ctx, reconcile.ParserFor(ch.Code), f, ch.ID, from, to)
+	if err != nil {
+		exitf("reconcile error: %v", err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *outPath != "" {
+		w, err := os.Create(*outPath)
+		if err != nil {
+			exitf("create output file error: %v", err)
+		}
+		defer func() { _ = w.Close() }()
+		out = w
+	}
+
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(report)
+	case "csv":
+		err = writeCSV(out, report)
+	default:
+		err = writeText(out, ch.Code, *dateFrom, *dateTo, report)
+	}
+	if err != nil {
+		exitf("write report error: %v", err)
+	}
+
+	if !report.Balanced() {
+		os.Exit(1)
+	}
+}
+
+func exitf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	os.Exit(2)
+}
+
+func allItems(r *reconcile.Report) []*reconcile.Item {
+	items := make([]*reconcile.Item, 0, r.Summary.ChannelCount+r.Summary.MissingOnChannel)
+	items = append(items, r.AmountMismatch...)
+	items = append(items, r.MissingOurSide...)
+	items = append(items, r.MissingOnChannel...)
+	items = append(items, r.Matched...)
+	return items
+}
+
+func writeText(w io.Writer, code string, dateFrom string, dateTo string, r *reconcile.Report) error {
+	s := r.Summary
+	fmt.Fprintf(w, "channel %s, %s ~ %s\n", code, dateFrom, dateTo)
+	fmt.Fprintf(w, "channel file: %d lines, %s\n", s.ChannelCount, yuan(s.ChannelAmount))
+	fmt.Fprintf(w, "our ledger:   %d records, %s\n", s.OurCount, yuan(s.OurAmount))
+	fmt.Fprintf(w, "matched %d, amount mismatch %d, missing on our side %d, missing on channel %d\n\n",
+		s.Matched, s.AmountMismatch, s.MissingOurSide, s.MissingOnChannel)
+
+	for _, it := range allItems(r) {
+		if it.Status == reconcile.StatusMatched {
+			continue
+		}
+		orderNo, ref, line, id := itemKeys(it)
+		fmt.Fprintf(w, "%-18s %-7s line=%-5s id=%-8s order=%s ref=%s diff=%s\n",
+			it.Status, it.Kind, line, id, orderNo, ref, yuan(it.Diff))
+	}
+	if r.Balanced() {
+		_, err := fmt.Fprintln(w, "balanced")
+		return err
+	}
+	return nil
+}
+
+func writeCSV(w io.Writer, r *reconcile.Report) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"status", "kind", "line_no", "record_id", "order_no", "channel_ref", "channel_amount", "our_amount", "diff"})
+	for _, it := range allItems(r) {
+		orderNo, ref, line, id := itemKeys(it)
+		channelAmount, ourAmount := "", ""
+		if it.Line != nil {
+			channelAmount = yuan(it.Line.Amount)
+		}
+		if it.Record != nil {
+			ourAmount = yuan(it.Record.Amount)
+		}
+		_ = cw.Write([]string{string(it.Status), string(it.Kind), line, id, orderNo, ref, channelAmount, ourAmount, yuan(it.Diff)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// itemKeys 取明细的单号，优先渠道侧
+func itemKeys(it *reconcile.Item) (orderNo string, ref string, line string, id string) {
+	if it.Record != nil {
+		orderNo, ref, id = it.Record.OrderNo, it.Record.ChannelRef, strconv.FormatUint(it.Record.ID, 10)
+	}
+	if it.Line != nil {
+		line = strconv.Itoa(it.Line.LineNo)
+		if it.Line.OrderNo != "" {
+			orderNo = it.Line.OrderNo
+		}
+		if it.Line.ChannelRef != "" {
+			ref = it.Line.ChannelRef
+		}
+	}
+	return orderNo, ref, line, id
+}
+
+// yuan 分转元字符串
+func yuan(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
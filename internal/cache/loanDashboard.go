@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/cache"
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+
+	"loan/internal/database"
+	"loan/internal/types"
+)
+
+const (
+	// cache prefix key, must end with a colon
+	loanDashboardCachePrefixKey = "loanDashboard:"
+	// LoanDashboardExpireTime expire time, 看板指标允许短时间的延迟
+	LoanDashboardExpireTime = 1 * time.Minute
+)
+
+var _ LoanDashboardCache = (*loanDashboardCache)(nil)
+
+// LoanDashboardCache cache interface
+type LoanDashboardCache interface {
+	Set(ctx context.Context, key string, data *types.DashboardKPIs, duration time.Duration) error
+	Get(ctx context.Context, key string) (*types.DashboardKPIs, error)
+}
+
+// loanDashboardCache define a cache struct
+type loanDashboardCache struct {
+	cache cache.Cache
+}
+
+// NewLoanDashboardCache new a cache
+func NewLoanDashboardCache(cacheType *database.CacheType) LoanDashboardCache {
+	jsonEncoding := encoding.JSONEncoding{}
+	cachePrefix := ""
+
+	cType := strings.ToLower(cacheType.CType)
+	switch cType {
+	case "redis":
+		c := cache.NewRedisCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
+			return &types.DashboardKPIs{}
+		})
+		return &loanDashboardCache{cache: c}
+	case "memory":
+		c := cache.NewMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
+			return &types.DashboardKPIs{}
+		})
+		return &loanDashboardCache{cache: c}
+	}
+
+	return nil // no cache
+}
+
+// GetLoanDashboardCacheKey cache key, key 为统计区间
+func (c *loanDashboardCache) GetLoanDashboardCacheKey(key string) string {
+	return loanDashboardCachePrefixKey + key
+}
+
+// Set write to cache
+func (c *loanDashboardCache) Set(ctx context.Context, key string, data *types.DashboardKPIs, duration time.Duration) error {
+	if data == nil || key == "" {
+		return nil
+	}
+	return c.cache.Set(ctx, c.GetLoanDashboardCacheKey(key), data, duration)
+}
+
+// Get cache value
+func (c *loanDashboardCache) Get(ctx context.Context, key string) (*types.DashboardKPIs, error) {
+	var data *types.DashboardKPIs
+	err := c.cache.Get(ctx, c.GetLoanDashboardCacheKey(key), &data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
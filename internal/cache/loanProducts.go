@@ -0,0 +1,153 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/cache"
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"loan/internal/database"
+	"loan/internal/model"
+)
+
+const (
+	// cache prefix key, must end with a colon
+	loanProductsCachePrefixKey = "loanProducts:"
+	// LoanProductsExpireTime expire time
+	LoanProductsExpireTime = 5 * time.Minute
+)
+
+var _ LoanProductsCache = (*loanProductsCache)(nil)
+
+// LoanProductsCache cache interface
+type LoanProductsCache interface {
+	Set(ctx context.Context, id uint64, data *model.LoanProducts, duration time.Duration) error
+	Get(ctx context.Context, id uint64) (*model.LoanProducts, error)
+	MultiGet(ctx context.Context, ids []uint64) (map[uint64]*model.LoanProducts, error)
+	MultiSet(ctx context.Context, data []*model.LoanProducts, duration time.Duration) error
+	Del(ctx context.Context, id uint64) error
+	SetPlaceholder(ctx context.Context, id uint64) error
+	IsPlaceholderErr(err error) bool
+}
+
+// loanProductsCache define a cache struct
+type loanProductsCache struct {
+	cache cache.Cache
+}
+
+// NewLoanProductsCache new a cache
+func NewLoanProductsCache(cacheType *database.CacheType) LoanProductsCache {
+	jsonEncoding := encoding.JSONEncoding{}
+	cachePrefix := ""
+
+	cType := strings.ToLower(cacheType.CType)
+	switch cType {
+	case "redis":
+		c := cache.NewRedisCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
+			return &model.LoanProducts{}
+		})
+		return &loanProductsCache{cache: c}
+	case "memory":
+		c := cache.NewMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
+			return &model.LoanProducts{}
+		})
+		return &loanProductsCache{cache: c}
+	}
+
+	return nil // no cache
+}
+
+// GetLoanProductsCacheKey cache key
+func (c *loanProductsCache) GetLoanProductsCacheKey(id uint64) string {
+	return loanProductsCachePrefixKey + utils.Uint64ToStr(id)
+}
+
+// Set write to cache
+func (c *loanProductsCache) Set(ctx context.Context, id uint64, data *model.LoanProducts, duration time.Duration) error {
+	if data == nil || id == 0 {
+		return nil
+	}
+	cacheKey := c.GetLoanProductsCacheKey(id)
+	err := c.cache.Set(ctx, cacheKey, data, duration)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Get cache value
+func (c *loanProductsCache) Get(ctx context.Context, id uint64) (*model.LoanProducts, error) {
+	var data *model.LoanProducts
+	cacheKey := c.GetLoanProductsCacheKey(id)
+	err := c.cache.Get(ctx, cacheKey, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// MultiSet multiple set cache
+func (c *loanProductsCache) MultiSet(ctx context.Context, data []*model.LoanProducts, duration time.Duration) error {
+	valMap := make(map[string]interface{})
+	for _, v := range data {
+		cacheKey := c.GetLoanProductsCacheKey(v.ID)
+		valMap[cacheKey] = v
+	}
+
+	err := c.cache.MultiSet(ctx, valMap, duration)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MultiGet multiple get cache, return key in map is id value
+func (c *loanProductsCache) MultiGet(ctx context.Context, ids []uint64) (map[uint64]*model.LoanProducts, error) {
+	var keys []string
+	for _, v := range ids {
+		cacheKey := c.GetLoanProductsCacheKey(v)
+		keys = append(keys, cacheKey)
+	}
+
+	itemMap := make(map[string]*model.LoanProducts)
+	err := c.cache.MultiGet(ctx, keys, itemMap)
+	if err != nil {
+		return nil, err
+	}
+
+	retMap := make(map[uint64]*model.LoanProducts)
+	for _, id := range ids {
+		val, ok := itemMap[c.GetLoanProductsCacheKey(id)]
+		if ok {
+			retMap[id] = val
+		}
+	}
+
+	return retMap, nil
+}
+
+// Del delete cache
+func (c *loanProductsCache) Del(ctx context.Context, id uint64) error {
+	cacheKey := c.GetLoanProductsCacheKey(id)
+	err := c.cache.Del(ctx, cacheKey)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetPlaceholder set placeholder value to cache
+func (c *loanProductsCache) SetPlaceholder(ctx context.Context, id uint64) error {
+	cacheKey := c.GetLoanProductsCacheKey(id)
+	return c.cache.SetCacheWithNotFound(ctx, cacheKey)
+}
+
+// IsPlaceholderErr check if cache is placeholder error
+func (c *loanProductsCache) IsPlaceholderErr(err error) bool {
+	return errors.Is(err, cache.ErrPlaceholder)
+}
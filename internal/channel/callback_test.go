@@ -0,0 +1,37 @@
+package channel
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"payRef":"T1","scheduleId":1,"amount":100,"status":"SUCCESS"}`)
+	sig := Sign("secret", ts, body)
+
+	assert.NoError(t, Verify("secret", ts, sig, body, now, DefaultSignatureTolerance))
+	assert.NoError(t, Verify("secret", ts, sig, body, now.Add(time.Hour), 0), "tolerance<=0 skips timestamp check")
+
+	assert.ErrorIs(t, Verify("other", ts, sig, body, now, DefaultSignatureTolerance), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", ts, sig, []byte(`{}`), now, DefaultSignatureTolerance), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", ts, sig, body, now.Add(10*time.Minute), DefaultSignatureTolerance), ErrSignatureExpired)
+	assert.ErrorIs(t, Verify("secret", "abc", sig, body, now, DefaultSignatureTolerance), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("", ts, sig, body, now, DefaultSignatureTolerance), ErrMissingSignature)
+	assert.ErrorIs(t, Verify("secret", ts, "", body, now, DefaultSignatureTolerance), ErrMissingSignature)
+}
+
+func TestCollectNotificationValidate(t *testing.T) {
+	n := &CollectNotification{PayRef: "T1", ScheduleID: 1, Amount: 100, Status: "success"}
+	assert.True(t, n.Succeeded())
+	assert.NoError(t, n.Validate())
+
+	assert.Error(t, (&CollectNotification{ScheduleID: 1, Amount: 100, Status: CollectStatusSuccess}).Validate())
+	assert.Error(t, (&CollectNotification{PayRef: "T1", Amount: 100, Status: CollectStatusSuccess}).Validate())
+	assert.Error(t, (&CollectNotification{PayRef: "T1", ScheduleID: 1, Status: CollectStatusSuccess}).Validate())
+	assert.NoError(t, (&CollectNotification{PayRef: "T1", ScheduleID: 1, Status: CollectStatusFailed}).Validate())
+}
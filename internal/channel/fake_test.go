@@ -0,0 +1,43 @@
+package channel
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeChannelSendCollect(t *testing.T) {
+	var got CollectNotification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/callbacks/collect/FAKE", r.URL.Path)
+		body, _ := io.ReadAll(r.Body)
+		err := Verify("s3cret", r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature), body, time.Now(), DefaultSignatureTolerance)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.Unmarshal(body, &got)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n := &CollectNotification{PayRef: "T100", ScheduleID: 7, Amount: 5000, Status: CollectStatusSuccess}
+
+	resp, err := NewFakeChannel("FAKE", "s3cret").SendCollect(nil, server.URL+"/", n)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, *n, got)
+
+	// 密钥不一致时服务端验签失败
+	resp, err = NewFakeChannel("FAKE", "wrong").SendCollect(nil, server.URL, n)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
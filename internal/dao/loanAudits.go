@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"loan/internal/types"
+	"time"
 
 	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
@@ -22,6 +23,8 @@ type LoanAuditsDao interface {
 	ListByBaseinfoID(ctx context.Context, baseinfoID uint64) ([]*model.LoanAudits, error)
 	GetByBaseinfoID(ctx context.Context, baseinfoID uint64, auditType int) (*types.LoanAuditDetail, error)
 	GetDisbursmentsByBaseInfoID(ctx context.Context, baseInfoID uint64) (*types.DisbursementWithChannel, error)
+	GetApproverIDsByTx(ctx context.Context, tx *gorm.DB, baseinfoID uint64) ([]uint64, error)
+	GetDecidedBetween(ctx context.Context, from time.Time, to time.Time, auditorUserID uint64) ([]*model.LoanAudits, error)
 }
 
 type loanAuditsDao struct {
@@ -84,8 +87,8 @@ func (d *loanAuditsDao) GetByBaseinfoID(ctx context.Context, baseinfoID uint64,
 	auditRecord := &types.LoanAuditDetail{}
 	// 核心查询部分
 	err := d.db.WithContext(ctx).Model(&model.LoanAudits{}).
-		Select("a.*, u.username as auditor_username").
-		Joins("INNER JOIN loan_users u ON a.auditor_user_id = u.id").
+		Select("a.*, COALESCE(u.username, ?) as auditor_username", model.SystemAuditorName). // 系统审核人员没有对应用户
+		Joins("LEFT JOIN loan_users u ON a.auditor_user_id = u.id").
 		Where("a.baseinfo_id =? and a.audit_type = ?", baseinfoID, auditType).
 		Table("loan_audits a").  // 给 loan_audits 起别名 a
 		First(auditRecord).Error // 关键修改2：用 First 替代 Find，查询单条记录
@@ -121,3 +124,24 @@ func (d *loanAuditsDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *mode
 	err := tx.WithContext(ctx).Create(table).Error
 	return table.ID, err
 }
+
+// GetApproverIDsByTx 申请单已通过节点的审批人，在审批事务内(申请单已加锁)查询
+func (d *loanAuditsDao) GetApproverIDsByTx(ctx context.Context, tx *gorm.DB, baseinfoID uint64) ([]uint64, error) {
+	var ids []uint64
+	err := tx.WithContext(ctx).Model(&model.LoanAudits{}).
+		Where("baseinfo_id = ? AND audit_result = ?", baseinfoID, model.AuditResultPass).
+		Distinct("auditor_user_id").
+		Pluck("auditor_user_id", &ids).Error
+	return ids, err
+}
+
+// GetDecidedBetween 审批决定时间在 [from, to) 内的审核记录，auditorUserID 为 0 表示全部审批人员
+func (d *loanAuditsDao) GetDecidedBetween(ctx context.Context, from time.Time, to time.Time, auditorUserID uint64) ([]*model.LoanAudits, error) {
+	var records []*model.LoanAudits
+	db := d.db.WithContext(ctx).Where("created_at >= ? AND created_at < ?", from, to)
+	if auditorUserID != 0 {
+		db = db.Where("auditor_user_id = ?", auditorUserID)
+	}
+	err := db.Order("id ASC").Find(&records).Error
+	return records, err
+}
@@ -8,6 +8,7 @@ import (
 
 	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/go-dev-frame/sponge/pkg/logger"
 	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
@@ -36,6 +37,15 @@ type LoanBaseinfoDao interface {
 	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.LoanBaseinfo) (uint64, error)
 	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
 	UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.LoanBaseinfo) error
+
+	GetByIDForUpdate(ctx context.Context, tx *gorm.DB, id uint64) (*model.LoanBaseinfo, error)
+	GetCreatedBetween(ctx context.Context, from time.Time, to time.Time, limit int) ([]*model.LoanBaseinfo, error)
+	GetClaimCandidatesByTx(ctx context.Context, tx *gorm.DB, now time.Time, maxStageNo int, offset int, limit int) ([]*model.LoanBaseinfo, error)
+	GetActiveClaimByTx(ctx context.Context, tx *gorm.DB, uid uint64, now time.Time) (*model.LoanBaseinfo, error)
+	ClaimByTx(ctx context.Context, tx *gorm.DB, id uint64, uid uint64, claimedAt time.Time, expiresAt time.Time) error
+	ReleaseClaimByTx(ctx context.Context, tx *gorm.DB, id uint64) error
+	FinishStageByTx(ctx context.Context, tx *gorm.DB, id uint64, at time.Time) error
+	UpdateScore(ctx context.Context, id uint64, score int, scorecardID uint64, breakdown string, at time.Time) error
 }
 
 type loanBaseinfoDao struct {
@@ -366,7 +376,9 @@ func (d *loanBaseinfoDao) GetByColumnsWithAuditRecords(ctx context.Context, para
 		for _, auditRecord := range result.AuditRecords {
 			// 从映射表获取姓名，无则显示"未知"
 			auditRecord.AuditorName = auditorNameMap[auditRecord.AuditorUserID]
-			if auditRecord.AuditorName == "" {
+			if auditRecord.AuditorUserID == model.SystemAuditorUserID {
+				auditRecord.AuditorName = model.SystemAuditorName
+			} else if auditRecord.AuditorName == "" {
 				auditRecord.AuditorName = "未知"
 			}
 		}
@@ -621,3 +633,123 @@ func (d *loanBaseinfoDao) UpdateByTx(ctx context.Context, tx *gorm.DB, table *mo
 
 	return err
 }
+
+// GetByIDForUpdate 在事务内按id查询并加行锁
+func (d *loanBaseinfoDao) GetByIDForUpdate(ctx context.Context, tx *gorm.DB, id uint64) (*model.LoanBaseinfo, error) {
+	record := &model.LoanBaseinfo{}
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(record).Error
+	return record, err
+}
+
+// GetCreatedBetween 提交时间在 [from, to) 内的申请单，按提交时间倒序，最多 limit 条
+func (d *loanBaseinfoDao) GetCreatedBetween(ctx context.Context, from time.Time, to time.Time, limit int) ([]*model.LoanBaseinfo, error) {
+	var records []*model.LoanBaseinfo
+	err := d.db.WithContext(ctx).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Find(&records).Error
+	return records, err
+}
+
+// GetClaimCandidatesByTx 审批工作队列的候选申请单：未拒绝、尚有待审节点(audit_status < maxStageNo)且未被有效领取，
+// 按节点待审时间先后排序并加锁，已被其他事务锁定的行直接跳过(SKIP LOCKED)
+func (d *loanBaseinfoDao) GetClaimCandidatesByTx(ctx context.Context, tx *gorm.DB, now time.Time, maxStageNo int, offset int, limit int) ([]*model.LoanBaseinfo, error) {
+	var records []*model.LoanBaseinfo
+	err := tx.WithContext(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("audit_status >= 0 AND audit_status < ?", maxStageNo).
+		Where("claimed_by IS NULL OR claim_expires_at IS NULL OR claim_expires_at <= ?", now).
+		Order("COALESCE(stage_received_at, created_at) ASC, id ASC").
+		Offset(offset).
+		Limit(limit).
+		Find(&records).Error
+	return records, err
+}
+
+// GetActiveClaimByTx 审批人当前租约未过期的领取
+func (d *loanBaseinfoDao) GetActiveClaimByTx(ctx context.Context, tx *gorm.DB, uid uint64, now time.Time) (*model.LoanBaseinfo, error) {
+	record := &model.LoanBaseinfo{}
+	err := tx.WithContext(ctx).
+		Where("claimed_by = ? AND claim_expires_at > ? AND audit_status >= 0", uid, now).
+		Order("claimed_at ASC, id ASC").
+		First(record).Error
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// ClaimByTx 将申请单领取给审批人，租约到 expiresAt
+func (d *loanBaseinfoDao) ClaimByTx(ctx context.Context, tx *gorm.DB, id uint64, uid uint64, claimedAt time.Time, expiresAt time.Time) error {
+	update := map[string]interface{}{
+		"claimed_by":       uid,
+		"claimed_at":       claimedAt,
+		"claim_expires_at": expiresAt,
+	}
+	err := tx.WithContext(ctx).Model(&model.LoanBaseinfo{}).Where("id = ?", id).Updates(update).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
+
+// ReleaseClaimByTx 释放申请单的领取
+func (d *loanBaseinfoDao) ReleaseClaimByTx(ctx context.Context, tx *gorm.DB, id uint64) error {
+	update := map[string]interface{}{
+		"claimed_by":       gorm.Expr("NULL"),
+		"claimed_at":       gorm.Expr("NULL"),
+		"claim_expires_at": gorm.Expr("NULL"),
+	}
+	err := tx.WithContext(ctx).Model(&model.LoanBaseinfo{}).Where("id = ?", id).Updates(update).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
+
+// FinishStageByTx 节点审批完成：释放领取，下一节点从 at 开始待审
+func (d *loanBaseinfoDao) FinishStageByTx(ctx context.Context, tx *gorm.DB, id uint64, at time.Time) error {
+	update := map[string]interface{}{
+		"claimed_by":        gorm.Expr("NULL"),
+		"claimed_at":        gorm.Expr("NULL"),
+		"claim_expires_at":  gorm.Expr("NULL"),
+		"stage_received_at": at,
+	}
+	err := tx.WithContext(ctx).Model(&model.LoanBaseinfo{}).Where("id = ?", id).Updates(update).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
+
+// UpdateScore 保存信用分、评分卡版本与评分明细
+func (d *loanBaseinfoDao) UpdateScore(ctx context.Context, id uint64, score int, scorecardID uint64, breakdown string, at time.Time) error {
+	update := map[string]interface{}{
+		"credit_score":    score,
+		"scorecard_id":    scorecardID,
+		"score_breakdown": breakdown,
+		"scored_at":       at,
+	}
+	err := d.db.WithContext(ctx).Model(&model.LoanBaseinfo{}).Where("id = ?", id).Updates(update).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"loan/internal/cache"
+	"loan/internal/database"
+	"loan/internal/model"
+	"loan/internal/types"
+)
+
+var _ LoanDashboardDao = (*loanDashboardDao)(nil)
+
+// LoanDashboardDao 运营看板指标统计
+type LoanDashboardDao interface {
+	GetKPIs(ctx context.Context, from time.Time, to time.Time) (*types.DashboardKPIs, error)
+}
+
+type loanDashboardDao struct {
+	db    *gorm.DB
+	cache cache.LoanDashboardCache // if nil, the cache is not used.
+	sfg   *singleflight.Group      // if cache is nil, the sfg is not used.
+}
+
+// NewLoanDashboardDao creating the dao interface
+func NewLoanDashboardDao(db *gorm.DB, xCache cache.LoanDashboardCache) LoanDashboardDao {
+	if xCache == nil {
+		return &loanDashboardDao{db: db}
+	}
+	return &loanDashboardDao{
+		db:    db,
+		cache: xCache,
+		sfg:   new(singleflight.Group),
+	}
+}
+
+// GetKPIs 统计 [from, to) 内的运营指标，结果短时间缓存
+func (d *loanDashboardDao) GetKPIs(ctx context.Context, from time.Time, to time.Time) (*types.DashboardKPIs, error) {
+	// no cache
+	if d.cache == nil {
+		return d.queryKPIs(ctx, from, to)
+	}
+
+	key := from.Format(time.DateTime) + "_" + to.Format(time.DateTime)
+	record, err := d.cache.Get(ctx, key)
+	if err == nil {
+		return record, nil
+	}
+	if !errors.Is(err, database.ErrCacheNotFound) {
+		return nil, err
+	}
+
+	// for the same period, prevent high concurrent simultaneous access to database
+	val, err, _ := d.sfg.Do(key, func() (interface{}, error) { //nolint
+		kpis, err := d.queryKPIs(ctx, from, to)
+		if err != nil {
+			return nil, err
+		}
+		if err = d.cache.Set(ctx, key, kpis, cache.LoanDashboardExpireTime); err != nil {
+			logger.Warn("cache.Set error", logger.Err(err), logger.String("key", key))
+		}
+		return kpis, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	kpis, ok := val.(*types.DashboardKPIs)
+	if !ok {
+		return nil, database.ErrRecordNotFound
+	}
+	return kpis, nil
+}
+
+func (d *loanDashboardDao) queryKPIs(ctx context.Context, from time.Time, to time.Time) (*types.DashboardKPIs, error) {
+	db := d.db.WithContext(ctx)
+	k := &types.DashboardKPIs{}
+
+	// 1) 借款申请
+	err := db.Model(&model.LoanBaseinfo{}).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Count(&k.ApplicationsReceived).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 2) 初审/放款审核通过与拒绝：按审批节点代码统计，插入其它节点后 stage_no 会变化；
+	// 审批流程上线前的记录 stage_code 为空，按当时固定的节点序号(1初审 2放款审核)归类
+	var audits []struct {
+		StageCode   string `gorm:"column:stage_code"`
+		AuditResult int    `gorm:"column:audit_result"`
+		Cnt         int64  `gorm:"column:cnt"`
+	}
+	err = db.Model(&model.LoanAudits{}).
+		Select("COALESCE(NULLIF(stage_code, ''), CASE audit_type WHEN 1 THEN ? WHEN 2 THEN ? END) AS stage_code, audit_result, COUNT(*) AS cnt",
+			model.StageCodePreReview, model.StageCodeFinanceReview).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("1, audit_result").
+		Scan(&audits).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range audits {
+		switch {
+		case a.StageCode == model.StageCodePreReview && a.AuditResult == model.AuditResultPass:
+			k.PreReviewPassed = a.Cnt
+		case a.StageCode == model.StageCodePreReview && a.AuditResult == model.AuditResultReject:
+			k.PreReviewRejected = a.Cnt
+		case a.StageCode == model.StageCodeFinanceReview && a.AuditResult == model.AuditResultPass:
+			k.FinanceReviewPassed = a.Cnt
+		case a.StageCode == model.StageCodeFinanceReview && a.AuditResult == model.AuditResultReject:
+			k.FinanceReviewRejected = a.Cnt
+		}
+	}
+
+	// 3) 放款
+	var disbursed struct {
+		Cnt       int64 `gorm:"column:cnt"`
+		Amount    int64 `gorm:"column:amount"`
+		NetAmount int64 `gorm:"column:net_amount"`
+	}
+	err = db.Model(&model.LoanDisbursements{}).
+		Select("COUNT(*) AS cnt, COALESCE(SUM(disburse_amount), 0) AS amount, COALESCE(SUM(net_amount), 0) AS net_amount").
+		Where("status = ? AND disbursed_at >= ? AND disbursed_at < ?", model.DisbursementStatusSuccess, from, to).
+		Scan(&disbursed).Error
+	if err != nil {
+		return nil, err
+	}
+	k.DisbursedCount, k.DisbursedAmount, k.DisbursedNetAmount = disbursed.Cnt, disbursed.Amount, disbursed.NetAmount
+
+	// 4) 回款(冲正流水金额为负数，直接相加即为净回款)
+	var repaid struct {
+		Cnt    int64 `gorm:"column:cnt"`
+		Amount int64 `gorm:"column:amount"`
+	}
+	err = db.Model(&model.LoanRepaymentTransactions{}).
+		Select("COALESCE(SUM(CASE WHEN status = ? THEN 1 ELSE 0 END), 0) AS cnt, COALESCE(SUM(pay_amount), 0) AS amount", model.TransactionStatusSuccess).
+		Where("status IN ? AND paid_at >= ? AND paid_at < ?",
+			[]int{model.TransactionStatusSuccess, model.TransactionStatusReversal}, from, to).
+		Scan(&repaid).Error
+	if err != nil {
+		return nil, err
+	}
+	k.RepaymentCount, k.RepaymentAmount = repaid.Cnt, repaid.Amount
+
+	// 5) 新增逾期：应还日次日(开始逾期)落在区间内，且至今未结清或结清时已逾期
+	err = db.Model(&model.LoanRepaymentSchedules{}).
+		Where("due_date >= ? AND due_date < ?", from.AddDate(0, 0, -1), to.AddDate(0, 0, -1)).
+		Where("settled_at IS NULL OR DATE(settled_at) > due_date").
+		Count(&k.NewOverdueSchedules).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// 6) 完成的催收任务
+	err = db.Model(&model.LoanCollectionCases{}).
+		Where("status = ? AND completed_at >= ? AND completed_at < ?", model.CollectionCaseStatusCompleted, from, to).
+		Count(&k.CollectionsCompleted).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
@@ -0,0 +1,53 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"loan/internal/model"
+)
+
+var _ LoanDisbursementAttemptsDao = (*loanDisbursementAttemptsDao)(nil)
+
+// LoanDisbursementAttemptsDao defining the dao interface
+type LoanDisbursementAttemptsDao interface {
+	GetByDisbursementID(ctx context.Context, disbursementID uint64) ([]*model.LoanDisbursementAttempts, error)
+
+	InsertIgnoreByTx(ctx context.Context, tx *gorm.DB, table *model.LoanDisbursementAttempts) (bool, error)
+	UpdateByMerchantOrderNoByTx(ctx context.Context, tx *gorm.DB, merchantOrderNo string, update map[string]interface{}) error
+}
+
+// loanDisbursementAttemptsDao 提交记录只追加、按放款单查询，不使用缓存
+type loanDisbursementAttemptsDao struct {
+	db *gorm.DB
+}
+
+// NewLoanDisbursementAttemptsDao creating the dao interface
+func NewLoanDisbursementAttemptsDao(db *gorm.DB) LoanDisbursementAttemptsDao {
+	return &loanDisbursementAttemptsDao{db: db}
+}
+
+// GetByDisbursementID get all attempts of a disbursement, ordered by attempt no
+func (d *loanDisbursementAttemptsDao) GetByDisbursementID(ctx context.Context, disbursementID uint64) ([]*model.LoanDisbursementAttempts, error) {
+	records := []*model.LoanDisbursementAttempts{}
+	err := d.db.WithContext(ctx).Where("disbursement_id = ?", disbursementID).Order("attempt_no ASC").Find(&records).Error
+	return records, err
+}
+
+// InsertIgnoreByTx 写入提交记录，同一商户单号已存在时忽略(重新提交同一单号不新增记录)，返回是否实际写入
+func (d *loanDisbursementAttemptsDao) InsertIgnoreByTx(ctx context.Context, tx *gorm.DB, table *model.LoanDisbursementAttempts) (bool, error) {
+	result := tx.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(table)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UpdateByMerchantOrderNoByTx 按商户单号回写渠道结果
+func (d *loanDisbursementAttemptsDao) UpdateByMerchantOrderNoByTx(ctx context.Context, tx *gorm.DB, merchantOrderNo string, update map[string]interface{}) error {
+	return tx.WithContext(ctx).Model(&model.LoanDisbursementAttempts{}).
+		Where("merchant_order_no = ?", merchantOrderNo).
+		Updates(update).Error
+}
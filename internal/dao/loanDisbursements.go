@@ -39,6 +39,15 @@ type LoanDisbursementsDao interface {
 	UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.LoanDisbursements) error
 
 	GetOverviewList(ctx context.Context, req *types.BaseOverviewRequest) (*types.ListLoanDisbursementsOverviewResponse, error)
+
+	GetByStatus(ctx context.Context, status int, lastID uint64, limit int) ([]*model.LoanDisbursements, error)
+	TransitStatus(ctx context.Context, id uint64, fromStatus int, update map[string]interface{}) (bool, error)
+	TransitStatusByTx(ctx context.Context, tx *gorm.DB, id uint64, fromStatus int, update map[string]interface{}) (bool, error)
+	GetSettledByChannel(ctx context.Context, channelID uint64, from time.Time, to time.Time) ([]*model.LoanDisbursements, error)
+	GetDisbursedBetween(ctx context.Context, from time.Time, to time.Time) ([]*model.LoanDisbursements, error)
+	GetFirstDisbursedAt(ctx context.Context) (*time.Time, error)
+	SumPayoutVolumeSince(ctx context.Context, since time.Time) (map[uint64]int64, error)
+	GetProductIDByID(ctx context.Context, id uint64) (uint64, error)
 }
 
 type loanDisbursementsDao struct {
@@ -475,3 +484,107 @@ func (d *loanDisbursementsDao) UpdateByTx(ctx context.Context, tx *gorm.DB, tabl
 
 	return err
 }
+
+// GetByStatus 按状态分批查询放款单(id 升序)，用于异步代付任务
+func (d *loanDisbursementsDao) GetByStatus(ctx context.Context, status int, lastID uint64, limit int) ([]*model.LoanDisbursements, error) {
+	records := []*model.LoanDisbursements{}
+	err := d.db.WithContext(ctx).
+		Where("status = ? AND id > ?", status, lastID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&records).Error
+	return records, err
+}
+
+// TransitStatus 放款单状态流转：仅当当前状态为 fromStatus 时更新，返回是否更新成功(用于并发下抢占/防止重复流转)
+func (d *loanDisbursementsDao) TransitStatus(ctx context.Context, id uint64, fromStatus int, update map[string]interface{}) (bool, error) {
+	return d.TransitStatusByTx(ctx, d.db, id, fromStatus, update)
+}
+
+// TransitStatusByTx 同 TransitStatus，在给定事务中执行
+func (d *loanDisbursementsDao) TransitStatusByTx(ctx context.Context, tx *gorm.DB, id uint64, fromStatus int, update map[string]interface{}) (bool, error) {
+	result := tx.WithContext(ctx).Model(&model.LoanDisbursements{}).
+		Where("id = ? AND status = ?", id, fromStatus).
+		Updates(update)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return result.RowsAffected > 0, nil
+}
+
+// GetSettledByChannel 查询渠道在 [from, to) 内已放款的放款单，用于渠道结算对账
+func (d *loanDisbursementsDao) GetSettledByChannel(ctx context.Context, channelID uint64, from time.Time, to time.Time) ([]*model.LoanDisbursements, error) {
+	records := []*model.LoanDisbursements{}
+	err := d.db.WithContext(ctx).
+		Where("payout_channel_id = ? AND status = ? AND disbursed_at >= ? AND disbursed_at < ?",
+			channelID, model.DisbursementStatusSuccess, from, to).
+		Order("id ASC").
+		Find(&records).Error
+	return records, err
+}
+
+// SumPayoutVolumeSince 按代付渠道汇总 since 之后占用的放款额度(到账金额)：
+// 已放款按放款时间、已提交按提交时间、待放款/待重试按审核时间计入，失败/取消不占用额度
+func (d *loanDisbursementsDao) SumPayoutVolumeSince(ctx context.Context, since time.Time) (map[uint64]int64, error) {
+	type row struct {
+		PayoutChannelID uint64
+		Total           int64
+	}
+	rows := []row{}
+	err := d.db.WithContext(ctx).Model(&model.LoanDisbursements{}).
+		Select("payout_channel_id, SUM(net_amount) AS total").
+		Where("status IN ?", []int{
+			model.DisbursementStatusPending,
+			model.DisbursementStatusSuccess,
+			model.DisbursementStatusSubmitted,
+			model.DisbursementStatusRetrying,
+		}).
+		Where("COALESCE(disbursed_at, submitted_at, audited_at) >= ?", since).
+		Group("payout_channel_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	volumes := make(map[uint64]int64, len(rows))
+	for _, r := range rows {
+		volumes[r.PayoutChannelID] = r.Total
+	}
+	return volumes, nil
+}
+
+// GetProductIDByID 通过 放款单→借款申请 查询放款单所属产品，未关联产品返回 0
+func (d *loanDisbursementsDao) GetProductIDByID(ctx context.Context, id uint64) (uint64, error) {
+	var productID uint64
+	err := d.db.WithContext(ctx).
+		Table("loan_disbursements AS d").
+		Select("COALESCE(b.product_id, 0)").
+		Joins("JOIN loan_baseinfo AS b ON b.id = d.baseinfo_id").
+		Where("d.id = ?", id).
+		Scan(&productID).Error
+	return productID, err
+}
+
+// GetDisbursedBetween 查询放款时间在 [from, to) 内的已放款单，用于放款批次(vintage)统计
+func (d *loanDisbursementsDao) GetDisbursedBetween(ctx context.Context, from time.Time, to time.Time) ([]*model.LoanDisbursements, error) {
+	records := []*model.LoanDisbursements{}
+	err := d.db.WithContext(ctx).
+		Select("id, disburse_amount, disbursed_at").
+		Where("status = ? AND disbursed_at >= ? AND disbursed_at < ?", model.DisbursementStatusSuccess, from, to).
+		Order("id ASC").
+		Find(&records).Error
+	return records, err
+}
+
+// GetFirstDisbursedAt 最早的放款时间，没有已放款单时返回 nil
+func (d *loanDisbursementsDao) GetFirstDisbursedAt(ctx context.Context) (*time.Time, error) {
+	var first *time.Time
+	err := d.db.WithContext(ctx).Model(&model.LoanDisbursements{}).
+		Select("MIN(disbursed_at)").
+		Where("status = ?", model.DisbursementStatusSuccess).
+		Scan(&first).Error
+	return first, err
+}
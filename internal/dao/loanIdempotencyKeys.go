@@ -0,0 +1,78 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"loan/internal/model"
+)
+
+var _ LoanIdempotencyKeysDao = (*loanIdempotencyKeysDao)(nil)
+
+// LoanIdempotencyKeysDao defining the dao interface
+type LoanIdempotencyKeysDao interface {
+	Acquire(ctx context.Context, table *model.LoanIdempotencyKeys) (bool, error)
+	GetByKey(ctx context.Context, userID uint64, scope string, idemKey string) (*model.LoanIdempotencyKeys, error)
+	Complete(ctx context.Context, id uint64, httpStatus int, body string) error
+	Release(ctx context.Context, id uint64) error
+	DeleteExpired(ctx context.Context, now time.Time, limit int) (int64, error)
+}
+
+// loanIdempotencyKeysDao 幂等键依赖唯一索引做并发控制，不使用缓存
+type loanIdempotencyKeysDao struct {
+	db *gorm.DB
+}
+
+// NewLoanIdempotencyKeysDao creating the dao interface
+func NewLoanIdempotencyKeysDao(db *gorm.DB) LoanIdempotencyKeysDao {
+	return &loanIdempotencyKeysDao{db: db}
+}
+
+// Acquire 占用幂等键，依赖 uk_user_scope_key 唯一索引，已被占用时返回 false
+func (d *loanIdempotencyKeysDao) Acquire(ctx context.Context, table *model.LoanIdempotencyKeys) (bool, error) {
+	result := d.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(table)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetByKey get a record by user, scope and key
+func (d *loanIdempotencyKeysDao) GetByKey(ctx context.Context, userID uint64, scope string, idemKey string) (*model.LoanIdempotencyKeys, error) {
+	record := &model.LoanIdempotencyKeys{}
+	err := d.db.WithContext(ctx).
+		Where("user_id = ? AND scope = ? AND idem_key = ?", userID, scope, idemKey).
+		First(record).Error
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Complete 保存首次响应，之后同键请求直接回放
+func (d *loanIdempotencyKeysDao) Complete(ctx context.Context, id uint64, httpStatus int, body string) error {
+	return d.db.WithContext(ctx).Model(&model.LoanIdempotencyKeys{}).
+		Where("id = ? AND status = ?", id, model.IdempotencyStatusProcessing).
+		Updates(map[string]interface{}{
+			"status":        model.IdempotencyStatusCompleted,
+			"http_status":   httpStatus,
+			"response_body": body,
+		}).Error
+}
+
+// Release 释放幂等键(物理删除)，请求失败后允许客户端用同一个键重试
+func (d *loanIdempotencyKeysDao) Release(ctx context.Context, id uint64) error {
+	return d.db.WithContext(ctx).Unscoped().Where("id = ?", id).Delete(&model.LoanIdempotencyKeys{}).Error
+}
+
+// DeleteExpired 分批物理删除已过期的幂等键，返回本批删除条数
+func (d *loanIdempotencyKeysDao) DeleteExpired(ctx context.Context, now time.Time, limit int) (int64, error) {
+	result := d.db.WithContext(ctx).Unscoped().
+		Where("expires_at < ?", now).
+		Limit(limit).
+		Delete(&model.LoanIdempotencyKeys{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,87 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+	"gorm.io/gorm"
+
+	"loan/internal/model"
+)
+
+var _ LoanLedgerEntriesDao = (*loanLedgerEntriesDao)(nil)
+
+// LoanLedgerEntriesDao defining the dao interface
+type LoanLedgerEntriesDao interface {
+	GetByColumns(ctx context.Context, params *query.Params) ([]*model.LoanLedgerEntries, int64, error)
+	SumByAccount(ctx context.Context, from *time.Time, to *time.Time) ([]*model.LoanLedgerAccountSums, error)
+
+	CreateByTx(ctx context.Context, tx *gorm.DB, entries []*model.LoanLedgerEntries) error
+}
+
+// loanLedgerEntriesDao 分录只追加、不提供修改/删除，不使用缓存
+type loanLedgerEntriesDao struct {
+	db *gorm.DB
+}
+
+// NewLoanLedgerEntriesDao creating the dao interface
+func NewLoanLedgerEntriesDao(db *gorm.DB) LoanLedgerEntriesDao {
+	return &loanLedgerEntriesDao{db: db}
+}
+
+// GetByColumns get a paginated list of ledger entries by custom conditions.
+// For more details, please refer to https://go-sponge.com/component/data/custom-page-query.html
+func (d *loanLedgerEntriesDao) GetByColumns(ctx context.Context, params *query.Params) ([]*model.LoanLedgerEntries, int64, error) {
+	queryStr, args, err := params.ConvertToGormConditions(query.WithWhitelistNames(model.LoanLedgerEntriesColumnNames))
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+
+	var total int64
+	if params.Sort != "ignore count" { // determine if count is required
+		err = d.db.WithContext(ctx).Model(&model.LoanLedgerEntries{}).Where(queryStr, args...).Count(&total).Error
+		if err != nil {
+			return nil, 0, err
+		}
+		if total == 0 {
+			return nil, total, nil
+		}
+	}
+
+	records := []*model.LoanLedgerEntries{}
+	order, limit, offset := params.ConvertToPage()
+	err = d.db.WithContext(ctx).Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, err
+}
+
+// SumByAccount 按科目汇总 posted_at 在 [from, to) 内的借贷发生额，from/to 为空表示不限
+func (d *loanLedgerEntriesDao) SumByAccount(ctx context.Context, from *time.Time, to *time.Time) ([]*model.LoanLedgerAccountSums, error) {
+	db := d.db.WithContext(ctx).Model(&model.LoanLedgerEntries{})
+	if from != nil {
+		db = db.Where("posted_at >= ?", *from)
+	}
+	if to != nil {
+		db = db.Where("posted_at < ?", *to)
+	}
+
+	sums := []*model.LoanLedgerAccountSums{}
+	err := db.Select("account, COALESCE(SUM(debit), 0) AS debit, COALESCE(SUM(credit), 0) AS credit").
+		Group("account").
+		Order("account ASC").
+		Scan(&sums).Error
+	return sums, err
+}
+
+// CreateByTx 在业务事务内写入一张凭证的全部分录
+func (d *loanLedgerEntriesDao) CreateByTx(ctx context.Context, tx *gorm.DB, entries []*model.LoanLedgerEntries) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return tx.WithContext(ctx).Create(entries).Error
+}
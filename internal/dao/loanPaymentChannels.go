@@ -31,6 +31,8 @@ type LoanPaymentChannelsDao interface {
 	GetByCondition(ctx context.Context, condition *query.Conditions) (*model.LoanPaymentChannels, error)
 	GetByIDs(ctx context.Context, ids []uint64) (map[uint64]*model.LoanPaymentChannels, error)
 	GetByLastID(ctx context.Context, lastID uint64, limit int, sort string) ([]*model.LoanPaymentChannels, error)
+	GetByCode(ctx context.Context, code string) (*model.LoanPaymentChannels, error)
+	GetPayoutEnabled(ctx context.Context) ([]*model.LoanPaymentChannels, error)
 
 	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.LoanPaymentChannels) (uint64, error)
 	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
@@ -145,6 +147,18 @@ func (d *loanPaymentChannelsDao) updateDataByID(ctx context.Context, db *gorm.DB
 	if table.SettlementDesc != "" {
 		update["settlement_desc"] = table.SettlementDesc
 	}
+	if len(table.CallbackKeyEnc) > 0 {
+		update["callback_key_enc"] = table.CallbackKeyEnc
+	}
+	if table.RoutePriority != 0 {
+		update["route_priority"] = table.RoutePriority
+	}
+	if table.RouteWeight != 0 {
+		update["route_weight"] = table.RouteWeight
+	}
+	if table.PayoutDailyCap != 0 {
+		update["payout_daily_cap"] = table.PayoutDailyCap
+	}
 
 	return db.WithContext(ctx).Model(table).Updates(update).Error
 }
@@ -350,6 +364,26 @@ func (d *loanPaymentChannelsDao) GetByLastID(ctx context.Context, lastID uint64,
 	return records, nil
 }
 
+// GetByCode get a loanPaymentChannels by channel code, return database.ErrRecordNotFound if not exists
+func (d *loanPaymentChannelsDao) GetByCode(ctx context.Context, code string) (*model.LoanPaymentChannels, error) {
+	record := &model.LoanPaymentChannels{}
+	err := d.db.WithContext(ctx).Where("code = ?", code).First(record).Error
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// GetPayoutEnabled 查询启用且支持代付的渠道，按路由优先级排序，用于自动路由
+func (d *loanPaymentChannelsDao) GetPayoutEnabled(ctx context.Context) ([]*model.LoanPaymentChannels, error) {
+	records := []*model.LoanPaymentChannels{}
+	err := d.db.WithContext(ctx).
+		Where("status = ? AND can_payout = ?", 1, 1).
+		Order("route_priority ASC, id ASC").
+		Find(&records).Error
+	return records, err
+}
+
 // CreateByTx create a record in the database using the provided transaction
 func (d *loanPaymentChannelsDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.LoanPaymentChannels) (uint64, error) {
 	err := tx.WithContext(ctx).Create(table).Error
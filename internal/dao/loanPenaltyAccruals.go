@@ -0,0 +1,68 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"loan/internal/model"
+)
+
+var _ LoanPenaltyAccrualsDao = (*loanPenaltyAccrualsDao)(nil)
+
+// LoanPenaltyAccrualsDao defining the dao interface
+type LoanPenaltyAccrualsDao interface {
+	GetByScheduleID(ctx context.Context, scheduleID uint64) ([]*model.LoanPenaltyAccruals, error)
+	SumAmountByScheduleID(ctx context.Context, scheduleID uint64) (int64, error)
+
+	GetLastAccrualDateByTx(ctx context.Context, tx *gorm.DB, scheduleID uint64) (*time.Time, error)
+	InsertIgnoreByTx(ctx context.Context, tx *gorm.DB, table *model.LoanPenaltyAccruals) (bool, error)
+}
+
+// loanPenaltyAccrualsDao 计提流水只追加、按期次查询，不使用缓存
+type loanPenaltyAccrualsDao struct {
+	db *gorm.DB
+}
+
+// NewLoanPenaltyAccrualsDao creating the dao interface
+func NewLoanPenaltyAccrualsDao(db *gorm.DB) LoanPenaltyAccrualsDao {
+	return &loanPenaltyAccrualsDao{db: db}
+}
+
+// GetByScheduleID get all accruals of a schedule, ordered by accrual date
+func (d *loanPenaltyAccrualsDao) GetByScheduleID(ctx context.Context, scheduleID uint64) ([]*model.LoanPenaltyAccruals, error) {
+	records := []*model.LoanPenaltyAccruals{}
+	err := d.db.WithContext(ctx).Where("schedule_id = ?", scheduleID).Order("accrual_date ASC").Find(&records).Error
+	return records, err
+}
+
+// SumAmountByScheduleID 汇总期次累计计提的罚息，用于核对/重算 penalty_due
+func (d *loanPenaltyAccrualsDao) SumAmountByScheduleID(ctx context.Context, scheduleID uint64) (int64, error) {
+	var total int64
+	err := d.db.WithContext(ctx).Model(&model.LoanPenaltyAccruals{}).
+		Where("schedule_id = ?", scheduleID).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// GetLastAccrualDateByTx 期次最近一次计提的日期，没有计提流水时返回 nil
+func (d *loanPenaltyAccrualsDao) GetLastAccrualDateByTx(ctx context.Context, tx *gorm.DB, scheduleID uint64) (*time.Time, error) {
+	records := []*model.LoanPenaltyAccruals{}
+	err := tx.WithContext(ctx).Where("schedule_id = ?", scheduleID).Order("accrual_date DESC").Limit(1).Find(&records).Error
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+	return records[0].AccrualDate, nil
+}
+
+// InsertIgnoreByTx 写入计提流水，同一期次同一计提日已存在时忽略，返回是否实际写入
+func (d *loanPenaltyAccrualsDao) InsertIgnoreByTx(ctx context.Context, tx *gorm.DB, table *model.LoanPenaltyAccruals) (bool, error) {
+	result := tx.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(table)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
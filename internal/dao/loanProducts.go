@@ -0,0 +1,282 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"loan/internal/cache"
+	"loan/internal/database"
+	"loan/internal/model"
+)
+
+var _ LoanProductsDao = (*loanProductsDao)(nil)
+
+// LoanProductsDao defining the dao interface
+type LoanProductsDao interface {
+	Create(ctx context.Context, table *model.LoanProducts) error
+	DeleteByID(ctx context.Context, id uint64) error
+	UpdateByID(ctx context.Context, table *model.LoanProducts) error
+	GetByID(ctx context.Context, id uint64) (*model.LoanProducts, error)
+	GetByColumns(ctx context.Context, params *query.Params) ([]*model.LoanProducts, int64, error)
+	GetByCode(ctx context.Context, code string) (*model.LoanProducts, error)
+
+	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.LoanProducts) (uint64, error)
+	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
+	UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.LoanProducts) error
+}
+
+type loanProductsDao struct {
+	db    *gorm.DB
+	cache cache.LoanProductsCache // if nil, the cache is not used.
+	sfg   *singleflight.Group     // if cache is nil, the sfg is not used.
+}
+
+// NewLoanProductsDao creating the dao interface
+func NewLoanProductsDao(db *gorm.DB, xCache cache.LoanProductsCache) LoanProductsDao {
+	if xCache == nil {
+		return &loanProductsDao{db: db}
+	}
+	return &loanProductsDao{
+		db:    db,
+		cache: xCache,
+		sfg:   new(singleflight.Group),
+	}
+}
+
+func (d *loanProductsDao) deleteCache(ctx context.Context, id uint64) error {
+	if d.cache != nil {
+		return d.cache.Del(ctx, id)
+	}
+	return nil
+}
+
+// Create a new loanProducts, insert the record and the id value is written back to the table
+func (d *loanProductsDao) Create(ctx context.Context, table *model.LoanProducts) error {
+	return d.db.WithContext(ctx).Create(table).Error
+}
+
+// DeleteByID delete a loanProducts by id
+func (d *loanProductsDao) DeleteByID(ctx context.Context, id uint64) error {
+	err := d.db.WithContext(ctx).Where("id = ?", id).Delete(&model.LoanProducts{}).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
+
+// UpdateByID update a loanProducts by id, support partial update
+func (d *loanProductsDao) UpdateByID(ctx context.Context, table *model.LoanProducts) error {
+	err := d.updateDataByID(ctx, d.db, table)
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
+
+func (d *loanProductsDao) updateDataByID(ctx context.Context, db *gorm.DB, table *model.LoanProducts) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+
+	update := map[string]interface{}{}
+
+	if table.Code != "" {
+		update["code"] = table.Code
+	}
+	if table.Name != "" {
+		update["name"] = table.Name
+	}
+	if table.Status != 0 {
+		update["status"] = table.Status
+	}
+	if table.RepaymentMethod != "" {
+		update["repayment_method"] = table.RepaymentMethod
+	}
+	if table.InstallmentCount != 0 {
+		update["installment_count"] = table.InstallmentCount
+	}
+	if table.InstallmentDays != 0 {
+		update["installment_days"] = table.InstallmentDays
+	}
+	if table.InterestRateBp != 0 {
+		update["interest_rate_bp"] = table.InterestRateBp
+	}
+	if table.FeeModel != "" {
+		update["fee_model"] = table.FeeModel
+	}
+	if table.ServiceFeeBp != 0 {
+		update["service_fee_bp"] = table.ServiceFeeBp
+	}
+	if table.ServiceFeeFixed != 0 {
+		update["service_fee_fixed"] = table.ServiceFeeFixed
+	}
+	if table.MinAmount != 0 {
+		update["min_amount"] = table.MinAmount
+	}
+	if table.MaxAmount != 0 {
+		update["max_amount"] = table.MaxAmount
+	}
+	if table.AllowedDays != "" {
+		update["allowed_days"] = table.AllowedDays
+	}
+	if table.PenaltyDailyRateBp != 0 {
+		update["penalty_daily_rate_bp"] = table.PenaltyDailyRateBp
+	}
+	if table.AllocationOrder != "" {
+		update["allocation_order"] = table.AllocationOrder
+	}
+	if table.PayoffFeeBp != 0 {
+		update["payoff_fee_bp"] = table.PayoffFeeBp
+	}
+	if table.PayoffFeeFixed != 0 {
+		update["payoff_fee_fixed"] = table.PayoffFeeFixed
+	}
+	if table.PayoffDiscountBp != 0 {
+		update["payoff_discount_bp"] = table.PayoffDiscountBp
+	}
+	if table.ActiveFrom != nil {
+		update["active_from"] = table.ActiveFrom
+	}
+	if table.ActiveTo != nil {
+		update["active_to"] = table.ActiveTo
+	}
+	if table.Remark != "" {
+		update["remark"] = table.Remark
+	}
+
+	return db.WithContext(ctx).Model(table).Updates(update).Error
+}
+
+// GetByID get a loanProducts by id
+func (d *loanProductsDao) GetByID(ctx context.Context, id uint64) (*model.LoanProducts, error) {
+	// no cache
+	if d.cache == nil {
+		record := &model.LoanProducts{}
+		err := d.db.WithContext(ctx).Where("id = ?", id).First(record).Error
+		return record, err
+	}
+
+	// get from cache
+	record, err := d.cache.Get(ctx, id)
+	if err == nil {
+		return record, nil
+	}
+
+	// get from database
+	if errors.Is(err, database.ErrCacheNotFound) {
+		// for the same id, prevent high concurrent simultaneous access to database
+		val, err, _ := d.sfg.Do(utils.Uint64ToStr(id), func() (interface{}, error) { //nolint
+			table := &model.LoanProducts{}
+			err = d.db.WithContext(ctx).Where("id = ?", id).First(table).Error
+			if err != nil {
+				if errors.Is(err, database.ErrRecordNotFound) {
+					// set placeholder cache to prevent cache penetration, default expiration time 10 minutes
+					if err = d.cache.SetPlaceholder(ctx, id); err != nil {
+						logger.Warn("cache.SetPlaceholder error", logger.Err(err), logger.Any("id", id))
+					}
+					return nil, database.ErrRecordNotFound
+				}
+				return nil, err
+			}
+			// set cache
+			if err = d.cache.Set(ctx, id, table, cache.LoanProductsExpireTime); err != nil {
+				logger.Warn("cache.Set error", logger.Err(err), logger.Any("id", id))
+			}
+			return table, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		table, ok := val.(*model.LoanProducts)
+		if !ok {
+			return nil, database.ErrRecordNotFound
+		}
+		return table, nil
+	}
+
+	if d.cache.IsPlaceholderErr(err) {
+		return nil, database.ErrRecordNotFound
+	}
+
+	return nil, err
+}
+
+// GetByColumns get a paginated list of loanProductss by custom conditions.
+// For more details, please refer to https://go-sponge.com/component/custom-page-query.html
+func (d *loanProductsDao) GetByColumns(ctx context.Context, params *query.Params) ([]*model.LoanProducts, int64, error) {
+	queryStr, args, err := params.ConvertToGormConditions(query.WithWhitelistNames(model.LoanProductsColumnNames))
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+
+	var total int64
+	if params.Sort != "ignore count" { // determine if count is required
+		err = d.db.WithContext(ctx).Model(&model.LoanProducts{}).Where(queryStr, args...).Count(&total).Error
+		if err != nil {
+			return nil, 0, err
+		}
+		if total == 0 {
+			return nil, total, nil
+		}
+	}
+
+	records := []*model.LoanProducts{}
+	order, limit, offset := params.ConvertToPage()
+	err = d.db.WithContext(ctx).Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, err
+}
+
+// GetByCode get a loanProducts by code
+func (d *loanProductsDao) GetByCode(ctx context.Context, code string) (*model.LoanProducts, error) {
+	record := &model.LoanProducts{}
+	err := d.db.WithContext(ctx).Where("code = ?", code).First(record).Error
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// CreateByTx create a record in the database using the provided transaction
+func (d *loanProductsDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.LoanProducts) (uint64, error) {
+	err := tx.WithContext(ctx).Create(table).Error
+	return table.ID, err
+}
+
+// DeleteByTx delete a record by id in the database using the provided transaction
+func (d *loanProductsDao) DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error {
+	err := tx.WithContext(ctx).Where("id = ?", id).Delete(&model.LoanProducts{}).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
+
+// UpdateByTx update a record by id in the database using the provided transaction
+func (d *loanProductsDao) UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.LoanProducts) error {
+	err := d.updateDataByID(ctx, tx, table)
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
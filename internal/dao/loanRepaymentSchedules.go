@@ -9,6 +9,7 @@ import (
 
 	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/go-dev-frame/sponge/pkg/logger"
 	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
@@ -37,6 +38,21 @@ type LoanRepaymentSchedulesDao interface {
 	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.LoanRepaymentSchedules) (uint64, error)
 	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
 	UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.LoanRepaymentSchedules) error
+	GetByIDForUpdate(ctx context.Context, tx *gorm.DB, id uint64) (*model.LoanRepaymentSchedules, error)
+	UpdateRepaymentByTx(ctx context.Context, tx *gorm.DB, table *model.LoanRepaymentSchedules) error
+	MarkOverdue(ctx context.Context, today time.Time, limit int) (int64, error)
+	GetOverdueIDs(ctx context.Context, lastID uint64, limit int) ([]uint64, error)
+	AccruePenaltyByTx(ctx context.Context, tx *gorm.DB, id uint64, amount int64) error
+	GetProductIDByScheduleID(ctx context.Context, id uint64) (uint64, error)
+	CountByDisbursementIDByTx(ctx context.Context, tx *gorm.DB, disbursementID uint64) (int64, error)
+	GetByDisbursementID(ctx context.Context, disbursementID uint64) ([]*model.LoanRepaymentSchedules, error)
+	GetByDisbursementIDs(ctx context.Context, disbursementIDs []uint64) ([]*model.LoanRepaymentSchedules, error)
+	GetByDisbursementIDForUpdate(ctx context.Context, tx *gorm.DB, disbursementID uint64) ([]*model.LoanRepaymentSchedules, error)
+	UpdatePayoffByTx(ctx context.Context, tx *gorm.DB, table *model.LoanRepaymentSchedules) error
+	UpdateExtensionByTx(ctx context.Context, tx *gorm.DB, table *model.LoanRepaymentSchedules) error
+	UpdateWaiverByTx(ctx context.Context, tx *gorm.DB, table *model.LoanRepaymentSchedules) error
+	UpdatePenaltyByTx(ctx context.Context, tx *gorm.DB, table *model.LoanRepaymentSchedules) error
+	GetAgingLoans(ctx context.Context, req *types.AgingReportRequest, disbursedFrom *time.Time, disbursedTo *time.Time) ([]*types.AgingLoanRow, error)
 
 	Overview(
 		ctx context.Context,
@@ -512,3 +528,287 @@ func (d *loanRepaymentSchedulesDao) UpdateByTx(ctx context.Context, tx *gorm.DB,
 
 	return err
 }
+
+// GetByIDForUpdate get a record by id and lock the row (SELECT ... FOR UPDATE) in the provided transaction
+func (d *loanRepaymentSchedulesDao) GetByIDForUpdate(ctx context.Context, tx *gorm.DB, id uint64) (*model.LoanRepaymentSchedules, error) {
+	record := &model.LoanRepaymentSchedules{}
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(record).Error
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// UpdateRepaymentByTx 更新已还各科目金额及期次状态，与 UpdateByTx 不同，零值也会写入
+func (d *loanRepaymentSchedulesDao) UpdateRepaymentByTx(ctx context.Context, tx *gorm.DB, table *model.LoanRepaymentSchedules) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+
+	update := map[string]interface{}{
+		"paid_principal": table.PaidPrincipal,
+		"paid_interest":  table.PaidInterest,
+		"paid_fee":       table.PaidFee,
+		"paid_penalty":   table.PaidPenalty,
+		"paid_total":     table.PaidTotal,
+		"status":         table.Status,
+		"last_paid_at":   table.LastPaidAt,
+		"settled_at":     table.SettledAt,
+	}
+	err := tx.WithContext(ctx).Model(&model.LoanRepaymentSchedules{}).Where("id = ?", table.ID).Updates(update).Error
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
+
+// MarkOverdue 把应还日期早于 today 且未还清的期次标记为逾期，每次最多处理 limit 条，返回实际更新条数
+func (d *loanRepaymentSchedulesDao) MarkOverdue(ctx context.Context, today time.Time, limit int) (int64, error) {
+	var ids []uint64
+	err := d.db.WithContext(ctx).Model(&model.LoanRepaymentSchedules{}).
+		Where("status = ? AND due_date < ?", model.ScheduleStatusUnpaid, today.Format("2006-01-02")).
+		Order("id ASC").Limit(limit).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	result := d.db.WithContext(ctx).Model(&model.LoanRepaymentSchedules{}).
+		Where("id IN ? AND status = ?", ids, model.ScheduleStatusUnpaid).
+		Update("status", model.ScheduleStatusOverdue)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	// delete cache
+	for _, id := range ids {
+		_ = d.deleteCache(ctx, id)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// GetOverdueIDs 按 id 游标分页获取逾期期次 id
+func (d *loanRepaymentSchedulesDao) GetOverdueIDs(ctx context.Context, lastID uint64, limit int) ([]uint64, error) {
+	var ids []uint64
+	err := d.db.WithContext(ctx).Model(&model.LoanRepaymentSchedules{}).
+		Where("status = ? AND id > ?", model.ScheduleStatusOverdue, lastID).
+		Order("id ASC").Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// AccruePenaltyByTx 累加应还罚息，应还总额同步增加
+func (d *loanRepaymentSchedulesDao) AccruePenaltyByTx(ctx context.Context, tx *gorm.DB, id uint64, amount int64) error {
+	err := tx.WithContext(ctx).Model(&model.LoanRepaymentSchedules{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"penalty_due": gorm.Expr("penalty_due + ?", amount),
+			"total_due":   gorm.Expr("total_due + ?", amount),
+		}).Error
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return err
+}
+
+// GetProductIDByScheduleID 通过 期次→放款单→借款申请 查询期次所属产品，未关联产品返回 0
+func (d *loanRepaymentSchedulesDao) GetProductIDByScheduleID(ctx context.Context, id uint64) (uint64, error) {
+	var productID uint64
+	err := d.db.WithContext(ctx).
+		Table("loan_repayment_schedules AS s").
+		Select("COALESCE(b.product_id, 0)").
+		Joins("JOIN loan_disbursements AS d ON d.id = s.disbursement_id").
+		Joins("JOIN loan_baseinfo AS b ON b.id = d.baseinfo_id").
+		Where("s.id = ?", id).
+		Scan(&productID).Error
+	return productID, err
+}
+
+// CountByDisbursementIDByTx 统计放款单已生成的期次数，用于放款成功时避免重复生成还款计划
+func (d *loanRepaymentSchedulesDao) CountByDisbursementIDByTx(ctx context.Context, tx *gorm.DB, disbursementID uint64) (int64, error) {
+	var total int64
+	err := tx.WithContext(ctx).Model(&model.LoanRepaymentSchedules{}).
+		Where("disbursement_id = ?", disbursementID).
+		Count(&total).Error
+	return total, err
+}
+
+// GetByDisbursementID 按期次顺序获取放款单的全部期次
+func (d *loanRepaymentSchedulesDao) GetByDisbursementID(ctx context.Context, disbursementID uint64) ([]*model.LoanRepaymentSchedules, error) {
+	records := []*model.LoanRepaymentSchedules{}
+	err := d.db.WithContext(ctx).
+		Where("disbursement_id = ?", disbursementID).
+		Order("installment_no ASC").
+		Find(&records).Error
+	return records, err
+}
+
+// GetByDisbursementIDForUpdate 按期次顺序获取并锁定放款单的全部期次(SELECT ... FOR UPDATE)
+func (d *loanRepaymentSchedulesDao) GetByDisbursementIDForUpdate(ctx context.Context, tx *gorm.DB, disbursementID uint64) ([]*model.LoanRepaymentSchedules, error) {
+	records := []*model.LoanRepaymentSchedules{}
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("disbursement_id = ?", disbursementID).
+		Order("installment_no ASC").
+		Find(&records).Error
+	return records, err
+}
+
+// UpdatePayoffByTx 提前结清：更新调整后的应还利息/费用/总额及已还科目、状态，零值也会写入
+func (d *loanRepaymentSchedulesDao) UpdatePayoffByTx(ctx context.Context, tx *gorm.DB, table *model.LoanRepaymentSchedules) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+
+	update := map[string]interface{}{
+		"interest_due":   table.InterestDue,
+		"fee_due":        table.FeeDue,
+		"total_due":      table.TotalDue,
+		"paid_principal": table.PaidPrincipal,
+		"paid_interest":  table.PaidInterest,
+		"paid_fee":       table.PaidFee,
+		"paid_penalty":   table.PaidPenalty,
+		"paid_total":     table.PaidTotal,
+		"status":         table.Status,
+		"last_paid_at":   table.LastPaidAt,
+		"settled_at":     table.SettledAt,
+	}
+	err := tx.WithContext(ctx).Model(&model.LoanRepaymentSchedules{}).Where("id = ?", table.ID).Updates(update).Error
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
+
+// UpdateExtensionByTx 展期：更新应还日期、应还费用/罚息/总额及已还科目、状态，零值也会写入
+func (d *loanRepaymentSchedulesDao) UpdateExtensionByTx(ctx context.Context, tx *gorm.DB, table *model.LoanRepaymentSchedules) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+
+	update := map[string]interface{}{
+		"due_date":     table.DueDate,
+		"fee_due":      table.FeeDue,
+		"penalty_due":  table.PenaltyDue,
+		"total_due":    table.TotalDue,
+		"paid_fee":     table.PaidFee,
+		"paid_total":   table.PaidTotal,
+		"status":       table.Status,
+		"last_paid_at": table.LastPaidAt,
+		"settled_at":   table.SettledAt,
+	}
+	err := tx.WithContext(ctx).Model(&model.LoanRepaymentSchedules{}).Where("id = ?", table.ID).Updates(update).Error
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
+
+// UpdateWaiverByTx 减免：更新应还罚息/费用、应还总额及状态，零值也会写入
+func (d *loanRepaymentSchedulesDao) UpdateWaiverByTx(ctx context.Context, tx *gorm.DB, table *model.LoanRepaymentSchedules) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+
+	update := map[string]interface{}{
+		"fee_due":     table.FeeDue,
+		"penalty_due": table.PenaltyDue,
+		"total_due":   table.TotalDue,
+		"status":      table.Status,
+		"settled_at":  table.SettledAt,
+	}
+	err := tx.WithContext(ctx).Model(&model.LoanRepaymentSchedules{}).Where("id = ?", table.ID).Updates(update).Error
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
+
+// UpdatePenaltyByTx 重算罚息：更新应还罚息、应还总额及状态，零值也会写入
+func (d *loanRepaymentSchedulesDao) UpdatePenaltyByTx(ctx context.Context, tx *gorm.DB, table *model.LoanRepaymentSchedules) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+
+	update := map[string]interface{}{
+		"penalty_due": table.PenaltyDue,
+		"total_due":   table.TotalDue,
+		"status":      table.Status,
+		"settled_at":  table.SettledAt,
+	}
+	err := tx.WithContext(ctx).Model(&model.LoanRepaymentSchedules{}).Where("id = ?", table.ID).Updates(update).Error
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
+
+// GetAgingLoans 账龄统计：按放款单汇总未结清期次的剩余本金及最早应还日期，只统计已放款的借款。
+// disbursedFrom/disbursedTo 为放款时间区间 [from, to)，为空表示不限；催收人员按任一期次的催收任务过滤
+func (d *loanRepaymentSchedulesDao) GetAgingLoans(ctx context.Context, req *types.AgingReportRequest, disbursedFrom *time.Time, disbursedTo *time.Time) ([]*types.AgingLoanRow, error) {
+	whereConditions := []string{
+		"s.deleted_at IS NULL",
+		"d.deleted_at IS NULL",
+		"s.status <> ?",
+		"d.status = ?",
+	}
+	whereArgs := []interface{}{model.ScheduleStatusSettled, model.DisbursementStatusSuccess}
+
+	if disbursedFrom != nil {
+		whereConditions = append(whereConditions, "d.disbursed_at >= ?")
+		whereArgs = append(whereArgs, *disbursedFrom)
+	}
+	if disbursedTo != nil {
+		whereConditions = append(whereConditions, "d.disbursed_at < ?")
+		whereArgs = append(whereArgs, *disbursedTo)
+	}
+	if req.ChannelID > 0 {
+		whereConditions = append(whereConditions, "d.payout_channel_id = ?")
+		whereArgs = append(whereArgs, req.ChannelID)
+	}
+	if req.ReferrerUserID > 0 {
+		whereConditions = append(whereConditions, "d.source_referrer_user_id = ?")
+		whereArgs = append(whereArgs, req.ReferrerUserID)
+	}
+	if req.CollectorUserID > 0 {
+		whereConditions = append(whereConditions, `EXISTS (
+            SELECT 1 FROM loan_collection_cases cc
+            INNER JOIN loan_repayment_schedules cs ON cc.schedule_id = cs.id
+            WHERE cs.disbursement_id = d.id AND cc.collector_user_id = ? AND cc.deleted_at IS NULL
+        )`)
+		whereArgs = append(whereArgs, req.CollectorUserID)
+	}
+
+	querySQL := `
+        SELECT
+            s.disbursement_id,
+            SUM(GREATEST(s.principal_due - s.paid_principal, 0)) AS outstanding_principal,
+            MIN(s.due_date) AS oldest_due_date
+        FROM
+            loan_repayment_schedules s
+            INNER JOIN loan_disbursements d ON s.disbursement_id = d.id
+        WHERE ` + strings.Join(whereConditions, " AND ") + `
+        GROUP BY s.disbursement_id
+    `
+	rows := []*types.AgingLoanRow{}
+	err := d.db.WithContext(ctx).Raw(querySQL, whereArgs...).Scan(&rows).Error
+	return rows, err
+}
+
+// GetByDisbursementIDs 批量查询放款单的全部期次，按放款单、期次排序
+func (d *loanRepaymentSchedulesDao) GetByDisbursementIDs(ctx context.Context, disbursementIDs []uint64) ([]*model.LoanRepaymentSchedules, error) {
+	records := []*model.LoanRepaymentSchedules{}
+	if len(disbursementIDs) == 0 {
+		return records, nil
+	}
+	err := d.db.WithContext(ctx).
+		Where("disbursement_id IN ?", disbursementIDs).
+		Order("disbursement_id ASC, installment_no ASC").
+		Find(&records).Error
+	return records, err
+}
@@ -8,6 +8,7 @@ import (
 
 	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/go-dev-frame/sponge/pkg/logger"
 	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
@@ -39,6 +40,11 @@ type LoanRepaymentTransactionsDao interface {
 
 	DetailByScheduleID(ctx context.Context, id uint64) (*types.RepaymentScheduleDetail, error)
 	GetByScheduleID(ctx context.Context, id uint64) ([]*types.LoanRepaymentTransactionsHistory, error)
+	GetByIDForUpdate(ctx context.Context, tx *gorm.DB, id uint64) (*model.LoanRepaymentTransactions, error)
+	GetByChannelPayRef(ctx context.Context, tx *gorm.DB, channelID int64, payRef string) (*model.LoanRepaymentTransactions, error)
+	GetReversalByOriginalID(ctx context.Context, tx *gorm.DB, originalID uint64) (*model.LoanRepaymentTransactions, error)
+	GetSettledByChannel(ctx context.Context, channelID int64, from time.Time, to time.Time) ([]*model.LoanRepaymentTransactions, error)
+	GetPostedByScheduleIDs(ctx context.Context, scheduleIDs []uint64) ([]*model.LoanRepaymentTransactions, error)
 }
 
 type loanRepaymentTransactionsDao struct {
@@ -228,6 +234,9 @@ func (d *loanRepaymentTransactionsDao) updateDataByID(ctx context.Context, db *g
 	if table.Remark != "" {
 		update["remark"] = table.Remark
 	}
+	if table.VoucherFileName != "" {
+		update["voucher_file_name"] = table.VoucherFileName
+	}
 
 	return db.WithContext(ctx).Model(table).Updates(update).Error
 }
@@ -464,3 +473,61 @@ func (d *loanRepaymentTransactionsDao) UpdateByTx(ctx context.Context, tx *gorm.
 
 	return err
 }
+
+// GetByIDForUpdate get a record by id and lock the row (SELECT ... FOR UPDATE) in the provided transaction
+func (d *loanRepaymentTransactionsDao) GetByIDForUpdate(ctx context.Context, tx *gorm.DB, id uint64) (*model.LoanRepaymentTransactions, error) {
+	record := &model.LoanRepaymentTransactions{}
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(record).Error
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// GetReversalByOriginalID 查询原流水对应的冲正流水，不存在返回 database.ErrRecordNotFound
+func (d *loanRepaymentTransactionsDao) GetReversalByOriginalID(ctx context.Context, tx *gorm.DB, originalID uint64) (*model.LoanRepaymentTransactions, error) {
+	record := &model.LoanRepaymentTransactions{}
+	err := tx.WithContext(ctx).Where("reversal_of_id = ?", originalID).First(record).Error
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// GetByChannelPayRef 按渠道+渠道流水号查询成功流水，用于回款入账去重，不存在返回 database.ErrRecordNotFound
+func (d *loanRepaymentTransactionsDao) GetByChannelPayRef(ctx context.Context, tx *gorm.DB, channelID int64, payRef string) (*model.LoanRepaymentTransactions, error) {
+	record := &model.LoanRepaymentTransactions{}
+	err := tx.WithContext(ctx).
+		Where("collect_channel_id = ? AND pay_ref = ? AND status = ?", channelID, payRef, model.TransactionStatusSuccess).
+		First(record).Error
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// GetSettledByChannel 查询渠道在 [from, to) 内的成功回款流水(不含冲正流水)，用于渠道结算对账
+func (d *loanRepaymentTransactionsDao) GetSettledByChannel(ctx context.Context, channelID int64, from time.Time, to time.Time) ([]*model.LoanRepaymentTransactions, error) {
+	records := []*model.LoanRepaymentTransactions{}
+	err := d.db.WithContext(ctx).
+		Where("collect_channel_id = ? AND status = ? AND paid_at >= ? AND paid_at < ?",
+			channelID, model.TransactionStatusSuccess, from, to).
+		Order("id ASC").
+		Find(&records).Error
+	return records, err
+}
+
+// GetPostedByScheduleIDs 查询期次的成功回款及冲正流水(冲正金额为负数)，用于回收率统计
+func (d *loanRepaymentTransactionsDao) GetPostedByScheduleIDs(ctx context.Context, scheduleIDs []uint64) ([]*model.LoanRepaymentTransactions, error) {
+	records := []*model.LoanRepaymentTransactions{}
+	if len(scheduleIDs) == 0 {
+		return records, nil
+	}
+	err := d.db.WithContext(ctx).
+		Select("id, schedule_id, pay_amount, alloc_principal, paid_at, status").
+		Where("schedule_id IN ? AND status IN ?", scheduleIDs,
+			[]int{model.TransactionStatusSuccess, model.TransactionStatusReversal}).
+		Order("id ASC").
+		Find(&records).Error
+	return records, err
+}
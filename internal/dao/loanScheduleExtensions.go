@@ -0,0 +1,62 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"loan/internal/model"
+)
+
+var _ LoanScheduleExtensionsDao = (*loanScheduleExtensionsDao)(nil)
+
+// LoanScheduleExtensionsDao defining the dao interface
+type LoanScheduleExtensionsDao interface {
+	GetByDisbursementID(ctx context.Context, disbursementID uint64) ([]*model.LoanScheduleExtensions, error)
+	SumPenaltyWaivedByScheduleID(ctx context.Context, scheduleID uint64) (int64, error)
+
+	CountByDisbursementIDByTx(ctx context.Context, tx *gorm.DB, disbursementID uint64) (int64, error)
+	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.LoanScheduleExtensions) (uint64, error)
+}
+
+// loanScheduleExtensionsDao 展期记录只追加、按放款单查询，不使用缓存
+type loanScheduleExtensionsDao struct {
+	db *gorm.DB
+}
+
+// NewLoanScheduleExtensionsDao creating the dao interface
+func NewLoanScheduleExtensionsDao(db *gorm.DB) LoanScheduleExtensionsDao {
+	return &loanScheduleExtensionsDao{db: db}
+}
+
+// GetByDisbursementID get all extensions of a disbursement, ordered by extension no
+func (d *loanScheduleExtensionsDao) GetByDisbursementID(ctx context.Context, disbursementID uint64) ([]*model.LoanScheduleExtensions, error) {
+	records := []*model.LoanScheduleExtensions{}
+	err := d.db.WithContext(ctx).Where("disbursement_id = ?", disbursementID).Order("extension_no ASC").Find(&records).Error
+	return records, err
+}
+
+// SumPenaltyWaivedByScheduleID 汇总期次展期时免除的罚息
+func (d *loanScheduleExtensionsDao) SumPenaltyWaivedByScheduleID(ctx context.Context, scheduleID uint64) (int64, error) {
+	var total int64
+	err := d.db.WithContext(ctx).Model(&model.LoanScheduleExtensions{}).
+		Where("schedule_id = ?", scheduleID).
+		Select("COALESCE(SUM(penalty_waived), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// CountByDisbursementIDByTx 统计放款单已展期次数，需在锁定期次的事务内调用
+func (d *loanScheduleExtensionsDao) CountByDisbursementIDByTx(ctx context.Context, tx *gorm.DB, disbursementID uint64) (int64, error) {
+	var total int64
+	err := tx.WithContext(ctx).Model(&model.LoanScheduleExtensions{}).
+		Where("disbursement_id = ?", disbursementID).
+		Count(&total).Error
+	return total, err
+}
+
+// CreateByTx create a record in the database using the provided transaction
+func (d *loanScheduleExtensionsDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.LoanScheduleExtensions) (uint64, error) {
+	err := tx.WithContext(ctx).Create(table).Error
+	return table.ID, err
+}
@@ -0,0 +1,80 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"loan/internal/model"
+)
+
+var _ LoanScorecardsDao = (*loanScorecardsDao)(nil)
+
+// LoanScorecardsDao defining the dao interface
+type LoanScorecardsDao interface {
+	Create(ctx context.Context, table *model.LoanScorecards) error
+	GetByID(ctx context.Context, id uint64) (*model.LoanScorecards, error)
+	GetAll(ctx context.Context) ([]*model.LoanScorecards, error)
+	GetActive(ctx context.Context) (*model.LoanScorecards, error)
+	GetLatestVersion(ctx context.Context, name string) (int, error)
+	UpdateStatus(ctx context.Context, id uint64, status int) error
+}
+
+// loanScorecardsDao 评分卡版本很少，评分时直接读库，启用新版本后立即生效，不使用缓存
+type loanScorecardsDao struct {
+	db *gorm.DB
+}
+
+// NewLoanScorecardsDao creating the dao interface
+func NewLoanScorecardsDao(db *gorm.DB) LoanScorecardsDao {
+	return &loanScorecardsDao{db: db}
+}
+
+// Create a new scorecard version
+func (d *loanScorecardsDao) Create(ctx context.Context, table *model.LoanScorecards) error {
+	return d.db.WithContext(ctx).Create(table).Error
+}
+
+// GetByID get a scorecard version by id
+func (d *loanScorecardsDao) GetByID(ctx context.Context, id uint64) (*model.LoanScorecards, error) {
+	record := &model.LoanScorecards{}
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(record).Error
+	return record, err
+}
+
+// GetAll 查询全部评分卡版本，按名称排序，同名按版本倒序
+func (d *loanScorecardsDao) GetAll(ctx context.Context) ([]*model.LoanScorecards, error) {
+	records := []*model.LoanScorecards{}
+	err := d.db.WithContext(ctx).Order("name ASC, version DESC").Find(&records).Error
+	return records, err
+}
+
+// GetActive 当前启用的评分卡版本
+func (d *loanScorecardsDao) GetActive(ctx context.Context) (*model.LoanScorecards, error) {
+	record := &model.LoanScorecards{}
+	err := d.db.WithContext(ctx).Where("status = 1").Order("id DESC").First(record).Error
+	return record, err
+}
+
+// GetLatestVersion 同名评分卡的最大版本号，不存在时返回 0
+func (d *loanScorecardsDao) GetLatestVersion(ctx context.Context, name string) (int, error) {
+	var version int
+	err := d.db.WithContext(ctx).Model(&model.LoanScorecards{}).Unscoped().
+		Where("name = ?", name).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&version).Error
+	return version, err
+}
+
+// UpdateStatus 启用或停用评分卡版本，启用时在同一事务内停用其他版本，保证同一时间只有一个版本启用
+func (d *loanScorecardsDao) UpdateStatus(ctx context.Context, id uint64, status int) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if status == 1 {
+			err := tx.Model(&model.LoanScorecards{}).Where("status = 1 AND id <> ?", id).Update("status", 0).Error
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Model(&model.LoanScorecards{}).Where("id = ?", id).Update("status", status).Error
+	})
+}
@@ -0,0 +1,82 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"loan/internal/model"
+	"loan/internal/types"
+)
+
+// scoringInboxSmsLimit 识别工资短信时最多读取的最近收件短信条数
+const scoringInboxSmsLimit = 1000
+
+var _ LoanScoringDataDao = (*loanScoringDataDao)(nil)
+
+// LoanScoringDataDao 信用评分需要的设备采集数据汇总
+type LoanScoringDataDao interface {
+	GetDeviceData(ctx context.Context, baseinfoID uint64) (*types.ScoringDeviceData, error)
+}
+
+type loanScoringDataDao struct {
+	db *gorm.DB
+}
+
+// NewLoanScoringDataDao creating the dao interface
+func NewLoanScoringDataDao(db *gorm.DB) LoanScoringDataDao {
+	return &loanScoringDataDao{db: db}
+}
+
+// GetDeviceData 汇总申请单的通讯录、通话记录、短信与已安装应用：条数、未接/拒接通话数、
+// 最近收到的短信内容与应用包名(由评分卡按关键字识别工资短信、借贷类应用)
+func (d *loanScoringDataDao) GetDeviceData(ctx context.Context, baseinfoID uint64) (*types.ScoringDeviceData, error) {
+	db := d.db.WithContext(ctx)
+	data := &types.ScoringDeviceData{}
+
+	// 1) 通讯录
+	if err := db.Model(&model.LoanUserContacts{}).Where("baseinfo_id = ?", baseinfoID).Count(&data.Contacts).Error; err != nil {
+		return nil, err
+	}
+
+	// 2) 通话记录：3未接 4拒接
+	var calls struct {
+		Total      int64 `gorm:"column:total"`
+		Unanswered int64 `gorm:"column:unanswered"`
+	}
+	err := db.Model(&model.LoanUserCallRecords{}).
+		Select("COUNT(*) AS total, COALESCE(SUM(CASE WHEN call_type IN (3, 4) THEN 1 ELSE 0 END), 0) AS unanswered").
+		Where("baseinfo_id = ?", baseinfoID).
+		Scan(&calls).Error
+	if err != nil {
+		return nil, err
+	}
+	data.CallRecords, data.UnansweredCalls = calls.Total, calls.Unanswered
+
+	// 3) 短信：条数与最近收到的短信内容
+	if err = db.Model(&model.LoanUserSmsRecords{}).Where("baseinfo_id = ?", baseinfoID).Count(&data.SmsRecords).Error; err != nil {
+		return nil, err
+	}
+	if data.SmsRecords > 0 {
+		err = db.Model(&model.LoanUserSmsRecords{}).
+			Where("baseinfo_id = ? AND direction = 1 AND body IS NOT NULL AND body <> ''", baseinfoID).
+			Order("sms_time DESC, id DESC").
+			Limit(scoringInboxSmsLimit).
+			Pluck("body", &data.InboxSms).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// 4) 已安装应用
+	err = db.Model(&model.LoanUserDeviceApps{}).
+		Where("baseinfo_id = ?", baseinfoID).
+		Distinct("package_name").
+		Pluck("package_name", &data.Packages).Error
+	if err != nil {
+		return nil, err
+	}
+	data.DeviceApps = int64(len(data.Packages))
+
+	return data, nil
+}
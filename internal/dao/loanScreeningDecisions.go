@@ -0,0 +1,39 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"loan/internal/model"
+)
+
+var _ LoanScreeningDecisionsDao = (*loanScreeningDecisionsDao)(nil)
+
+// LoanScreeningDecisionsDao defining the dao interface
+type LoanScreeningDecisionsDao interface {
+	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.LoanScreeningDecisions) (uint64, error)
+	ListByBaseinfoID(ctx context.Context, baseinfoID uint64) ([]*model.LoanScreeningDecisions, error)
+}
+
+type loanScreeningDecisionsDao struct {
+	db *gorm.DB
+}
+
+// NewLoanScreeningDecisionsDao creating the dao interface
+func NewLoanScreeningDecisionsDao(db *gorm.DB) LoanScreeningDecisionsDao {
+	return &loanScreeningDecisionsDao{db: db}
+}
+
+// CreateByTx create a record in the database using the provided transaction
+func (d *loanScreeningDecisionsDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.LoanScreeningDecisions) (uint64, error) {
+	err := tx.WithContext(ctx).Create(table).Error
+	return table.ID, err
+}
+
+// ListByBaseinfoID 申请单的预审决策日志，最新的在前
+func (d *loanScreeningDecisionsDao) ListByBaseinfoID(ctx context.Context, baseinfoID uint64) ([]*model.LoanScreeningDecisions, error) {
+	records := []*model.LoanScreeningDecisions{}
+	err := d.db.WithContext(ctx).Where("baseinfo_id = ?", baseinfoID).Order("id DESC").Find(&records).Error
+	return records, err
+}
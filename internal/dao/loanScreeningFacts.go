@@ -0,0 +1,121 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"loan/internal/model"
+	"loan/internal/types"
+)
+
+var _ LoanScreeningFactsDao = (*loanScreeningFactsDao)(nil)
+
+// LoanScreeningFactsDao 自动预审需要的申请单之外的数据
+type LoanScreeningFactsDao interface {
+	GetExtraFacts(ctx context.Context, records []*model.LoanBaseinfo) (map[uint64]*types.ScreeningExtraFacts, error)
+}
+
+type loanScreeningFactsDao struct {
+	db *gorm.DB
+}
+
+// NewLoanScreeningFactsDao creating the dao interface
+func NewLoanScreeningFactsDao(db *gorm.DB) LoanScreeningFactsDao {
+	return &loanScreeningFactsDao{db: db}
+}
+
+// GetExtraFacts 批量查询申请单的名单状态与设备数据条数。
+// 名单状态按证件号或手机号匹配同一客户的任一申请，黑名单优先于白名单。
+func (d *loanScreeningFactsDao) GetExtraFacts(ctx context.Context, records []*model.LoanBaseinfo) (map[uint64]*types.ScreeningExtraFacts, error) {
+	result := make(map[uint64]*types.ScreeningExtraFacts, len(records))
+	if len(records) == 0 {
+		return result, nil
+	}
+	db := d.db.WithContext(ctx)
+
+	ids := make([]uint64, 0, len(records))
+	var idNumbers, mobiles []string
+	for _, b := range records {
+		result[b.ID] = &types.ScreeningExtraFacts{}
+		ids = append(ids, b.ID)
+		if b.IdNumber != "" {
+			idNumbers = append(idNumbers, b.IdNumber)
+		}
+		if b.Mobile != "" {
+			mobiles = append(mobiles, b.Mobile)
+		}
+	}
+
+	// 1) 设备数据条数
+	counts := []struct {
+		table string
+		set   func(f *types.ScreeningExtraFacts, n int64)
+	}{
+		{"loan_user_contacts", func(f *types.ScreeningExtraFacts, n int64) { f.Contacts = n }},
+		{"loan_user_call_records", func(f *types.ScreeningExtraFacts, n int64) { f.CallRecords = n }},
+		{"loan_user_sms_records", func(f *types.ScreeningExtraFacts, n int64) { f.SmsRecords = n }},
+		{"loan_user_device_apps", func(f *types.ScreeningExtraFacts, n int64) { f.DeviceApps = n }},
+	}
+	for _, c := range counts {
+		var rows []struct {
+			BaseinfoID uint64 `gorm:"column:baseinfo_id"`
+			Cnt        int64  `gorm:"column:cnt"`
+		}
+		err := db.Table(c.table).
+			Select("baseinfo_id, COUNT(*) AS cnt").
+			Where("baseinfo_id IN ? AND deleted_at IS NULL", ids).
+			Group("baseinfo_id").
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			if f, ok := result[r.BaseinfoID]; ok {
+				c.set(f, r.Cnt)
+			}
+		}
+	}
+
+	// 2) 同一客户的名单状态
+	if len(idNumbers) == 0 && len(mobiles) == 0 {
+		return result, nil
+	}
+	var risks []struct {
+		IdNumber string `gorm:"column:id_number"`
+		Mobile   string `gorm:"column:mobile"`
+		RiskType int    `gorm:"column:risk_type"`
+	}
+	q := db.Table("loan_risk_customer AS r").
+		Select("b.id_number, b.mobile, r.risk_type").
+		Joins("JOIN loan_baseinfo AS b ON b.id = r.loan_baseinfo_id").
+		Where("r.deleted_at IS NULL AND b.deleted_at IS NULL")
+	switch {
+	case len(idNumbers) > 0 && len(mobiles) > 0:
+		q = q.Where("b.id_number IN ? OR b.mobile IN ?", idNumbers, mobiles)
+	case len(idNumbers) > 0:
+		q = q.Where("b.id_number IN ?", idNumbers)
+	default:
+		q = q.Where("b.mobile IN ?", mobiles)
+	}
+	if err := q.Scan(&risks).Error; err != nil {
+		return nil, err
+	}
+	for _, b := range records {
+		f := result[b.ID]
+		for _, r := range risks {
+			if (b.IdNumber == "" || r.IdNumber != b.IdNumber) && (b.Mobile == "" || r.Mobile != b.Mobile) {
+				continue
+			}
+			switch r.RiskType {
+			case -1:
+				f.RiskListStatus = 2 // 黑名单
+			case 1:
+				if f.RiskListStatus == 0 {
+					f.RiskListStatus = 1 // 白名单
+				}
+			}
+		}
+	}
+	return result, nil
+}
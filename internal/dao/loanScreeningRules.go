@@ -0,0 +1,60 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"loan/internal/model"
+)
+
+var _ LoanScreeningRulesDao = (*loanScreeningRulesDao)(nil)
+
+// LoanScreeningRulesDao defining the dao interface
+type LoanScreeningRulesDao interface {
+	Create(ctx context.Context, table *model.LoanScreeningRules) error
+	DeleteByID(ctx context.Context, id uint64) error
+	UpdateByID(ctx context.Context, table *model.LoanScreeningRules) error
+	GetByID(ctx context.Context, id uint64) (*model.LoanScreeningRules, error)
+	GetAll(ctx context.Context) ([]*model.LoanScreeningRules, error)
+}
+
+// loanScreeningRulesDao 规则数量很少，提交申请时直接读库，修改后立即生效，不使用缓存
+type loanScreeningRulesDao struct {
+	db *gorm.DB
+}
+
+// NewLoanScreeningRulesDao creating the dao interface
+func NewLoanScreeningRulesDao(db *gorm.DB) LoanScreeningRulesDao {
+	return &loanScreeningRulesDao{db: db}
+}
+
+// Create a new screening rule
+func (d *loanScreeningRulesDao) Create(ctx context.Context, table *model.LoanScreeningRules) error {
+	return d.db.WithContext(ctx).Create(table).Error
+}
+
+// DeleteByID delete a screening rule by id
+func (d *loanScreeningRulesDao) DeleteByID(ctx context.Context, id uint64) error {
+	return d.db.WithContext(ctx).Where("id = ?", id).Delete(&model.LoanScreeningRules{}).Error
+}
+
+// UpdateByID update a screening rule by id, all editable fields are written (priority/score/status may be 0)
+func (d *loanScreeningRulesDao) UpdateByID(ctx context.Context, table *model.LoanScreeningRules) error {
+	return d.db.WithContext(ctx).Model(table).Select("name", "priority", "conditions", "action", "score", "status", "remark").
+		Updates(table).Error
+}
+
+// GetByID get a screening rule by id
+func (d *loanScreeningRulesDao) GetByID(ctx context.Context, id uint64) (*model.LoanScreeningRules, error) {
+	record := &model.LoanScreeningRules{}
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(record).Error
+	return record, err
+}
+
+// GetAll 查询全部规则(含停用)，按执行顺序排序
+func (d *loanScreeningRulesDao) GetAll(ctx context.Context) ([]*model.LoanScreeningRules, error) {
+	records := []*model.LoanScreeningRules{}
+	err := d.db.WithContext(ctx).Order("priority ASC, id ASC").Find(&records).Error
+	return records, err
+}
@@ -3,6 +3,8 @@ package dao
 import (
 	"context"
 	"errors"
+	"strconv"
+	"strings"
 
 	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
@@ -25,6 +27,8 @@ type LoanSettingsDao interface {
 	UpdateByID(ctx context.Context, table *model.LoanSettings) error
 	GetByID(ctx context.Context, id uint64) (*model.LoanSettings, error)
 	GetByColumns(ctx context.Context, params *query.Params) ([]*model.LoanSettings, int64, error)
+	GetByName(ctx context.Context, name string) (*model.LoanSettings, error)
+	GetInt64ByName(ctx context.Context, name string, def int64) int64
 
 	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.LoanSettings) (uint64, error)
 	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
@@ -188,6 +192,33 @@ func (d *loanSettingsDao) GetByColumns(ctx context.Context, params *query.Params
 	return records, total, err
 }
 
+// GetByName get a loanSettings by name, return database.ErrRecordNotFound if not configured
+func (d *loanSettingsDao) GetByName(ctx context.Context, name string) (*model.LoanSettings, error) {
+	record := &model.LoanSettings{}
+	err := d.db.WithContext(ctx).Where("name = ?", name).Order("id DESC").First(record).Error
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// GetInt64ByName 读取整数类型的系统设置，未配置或格式错误时返回默认值
+func (d *loanSettingsDao) GetInt64ByName(ctx context.Context, name string, def int64) int64 {
+	setting, err := d.GetByName(ctx, name)
+	if err != nil {
+		if !errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("get setting failed, use default", logger.Err(err), logger.String("name", name))
+		}
+		return def
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(setting.Value), 10, 64)
+	if err != nil {
+		logger.Warn("invalid integer setting, use default", logger.String("name", name), logger.String("value", setting.Value))
+		return def
+	}
+	return v
+}
+
 // CreateByTx create a record in the database using the provided transaction
 func (d *loanSettingsDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.LoanSettings) (uint64, error) {
 	err := tx.WithContext(ctx).Create(table).Error
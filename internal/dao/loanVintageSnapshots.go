@@ -0,0 +1,64 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"loan/internal/model"
+)
+
+var _ LoanVintageSnapshotsDao = (*loanVintageSnapshotsDao)(nil)
+
+// LoanVintageSnapshotsDao defining the dao interface
+type LoanVintageSnapshotsDao interface {
+	GetByCohortRange(ctx context.Context, from string, to string) ([]*model.LoanVintageSnapshots, error)
+	GetCohorts(ctx context.Context) ([]string, error)
+	ReplaceCohort(ctx context.Context, cohort string, rows []*model.LoanVintageSnapshots) error
+}
+
+// loanVintageSnapshotsDao 快照由定时任务整批重算，不使用缓存
+type loanVintageSnapshotsDao struct {
+	db *gorm.DB
+}
+
+// NewLoanVintageSnapshotsDao creating the dao interface
+func NewLoanVintageSnapshotsDao(db *gorm.DB) LoanVintageSnapshotsDao {
+	return &loanVintageSnapshotsDao{db: db}
+}
+
+// GetByCohortRange 查询放款月份在 [from, to] 内的快照(yyyy-mm，为空表示不限)，按月份、MOB 排序
+func (d *loanVintageSnapshotsDao) GetByCohortRange(ctx context.Context, from string, to string) ([]*model.LoanVintageSnapshots, error) {
+	db := d.db.WithContext(ctx)
+	if from != "" {
+		db = db.Where("cohort >= ?", from)
+	}
+	if to != "" {
+		db = db.Where("cohort <= ?", to)
+	}
+	records := []*model.LoanVintageSnapshots{}
+	err := db.Order("cohort ASC, mob ASC").Find(&records).Error
+	return records, err
+}
+
+// GetCohorts 已生成快照的放款月份
+func (d *loanVintageSnapshotsDao) GetCohorts(ctx context.Context) ([]string, error) {
+	var cohorts []string
+	err := d.db.WithContext(ctx).Model(&model.LoanVintageSnapshots{}).
+		Distinct("cohort").
+		Pluck("cohort", &cohorts).Error
+	return cohorts, err
+}
+
+// ReplaceCohort 在一个事务内用本次计算结果替换该放款月份的全部快照
+func (d *loanVintageSnapshotsDao) ReplaceCohort(ctx context.Context, cohort string, rows []*model.LoanVintageSnapshots) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("cohort = ?", cohort).Delete(&model.LoanVintageSnapshots{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.Create(rows).Error
+	})
+}
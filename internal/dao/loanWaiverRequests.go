@@ -0,0 +1,112 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"loan/internal/model"
+)
+
+var _ LoanWaiverRequestsDao = (*loanWaiverRequestsDao)(nil)
+
+// LoanWaiverRequestsDao defining the dao interface
+type LoanWaiverRequestsDao interface {
+	Create(ctx context.Context, table *model.LoanWaiverRequests) error
+	GetByID(ctx context.Context, id uint64) (*model.LoanWaiverRequests, error)
+	GetByColumns(ctx context.Context, params *query.Params) ([]*model.LoanWaiverRequests, int64, error)
+	SumApprovedByScheduleID(ctx context.Context, scheduleID uint64, component string) (int64, error)
+
+	GetByIDForUpdate(ctx context.Context, tx *gorm.DB, id uint64) (*model.LoanWaiverRequests, error)
+	UpdateReviewByTx(ctx context.Context, tx *gorm.DB, table *model.LoanWaiverRequests) error
+}
+
+// loanWaiverRequestsDao 减免申请按状态流转、审批时加锁，不使用缓存
+type loanWaiverRequestsDao struct {
+	db *gorm.DB
+}
+
+// NewLoanWaiverRequestsDao creating the dao interface
+func NewLoanWaiverRequestsDao(db *gorm.DB) LoanWaiverRequestsDao {
+	return &loanWaiverRequestsDao{db: db}
+}
+
+// Create a new waiver request
+func (d *loanWaiverRequestsDao) Create(ctx context.Context, table *model.LoanWaiverRequests) error {
+	return d.db.WithContext(ctx).Create(table).Error
+}
+
+// GetByID get a waiver request by id
+func (d *loanWaiverRequestsDao) GetByID(ctx context.Context, id uint64) (*model.LoanWaiverRequests, error) {
+	record := &model.LoanWaiverRequests{}
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(record).Error
+	return record, err
+}
+
+// GetByColumns get a paginated list of waiver requests by custom conditions.
+// For more details, please refer to https://go-sponge.com/component/data/custom-page-query.html
+func (d *loanWaiverRequestsDao) GetByColumns(ctx context.Context, params *query.Params) ([]*model.LoanWaiverRequests, int64, error) {
+	queryStr, args, err := params.ConvertToGormConditions(query.WithWhitelistNames(model.LoanWaiverRequestsColumnNames))
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+
+	var total int64
+	if params.Sort != "ignore count" { // determine if count is required
+		err = d.db.WithContext(ctx).Model(&model.LoanWaiverRequests{}).Where(queryStr, args...).Count(&total).Error
+		if err != nil {
+			return nil, 0, err
+		}
+		if total == 0 {
+			return nil, total, nil
+		}
+	}
+
+	records := []*model.LoanWaiverRequests{}
+	order, limit, offset := params.ConvertToPage()
+	err = d.db.WithContext(ctx).Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, err
+}
+
+// SumApprovedByScheduleID 汇总期次某科目已审批通过的减免金额
+func (d *loanWaiverRequestsDao) SumApprovedByScheduleID(ctx context.Context, scheduleID uint64, component string) (int64, error) {
+	var total int64
+	err := d.db.WithContext(ctx).Model(&model.LoanWaiverRequests{}).
+		Where("schedule_id = ? AND component = ? AND status = ?", scheduleID, component, model.WaiverStatusApproved).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&total).Error
+	return total, err
+}
+
+// GetByIDForUpdate 事务内加行锁读取减免申请，防止重复审批
+func (d *loanWaiverRequestsDao) GetByIDForUpdate(ctx context.Context, tx *gorm.DB, id uint64) (*model.LoanWaiverRequests, error) {
+	record := &model.LoanWaiverRequests{}
+	err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(record).Error
+	return record, err
+}
+
+// UpdateReviewByTx 写入审批结果
+func (d *loanWaiverRequestsDao) UpdateReviewByTx(ctx context.Context, tx *gorm.DB, table *model.LoanWaiverRequests) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+
+	update := map[string]interface{}{
+		"status":        table.Status,
+		"reviewed_by":   table.ReviewedBy,
+		"reviewed_at":   table.ReviewedAt,
+		"review_note":   table.ReviewNote,
+		"due_before":    table.DueBefore,
+		"due_after":     table.DueAfter,
+		"status_before": table.StatusBefore,
+		"status_after":  table.StatusAfter,
+	}
+	return tx.WithContext(ctx).Model(&model.LoanWaiverRequests{}).Where("id = ?", table.ID).Updates(update).Error
+}
@@ -0,0 +1,60 @@
+package dao
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"loan/internal/model"
+)
+
+var _ LoanWorkflowStagesDao = (*loanWorkflowStagesDao)(nil)
+
+// LoanWorkflowStagesDao defining the dao interface
+type LoanWorkflowStagesDao interface {
+	Create(ctx context.Context, table *model.LoanWorkflowStages) error
+	DeleteByID(ctx context.Context, id uint64) error
+	UpdateByID(ctx context.Context, table *model.LoanWorkflowStages) error
+	GetByID(ctx context.Context, id uint64) (*model.LoanWorkflowStages, error)
+	GetByWorkflow(ctx context.Context, workflow string) ([]*model.LoanWorkflowStages, error)
+}
+
+// loanWorkflowStagesDao 流程定义节点很少，审批时直接读库，修改后立即生效，不使用缓存
+type loanWorkflowStagesDao struct {
+	db *gorm.DB
+}
+
+// NewLoanWorkflowStagesDao creating the dao interface
+func NewLoanWorkflowStagesDao(db *gorm.DB) LoanWorkflowStagesDao {
+	return &loanWorkflowStagesDao{db: db}
+}
+
+// Create a new workflow stage
+func (d *loanWorkflowStagesDao) Create(ctx context.Context, table *model.LoanWorkflowStages) error {
+	return d.db.WithContext(ctx).Create(table).Error
+}
+
+// DeleteByID delete a workflow stage by id
+func (d *loanWorkflowStagesDao) DeleteByID(ctx context.Context, id uint64) error {
+	return d.db.WithContext(ctx).Where("id = ?", id).Delete(&model.LoanWorkflowStages{}).Error
+}
+
+// UpdateByID update a workflow stage by id, all editable fields are written (status/min_amount may be 0)
+func (d *loanWorkflowStagesDao) UpdateByID(ctx context.Context, table *model.LoanWorkflowStages) error {
+	return d.db.WithContext(ctx).Model(table).Select("stage_no", "code", "name", "permission", "min_amount", "status").
+		Updates(table).Error
+}
+
+// GetByID get a workflow stage by id
+func (d *loanWorkflowStagesDao) GetByID(ctx context.Context, id uint64) (*model.LoanWorkflowStages, error) {
+	record := &model.LoanWorkflowStages{}
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(record).Error
+	return record, err
+}
+
+// GetByWorkflow 查询流程的全部节点(含停用)，按节点序号排序
+func (d *loanWorkflowStagesDao) GetByWorkflow(ctx context.Context, workflow string) ([]*model.LoanWorkflowStages, error) {
+	records := []*model.LoanWorkflowStages{}
+	err := d.db.WithContext(ctx).Where("workflow = ?", workflow).Order("stage_no ASC").Find(&records).Error
+	return records, err
+}
@@ -22,5 +22,11 @@ var (
 	ErrListByIDsLoanDisbursements      = errcode.NewError(loanDisbursementsBaseCode+8, "failed to list by batch ids "+loanDisbursementsName)
 	ErrListByLastIDLoanDisbursements   = errcode.NewError(loanDisbursementsBaseCode+9, "failed to list by last id "+loanDisbursementsName)
 
+	ErrPayoutGatewayUnavailable   = errcode.NewError(loanDisbursementsBaseCode+10, "payout gateway is not available for the channel")
+	ErrDisbursementNotRetryable   = errcode.NewError(loanDisbursementsBaseCode+11, "only failed disbursements can be retried")
+	ErrDisbursementNotCancellable = errcode.NewError(loanDisbursementsBaseCode+12, "disbursement can not be cancelled in current status")
+	ErrPayoutAmountOutOfLimit     = errcode.NewError(loanDisbursementsBaseCode+13, "disbursement amount is outside the payout limits of the channel")
+	ErrNoPayoutRoute              = errcode.NewError(loanDisbursementsBaseCode+14, "no payout channel available for the disbursement")
+
 	// error codes are globally unique, adding 1 to the previous error code
 )
@@ -0,0 +1,18 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// loanIdempotencyKeys business-level http error codes.
+// the loanIdempotencyKeysNO value range is 1~999, if the same error code is used, it will cause panic.
+var (
+	loanIdempotencyKeysNO       = 104
+	loanIdempotencyKeysBaseCode = errcode.HCode(loanIdempotencyKeysNO)
+
+	ErrInvalidIdempotencyKey    = errcode.NewError(loanIdempotencyKeysBaseCode+1, "invalid Idempotency-Key header")
+	ErrIdempotencyKeyInProgress = errcode.NewError(loanIdempotencyKeysBaseCode+2, "a request with the same Idempotency-Key is still in progress")
+	ErrIdempotencyKeyReused     = errcode.NewError(loanIdempotencyKeysBaseCode+3, "Idempotency-Key was already used with a different request body")
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
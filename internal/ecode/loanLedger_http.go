@@ -0,0 +1,18 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// loanLedger business-level http error codes.
+// the loanLedgerNO value range is 1~999, if the same error code is used, it will cause panic.
+var (
+	loanLedgerNO       = 107
+	loanLedgerBaseCode = errcode.HCode(loanLedgerNO)
+
+	ErrLedgerPeriod         = errcode.NewError(loanLedgerBaseCode+1, "invalid ledger date range")
+	ErrLedgerUnknownAccount = errcode.NewError(loanLedgerBaseCode+2, "unknown ledger account")
+	ErrLedgerQuery          = errcode.NewError(loanLedgerBaseCode+3, "failed to query ledger entries")
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
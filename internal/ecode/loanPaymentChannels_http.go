@@ -22,5 +22,9 @@ var (
 	ErrListByIDsLoanPaymentChannels      = errcode.NewError(loanPaymentChannelsBaseCode+8, "failed to list by batch ids "+loanPaymentChannelsName)
 	ErrListByLastIDLoanPaymentChannels   = errcode.NewError(loanPaymentChannelsBaseCode+9, "failed to list by last id "+loanPaymentChannelsName)
 
+	ErrCallbackChannelUnavailable = errcode.NewError(loanPaymentChannelsBaseCode+10, "payment channel is not available for callbacks")
+	ErrCallbackSignature          = errcode.NewError(loanPaymentChannelsBaseCode+11, "invalid callback signature")
+	ErrCallbackPayload            = errcode.NewError(loanPaymentChannelsBaseCode+12, "invalid callback payload")
+
 	// error codes are globally unique, adding 1 to the previous error code
 )
@@ -0,0 +1,26 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// loanProducts business-level http error codes.
+// the loanProductsNO value range is 1~999, if the same error code is used, it will cause panic.
+var (
+	loanProductsNO       = 103
+	loanProductsName     = "loanProducts"
+	loanProductsBaseCode = errcode.HCode(loanProductsNO)
+
+	ErrCreateLoanProducts     = errcode.NewError(loanProductsBaseCode+1, "failed to create "+loanProductsName)
+	ErrDeleteByIDLoanProducts = errcode.NewError(loanProductsBaseCode+2, "failed to delete "+loanProductsName)
+	ErrUpdateByIDLoanProducts = errcode.NewError(loanProductsBaseCode+3, "failed to update "+loanProductsName)
+	ErrGetByIDLoanProducts    = errcode.NewError(loanProductsBaseCode+4, "failed to get "+loanProductsName+" details")
+	ErrListLoanProducts       = errcode.NewError(loanProductsBaseCode+5, "failed to list of "+loanProductsName)
+
+	ErrInvalidLoanProduct          = errcode.NewError(loanProductsBaseCode+6, "invalid loan product configuration")
+	ErrLoanProductInactive         = errcode.NewError(loanProductsBaseCode+7, "loan product is not available")
+	ErrApplicationAmountOutOfRange = errcode.NewError(loanProductsBaseCode+8, "application amount is out of the product range")
+	ErrLoanDaysNotAllowed          = errcode.NewError(loanProductsBaseCode+9, "loan days is not allowed by the product")
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -0,0 +1,19 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// loanReconciliation business-level http error codes.
+// the loanReconciliationNO value range is 1~999, if the same error code is used, it will cause panic.
+var (
+	loanReconciliationNO       = 105
+	loanReconciliationBaseCode = errcode.HCode(loanReconciliationNO)
+
+	ErrSettlementFileRequired = errcode.NewError(loanReconciliationBaseCode+1, "settlement file is required")
+	ErrParseSettlementFile    = errcode.NewError(loanReconciliationBaseCode+2, "failed to parse settlement file")
+	ErrReconcileWindow        = errcode.NewError(loanReconciliationBaseCode+3, "invalid reconciliation date range")
+	ErrReconcileLedger        = errcode.NewError(loanReconciliationBaseCode+4, "failed to load ledger records for reconciliation")
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
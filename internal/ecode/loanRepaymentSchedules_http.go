@@ -21,6 +21,12 @@ var (
 	ErrGetByConditionLoanRepaymentSchedules = errcode.NewError(loanRepaymentSchedulesBaseCode+7, "failed to get "+loanRepaymentSchedulesName+" details by conditions")
 	ErrListByIDsLoanRepaymentSchedules      = errcode.NewError(loanRepaymentSchedulesBaseCode+8, "failed to list by batch ids "+loanRepaymentSchedulesName)
 	ErrListByLastIDLoanRepaymentSchedules   = errcode.NewError(loanRepaymentSchedulesBaseCode+9, "failed to list by last id "+loanRepaymentSchedulesName)
+	ErrNothingToPayoff                      = errcode.NewError(loanRepaymentSchedulesBaseCode+10, "the disbursement has no open schedules to pay off")
+	ErrExtensionDisabled                    = errcode.NewError(loanRepaymentSchedulesBaseCode+11, "loan extension is not enabled")
+	ErrExtensionLimitReached                = errcode.NewError(loanRepaymentSchedulesBaseCode+12, "the loan has reached the maximum number of extensions")
+	ErrInvalidExtensionDays                 = errcode.NewError(loanRepaymentSchedulesBaseCode+13, "extension days are out of the allowed range")
+	ErrScheduleAlreadySettled               = errcode.NewError(loanRepaymentSchedulesBaseCode+14, "the schedule is already settled")
+	ErrDuplicatePayRef                      = errcode.NewError(loanRepaymentSchedulesBaseCode+15, "the pay ref has already been recorded for this channel")
 
 	// error codes are globally unique, adding 1 to the previous error code
 )
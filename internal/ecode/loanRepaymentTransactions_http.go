@@ -27,5 +27,11 @@ var (
 	ErrSaveFile                                = errcode.NewError(loanRepaymentTransactionsBaseCode+13, "保存文件失败")
 	FileNotFound                               = errcode.NewError(loanRepaymentTransactionsBaseCode+14, "file not found")
 	ErrReadFile                                = errcode.NewError(loanRepaymentTransactionsBaseCode+15, "failed to read the file")
+	ErrRepaymentExceedsOutstanding             = errcode.NewError(loanRepaymentTransactionsBaseCode+16, "pay amount exceeds the outstanding amount of the schedule")
+	ErrTransactionNotReversible                = errcode.NewError(loanRepaymentTransactionsBaseCode+17, "only successful repayment transactions can be reversed")
+	ErrTransactionAlreadyReversed              = errcode.NewError(loanRepaymentTransactionsBaseCode+18, "repayment transaction has already been reversed")
+	ErrTransactionImmutable                    = errcode.NewError(loanRepaymentTransactionsBaseCode+19, "repayment transactions cannot be deleted or modified, please reverse it instead")
+	ErrCollectAmountOutOfLimit                 = errcode.NewError(loanRepaymentTransactionsBaseCode+20, "pay amount is outside the collect limits of the channel")
+	ErrPayoffAmountMismatch                    = errcode.NewError(loanRepaymentTransactionsBaseCode+21, "pay amount does not match today's payoff quote")
 	// error codes are globally unique, adding 1 to the previous error code
 )
@@ -0,0 +1,19 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// loanReports business-level http error codes.
+// the loanReportsNO value range is 1~999, if the same error code is used, it will cause panic.
+var (
+	loanReportsNO       = 108
+	loanReportsBaseCode = errcode.HCode(loanReportsNO)
+
+	ErrReportDateRange = errcode.NewError(loanReportsBaseCode+1, "invalid report date")
+	ErrReportQuery     = errcode.NewError(loanReportsBaseCode+2, "failed to query report data")
+	ErrReportExport    = errcode.NewError(loanReportsBaseCode+3, "failed to export report")
+	ErrReportCohort    = errcode.NewError(loanReportsBaseCode+4, "invalid cohort month")
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
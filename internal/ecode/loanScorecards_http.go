@@ -0,0 +1,22 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// loanScorecards business-level http error codes.
+// the loanScorecardsNO value range is 1~999, if the same error code is used, it will cause panic.
+var (
+	loanScorecardsNO       = 111
+	loanScorecardsBaseCode = errcode.HCode(loanScorecardsNO)
+
+	ErrCreateLoanScorecards       = errcode.NewError(loanScorecardsBaseCode+1, "failed to create scorecard")
+	ErrGetByIDLoanScorecards      = errcode.NewError(loanScorecardsBaseCode+2, "failed to get scorecard")
+	ErrListLoanScorecards         = errcode.NewError(loanScorecardsBaseCode+3, "failed to list scorecards")
+	ErrUpdateStatusLoanScorecards = errcode.NewError(loanScorecardsBaseCode+4, "failed to update scorecard status")
+	ErrScorecardInvalid           = errcode.NewError(loanScorecardsBaseCode+5, "invalid scorecard definition")
+	ErrNoActiveScorecard          = errcode.NewError(loanScorecardsBaseCode+6, "no active scorecard")
+	ErrCreditScoring              = errcode.NewError(loanScorecardsBaseCode+7, "failed to score application")
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -0,0 +1,25 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// loanScreeningRules business-level http error codes.
+// the loanScreeningRulesNO value range is 1~999, if the same error code is used, it will cause panic.
+var (
+	loanScreeningRulesNO       = 110
+	loanScreeningRulesBaseCode = errcode.HCode(loanScreeningRulesNO)
+
+	ErrCreateLoanScreeningRules     = errcode.NewError(loanScreeningRulesBaseCode+1, "failed to create screening rule")
+	ErrDeleteByIDLoanScreeningRules = errcode.NewError(loanScreeningRulesBaseCode+2, "failed to delete screening rule")
+	ErrUpdateByIDLoanScreeningRules = errcode.NewError(loanScreeningRulesBaseCode+3, "failed to update screening rule")
+	ErrGetByIDLoanScreeningRules    = errcode.NewError(loanScreeningRulesBaseCode+4, "failed to get screening rule")
+	ErrListLoanScreeningRules       = errcode.NewError(loanScreeningRulesBaseCode+5, "failed to list screening rules")
+	ErrScreeningRuleInvalid         = errcode.NewError(loanScreeningRulesBaseCode+6, "invalid screening rule")
+	ErrScreeningDryRun              = errcode.NewError(loanScreeningRulesBaseCode+7, "failed to dry-run screening rules")
+	ErrScreeningRun                 = errcode.NewError(loanScreeningRulesBaseCode+8, "failed to run screening")
+	ErrScreeningNotPending          = errcode.NewError(loanScreeningRulesBaseCode+9, "application is no longer waiting for pre-review")
+	ErrListLoanScreeningDecisions   = errcode.NewError(loanScreeningRulesBaseCode+10, "failed to list screening decisions")
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -0,0 +1,24 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// loanWaiverRequests business-level http error codes.
+// the loanWaiverRequestsNO value range is 1~999, if the same error code is used, it will cause panic.
+var (
+	loanWaiverRequestsNO       = 106
+	loanWaiverRequestsBaseCode = errcode.HCode(loanWaiverRequestsNO)
+
+	ErrCreateLoanWaiverRequests  = errcode.NewError(loanWaiverRequestsBaseCode+1, "failed to create loanWaiverRequests")
+	ErrGetByIDLoanWaiverRequests = errcode.NewError(loanWaiverRequestsBaseCode+2, "failed to get loanWaiverRequests details")
+	ErrListLoanWaiverRequests    = errcode.NewError(loanWaiverRequestsBaseCode+3, "failed to list of loanWaiverRequests")
+	ErrWaiverComponent           = errcode.NewError(loanWaiverRequestsBaseCode+4, "only penalty or fee can be waived")
+	ErrWaiverExceedsOutstanding  = errcode.NewError(loanWaiverRequestsBaseCode+5, "waiver amount exceeds outstanding")
+	ErrWaiverNotPending          = errcode.NewError(loanWaiverRequestsBaseCode+6, "waiver request has already been reviewed")
+	ErrWaiverCaseMismatch        = errcode.NewError(loanWaiverRequestsBaseCode+7, "collection case does not belong to the schedule")
+	ErrReviewLoanWaiverRequests  = errcode.NewError(loanWaiverRequestsBaseCode+8, "failed to review loanWaiverRequests")
+	ErrWaiverSelfReview          = errcode.NewError(loanWaiverRequestsBaseCode+9, "waiver request cannot be reviewed by its requester")
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -0,0 +1,30 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// loanWorkflowStages business-level http error codes.
+// the loanWorkflowStagesNO value range is 1~999, if the same error code is used, it will cause panic.
+var (
+	loanWorkflowStagesNO       = 109
+	loanWorkflowStagesBaseCode = errcode.HCode(loanWorkflowStagesNO)
+
+	ErrCreateLoanWorkflowStages     = errcode.NewError(loanWorkflowStagesBaseCode+1, "failed to create workflow stage")
+	ErrDeleteByIDLoanWorkflowStages = errcode.NewError(loanWorkflowStagesBaseCode+2, "failed to delete workflow stage")
+	ErrUpdateByIDLoanWorkflowStages = errcode.NewError(loanWorkflowStagesBaseCode+3, "failed to update workflow stage")
+	ErrGetByIDLoanWorkflowStages    = errcode.NewError(loanWorkflowStagesBaseCode+4, "failed to get workflow stage")
+	ErrListLoanWorkflowStages       = errcode.NewError(loanWorkflowStagesBaseCode+5, "failed to list workflow stages")
+	ErrWorkflowDefinition           = errcode.NewError(loanWorkflowStagesBaseCode+6, "invalid workflow definition")
+	ErrWorkflowRejected             = errcode.NewError(loanWorkflowStagesBaseCode+7, "application has been rejected")
+	ErrWorkflowCompleted            = errcode.NewError(loanWorkflowStagesBaseCode+8, "all approval stages are completed")
+	ErrWorkflowStageMismatch        = errcode.NewError(loanWorkflowStagesBaseCode+9, "application is waiting for another approval stage")
+	ErrWorkflowStageForbidden       = errcode.NewError(loanWorkflowStagesBaseCode+10, "no permission to approve the current stage")
+	ErrSegregationOfDuties          = errcode.NewError(loanWorkflowStagesBaseCode+11, "segregation of duties: reviewer is not allowed to review this application")
+	ErrReviewQueueEmpty             = errcode.NewError(loanWorkflowStagesBaseCode+12, "no pending application available to claim")
+	ErrApplicationClaimed           = errcode.NewError(loanWorkflowStagesBaseCode+13, "application is claimed by another reviewer")
+	ErrClaimNotHeld                 = errcode.NewError(loanWorkflowStagesBaseCode+14, "application is not claimed by the current user")
+	ErrClaimApplication             = errcode.NewError(loanWorkflowStagesBaseCode+15, "failed to claim application")
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
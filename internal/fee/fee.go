@@ -0,0 +1,105 @@
+// Package fee 支付渠道手续费与限额：按 loan_payment_channels 的代付/代收配置计算手续费(费率+固定费)并校验单笔限额，金额单位为分。
+package fee
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	"loan/internal/model"
+)
+
+var (
+	// ErrInvalidAmount 金额必须大于0
+	ErrInvalidAmount = errors.New("amount must be positive")
+	// ErrBelowMin 低于渠道单笔最小金额
+	ErrBelowMin = errors.New("amount is below the channel minimum")
+	// ErrAboveMax 高于渠道单笔最大金额
+	ErrAboveMax = errors.New("amount is above the channel maximum")
+)
+
+// Rule 渠道单一方向(代付或代收)的计费与限额规则
+type Rule struct {
+	RatePercent int   // 费率，沿用渠道配置口径：35=35%
+	Fixed       int64 // 每笔固定手续费(分)
+	MinAmount   int64 // 单笔最小金额(分)，0不限
+	MaxAmount   int64 // 单笔最大金额(分)，0不限
+}
+
+// PayoutRule 渠道代付(放款)规则
+func PayoutRule(ch *model.LoanPaymentChannels) Rule {
+	return Rule{
+		RatePercent: ch.PayoutFeeRate,
+		Fixed:       int64(ch.PayoutFeeFixed),
+		MinAmount:   int64(ch.PayoutMinAmount),
+		MaxAmount:   int64(ch.PayoutMaxAmount),
+	}
+}
+
+// CollectRule 渠道代收(回款)规则
+func CollectRule(ch *model.LoanPaymentChannels) Rule {
+	return Rule{
+		RatePercent: ch.CollectFeeRate,
+		Fixed:       int64(ch.CollectFeeFixed),
+		MinAmount:   int64(ch.CollectMinAmount),
+		MaxAmount:   int64(ch.CollectMaxAmount),
+	}
+}
+
+// Fee 手续费=金额*费率(四舍五入到分)+固定费
+func (r Rule) Fee(amount int64) int64 {
+	fee := decimal.NewFromInt(amount).
+		Mul(decimal.NewFromInt(int64(r.RatePercent))).
+		Div(decimal.NewFromInt(100)).
+		Round(0).IntPart()
+	return fee + r.Fixed
+}
+
+// Check 校验单笔金额在渠道限额内
+func (r Rule) Check(amount int64) error {
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+	if r.MinAmount > 0 && amount < r.MinAmount {
+		return fmt.Errorf("%w: %d < %d", ErrBelowMin, amount, r.MinAmount)
+	}
+	if r.MaxAmount > 0 && amount > r.MaxAmount {
+		return fmt.Errorf("%w: %d > %d", ErrAboveMax, amount, r.MaxAmount)
+	}
+	return nil
+}
+
+// Payout 放款计费结果
+type Payout struct {
+	Fee       int64 // 代付手续费(分)
+	NetAmount int64 // 到账金额(分)，即提交代付渠道的金额
+}
+
+// ErrFeeExceedsAmount 手续费及前置扣费不小于放款金额
+var ErrFeeExceedsAmount = errors.New("fees exceed the disbursement amount")
+
+// CalcPayout 计算放款手续费与到账金额：到账金额=放款金额-代付手续费-其它前置扣费(如产品前置服务费)，
+// 代付限额按实际提交渠道的到账金额校验
+func CalcPayout(r Rule, disburseAmount int64, otherDeductions int64) (Payout, error) {
+	if disburseAmount <= 0 {
+		return Payout{}, ErrInvalidAmount
+	}
+	p := Payout{Fee: r.Fee(disburseAmount)}
+	p.NetAmount = disburseAmount - p.Fee - otherDeductions
+	if p.NetAmount <= 0 {
+		return Payout{}, ErrFeeExceedsAmount
+	}
+	if err := r.Check(p.NetAmount); err != nil {
+		return Payout{}, err
+	}
+	return p, nil
+}
+
+// Collect 校验代收限额并返回渠道代收手续费(由平台承担，不影响借款人还款金额)
+func Collect(r Rule, payAmount int64) (int64, error) {
+	if err := r.Check(payAmount); err != nil {
+		return 0, err
+	}
+	return r.Fee(payAmount), nil
+}
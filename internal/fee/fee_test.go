@@ -0,0 +1,72 @@
+package fee
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan/internal/model"
+)
+
+func TestRuleFee(t *testing.T) {
+	r := Rule{RatePercent: 3, Fixed: 200}
+	assert.Equal(t, int64(3200), r.Fee(100000))
+	// 四舍五入到分
+	assert.Equal(t, int64(200+1), r.Fee(49)) // 1.47
+	assert.Equal(t, int64(200+2), r.Fee(50)) // 1.5
+	assert.Equal(t, int64(0), Rule{}.Fee(100000))
+}
+
+func TestRuleCheck(t *testing.T) {
+	r := Rule{MinAmount: 1000, MaxAmount: 500000}
+	assert.NoError(t, r.Check(1000))
+	assert.NoError(t, r.Check(500000))
+	assert.ErrorIs(t, r.Check(999), ErrBelowMin)
+	assert.ErrorIs(t, r.Check(500001), ErrAboveMax)
+	assert.ErrorIs(t, r.Check(0), ErrInvalidAmount)
+
+	// 0 表示不限
+	assert.NoError(t, Rule{}.Check(1))
+}
+
+func TestChannelRules(t *testing.T) {
+	ch := &model.LoanPaymentChannels{
+		PayoutFeeRate: 2, PayoutFeeFixed: 100, PayoutMinAmount: 10000, PayoutMaxAmount: 1000000,
+		CollectFeeRate: 1, CollectFeeFixed: 50, CollectMinAmount: 100, CollectMaxAmount: 200000,
+	}
+	assert.Equal(t, Rule{RatePercent: 2, Fixed: 100, MinAmount: 10000, MaxAmount: 1000000}, PayoutRule(ch))
+	assert.Equal(t, Rule{RatePercent: 1, Fixed: 50, MinAmount: 100, MaxAmount: 200000}, CollectRule(ch))
+}
+
+func TestCalcPayout(t *testing.T) {
+	r := Rule{RatePercent: 2, Fixed: 100, MinAmount: 10000, MaxAmount: 100000}
+
+	p, err := CalcPayout(r, 100000, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2100), p.Fee)
+	assert.Equal(t, int64(100000-2100-5000), p.NetAmount)
+
+	// 限额按到账金额校验
+	_, err = CalcPayout(r, 10000, 0)
+	assert.ErrorIs(t, err, ErrBelowMin)
+	_, err = CalcPayout(r, 200000, 0)
+	assert.ErrorIs(t, err, ErrAboveMax)
+
+	_, err = CalcPayout(Rule{RatePercent: 100}, 10000, 0)
+	assert.ErrorIs(t, err, ErrFeeExceedsAmount)
+	_, err = CalcPayout(r, 0, 0)
+	assert.ErrorIs(t, err, ErrInvalidAmount)
+}
+
+func TestCollect(t *testing.T) {
+	r := Rule{RatePercent: 1, Fixed: 50, MinAmount: 100, MaxAmount: 200000}
+	f, err := Collect(r, 20000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(250), f)
+
+	_, err = Collect(r, 99)
+	assert.ErrorIs(t, err, ErrBelowMin)
+	_, err = Collect(r, 200001)
+	assert.ErrorIs(t, err, ErrAboveMax)
+}
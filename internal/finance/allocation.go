@@ -0,0 +1,170 @@
+// Package finance 放置与数据库无关的纯计算逻辑(还款分配、计划生成、罚息、费用等)，便于单元测试。
+package finance
+
+import (
+	"fmt"
+	"strings"
+
+	"loan/internal/model"
+)
+
+// Component 还款分配科目
+type Component string
+
+const (
+	ComponentPenalty   Component = "penalty"   // 罚息
+	ComponentFee       Component = "fee"       // 费用
+	ComponentInterest  Component = "interest"  // 利息
+	ComponentPrincipal Component = "principal" // 本金
+)
+
+// DefaultAllocationOrder 默认冲销顺序：罚息 → 费用 → 利息 → 本金
+var DefaultAllocationOrder = []Component{ComponentPenalty, ComponentFee, ComponentInterest, ComponentPrincipal}
+
+// ParseAllocationOrder 解析逗号分隔的冲销顺序，如 "penalty,fee,interest,principal"。
+// 四个科目必须且只能各出现一次。
+func ParseAllocationOrder(s string) ([]Component, error) {
+	parts := strings.Split(s, ",")
+	seen := make(map[Component]bool, len(parts))
+	order := make([]Component, 0, len(parts))
+	for _, p := range parts {
+		comp := Component(strings.ToLower(strings.TrimSpace(p)))
+		switch comp {
+		case ComponentPenalty, ComponentFee, ComponentInterest, ComponentPrincipal:
+		default:
+			return nil, fmt.Errorf("unknown allocation component %q", p)
+		}
+		if seen[comp] {
+			return nil, fmt.Errorf("duplicate allocation component %q", p)
+		}
+		seen[comp] = true
+		order = append(order, comp)
+	}
+	if len(order) != len(DefaultAllocationOrder) {
+		return nil, fmt.Errorf("allocation order must contain penalty, fee, interest and principal, got %q", s)
+	}
+	return order, nil
+}
+
+// Buckets 按科目拆分的金额(分)
+type Buckets struct {
+	Principal int64
+	Interest  int64
+	Fee       int64
+	Penalty   int64
+}
+
+// Total 各科目合计
+func (b Buckets) Total() int64 {
+	return b.Principal + b.Interest + b.Fee + b.Penalty
+}
+
+func (b *Buckets) get(comp Component) int64 {
+	switch comp {
+	case ComponentPenalty:
+		return b.Penalty
+	case ComponentFee:
+		return b.Fee
+	case ComponentInterest:
+		return b.Interest
+	default:
+		return b.Principal
+	}
+}
+
+func (b *Buckets) add(comp Component, v int64) {
+	switch comp {
+	case ComponentPenalty:
+		b.Penalty += v
+	case ComponentFee:
+		b.Fee += v
+	case ComponentInterest:
+		b.Interest += v
+	default:
+		b.Principal += v
+	}
+}
+
+// Allocation 一笔回款的分配结果
+type Allocation struct {
+	Buckets
+	Remainder int64 // 各科目冲销完后仍剩余的金额(溢缴)
+}
+
+// Allocate 按 order 顺序把 amount 依次冲销到 outstanding 的各科目上。
+// order 为空时使用 DefaultAllocationOrder；负数的未还金额按 0 处理。
+func Allocate(amount int64, outstanding Buckets, order []Component) Allocation {
+	if len(order) == 0 {
+		order = DefaultAllocationOrder
+	}
+	alloc := Allocation{}
+	left := amount
+	for _, comp := range order {
+		if left <= 0 {
+			break
+		}
+		due := outstanding.get(comp)
+		if due <= 0 {
+			continue
+		}
+		part := min(due, left)
+		alloc.add(comp, part)
+		left -= part
+	}
+	if left > 0 {
+		alloc.Remainder = left
+	}
+	return alloc
+}
+
+// ScheduleOutstanding 计算还款计划各科目剩余未还金额
+func ScheduleOutstanding(s *model.LoanRepaymentSchedules) Buckets {
+	return Buckets{
+		Principal: s.PrincipalDue - int64(s.PaidPrincipal),
+		Interest:  s.InterestDue - int64(s.PaidInterest),
+		Fee:       s.FeeDue - int64(s.PaidFee),
+		Penalty:   int64(s.PenaltyDue) - int64(s.PaidPenalty),
+	}
+}
+
+// ApplyToSchedule 把分配结果累加到还款计划的已还科目上
+func ApplyToSchedule(s *model.LoanRepaymentSchedules, alloc Allocation) {
+	s.PaidPrincipal += int(alloc.Principal)
+	s.PaidInterest += int(alloc.Interest)
+	s.PaidFee += int(alloc.Fee)
+	s.PaidPenalty += int(alloc.Penalty)
+	s.PaidTotal += int(alloc.Total())
+}
+
+// ApplyToTransaction 把分配结果写到回款流水的分配字段上
+func ApplyToTransaction(t *model.LoanRepaymentTransactions, alloc Allocation) {
+	t.AllocPrincipal = int(alloc.Principal)
+	t.AllocInterest = int(alloc.Interest)
+	t.AllocFee = int(alloc.Fee)
+	t.AllocPenalty = int(alloc.Penalty)
+}
+
+// TransactionAllocation 读取回款流水上记录的分配结果
+func TransactionAllocation(t *model.LoanRepaymentTransactions) Allocation {
+	return Allocation{Buckets: Buckets{
+		Principal: int64(t.AllocPrincipal),
+		Interest:  int64(t.AllocInterest),
+		Fee:       int64(t.AllocFee),
+		Penalty:   int64(t.AllocPenalty),
+	}}
+}
+
+// RevertFromSchedule 从还款计划已还科目中扣回一笔分配(冲正)，任一科目不足扣回时返回错误且不修改计划
+func RevertFromSchedule(s *model.LoanRepaymentSchedules, alloc Allocation) error {
+	if int64(s.PaidPrincipal) < alloc.Principal || int64(s.PaidInterest) < alloc.Interest ||
+		int64(s.PaidFee) < alloc.Fee || int64(s.PaidPenalty) < alloc.Penalty ||
+		int64(s.PaidTotal) < alloc.Total() {
+		return fmt.Errorf("schedule %d paid amounts are less than the allocation to revert", s.ID)
+	}
+	s.PaidPrincipal -= int(alloc.Principal)
+	s.PaidInterest -= int(alloc.Interest)
+	s.PaidFee -= int(alloc.Fee)
+	s.PaidPenalty -= int(alloc.Penalty)
+	s.PaidTotal -= int(alloc.Total())
+	return nil
+}
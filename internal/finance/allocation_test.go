@@ -0,0 +1,80 @@
+package finance
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"loan/internal/model"
+)
+
+func TestParseAllocationOrder(t *testing.T) {
+	order, err := ParseAllocationOrder(" Principal,interest , fee,penalty")
+	assert.NoError(t, err)
+	assert.Equal(t, []Component{ComponentPrincipal, ComponentInterest, ComponentFee, ComponentPenalty}, order)
+
+	_, err = ParseAllocationOrder("penalty,fee,interest")
+	assert.Error(t, err)
+	_, err = ParseAllocationOrder("penalty,fee,fee,principal")
+	assert.Error(t, err)
+	_, err = ParseAllocationOrder("penalty,fee,tax,principal")
+	assert.Error(t, err)
+}
+
+func TestAllocate(t *testing.T) {
+	outstanding := Buckets{Principal: 10000, Interest: 500, Fee: 300, Penalty: 200}
+
+	// 默认顺序：罚息 → 费用 → 利息 → 本金
+	alloc := Allocate(600, outstanding, nil)
+	assert.Equal(t, Buckets{Penalty: 200, Fee: 300, Interest: 100}, alloc.Buckets)
+	assert.Equal(t, int64(0), alloc.Remainder)
+
+	// 本金优先
+	order := []Component{ComponentPrincipal, ComponentInterest, ComponentFee, ComponentPenalty}
+	alloc = Allocate(10200, outstanding, order)
+	assert.Equal(t, Buckets{Principal: 10000, Interest: 200}, alloc.Buckets)
+
+	// 溢缴
+	alloc = Allocate(12000, outstanding, nil)
+	assert.Equal(t, outstanding, alloc.Buckets)
+	assert.Equal(t, int64(1000), alloc.Remainder)
+	assert.Equal(t, int64(11000), alloc.Total())
+
+	// 负数未还按 0 处理
+	alloc = Allocate(100, Buckets{Penalty: -50, Principal: 100}, nil)
+	assert.Equal(t, Buckets{Principal: 100}, alloc.Buckets)
+}
+
+func TestApplyToSchedule(t *testing.T) {
+	s := &model.LoanRepaymentSchedules{PrincipalDue: 1000, InterestDue: 100, FeeDue: 50, PenaltyDue: 20, PaidPenalty: 20}
+	alloc := Allocate(120, ScheduleOutstanding(s), nil)
+	ApplyToSchedule(s, alloc)
+	assert.Equal(t, 20, s.PaidPenalty)
+	assert.Equal(t, 50, s.PaidFee)
+	assert.Equal(t, 70, s.PaidInterest)
+	assert.Equal(t, 120, s.PaidTotal)
+
+	tr := &model.LoanRepaymentTransactions{}
+	ApplyToTransaction(tr, alloc)
+	assert.Equal(t, 50, tr.AllocFee)
+	assert.Equal(t, 70, tr.AllocInterest)
+	assert.Equal(t, 0, tr.AllocPenalty)
+}
+
+func TestRevertFromSchedule(t *testing.T) {
+	s := &model.LoanRepaymentSchedules{PrincipalDue: 1000, InterestDue: 100}
+	alloc := Allocate(1100, ScheduleOutstanding(s), nil)
+	ApplyToSchedule(s, alloc)
+
+	tr := &model.LoanRepaymentTransactions{}
+	ApplyToTransaction(tr, alloc)
+
+	assert.NoError(t, RevertFromSchedule(s, TransactionAllocation(tr)))
+	assert.Equal(t, 0, s.PaidPrincipal)
+	assert.Equal(t, 0, s.PaidInterest)
+	assert.Equal(t, 0, s.PaidTotal)
+
+	// 再次冲正会使已还金额为负，拒绝且不修改
+	assert.Error(t, RevertFromSchedule(s, TransactionAllocation(tr)))
+	assert.Equal(t, 0, s.PaidTotal)
+}
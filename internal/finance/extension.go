@@ -0,0 +1,103 @@
+package finance
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"loan/internal/model"
+)
+
+// 展期校验失败原因，handler 根据错误类型映射到对应的错误码
+var (
+	ErrExtensionDisabled     = errors.New("loan extension is disabled")
+	ErrExtensionLimitReached = errors.New("maximum extension count reached")
+	ErrInvalidExtensionDays  = errors.New("invalid extension days")
+	ErrScheduleSettled       = errors.New("schedule is already settled")
+)
+
+// ExtensionPolicy 展期规则，来源于系统设置
+type ExtensionPolicy struct {
+	MaxCount    int    // 每笔借款最多展期次数，<=0 表示不允许展期
+	MaxDays     int    // 单次最多展期天数，<=0 表示不限
+	FeeDailyBp  int64  // 展期日费率(万分比)，展期费=剩余本金*日费率*展期天数
+	FeeFixed    int64  // 每次展期固定费用(分)
+	PenaltyMode string // 已产生罚息的处理方式：FREEZE 保留待还(展期后不再计提) WAIVE 免除未还罚息
+}
+
+// Extension 一次展期的结果
+type Extension struct {
+	Fee           int64 // 展期费(分)
+	PenaltyWaived int64 // 免除的罚息(分)
+	DueDateBefore time.Time
+	DueDateAfter  time.Time
+	StatusBefore  int
+}
+
+// CheckExtension 校验期次能否展期，used 为该笔借款已展期次数
+func CheckExtension(s *model.LoanRepaymentSchedules, used int, days int, p ExtensionPolicy) error {
+	if p.MaxCount <= 0 {
+		return ErrExtensionDisabled
+	}
+	if used >= p.MaxCount {
+		return fmt.Errorf("%w: %d/%d", ErrExtensionLimitReached, used, p.MaxCount)
+	}
+	if days <= 0 || (p.MaxDays > 0 && days > p.MaxDays) {
+		return fmt.Errorf("%w: %d", ErrInvalidExtensionDays, days)
+	}
+	if s.Status == model.ScheduleStatusSettled {
+		return ErrScheduleSettled
+	}
+	if s.DueDate == nil {
+		return fmt.Errorf("schedule %d has no due date", s.ID)
+	}
+	return nil
+}
+
+// ExtensionFee 展期费=剩余未还本金*日费率*展期天数(四舍五入到分)+固定费用
+func ExtensionFee(s *model.LoanRepaymentSchedules, days int, p ExtensionPolicy) int64 {
+	principal := max(s.PrincipalDue-int64(s.PaidPrincipal), 0)
+	fee := decimal.NewFromInt(principal).
+		Mul(decimal.NewFromInt(p.FeeDailyBp)).
+		Mul(decimal.NewFromInt(int64(days))).
+		Div(decimal.NewFromInt(10000)).
+		Round(0).IntPart()
+	return fee + p.FeeFixed
+}
+
+// ApplyExtension 展期：应还日期顺延 days 天，展期费计入应还费用并记为已还(展期费需当场支付)，
+// 按 PenaltyMode 免除未还罚息，并按新的应还日期重新计算期次状态
+func ApplyExtension(s *model.LoanRepaymentSchedules, days int, fee int64, p ExtensionPolicy, now time.Time) Extension {
+	ext := Extension{
+		Fee:           fee,
+		DueDateBefore: *s.DueDate,
+		StatusBefore:  s.Status,
+	}
+	ShiftDueDate(s, days)
+	ext.DueDateAfter = *s.DueDate
+
+	if fee > 0 {
+		s.FeeDue += fee
+		s.TotalDue += fee
+		ApplyToSchedule(s, Allocation{Buckets: Buckets{Fee: fee}})
+		s.LastPaidAt = &now
+	}
+	if p.PenaltyMode == model.ExtensionPenaltyWaive {
+		ext.PenaltyWaived = max(int64(s.PenaltyDue-s.PaidPenalty), 0)
+		s.PenaltyDue -= int(ext.PenaltyWaived)
+		s.TotalDue -= ext.PenaltyWaived
+	}
+	RefreshScheduleStatus(s, now)
+	return ext
+}
+
+// ShiftDueDate 应还日期顺延 days 天
+func ShiftDueDate(s *model.LoanRepaymentSchedules, days int) {
+	if s.DueDate == nil {
+		return
+	}
+	due := s.DueDate.AddDate(0, 0, days)
+	s.DueDate = &due
+}
@@ -0,0 +1,95 @@
+package finance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan/internal/model"
+)
+
+// overdueSchedule 本金10000已还4000，利息500，罚息200未还，已逾期
+func overdueSchedule() *model.LoanRepaymentSchedules {
+	due := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	s := &model.LoanRepaymentSchedules{
+		InstallmentNo: 1,
+		DueDate:       &due,
+		PrincipalDue:  10000,
+		InterestDue:   500,
+		PenaltyDue:    200,
+		TotalDue:      10700,
+		PaidPrincipal: 4000,
+		PaidTotal:     4000,
+		Status:        model.ScheduleStatusOverdue,
+	}
+	s.ID = 1
+	return s
+}
+
+func TestCheckExtension(t *testing.T) {
+	s := overdueSchedule()
+	p := ExtensionPolicy{MaxCount: 2, MaxDays: 14}
+
+	assert.ErrorIs(t, CheckExtension(s, 0, 7, ExtensionPolicy{}), ErrExtensionDisabled)
+	assert.ErrorIs(t, CheckExtension(s, 2, 7, p), ErrExtensionLimitReached)
+	assert.ErrorIs(t, CheckExtension(s, 0, 0, p), ErrInvalidExtensionDays)
+	assert.ErrorIs(t, CheckExtension(s, 0, 15, p), ErrInvalidExtensionDays)
+	assert.NoError(t, CheckExtension(s, 1, 14, p))
+	assert.NoError(t, CheckExtension(s, 1, 30, ExtensionPolicy{MaxCount: 2}))
+
+	s.Status = model.ScheduleStatusSettled
+	assert.ErrorIs(t, CheckExtension(s, 0, 7, p), ErrScheduleSettled)
+}
+
+func TestExtensionFee(t *testing.T) {
+	s := overdueSchedule()
+	// 剩余本金6000 * 0.15% * 7天 = 63，加固定费用100
+	assert.Equal(t, int64(163), ExtensionFee(s, 7, ExtensionPolicy{FeeDailyBp: 15, FeeFixed: 100}))
+	assert.Equal(t, int64(0), ExtensionFee(s, 7, ExtensionPolicy{}))
+}
+
+func TestApplyExtensionFreeze(t *testing.T) {
+	s := overdueSchedule()
+	now := time.Date(2026, 3, 5, 12, 0, 0, 0, time.UTC)
+
+	ext := ApplyExtension(s, 14, 163, ExtensionPolicy{PenaltyMode: model.ExtensionPenaltyFreeze}, now)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), ext.DueDateBefore)
+	assert.Equal(t, time.Date(2026, 3, 15, 0, 0, 0, 0, time.UTC), ext.DueDateAfter)
+	assert.Equal(t, model.ScheduleStatusOverdue, ext.StatusBefore)
+	assert.Equal(t, int64(0), ext.PenaltyWaived)
+
+	// 展期费计入应还费用并记为已还，罚息保留，期次不再逾期
+	assert.Equal(t, int64(163), s.FeeDue)
+	assert.Equal(t, 163, s.PaidFee)
+	assert.Equal(t, 200, s.PenaltyDue)
+	assert.Equal(t, int64(10863), s.TotalDue)
+	assert.Equal(t, 4163, s.PaidTotal)
+	assert.Equal(t, model.ScheduleStatusUnpaid, s.Status)
+	require.NotNil(t, s.LastPaidAt)
+}
+
+func TestApplyExtensionWaive(t *testing.T) {
+	s := overdueSchedule()
+	s.PaidPenalty = 50
+	s.PaidTotal += 50
+	now := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+
+	ext := ApplyExtension(s, 7, 0, ExtensionPolicy{PenaltyMode: model.ExtensionPenaltyWaive}, now)
+	assert.Equal(t, int64(150), ext.PenaltyWaived)
+	assert.Equal(t, 50, s.PenaltyDue)
+	assert.Equal(t, int64(10550), s.TotalDue)
+	assert.Equal(t, int64(0), s.FeeDue)
+	assert.Nil(t, s.LastPaidAt)
+}
+
+func TestShiftDueDate(t *testing.T) {
+	s := &model.LoanRepaymentSchedules{}
+	ShiftDueDate(s, 7)
+	assert.Nil(t, s.DueDate)
+
+	s = overdueSchedule()
+	ShiftDueDate(s, 31)
+	assert.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), *s.DueDate)
+}
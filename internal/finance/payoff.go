@@ -0,0 +1,158 @@
+package finance
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"loan/internal/model"
+)
+
+// ErrNothingToPayoff 放款单没有未结清的期次
+var ErrNothingToPayoff = errors.New("no open schedules to pay off")
+
+// PayoffPolicy 提前结清规则，来源于产品配置
+type PayoffPolicy struct {
+	FeeBp      int64 // 提前结清手续费率(占剩余本金万分比)
+	FeeFixed   int64 // 提前结清固定手续费(分)
+	DiscountBp int64 // 已计利息及费用的减免比例(万分比)
+}
+
+// PayoffPolicyFromProduct 读取产品的提前结清配置，未关联产品的历史借款不收手续费、不减免
+func PayoffPolicyFromProduct(p *model.LoanProducts) PayoffPolicy {
+	if p == nil {
+		return PayoffPolicy{}
+	}
+	return PayoffPolicy{
+		FeeBp:      int64(p.PayoffFeeBp),
+		FeeFixed:   p.PayoffFeeFixed,
+		DiscountBp: int64(p.PayoffDiscountBp),
+	}
+}
+
+// PayoffItem 单个未结清期次的结清金额
+type PayoffItem struct {
+	ScheduleID    uint64
+	InstallmentNo int
+	Due           Buckets // 本期结清需支付的各科目金额(已扣减免，最后一期含提前结清手续费)
+	Waived        Buckets // 本期免收的利息/费用(未计提部分及减免)
+	Discount      Buckets // 本期按 DiscountBp 减免的利息/费用(已计提，包含在 Waived 中)
+}
+
+// PayoffQuote 放款单提前结清报价
+type PayoffQuote struct {
+	On          time.Time
+	Items       []PayoffItem
+	Outstanding Buckets // 剩余本金 + 截至 On 已计提的利息/费用 + 罚息(减免前)
+	Unaccrued   Buckets // 尚未计提、提前结清免收的利息/费用
+	Discount    int64   // 按 DiscountBp 减免的利息/费用
+	Fee         int64   // 提前结清手续费，计入最后一期的费用
+	Total       int64   // 结清应付总额 = Outstanding - Discount + Fee
+}
+
+// QuotePayoff 计算放款单在 on 当天提前结清的应付金额。
+// schedules 为放款单的全部期次(含已结清期次，用于确定各期计息起点)，start 为起息日(放款日)。
+// 到期(含当天)期次的利息/费用全额计收；当前计息期按已过天数/本期天数计提；未开始的计息期不收利息/费用；
+// 本金与罚息全额计收。减免按比例冲减已计利息/费用，手续费按剩余本金计算。
+func QuotePayoff(schedules []*model.LoanRepaymentSchedules, start time.Time, on time.Time, p PayoffPolicy) (*PayoffQuote, error) {
+	sorted := make([]*model.LoanRepaymentSchedules, len(schedules))
+	copy(sorted, schedules)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].InstallmentNo < sorted[j].InstallmentNo })
+
+	q := &PayoffQuote{On: on}
+	periodStart := start
+	for _, s := range sorted {
+		dueDate := periodStart
+		if s.DueDate != nil {
+			dueDate = *s.DueDate
+		}
+		ratio := accrualRatio(periodStart, dueDate, on)
+		periodStart = dueDate
+		if s.Status == model.ScheduleStatusSettled {
+			continue
+		}
+
+		out := ScheduleOutstanding(s)
+		item := PayoffItem{ScheduleID: s.ID, InstallmentNo: s.InstallmentNo}
+		item.Due.Principal = max(out.Principal, 0)
+		item.Due.Penalty = max(out.Penalty, 0)
+		item.Due.Interest = max(ratio.Mul(decimal.NewFromInt(s.InterestDue)).Round(0).IntPart()-int64(s.PaidInterest), 0)
+		item.Due.Fee = max(ratio.Mul(decimal.NewFromInt(s.FeeDue)).Round(0).IntPart()-int64(s.PaidFee), 0)
+		item.Waived.Interest = max(out.Interest-item.Due.Interest, 0)
+		item.Waived.Fee = max(out.Fee-item.Due.Fee, 0)
+
+		q.Outstanding = addBuckets(q.Outstanding, item.Due)
+		q.Unaccrued = addBuckets(q.Unaccrued, item.Waived)
+		q.Items = append(q.Items, item)
+	}
+	if len(q.Items) == 0 {
+		return nil, ErrNothingToPayoff
+	}
+
+	// 减免：从最后一期往前依次冲减费用、利息
+	if p.DiscountBp > 0 {
+		q.Discount = bpOf(q.Outstanding.Interest+q.Outstanding.Fee, min(p.DiscountBp, 10000))
+		left := q.Discount
+		for i := len(q.Items) - 1; i >= 0 && left > 0; i-- {
+			item := &q.Items[i]
+			for _, comp := range []Component{ComponentFee, ComponentInterest} {
+				part := min(item.Due.get(comp), left)
+				item.Due.add(comp, -part)
+				item.Waived.add(comp, part)
+				item.Discount.add(comp, part)
+				left -= part
+			}
+		}
+	}
+
+	// 手续费：结清剩余本金时收取，计入最后一期
+	if q.Outstanding.Principal > 0 && (p.FeeBp > 0 || p.FeeFixed > 0) {
+		q.Fee = bpOf(q.Outstanding.Principal, p.FeeBp) + p.FeeFixed
+		q.Items[len(q.Items)-1].Due.Fee += q.Fee
+	}
+
+	q.Total = q.Outstanding.Total() - q.Discount + q.Fee
+	return q, nil
+}
+
+// ApplyPayoff 按结清明细调整期次应还(免收部分从应还利息/费用中扣除，手续费计入应还费用)，
+// 并把本期结清金额记为已还，返回本期的分配结果
+func ApplyPayoff(s *model.LoanRepaymentSchedules, item PayoffItem, now time.Time) Allocation {
+	s.InterestDue = int64(s.PaidInterest) + item.Due.Interest
+	s.FeeDue = int64(s.PaidFee) + item.Due.Fee
+	s.TotalDue = s.PrincipalDue + s.InterestDue + s.FeeDue + int64(s.PenaltyDue)
+
+	alloc := Allocation{Buckets: item.Due}
+	ApplyToSchedule(s, alloc)
+	s.LastPaidAt = &now
+	RefreshScheduleStatus(s, now)
+	return alloc
+}
+
+// accrualRatio 计息期 [periodStart, dueDate] 在 on 当天已计提的比例
+func accrualRatio(periodStart time.Time, dueDate time.Time, on time.Time) decimal.Decimal {
+	total := OverdueDays(periodStart, dueDate)
+	elapsed := OverdueDays(periodStart, on)
+	if total <= 0 || elapsed >= total {
+		return decimal.NewFromInt(1)
+	}
+	return decimal.NewFromInt(int64(elapsed)).Div(decimal.NewFromInt(int64(total)))
+}
+
+func bpOf(amount int64, bp int64) int64 {
+	return decimal.NewFromInt(amount).
+		Mul(decimal.NewFromInt(bp)).
+		Div(decimal.NewFromInt(10000)).
+		Round(0).IntPart()
+}
+
+func addBuckets(a Buckets, b Buckets) Buckets {
+	return Buckets{
+		Principal: a.Principal + b.Principal,
+		Interest:  a.Interest + b.Interest,
+		Fee:       a.Fee + b.Fee,
+		Penalty:   a.Penalty + b.Penalty,
+	}
+}
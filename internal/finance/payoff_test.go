@@ -0,0 +1,158 @@
+package finance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan/internal/model"
+)
+
+// payoffSchedules 3期等额本金，每期30天：本金各10000，利息3000/2000/1000，费用各300，第1期已结清
+func payoffSchedules() []*model.LoanRepaymentSchedules {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	var list []*model.LoanRepaymentSchedules
+	for i, interest := range []int64{3000, 2000, 1000} {
+		due := start.AddDate(0, 0, 30*(i+1))
+		s := &model.LoanRepaymentSchedules{
+			InstallmentNo: i + 1,
+			DueDate:       &due,
+			PrincipalDue:  10000,
+			InterestDue:   interest,
+			FeeDue:        300,
+			TotalDue:      10000 + interest + 300,
+			Status:        model.ScheduleStatusUnpaid,
+		}
+		s.ID = uint64(i + 1)
+		list = append(list, s)
+	}
+	first := list[0]
+	first.PaidPrincipal, first.PaidInterest, first.PaidFee = 10000, 3000, 300
+	first.PaidTotal = int(first.TotalDue)
+	first.Status = model.ScheduleStatusSettled
+	// 倒序传入，QuotePayoff 按期次排序
+	return []*model.LoanRepaymentSchedules{list[2], list[1], list[0]}
+}
+
+func TestQuotePayoffAccrued(t *testing.T) {
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+	on := time.Date(2026, 2, 15, 18, 0, 0, 0, time.UTC) // 第2期计息 15/30 天
+
+	q, err := QuotePayoff(payoffSchedules(), start, on, PayoffPolicy{})
+	require.NoError(t, err)
+	require.Len(t, q.Items, 2)
+
+	assert.Equal(t, Buckets{Principal: 10000, Interest: 1000, Fee: 150}, q.Items[0].Due)
+	assert.Equal(t, Buckets{Interest: 1000, Fee: 150}, q.Items[0].Waived)
+	assert.Equal(t, Buckets{Principal: 10000}, q.Items[1].Due)
+	assert.Equal(t, Buckets{Interest: 1000, Fee: 300}, q.Items[1].Waived)
+
+	assert.Equal(t, Buckets{Principal: 20000, Interest: 1000, Fee: 150}, q.Outstanding)
+	assert.Equal(t, Buckets{Interest: 2000, Fee: 450}, q.Unaccrued)
+	assert.Equal(t, int64(21150), q.Total)
+}
+
+func TestQuotePayoffDiscountAndFee(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	on := time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC)
+
+	q, err := QuotePayoff(payoffSchedules(), start, on, PayoffPolicy{FeeBp: 100, FeeFixed: 50, DiscountBp: 2000})
+	require.NoError(t, err)
+
+	// 减免 (1000+150)*20%=230，从最后一期往前先冲费用再冲利息
+	assert.Equal(t, int64(230), q.Discount)
+	// 手续费 20000*1%+50=250，计入最后一期费用
+	assert.Equal(t, int64(250), q.Fee)
+	assert.Equal(t, Buckets{Principal: 10000, Interest: 920}, q.Items[0].Due)
+	assert.Equal(t, Buckets{Interest: 1080, Fee: 300}, q.Items[0].Waived)
+	assert.Equal(t, Buckets{Interest: 80, Fee: 150}, q.Items[0].Discount)
+	assert.Equal(t, Buckets{}, q.Items[1].Discount)
+	assert.Equal(t, Buckets{Principal: 10000, Fee: 250}, q.Items[1].Due)
+	assert.Equal(t, int64(21170), q.Total)
+
+	var sum int64
+	for _, item := range q.Items {
+		sum += item.Due.Total()
+	}
+	assert.Equal(t, q.Total, sum)
+}
+
+func TestQuotePayoffOverdue(t *testing.T) {
+	schedules := payoffSchedules()
+	second := schedules[1]
+	second.PenaltyDue = 120
+	second.TotalDue += 120
+	second.PaidInterest = 500
+	second.PaidTotal = 500
+	second.Status = model.ScheduleStatusOverdue
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	on := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC) // 第2期已到期，第3期计息 8/30 天
+
+	q, err := QuotePayoff(schedules, start, on, PayoffPolicy{})
+	require.NoError(t, err)
+	assert.Equal(t, Buckets{Principal: 10000, Interest: 1500, Fee: 300, Penalty: 120}, q.Items[0].Due)
+	assert.Equal(t, Buckets{}, q.Items[0].Waived)
+	assert.Equal(t, Buckets{Principal: 10000, Interest: 267, Fee: 80}, q.Items[1].Due)
+}
+
+func TestQuotePayoffPrepaidInterest(t *testing.T) {
+	schedules := payoffSchedules()
+	second := schedules[1]
+	second.PaidInterest = 1800
+	second.PaidTotal = 1800
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	on := time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC)
+
+	q, err := QuotePayoff(schedules, start, on, PayoffPolicy{})
+	require.NoError(t, err)
+	// 已还利息超过已计提部分时不退还，也不再收取
+	assert.Equal(t, Buckets{Principal: 10000, Fee: 150}, q.Items[0].Due)
+	assert.Equal(t, Buckets{Interest: 200, Fee: 150}, q.Items[0].Waived)
+}
+
+func TestQuotePayoffNothingOpen(t *testing.T) {
+	schedules := payoffSchedules()
+	for _, s := range schedules {
+		s.Status = model.ScheduleStatusSettled
+	}
+	_, err := QuotePayoff(schedules, time.Now(), time.Now(), PayoffPolicy{})
+	assert.ErrorIs(t, err, ErrNothingToPayoff)
+}
+
+func TestApplyPayoff(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	on := time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC)
+	schedules := payoffSchedules()
+	q, err := QuotePayoff(schedules, start, on, PayoffPolicy{FeeBp: 100, FeeFixed: 50, DiscountBp: 2000})
+	require.NoError(t, err)
+
+	byID := map[uint64]*model.LoanRepaymentSchedules{}
+	for _, s := range schedules {
+		byID[s.ID] = s
+	}
+	var paid int64
+	for _, item := range q.Items {
+		s := byID[item.ScheduleID]
+		alloc := ApplyPayoff(s, item, on)
+		paid += alloc.Total()
+		assert.Equal(t, model.ScheduleStatusSettled, s.Status)
+		assert.Equal(t, s.TotalDue, int64(s.PaidTotal))
+		assert.NotNil(t, s.SettledAt)
+	}
+	assert.Equal(t, q.Total, paid)
+
+	third := byID[3]
+	assert.Equal(t, int64(0), third.InterestDue)
+	assert.Equal(t, int64(250), third.FeeDue)
+	assert.Equal(t, int64(10250), third.TotalDue)
+}
+
+func TestPayoffPolicyFromProduct(t *testing.T) {
+	assert.Equal(t, PayoffPolicy{}, PayoffPolicyFromProduct(nil))
+	p := &model.LoanProducts{PayoffFeeBp: 150, PayoffFeeFixed: 500, PayoffDiscountBp: 3000}
+	assert.Equal(t, PayoffPolicy{FeeBp: 150, FeeFixed: 500, DiscountBp: 3000}, PayoffPolicyFromProduct(p))
+}
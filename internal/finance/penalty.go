@@ -0,0 +1,87 @@
+package finance
+
+import (
+	"math"
+	"time"
+
+	"loan/internal/model"
+)
+
+// PenaltyPolicy 罚息计提规则，来源于系统设置
+type PenaltyPolicy struct {
+	DailyRateBp int64 // 日罚息率(万分比)，按剩余未还本金计提
+	GraceDays   int   // 宽限天数，逾期天数不超过宽限期时不计提
+	CapPercent  int64 // 罚息累计上限(占应还本金的百分比)，<=0 表示不设上限
+}
+
+// PenaltyAccrual 单个期次某一天的罚息计提结果
+type PenaltyAccrual struct {
+	Date        time.Time // 计提日期
+	OverdueDays int
+	BaseAmount  int64 // 计提基数(分)
+	Amount      int64 // 本次计提金额(分)，已按上限截断
+}
+
+// OverdueDays 计算 on 当天相对应还日期的逾期天数(按 on 的时区取日期，与 IsPastDue 一致)，未逾期返回 0
+func OverdueDays(dueDate time.Time, on time.Time) int {
+	days := DateOnly(on, on.Location()).Sub(DateOnly(dueDate, on.Location())).Hours() / 24
+	if days <= 0 {
+		return 0
+	}
+	return int(math.Round(days))
+}
+
+// DailyPenalty 计算期次在 on 当天应计提的罚息
+func DailyPenalty(s *model.LoanRepaymentSchedules, on time.Time, p PenaltyPolicy) PenaltyAccrual {
+	result := PenaltyAccrual{Date: DateOnly(on, on.Location())}
+	if s.DueDate == nil || p.DailyRateBp <= 0 {
+		return result
+	}
+
+	result.OverdueDays = OverdueDays(*s.DueDate, on)
+	if result.OverdueDays == 0 || result.OverdueDays <= p.GraceDays {
+		return result
+	}
+
+	result.BaseAmount = s.PrincipalDue - int64(s.PaidPrincipal)
+	if result.BaseAmount <= 0 {
+		return result
+	}
+
+	amount := result.BaseAmount * p.DailyRateBp / 10000
+	if p.CapPercent > 0 {
+		capLeft := s.PrincipalDue*p.CapPercent/100 - int64(s.PenaltyDue)
+		amount = min(amount, capLeft)
+	}
+	result.Amount = max(amount, 0)
+	return result
+}
+
+// BackfillPenalties 计算 from 至 to(含)每天应计提的罚息(任务漏跑的日期一并补提)，只返回金额大于 0 的计提。
+// 逐日累加应还罚息以按上限截断，计提基数取当前剩余未还本金；不修改 s
+func BackfillPenalties(s *model.LoanRepaymentSchedules, from, to time.Time, p PenaltyPolicy) []PenaltyAccrual {
+	sim := *s
+	var list []PenaltyAccrual
+	for day, end := DateOnly(from, to.Location()), DateOnly(to, to.Location()); !day.After(end); day = day.AddDate(0, 0, 1) {
+		result := DailyPenalty(&sim, day, p)
+		if result.Amount <= 0 {
+			continue
+		}
+		sim.PenaltyDue += int(result.Amount)
+		list = append(list, result)
+	}
+	return list
+}
+
+// RecomputePenalty 以计提流水合计减去已减免的罚息重算应还罚息(不低于 0)，应还总额同步调整并重新计算状态，
+// 返回调整金额(重算后-重算前)
+func RecomputePenalty(s *model.LoanRepaymentSchedules, accrued, waived int64, now time.Time) int64 {
+	delta := max(accrued-waived, 0) - int64(s.PenaltyDue)
+	if delta == 0 {
+		return 0
+	}
+	s.PenaltyDue += int(delta)
+	s.TotalDue += delta
+	RefreshScheduleStatus(s, now)
+	return delta
+}
@@ -0,0 +1,95 @@
+package finance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan/internal/model"
+)
+
+func TestOverdueDays(t *testing.T) {
+	due := time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)
+	assert.Equal(t, 0, OverdueDays(due, time.Date(2026, 3, 10, 18, 0, 0, 0, time.Local)))
+	assert.Equal(t, 1, OverdueDays(due, time.Date(2026, 3, 11, 0, 30, 0, 0, time.Local)))
+	assert.Equal(t, 31, OverdueDays(due, time.Date(2026, 4, 10, 0, 30, 0, 0, time.Local)))
+
+	// UTC+8：当地零点的应还日期从数据库读出为前一天 16:00 UTC，仍按当地日期计算
+	cst := time.FixedZone("CST", 8*3600)
+	due = time.Date(2026, 3, 10, 0, 0, 0, 0, cst).UTC()
+	assert.Equal(t, 0, OverdueDays(due, time.Date(2026, 3, 10, 9, 0, 0, 0, cst)))
+	assert.False(t, IsPastDue(due, time.Date(2026, 3, 10, 23, 59, 0, 0, cst)))
+	assert.Equal(t, 1, OverdueDays(due, time.Date(2026, 3, 11, 0, 30, 0, 0, cst)))
+	assert.True(t, IsPastDue(due, time.Date(2026, 3, 11, 0, 30, 0, 0, cst)))
+}
+
+func TestDailyPenalty(t *testing.T) {
+	due := time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)
+	s := &model.LoanRepaymentSchedules{DueDate: &due, PrincipalDue: 100000, PaidPrincipal: 20000}
+	policy := PenaltyPolicy{DailyRateBp: 50, GraceDays: 2, CapPercent: 100}
+
+	// 宽限期内不计提
+	r := DailyPenalty(s, time.Date(2026, 3, 12, 1, 0, 0, 0, time.Local), policy)
+	assert.Equal(t, 2, r.OverdueDays)
+	assert.Equal(t, int64(0), r.Amount)
+
+	// 剩余本金 80000 * 0.5% = 400
+	r = DailyPenalty(s, time.Date(2026, 3, 13, 1, 0, 0, 0, time.Local), policy)
+	assert.Equal(t, 3, r.OverdueDays)
+	assert.Equal(t, int64(80000), r.BaseAmount)
+	assert.Equal(t, int64(400), r.Amount)
+
+	// 触达上限：上限 100000，已计 99800
+	s.PenaltyDue = 99800
+	r = DailyPenalty(s, time.Date(2026, 3, 13, 1, 0, 0, 0, time.Local), policy)
+	assert.Equal(t, int64(200), r.Amount)
+
+	s.PenaltyDue = 100000
+	r = DailyPenalty(s, time.Date(2026, 3, 13, 1, 0, 0, 0, time.Local), policy)
+	assert.Equal(t, int64(0), r.Amount)
+
+	// 不设上限
+	policy.CapPercent = 0
+	r = DailyPenalty(s, time.Date(2026, 3, 13, 1, 0, 0, 0, time.Local), policy)
+	assert.Equal(t, int64(400), r.Amount)
+
+	// 未配置费率
+	r = DailyPenalty(s, time.Date(2026, 3, 13, 1, 0, 0, 0, time.Local), PenaltyPolicy{})
+	assert.Equal(t, int64(0), r.Amount)
+}
+
+func TestBackfillPenalties(t *testing.T) {
+	due := time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)
+	s := &model.LoanRepaymentSchedules{DueDate: &due, PrincipalDue: 100000, PaidPrincipal: 20000, PenaltyDue: 99000}
+	policy := PenaltyPolicy{DailyRateBp: 50, GraceDays: 2, CapPercent: 100}
+
+	// 03-11、03-12 在宽限期内；逐日累加后 03-15 触达上限，03-16 不再计提
+	list := BackfillPenalties(s, time.Date(2026, 3, 11, 0, 0, 0, 0, time.Local), time.Date(2026, 3, 16, 0, 30, 0, 0, time.Local), policy)
+	require.Len(t, list, 3)
+	assert.Equal(t, time.Date(2026, 3, 13, 0, 0, 0, 0, time.Local), list[0].Date)
+	assert.Equal(t, []int64{400, 400, 200}, []int64{list[0].Amount, list[1].Amount, list[2].Amount})
+	assert.Equal(t, 5, list[2].OverdueDays)
+	assert.Equal(t, 99000, s.PenaltyDue)
+
+	// 起始日期晚于截止日期
+	assert.Empty(t, BackfillPenalties(s, time.Date(2026, 3, 17, 0, 0, 0, 0, time.Local), time.Date(2026, 3, 16, 0, 30, 0, 0, time.Local), policy))
+}
+
+func TestRecomputePenalty(t *testing.T) {
+	s := &model.LoanRepaymentSchedules{PrincipalDue: 100000, PenaltyDue: 1000, TotalDue: 101000, Status: model.ScheduleStatusOverdue}
+	now := time.Date(2026, 3, 20, 10, 0, 0, 0, time.Local)
+
+	// 计提 1500，减免 300，应还罚息 1200
+	assert.Equal(t, int64(200), RecomputePenalty(s, 1500, 300, now))
+	assert.Equal(t, 1200, s.PenaltyDue)
+	assert.Equal(t, int64(101200), s.TotalDue)
+	assert.Equal(t, int64(0), RecomputePenalty(s, 1500, 300, now))
+
+	// 减免超过计提时应还罚息为 0，已还清则结清
+	s.PaidTotal = 100000
+	assert.Equal(t, int64(-1200), RecomputePenalty(s, 100, 300, now))
+	assert.Equal(t, 0, s.PenaltyDue)
+	assert.Equal(t, model.ScheduleStatusSettled, s.Status)
+}
@@ -0,0 +1,120 @@
+package finance
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"loan/internal/model"
+)
+
+// 申请校验失败原因，handler 根据错误类型映射到对应的错误码
+var (
+	ErrProductInactive     = errors.New("product is not active")
+	ErrAmountOutOfRange    = errors.New("application amount is out of the product range")
+	ErrLoanDaysNotAllowed  = errors.New("loan days is not allowed by the product")
+	ErrInvalidProductSetup = errors.New("invalid product configuration")
+)
+
+// ParseAllowedDays 解析逗号分隔的可选借款天数，空字符串表示不限制
+func ParseAllowedDays(s string) ([]int, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	var days []int
+	for _, p := range strings.Split(s, ",") {
+		d, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid loan days %q", p)
+		}
+		days = append(days, d)
+	}
+	return days, nil
+}
+
+// ProductActiveAt 产品在 now 时是否处于上架且在生效期内
+func ProductActiveAt(p *model.LoanProducts, now time.Time) bool {
+	if p.Status != 1 {
+		return false
+	}
+	if p.ActiveFrom != nil && now.Before(*p.ActiveFrom) {
+		return false
+	}
+	if p.ActiveTo != nil && now.After(*p.ActiveTo) {
+		return false
+	}
+	return true
+}
+
+// ValidateProduct 校验产品配置是否自洽
+func ValidateProduct(p *model.LoanProducts) error {
+	switch p.RepaymentMethod {
+	case model.RepaymentMethodBullet:
+		if p.InstallmentCount > 1 {
+			return fmt.Errorf("%w: bullet repayment only supports one installment", ErrInvalidProductSetup)
+		}
+	case model.RepaymentMethodEqualInstallment, model.RepaymentMethodEqualPrincipal:
+		if p.InstallmentCount < 1 {
+			return fmt.Errorf("%w: installment count must be at least 1", ErrInvalidProductSetup)
+		}
+	default:
+		return fmt.Errorf("%w: unknown repayment method %q", ErrInvalidProductSetup, p.RepaymentMethod)
+	}
+
+	switch p.FeeModel {
+	case model.FeeModelNone, model.FeeModelUpfront, model.FeeModelInstallment:
+	default:
+		return fmt.Errorf("%w: unknown fee model %q", ErrInvalidProductSetup, p.FeeModel)
+	}
+
+	if p.InterestRateBp < 0 || p.ServiceFeeBp < 0 || p.ServiceFeeFixed < 0 || p.PenaltyDailyRateBp < 0 || p.InstallmentDays < 0 {
+		return fmt.Errorf("%w: rates and fees cannot be negative", ErrInvalidProductSetup)
+	}
+	if p.PayoffFeeBp < 0 || p.PayoffFeeFixed < 0 || p.PayoffDiscountBp < 0 || p.PayoffDiscountBp > 10000 {
+		return fmt.Errorf("%w: invalid early settlement fee or discount", ErrInvalidProductSetup)
+	}
+	if p.MinAmount < 0 || p.MaxAmount < 0 || (p.MaxAmount > 0 && p.MinAmount > p.MaxAmount) {
+		return fmt.Errorf("%w: invalid amount range", ErrInvalidProductSetup)
+	}
+	if p.ActiveFrom != nil && p.ActiveTo != nil && p.ActiveTo.Before(*p.ActiveFrom) {
+		return fmt.Errorf("%w: invalid active window", ErrInvalidProductSetup)
+	}
+	if _, err := ParseAllowedDays(p.AllowedDays); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProductSetup, err)
+	}
+	if p.AllocationOrder != "" {
+		if _, err := ParseAllocationOrder(p.AllocationOrder); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidProductSetup, err)
+		}
+	}
+	return nil
+}
+
+// ValidateApplication 校验借款申请的金额和天数是否符合产品要求
+func ValidateApplication(p *model.LoanProducts, amount int64, loanDays int, now time.Time) error {
+	if !ProductActiveAt(p, now) {
+		return ErrProductInactive
+	}
+	if amount <= 0 || (p.MinAmount > 0 && amount < p.MinAmount) || (p.MaxAmount > 0 && amount > p.MaxAmount) {
+		return ErrAmountOutOfRange
+	}
+	days, err := ParseAllowedDays(p.AllowedDays)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidProductSetup, err)
+	}
+	if len(days) == 0 {
+		if loanDays <= 0 {
+			return ErrLoanDaysNotAllowed
+		}
+		return nil
+	}
+	for _, d := range days {
+		if d == loanDays {
+			return nil
+		}
+	}
+	return ErrLoanDaysNotAllowed
+}
@@ -0,0 +1,85 @@
+package finance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"loan/internal/model"
+)
+
+func TestParseAllowedDays(t *testing.T) {
+	days, err := ParseAllowedDays(" 7, 14,30 ")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{7, 14, 30}, days)
+
+	days, err = ParseAllowedDays("")
+	assert.NoError(t, err)
+	assert.Nil(t, days)
+
+	_, err = ParseAllowedDays("7,abc")
+	assert.Error(t, err)
+	_, err = ParseAllowedDays("7,-1")
+	assert.Error(t, err)
+}
+
+func TestValidateProduct(t *testing.T) {
+	p := &model.LoanProducts{
+		RepaymentMethod:  model.RepaymentMethodEqualPrincipal,
+		InstallmentCount: 3,
+		FeeModel:         model.FeeModelUpfront,
+		MinAmount:        10000,
+		MaxAmount:        500000,
+		AllowedDays:      "30,60,90",
+	}
+	assert.NoError(t, ValidateProduct(p))
+
+	p.AllocationOrder = "fee,interest"
+	assert.ErrorIs(t, ValidateProduct(p), ErrInvalidProductSetup)
+	p.AllocationOrder = ""
+
+	p.MinAmount = 600000
+	assert.ErrorIs(t, ValidateProduct(p), ErrInvalidProductSetup)
+	p.MinAmount = 10000
+
+	p.PayoffDiscountBp = 10001
+	assert.ErrorIs(t, ValidateProduct(p), ErrInvalidProductSetup)
+	p.PayoffDiscountBp = 10000
+	assert.NoError(t, ValidateProduct(p))
+
+	p.RepaymentMethod = model.RepaymentMethodBullet
+	assert.ErrorIs(t, ValidateProduct(p), ErrInvalidProductSetup)
+
+	p.RepaymentMethod = "BALLOON"
+	assert.ErrorIs(t, ValidateProduct(p), ErrInvalidProductSetup)
+}
+
+func TestValidateApplication(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.Local)
+	from := now.AddDate(0, -1, 0)
+	to := now.AddDate(0, 1, 0)
+	p := &model.LoanProducts{
+		Status:      1,
+		MinAmount:   10000,
+		MaxAmount:   500000,
+		AllowedDays: "7,14,30",
+		ActiveFrom:  &from,
+		ActiveTo:    &to,
+	}
+
+	assert.NoError(t, ValidateApplication(p, 100000, 14, now))
+	assert.ErrorIs(t, ValidateApplication(p, 5000, 14, now), ErrAmountOutOfRange)
+	assert.ErrorIs(t, ValidateApplication(p, 600000, 14, now), ErrAmountOutOfRange)
+	assert.ErrorIs(t, ValidateApplication(p, 100000, 21, now), ErrLoanDaysNotAllowed)
+	assert.ErrorIs(t, ValidateApplication(p, 100000, 14, to.Add(time.Second)), ErrProductInactive)
+
+	p.Status = 0
+	assert.ErrorIs(t, ValidateApplication(p, 100000, 14, now), ErrProductInactive)
+
+	// 未限制天数时只要求天数为正
+	p.Status = 1
+	p.AllowedDays = ""
+	assert.NoError(t, ValidateApplication(p, 100000, 21, now))
+	assert.ErrorIs(t, ValidateApplication(p, 100000, 0, now), ErrLoanDaysNotAllowed)
+}
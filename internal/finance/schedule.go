@@ -0,0 +1,203 @@
+package finance
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/shopspring/decimal"
+
+	"loan/internal/model"
+)
+
+// defaultLoanDays 申请未填写借款天数时的默认期限
+const defaultLoanDays = 30
+
+// ScheduleTerms 生成还款计划所需的条件，金额单位为分
+type ScheduleTerms struct {
+	Principal        int64
+	RepaymentMethod  string
+	InstallmentCount int
+	PeriodDays       int       // 每期天数
+	DailyRateBp      int64     // 日利率(万分比)
+	InstallmentFee   int64     // 需要平摊到各期的服务费总额
+	StartDate        time.Time // 起息日(放款日)
+}
+
+// Installment 一期应还明细
+type Installment struct {
+	No        int
+	DueDate   time.Time
+	Principal int64
+	Interest  int64
+	Fee       int64
+}
+
+// Total 本期应还合计
+func (i Installment) Total() int64 {
+	return i.Principal + i.Interest + i.Fee
+}
+
+// ServiceFee 计算产品服务费=本金*费率+固定费用
+func ServiceFee(p *model.LoanProducts, principal int64) int64 {
+	if p == nil || p.FeeModel == model.FeeModelNone || p.FeeModel == "" {
+		return 0
+	}
+	fee := decimal.NewFromInt(principal).
+		Mul(decimal.NewFromInt(int64(p.ServiceFeeBp))).
+		Div(decimal.NewFromInt(10000)).
+		Round(0).IntPart()
+	return fee + p.ServiceFeeFixed
+}
+
+// UpfrontFee 放款时需从到账金额中扣除的服务费
+func UpfrontFee(p *model.LoanProducts, principal int64) int64 {
+	if p == nil || p.FeeModel != model.FeeModelUpfront {
+		return 0
+	}
+	return ServiceFee(p, principal)
+}
+
+// TermsFromProduct 根据产品和申请信息组装还款计划条件。
+// 未关联产品的历史申请按到期一次还本、无利息、无服务费处理。
+func TermsFromProduct(p *model.LoanProducts, principal int64, loanDays int, start time.Time) ScheduleTerms {
+	if loanDays <= 0 {
+		loanDays = defaultLoanDays
+	}
+	terms := ScheduleTerms{
+		Principal:        principal,
+		RepaymentMethod:  model.RepaymentMethodBullet,
+		InstallmentCount: 1,
+		PeriodDays:       loanDays,
+		StartDate:        start,
+	}
+	if p == nil {
+		return terms
+	}
+
+	terms.RepaymentMethod = p.RepaymentMethod
+	terms.DailyRateBp = int64(p.InterestRateBp)
+	if p.RepaymentMethod != model.RepaymentMethodBullet && p.InstallmentCount > 1 {
+		terms.InstallmentCount = p.InstallmentCount
+		terms.PeriodDays = p.InstallmentDays
+		if terms.PeriodDays <= 0 {
+			terms.PeriodDays = max(loanDays/p.InstallmentCount, 1)
+		}
+	}
+	if p.FeeModel == model.FeeModelInstallment {
+		terms.InstallmentFee = ServiceFee(p, principal)
+	}
+	return terms
+}
+
+// GenerateSchedule 生成分期还款计划。
+// 利息按 本金(或剩余本金)*日利率*每期天数 计算并四舍五入到分，本金的舍入误差由最后一期承担，
+// 服务费平均分摊，余数计入最后一期。
+func GenerateSchedule(t ScheduleTerms) ([]Installment, error) {
+	if t.Principal <= 0 {
+		return nil, errors.New("principal must be greater than 0")
+	}
+	if t.InstallmentCount <= 0 || t.PeriodDays <= 0 {
+		return nil, fmt.Errorf("invalid installment count %d or period days %d", t.InstallmentCount, t.PeriodDays)
+	}
+	if t.DailyRateBp < 0 || t.InstallmentFee < 0 {
+		return nil, errors.New("interest rate and fee cannot be negative")
+	}
+
+	n := t.InstallmentCount
+	periodRate := decimal.NewFromInt(t.DailyRateBp).
+		Mul(decimal.NewFromInt(int64(t.PeriodDays))).
+		Div(decimal.NewFromInt(10000))
+
+	var installments []Installment
+	switch t.RepaymentMethod {
+	case model.RepaymentMethodBullet, "":
+		if n != 1 {
+			return nil, errors.New("bullet repayment only supports one installment")
+		}
+		installments = []Installment{{
+			Principal: t.Principal,
+			Interest:  periodRate.Mul(decimal.NewFromInt(t.Principal)).Round(0).IntPart(),
+		}}
+	case model.RepaymentMethodEqualPrincipal:
+		installments = equalPrincipal(t.Principal, n, periodRate)
+	case model.RepaymentMethodEqualInstallment:
+		installments = equalInstallment(t.Principal, n, periodRate)
+	default:
+		return nil, fmt.Errorf("unsupported repayment method %q", t.RepaymentMethod)
+	}
+
+	feePer := t.InstallmentFee / int64(n)
+	start := DateOnly(t.StartDate, t.StartDate.Location())
+	for i := range installments {
+		installments[i].No = i + 1
+		installments[i].DueDate = start.AddDate(0, 0, t.PeriodDays*(i+1))
+		installments[i].Fee = feePer
+	}
+	installments[n-1].Fee += t.InstallmentFee - feePer*int64(n)
+
+	return installments, nil
+}
+
+// ScheduleRecords 将分期计划转换为待入库的还款计划记录。
+// 渠道手续费及前置服务费已在放款时扣除，FeeDue 只包含按期收取的服务费。
+func ScheduleRecords(disbursementID uint64, installments []Installment) []*model.LoanRepaymentSchedules {
+	records := make([]*model.LoanRepaymentSchedules, 0, len(installments))
+	for _, item := range installments {
+		dueDate := item.DueDate
+		records = append(records, &model.LoanRepaymentSchedules{
+			DisbursementID: int64(disbursementID),
+			InstallmentNo:  item.No,
+			DueDate:        &dueDate,
+			PrincipalDue:   item.Principal,
+			InterestDue:    item.Interest,
+			FeeDue:         item.Fee,
+			PenaltyDue:     0,
+			TotalDue:       item.Total(),
+			Status:         model.ScheduleStatusUnpaid,
+		})
+	}
+	return records
+}
+
+// equalPrincipal 等额本金：每期本金相同，利息按剩余本金计算
+func equalPrincipal(principal int64, n int, periodRate decimal.Decimal) []Installment {
+	per := principal / int64(n)
+	remaining := principal
+	installments := make([]Installment, n)
+	for i := 0; i < n; i++ {
+		p := per
+		if i == n-1 {
+			p = remaining
+		}
+		installments[i].Principal = p
+		installments[i].Interest = periodRate.Mul(decimal.NewFromInt(remaining)).Round(0).IntPart()
+		remaining -= p
+	}
+	return installments
+}
+
+// equalInstallment 等额本息：每期本息合计相同，payment = P*r*(1+r)^n/((1+r)^n-1)
+func equalInstallment(principal int64, n int, periodRate decimal.Decimal) []Installment {
+	if periodRate.IsZero() {
+		return equalPrincipal(principal, n, periodRate)
+	}
+
+	p := decimal.NewFromInt(principal)
+	factor := decimal.NewFromInt(1).Add(periodRate).Pow(decimal.NewFromInt(int64(n)))
+	payment := p.Mul(periodRate).Mul(factor).Div(factor.Sub(decimal.NewFromInt(1)))
+
+	remaining := principal
+	installments := make([]Installment, n)
+	for i := 0; i < n; i++ {
+		interest := periodRate.Mul(decimal.NewFromInt(remaining)).Round(0).IntPart()
+		pr := payment.Round(0).IntPart() - interest
+		if i == n-1 || pr > remaining {
+			pr = remaining
+		}
+		installments[i].Principal = pr
+		installments[i].Interest = interest
+		remaining -= pr
+	}
+	return installments
+}
@@ -0,0 +1,158 @@
+package finance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"loan/internal/model"
+)
+
+func sumInstallments(items []Installment) (principal, interest, fee int64) {
+	for _, it := range items {
+		principal += it.Principal
+		interest += it.Interest
+		fee += it.Fee
+	}
+	return
+}
+
+func TestGenerateScheduleBullet(t *testing.T) {
+	start := time.Date(2026, 3, 1, 15, 30, 0, 0, time.Local)
+	items, err := GenerateSchedule(ScheduleTerms{
+		Principal:        100000,
+		RepaymentMethod:  model.RepaymentMethodBullet,
+		InstallmentCount: 1,
+		PeriodDays:       30,
+		DailyRateBp:      5, // 0.05%/天
+		StartDate:        start,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, 1, items[0].No)
+	assert.Equal(t, int64(100000), items[0].Principal)
+	assert.Equal(t, int64(1500), items[0].Interest)
+	assert.Equal(t, time.Date(2026, 3, 31, 0, 0, 0, 0, time.Local), items[0].DueDate)
+}
+
+func TestGenerateScheduleEqualPrincipal(t *testing.T) {
+	items, err := GenerateSchedule(ScheduleTerms{
+		Principal:        100000,
+		RepaymentMethod:  model.RepaymentMethodEqualPrincipal,
+		InstallmentCount: 3,
+		PeriodDays:       10,
+		DailyRateBp:      10,
+		InstallmentFee:   1000,
+		StartDate:        time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, items, 3)
+	assert.Equal(t, []int64{33333, 33333, 33334}, []int64{items[0].Principal, items[1].Principal, items[2].Principal})
+	// 每期利率 1%，按剩余本金计息
+	assert.Equal(t, []int64{1000, 667, 333}, []int64{items[0].Interest, items[1].Interest, items[2].Interest})
+	assert.Equal(t, []int64{333, 333, 334}, []int64{items[0].Fee, items[1].Fee, items[2].Fee})
+	assert.Equal(t, time.Date(2026, 3, 31, 0, 0, 0, 0, time.Local), items[2].DueDate)
+
+	principal, _, fee := sumInstallments(items)
+	assert.Equal(t, int64(100000), principal)
+	assert.Equal(t, int64(1000), fee)
+}
+
+func TestGenerateScheduleEqualInstallment(t *testing.T) {
+	items, err := GenerateSchedule(ScheduleTerms{
+		Principal:        120000,
+		RepaymentMethod:  model.RepaymentMethodEqualInstallment,
+		InstallmentCount: 4,
+		PeriodDays:       7,
+		DailyRateBp:      20,
+		StartDate:        time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, items, 4)
+
+	principal, _, _ := sumInstallments(items)
+	assert.Equal(t, int64(120000), principal)
+	// 除最后一期吸收舍入误差外，每期本息合计相同
+	for i := 1; i < 3; i++ {
+		assert.Equal(t, items[0].Total(), items[i].Total())
+	}
+	assert.InDelta(t, items[0].Total(), items[3].Total(), 3)
+	// 剩余本金递减，利息递减
+	assert.Greater(t, items[0].Interest, items[3].Interest)
+
+	// 零利率退化为平均本金
+	items, err = GenerateSchedule(ScheduleTerms{
+		Principal:        1000,
+		RepaymentMethod:  model.RepaymentMethodEqualInstallment,
+		InstallmentCount: 3,
+		PeriodDays:       7,
+		StartDate:        time.Now(),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{333, 333, 334}, []int64{items[0].Principal, items[1].Principal, items[2].Principal})
+}
+
+func TestGenerateScheduleInvalid(t *testing.T) {
+	_, err := GenerateSchedule(ScheduleTerms{Principal: 0, InstallmentCount: 1, PeriodDays: 30})
+	assert.Error(t, err)
+	_, err = GenerateSchedule(ScheduleTerms{Principal: 100, InstallmentCount: 0, PeriodDays: 30})
+	assert.Error(t, err)
+	_, err = GenerateSchedule(ScheduleTerms{Principal: 100, RepaymentMethod: "BALLOON", InstallmentCount: 1, PeriodDays: 30})
+	assert.Error(t, err)
+	_, err = GenerateSchedule(ScheduleTerms{Principal: 100, RepaymentMethod: model.RepaymentMethodBullet, InstallmentCount: 2, PeriodDays: 30})
+	assert.Error(t, err)
+}
+
+func TestTermsFromProduct(t *testing.T) {
+	start := time.Now()
+
+	// 无产品：按借款天数一次还本
+	terms := TermsFromProduct(nil, 50000, 0, start)
+	assert.Equal(t, model.RepaymentMethodBullet, terms.RepaymentMethod)
+	assert.Equal(t, 1, terms.InstallmentCount)
+	assert.Equal(t, 30, terms.PeriodDays)
+
+	p := &model.LoanProducts{
+		RepaymentMethod:  model.RepaymentMethodEqualPrincipal,
+		InstallmentCount: 4,
+		InterestRateBp:   3,
+		FeeModel:         model.FeeModelInstallment,
+		ServiceFeeBp:     200,
+		ServiceFeeFixed:  500,
+	}
+	terms = TermsFromProduct(p, 50000, 28, start)
+	assert.Equal(t, 4, terms.InstallmentCount)
+	assert.Equal(t, 7, terms.PeriodDays)
+	assert.Equal(t, int64(1500), terms.InstallmentFee)
+	assert.Equal(t, int64(0), UpfrontFee(p, 50000))
+
+	p.FeeModel = model.FeeModelUpfront
+	terms = TermsFromProduct(p, 50000, 28, start)
+	assert.Equal(t, int64(0), terms.InstallmentFee)
+	assert.Equal(t, int64(1500), UpfrontFee(p, 50000))
+}
+
+func TestScheduleRecords(t *testing.T) {
+	start := time.Date(2026, 3, 1, 15, 0, 0, 0, time.Local)
+	items, err := GenerateSchedule(ScheduleTerms{
+		Principal:        30000,
+		RepaymentMethod:  model.RepaymentMethodEqualPrincipal,
+		InstallmentCount: 3,
+		PeriodDays:       10,
+		InstallmentFee:   300,
+		StartDate:        start,
+	})
+	assert.NoError(t, err)
+
+	records := ScheduleRecords(9, items)
+	assert.Len(t, records, 3)
+	for i, r := range records {
+		assert.Equal(t, int64(9), r.DisbursementID)
+		assert.Equal(t, i+1, r.InstallmentNo)
+		assert.Equal(t, items[i].Total(), r.TotalDue)
+		assert.Equal(t, int64(100), r.FeeDue)
+		assert.Equal(t, model.ScheduleStatusUnpaid, r.Status)
+		assert.True(t, r.DueDate.Equal(items[i].DueDate))
+	}
+}
@@ -0,0 +1,42 @@
+package finance
+
+import (
+	"time"
+
+	"loan/internal/model"
+)
+
+// DateOnly 截取 t 在 loc 时区下的日期(零点)
+func DateOnly(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// IsPastDue 应还日期当天仍在宽限内，从次日起算逾期
+func IsPastDue(dueDate time.Time, now time.Time) bool {
+	return DateOnly(now, now.Location()).After(DateOnly(dueDate, now.Location()))
+}
+
+// ScheduleStatus 根据已还总额和应还日期计算期次状态
+func ScheduleStatus(s *model.LoanRepaymentSchedules, now time.Time) int {
+	if int64(s.PaidTotal) >= s.TotalDue {
+		return model.ScheduleStatusSettled
+	}
+	if s.DueDate != nil && IsPastDue(*s.DueDate, now) {
+		return model.ScheduleStatusOverdue
+	}
+	return model.ScheduleStatusUnpaid
+}
+
+// RefreshScheduleStatus 重新计算期次状态，结清时写入结清时间，重新打开时清空结清时间
+func RefreshScheduleStatus(s *model.LoanRepaymentSchedules, now time.Time) {
+	s.Status = ScheduleStatus(s, now)
+	if s.Status == model.ScheduleStatusSettled {
+		if s.SettledAt == nil {
+			settledAt := now
+			s.SettledAt = &settledAt
+		}
+		return
+	}
+	s.SettledAt = nil
+}
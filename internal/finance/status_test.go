@@ -0,0 +1,38 @@
+package finance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"loan/internal/model"
+)
+
+func TestIsPastDue(t *testing.T) {
+	due := time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)
+	assert.False(t, IsPastDue(due, time.Date(2026, 3, 10, 23, 59, 0, 0, time.Local)))
+	assert.True(t, IsPastDue(due, time.Date(2026, 3, 11, 0, 0, 1, 0, time.Local)))
+	assert.False(t, IsPastDue(due, time.Date(2026, 3, 9, 12, 0, 0, 0, time.Local)))
+}
+
+func TestRefreshScheduleStatus(t *testing.T) {
+	due := time.Date(2026, 3, 10, 0, 0, 0, 0, time.Local)
+	now := time.Date(2026, 3, 12, 9, 0, 0, 0, time.Local)
+
+	s := &model.LoanRepaymentSchedules{DueDate: &due, TotalDue: 1000, PaidTotal: 400}
+	RefreshScheduleStatus(s, now)
+	assert.Equal(t, model.ScheduleStatusOverdue, s.Status)
+	assert.Nil(t, s.SettledAt)
+
+	s.PaidTotal = 1000
+	RefreshScheduleStatus(s, now)
+	assert.Equal(t, model.ScheduleStatusSettled, s.Status)
+	assert.Equal(t, now, *s.SettledAt)
+
+	// 冲正后重新打开
+	s.PaidTotal = 600
+	RefreshScheduleStatus(s, due)
+	assert.Equal(t, model.ScheduleStatusUnpaid, s.Status)
+	assert.Nil(t, s.SettledAt)
+}
@@ -0,0 +1,59 @@
+package finance
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"loan/internal/model"
+)
+
+var (
+	// ErrWaiverComponent 只允许减免罚息和费用
+	ErrWaiverComponent = errors.New("only penalty or fee can be waived")
+	// ErrWaiverExceedsOutstanding 减免金额超过该科目剩余未还
+	ErrWaiverExceedsOutstanding = errors.New("waiver amount exceeds outstanding")
+)
+
+// Waiver 一次减免的结果
+type Waiver struct {
+	DueBefore    int64 // 减免前该科目应还(分)
+	DueAfter     int64 // 减免后该科目应还(分)
+	StatusBefore int
+}
+
+// CheckWaiver 校验减免科目与金额，amount 不能超过该科目当前剩余未还
+func CheckWaiver(s *model.LoanRepaymentSchedules, comp Component, amount int64) error {
+	if comp != ComponentPenalty && comp != ComponentFee {
+		return ErrWaiverComponent
+	}
+	if s.Status == model.ScheduleStatusSettled {
+		return ErrScheduleSettled
+	}
+	out := ScheduleOutstanding(s)
+	if amount <= 0 || amount > out.get(comp) {
+		return fmt.Errorf("%w: %d > %d", ErrWaiverExceedsOutstanding, amount, max(out.get(comp), 0))
+	}
+	return nil
+}
+
+// ApplyWaiver 减免：从该科目应还及应还总额中扣除 amount，并重新计算期次状态(减免后已还清则结清)
+func ApplyWaiver(s *model.LoanRepaymentSchedules, comp Component, amount int64, now time.Time) (Waiver, error) {
+	if err := CheckWaiver(s, comp, amount); err != nil {
+		return Waiver{}, err
+	}
+	w := Waiver{StatusBefore: s.Status}
+	switch comp {
+	case ComponentPenalty:
+		w.DueBefore = int64(s.PenaltyDue)
+		s.PenaltyDue -= int(amount)
+		w.DueAfter = int64(s.PenaltyDue)
+	case ComponentFee:
+		w.DueBefore = s.FeeDue
+		s.FeeDue -= amount
+		w.DueAfter = s.FeeDue
+	}
+	s.TotalDue -= amount
+	RefreshScheduleStatus(s, now)
+	return w, nil
+}
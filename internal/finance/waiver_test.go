@@ -0,0 +1,56 @@
+package finance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan/internal/model"
+)
+
+func TestCheckWaiver(t *testing.T) {
+	s := overdueSchedule()
+	s.FeeDue, s.TotalDue = 300, s.TotalDue+300
+
+	assert.ErrorIs(t, CheckWaiver(s, ComponentInterest, 100), ErrWaiverComponent)
+	assert.ErrorIs(t, CheckWaiver(s, ComponentPrincipal, 100), ErrWaiverComponent)
+	assert.ErrorIs(t, CheckWaiver(s, ComponentPenalty, 0), ErrWaiverExceedsOutstanding)
+	assert.ErrorIs(t, CheckWaiver(s, ComponentPenalty, 201), ErrWaiverExceedsOutstanding)
+	assert.NoError(t, CheckWaiver(s, ComponentPenalty, 200))
+	assert.NoError(t, CheckWaiver(s, ComponentFee, 300))
+
+	s.PaidFee = 100
+	assert.ErrorIs(t, CheckWaiver(s, ComponentFee, 300), ErrWaiverExceedsOutstanding)
+
+	s.Status = model.ScheduleStatusSettled
+	assert.ErrorIs(t, CheckWaiver(s, ComponentFee, 100), ErrScheduleSettled)
+}
+
+func TestApplyWaiverPartial(t *testing.T) {
+	s := overdueSchedule()
+	now := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+
+	w, err := ApplyWaiver(s, ComponentPenalty, 150, now)
+	require.NoError(t, err)
+	assert.Equal(t, Waiver{DueBefore: 200, DueAfter: 50, StatusBefore: model.ScheduleStatusOverdue}, w)
+	assert.Equal(t, int64(10550), s.TotalDue)
+	assert.Equal(t, model.ScheduleStatusOverdue, s.Status)
+}
+
+func TestApplyWaiverSettles(t *testing.T) {
+	// 本金利息已还清，仅剩罚息，减免后期次结清
+	s := overdueSchedule()
+	s.PaidPrincipal, s.PaidInterest = 10000, 500
+	s.PaidTotal = 10500
+	now := time.Date(2026, 3, 5, 0, 0, 0, 0, time.UTC)
+
+	_, err := ApplyWaiver(s, ComponentPenalty, 200, now)
+	require.NoError(t, err)
+	assert.Equal(t, 0, s.PenaltyDue)
+	assert.Equal(t, int64(10500), s.TotalDue)
+	assert.Equal(t, model.ScheduleStatusSettled, s.Status)
+	require.NotNil(t, s.SettledAt)
+	assert.Equal(t, now, *s.SettledAt)
+}
@@ -1,13 +1,17 @@
 package handler
 
 import (
+	"context"
+
 	"github.com/gin-gonic/gin"
 
 	"loan/internal/cache"
 	"loan/internal/dao"
 	"loan/internal/database"
 	"loan/internal/ecode"
+	"loan/internal/model"
 	"loan/internal/types"
+	"loan/internal/workflow"
 
 	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
 	"github.com/go-dev-frame/sponge/pkg/gin/response"
@@ -22,7 +26,9 @@ type LoanAuditsHandler interface {
 }
 
 type loanAuditsHandler struct {
-	iDao dao.LoanAuditsDao
+	iDao        dao.LoanAuditsDao
+	baseinfoDao dao.LoanBaseinfoDao
+	stageDao    dao.LoanWorkflowStagesDao
 }
 
 // NewLoanAuditsHandler creating the handler interface
@@ -32,6 +38,11 @@ func NewLoanAuditsHandler() LoanAuditsHandler {
 			database.GetDB(), // db driver is mysql
 			cache.NewLoanAuditsCache(database.GetCacheType()),
 		),
+		baseinfoDao: dao.NewLoanBaseinfoDao(
+			database.GetDB(),
+			cache.NewLoanBaseinfoCache(database.GetCacheType()),
+		),
+		stageDao: dao.NewLoanWorkflowStagesDao(database.GetDB()),
 	}
 }
 
@@ -52,14 +63,22 @@ func (h *loanAuditsHandler) Detail(c *gin.Context) {
 	}
 	var disbursmentRecord *types.DisbursementWithChannel // 核心修正：改为放款记录模型类型
 
-	if form.AuditType == FinanceReviewType && record != nil && record.AuditResult == 1 {
-
-		disbursmentRecord, err = h.iDao.GetDisbursmentsByBaseInfoID(c, form.BaseinfoID)
+	// 最终审批节点通过时附带放款单(仅最终节点通过后才会生成)，最终节点由审批流程与申请金额决定
+	if record != nil && record.AuditResult == model.AuditResultPass {
+		final, err := h.isFinalStage(ctx, form.BaseinfoID, form.AuditType)
 		if err != nil {
-			logger.Warn("GetByCondition error: ", logger.Err(err))
-			response.Error(c, ecode.ErrGetByConditionLoanDisbursements)
+			logger.Warn("isFinalStage error: ", logger.Err(err))
+			response.Error(c, ecode.InternalServerError)
 			return
 		}
+		if final {
+			disbursmentRecord, err = h.iDao.GetDisbursmentsByBaseInfoID(c, form.BaseinfoID)
+			if err != nil {
+				logger.Warn("GetByCondition error: ", logger.Err(err))
+				response.Error(c, ecode.ErrGetByConditionLoanDisbursements)
+				return
+			}
+		}
 	}
 	response.Success(c, gin.H{
 		"record": record,
@@ -67,3 +86,16 @@ func (h *loanAuditsHandler) Detail(c *gin.Context) {
 	})
 
 }
+
+// isFinalStage 审批节点 stageNo 是否为该申请单需要经过的最后一个节点
+func (h *loanAuditsHandler) isFinalStage(ctx context.Context, baseinfoID uint64, stageNo int) (bool, error) {
+	baseinfo, err := h.baseinfoDao.GetByID(ctx, baseinfoID)
+	if err != nil {
+		return false, err
+	}
+	stages, err := loadLoanWorkflow(ctx, h.stageDao)
+	if err != nil {
+		return false, err
+	}
+	return workflow.IsFinal(stages, baseinfo.ApplicationAmount, stageNo), nil
+}
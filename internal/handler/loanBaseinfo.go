@@ -1,7 +1,9 @@
 package handler
 
 import (
+	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -9,6 +11,7 @@ import (
 	"loan/internal/tool"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -27,8 +30,11 @@ import (
 	"loan/internal/dao"
 	"loan/internal/database"
 	"loan/internal/ecode"
+	"loan/internal/finance"
 	"loan/internal/model"
+	"loan/internal/routing"
 	"loan/internal/types"
+	"loan/internal/workflow"
 )
 
 var _ LoanBaseinfoHandler = (*loanBaseinfoHandler)(nil)
@@ -42,6 +48,11 @@ type LoanBaseinfoHandler interface {
 	List(c *gin.Context)
 	PreReview(c *gin.Context)
 	FinanceReview(c *gin.Context)
+	Review(c *gin.Context)
+	ReviewProgress(c *gin.Context)
+	Claim(c *gin.Context)
+	ReleaseClaim(c *gin.Context)
+	ReassignClaim(c *gin.Context)
 	WithAuditRecordList(c *gin.Context)
 	UploadCertificate(c *gin.Context)
 	GetCertificateBase64(c *gin.Context)
@@ -51,9 +62,14 @@ type loanBaseinfoHandler struct {
 	iDao                 dao.LoanBaseinfoDao
 	auditDao             dao.LoanAuditsDao
 	userDao              dao.LoanUsersDao
-	channelDao           dao.LoanPaymentChannelsDao
 	disbursmentDao       dao.LoanDisbursementsDao
 	repaymentScheduleDao dao.LoanRepaymentSchedulesDao
+	productDao           dao.LoanProductsDao
+	stageDao             dao.LoanWorkflowStagesDao
+	settingsDao          dao.LoanSettingsDao
+	router               *routing.Router
+	screener             *screener
+	scorer               *scorer
 }
 
 // NewLoanBaseinfoHandler creating the handler interface
@@ -71,10 +87,6 @@ func NewLoanBaseinfoHandler() LoanBaseinfoHandler {
 			database.GetDB(),
 			cache.NewLoanUsersCache(database.GetCacheType()),
 		),
-		channelDao: dao.NewLoanPaymentChannelsDao(
-			database.GetDB(),
-			cache.NewLoanPaymentChannelsCache(database.GetCacheType()),
-		),
 		disbursmentDao: dao.NewLoanDisbursementsDao(
 			database.GetDB(),
 			cache.NewLoanDisbursementsCache(database.GetCacheType()),
@@ -83,17 +95,24 @@ func NewLoanBaseinfoHandler() LoanBaseinfoHandler {
 			database.GetDB(),
 			cache.NewLoanRepaymentSchedulesCache(database.GetCacheType()),
 		),
+		productDao: dao.NewLoanProductsDao(
+			database.GetDB(),
+			cache.NewLoanProductsCache(database.GetCacheType()),
+		),
+		stageDao: dao.NewLoanWorkflowStagesDao(database.GetDB()),
+		settingsDao: dao.NewLoanSettingsDao(
+			database.GetDB(),
+			cache.NewLoanSettingsCache(database.GetCacheType()),
+		),
+		router:   newPayoutRouter(),
+		screener: newScreener(),
+		scorer:   newScorer(),
 	}
 }
 
 type Audit_Status int
 
 type AuditType int // 修正原Audit_Type命名，符合Go大驼峰规范
-const (
-	PreReviewType     = 1 //初审审核
-	FinanceReviewType = 2 //放款审核
-	//IncomeReviewType  = 3 //回款审核
-)
 
 func (h *loanBaseinfoHandler) WithAuditRecordList(c *gin.Context) {
 	form := &types.ListLoanBaseinfosRequestWithAuditType{}
@@ -132,31 +151,419 @@ func (h *loanBaseinfoHandler) WithAuditRecordList(c *gin.Context) {
 // 新增：将请求的audit_type字符串（0/1/2）转换为AuditType枚举，同时做合法性校验
 
 func (h *loanBaseinfoHandler) PreReview(c *gin.Context) {
-	h.review(c, PreReviewType)
+	h.review(c, model.StageCodePreReview)
 }
 
 func (h *loanBaseinfoHandler) FinanceReview(c *gin.Context) {
-	h.review(c, FinanceReviewType)
+	h.review(c, model.StageCodeFinanceReview)
 }
 
-func (h *loanBaseinfoHandler) review(c *gin.Context, forcedAuditType int) {
+// Review 审批申请单的当前节点，节点由流程定义与申请金额决定，审批人需具备该节点的权限
+// @Summary Approve or reject the current workflow stage
+// @Description Advances a loan application through the configured approval workflow. The current stage is derived from the application's audit status and amount; the reviewer needs the stage's permission and MFA. Approving the final stage creates the pending disbursement.
+// @Tags loanBaseinfo
+// @Accept json
+// @Produce json
+// @Param data body types.AuditRequest true "review"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/loanBaseinfo/review [post]
+// @Security BearerAuth
+func (h *loanBaseinfoHandler) Review(c *gin.Context) {
+	h.review(c, "")
+}
+
+// ReviewProgress 申请单的审批进度：需要经过的节点及当前待审批节点
+// @Summary Workflow progress of an application
+// @Description Lists the approval stages required for the application amount, the stages already passed and the stage waiting for review.
+// @Tags loanBaseinfo
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/loanBaseinfo/{id}/review-progress [get]
+// @Security BearerAuth
+func (h *loanBaseinfoHandler) ReviewProgress(c *gin.Context) {
+	_, id, isAbort := getLoanBaseinfoIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
 	ctx := middleware.WrapCtx(c)
+	record, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	stages, err := h.loadWorkflow(ctx)
+	if err != nil {
+		logger.Error("load workflow error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrListLoanWorkflowStages)
+		return
+	}
+
+	var current *model.LoanWorkflowStages
+	if step, err := workflow.Next(stages, record.ApplicationAmount, record.AuditStatus); err == nil {
+		current = step.Stage
+	}
+	response.Success(c, gin.H{
+		"auditStatus":  record.AuditStatus,
+		"rejected":     record.AuditStatus == workflow.AuditStatusRejected,
+		"stages":       workflow.Applicable(stages, record.ApplicationAmount),
+		"currentStage": current,
+	})
+}
+
+// loadWorkflow 读取借款申请的审批流程，未配置时使用内置两级审批
+func (h *loanBaseinfoHandler) loadWorkflow(ctx context.Context) ([]*model.LoanWorkflowStages, error) {
+	return loadLoanWorkflow(ctx, h.stageDao)
+}
+
+func loadLoanWorkflow(ctx context.Context, stageDao dao.LoanWorkflowStagesDao) ([]*model.LoanWorkflowStages, error) {
+	stages, err := stageDao.GetByWorkflow(ctx, model.WorkflowLoanApplication)
+	if err != nil {
+		return nil, err
+	}
+	if len(stages) == 0 {
+		return workflow.DefaultStages(), nil
+	}
+	return stages, nil
+}
 
+// loadSoDPolicy 读取职责分离规则，未配置时默认全部启用
+func loadSoDPolicy(ctx context.Context, settingsDao dao.LoanSettingsDao) workflow.SoDPolicy {
+	return workflow.SoDPolicy{
+		DistinctApprovers: settingsDao.GetInt64ByName(ctx, model.SettingSodDistinctApprovers, 1) != 0,
+		BlockReferrer:     settingsDao.GetInt64ByName(ctx, model.SettingSodBlockReferrer, 1) != 0,
+	}
+}
+
+// claimPageSize 领取时每批扫描的候选申请单数，claimMaxPages 最多扫描的批数
+const (
+	claimPageSize = 20
+	claimMaxPages = 10
+)
+
+// Claim 从审批工作队列领取下一张可审批的申请单
+// @Summary Claim the next application from the review queue
+// @Description Atomically locks the oldest pending application whose current workflow stage the caller may approve (stage permission and segregation of duties) and assigns it to the caller until the lease expires (setting review_claim_lease_minutes, default 30). A reviewer holds at most one claim: if the caller already has an unexpired claim it is returned instead.
+// @Tags loanBaseinfo
+// @Produce json
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/loanBaseinfo/claim [post]
+// @Security BearerAuth
+func (h *loanBaseinfoHandler) Claim(c *gin.Context) {
+	ctx := middleware.WrapCtx(c)
 	uid, ok := getUIDFromClaims(c)
 	if !ok || uid == 0 {
 		response.Out(c, ecode.Unauthorized)
 		return
 	}
 
-	form := &types.AuditRequest{}
+	// 1) 流程定义、权限、职责分离规则与租约时长（不进事务）
+	stages, err := h.loadWorkflow(ctx)
+	if err != nil {
+		logger.Error("load workflow error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrListLoanWorkflowStages)
+		return
+	}
+	perms, err := h.userDao.GetPermissionCodesByUserID(ctx, uid)
+	if err != nil {
+		logger.Error("GetPermissionCodesByUserID error", logger.Err(err), logger.Uint64("uid", uid), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+	sod := loadSoDPolicy(ctx, h.settingsDao)
+	lease := workflow.LeaseDuration(h.settingsDao.GetInt64ByName(ctx, model.SettingReviewClaimLeaseMinutes, workflow.DefaultLeaseMinutes))
+	// audit_status 达到最大启用节点序号的申请单必定已审批完成，不再扫描
+	maxStageNo := 0
+	for _, s := range stages {
+		if s.Status == 1 {
+			maxStageNo = max(maxStageNo, s.StageNo)
+		}
+	}
+
+	tx := database.GetDB().WithContext(ctx).Begin()
+	if tx.Error != nil {
+		logger.Error("tx begin error", logger.Err(tx.Error), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+	// 兜底：函数任何提前 return 都会回滚（Commit 后 Rollback 不会生效）
+	defer func() {
+		_ = tx.Rollback().Error
+	}()
+
+	now := time.Now()
+
+	// 2) 已持有未过期的领取时直接返回，每人同时只处理一张申请单
+	held, err := h.iDao.GetActiveClaimByTx(ctx, tx, uid, now)
+	if err == nil {
+		step, _ := workflow.Next(stages, held.ApplicationAmount, held.AuditStatus)
+		h.respondClaim(c, held, step.Stage)
+		return
+	}
+	if !errors.Is(err, database.ErrRecordNotFound) {
+		logger.Error("GetActiveClaimByTx error", logger.Err(err), logger.Uint64("uid", uid), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrClaimApplication)
+		return
+	}
+
+	// 3) 按待审时间先后扫描候选申请单(已加锁，跳过其他事务正在处理的行)，领取第一张可审批的
+	for page := 0; page < claimMaxPages; page++ {
+		candidates, err := h.iDao.GetClaimCandidatesByTx(ctx, tx, now, maxStageNo, page*claimPageSize, claimPageSize)
+		if err != nil {
+			logger.Error("GetClaimCandidatesByTx error", logger.Err(err), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrClaimApplication)
+			return
+		}
+		for _, b := range candidates {
+			approvers, err := h.auditDao.GetApproverIDsByTx(ctx, tx, b.ID)
+			if err != nil {
+				logger.Error("GetApproverIDsByTx error", logger.Err(err), logger.Uint64("customer_id", b.ID), middleware.GCtxRequestIDField(c))
+				response.Error(c, ecode.ErrClaimApplication)
+				return
+			}
+			step, err := workflow.Eligible(stages, b, uid, perms, sod, approvers)
+			if err != nil {
+				continue
+			}
+
+			expiresAt := now.Add(lease)
+			if err = h.iDao.ClaimByTx(ctx, tx, b.ID, uid, now, expiresAt); err != nil {
+				logger.Error("ClaimByTx error", logger.Err(err), logger.Uint64("customer_id", b.ID), middleware.GCtxRequestIDField(c))
+				response.Error(c, ecode.ErrClaimApplication)
+				return
+			}
+			if err = tx.Commit().Error; err != nil {
+				logger.Error("tx commit failed", logger.Err(err), middleware.GCtxRequestIDField(c))
+				response.Error(c, ecode.ErrClaimApplication)
+				return
+			}
+			b.ClaimedBy, b.ClaimedAt, b.ClaimExpiresAt = &uid, &now, &expiresAt
+			h.respondClaim(c, b, step.Stage)
+			return
+		}
+		if len(candidates) < claimPageSize {
+			break
+		}
+	}
+
+	response.Error(c, ecode.ErrReviewQueueEmpty)
+}
+
+// ReleaseClaim 释放申请单的领取，放回审批工作队列
+// @Summary Release a claimed application
+// @Description Returns the application to the review queue. Only the current holder, or a user with customer:reassign, may release it.
+// @Tags loanBaseinfo
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/loanBaseinfo/{id}/release [post]
+// @Security BearerAuth
+func (h *loanBaseinfoHandler) ReleaseClaim(c *gin.Context) {
+	_, id, isAbort := getLoanBaseinfoIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	ctx := middleware.WrapCtx(c)
+	uid, ok := getUIDFromClaims(c)
+	if !ok || uid == 0 {
+		response.Out(c, ecode.Unauthorized)
+		return
+	}
+	perms, err := h.userDao.GetPermissionCodesByUserID(ctx, uid)
+	if err != nil {
+		logger.Error("GetPermissionCodesByUserID error", logger.Err(err), logger.Uint64("uid", uid), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+
+	tx := database.GetDB().WithContext(ctx).Begin()
+	if tx.Error != nil {
+		logger.Error("tx begin error", logger.Err(tx.Error), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+	defer func() {
+		_ = tx.Rollback().Error
+	}()
+
+	record, err := h.iDao.GetByIDForUpdate(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByIDForUpdate error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.InternalServerError)
+		}
+		return
+	}
+	holder, held := workflow.ClaimHolder(record, time.Now())
+	if !held || (holder != uid && !slices.Contains(perms, "customer:reassign")) {
+		response.Error(c, ecode.ErrClaimNotHeld)
+		return
+	}
+	if err = h.iDao.ReleaseClaimByTx(ctx, tx, id); err != nil {
+		logger.Error("ReleaseClaimByTx error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrUpdateByIDLoanBaseinfo)
+		return
+	}
+	if err = tx.Commit().Error; err != nil {
+		logger.Error("tx commit failed", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+
+	logger.Info("review claim released", logger.Uint64("id", id), logger.Uint64("holder", holder), logger.Uint64("operator", uid), middleware.GCtxRequestIDField(c))
+	response.Success(c)
+}
+
+// ReassignClaim 将申请单改派给其他审批人员，重新计算租约
+// @Summary Reassign an application to another reviewer
+// @Description Assigns the application to the given reviewer with a fresh lease, whether or not it is currently claimed. The new reviewer must be able to approve the current stage (stage permission and segregation of duties).
+// @Tags loanBaseinfo
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.ReassignClaimRequest true "new reviewer"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/loanBaseinfo/{id}/reassign [post]
+// @Security BearerAuth
+func (h *loanBaseinfoHandler) ReassignClaim(c *gin.Context) {
+	_, id, isAbort := getLoanBaseinfoIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	form := &types.ReassignClaimRequest{}
 	if err := c.ShouldBindJSON(form); err != nil {
 		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
 		response.Error(c, ecode.InvalidParams)
 		return
 	}
+	ctx := middleware.WrapCtx(c)
+	uid, _ := getUIDFromClaims(c)
+
+	// 1) 接手人员的权限与流程定义（不进事务）
+	if _, err := h.userDao.GetByID(ctx, form.UserID); err != nil {
+		logger.Warn("reassign target not found", logger.Err(err), logger.Uint64("user_id", form.UserID), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrGetByIDLoanUsers)
+		return
+	}
+	perms, err := h.userDao.GetPermissionCodesByUserID(ctx, form.UserID)
+	if err != nil {
+		logger.Error("GetPermissionCodesByUserID error", logger.Err(err), logger.Uint64("uid", form.UserID), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+	stages, err := h.loadWorkflow(ctx)
+	if err != nil {
+		logger.Error("load workflow error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrListLoanWorkflowStages)
+		return
+	}
+	sod := loadSoDPolicy(ctx, h.settingsDao)
+	lease := workflow.LeaseDuration(h.settingsDao.GetInt64ByName(ctx, model.SettingReviewClaimLeaseMinutes, workflow.DefaultLeaseMinutes))
+
+	tx := database.GetDB().WithContext(ctx).Begin()
+	if tx.Error != nil {
+		logger.Error("tx begin error", logger.Err(tx.Error), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+	defer func() {
+		_ = tx.Rollback().Error
+	}()
 
-	// 不再使用前端传入的 AuditType，统一由路由/handler 决定
-	auditType := forcedAuditType
+	// 2) 锁定申请单，校验接手人员能否审批当前节点
+	record, err := h.iDao.GetByIDForUpdate(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByIDForUpdate error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.InternalServerError)
+		}
+		return
+	}
+	approvers, err := h.auditDao.GetApproverIDsByTx(ctx, tx, id)
+	if err != nil {
+		logger.Error("GetApproverIDsByTx error", logger.Err(err), logger.Uint64("customer_id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+	step, err := workflow.Eligible(stages, record, form.UserID, perms, sod, approvers)
+	if err != nil {
+		switch {
+		case errors.Is(err, workflow.ErrRejected):
+			response.Error(c, ecode.ErrWorkflowRejected)
+		case errors.Is(err, workflow.ErrCompleted):
+			response.Error(c, ecode.ErrWorkflowCompleted)
+		case errors.Is(err, workflow.ErrStageForbidden):
+			response.Error(c, ecode.ErrWorkflowStageForbidden)
+		default:
+			response.Error(c, ecode.ErrSegregationOfDuties.WithDetails(err.Error()))
+		}
+		return
+	}
+
+	// 3) 改派
+	now := time.Now()
+	expiresAt := now.Add(lease)
+	if err = h.iDao.ClaimByTx(ctx, tx, id, form.UserID, now, expiresAt); err != nil {
+		logger.Error("ClaimByTx error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrClaimApplication)
+		return
+	}
+	if err = tx.Commit().Error; err != nil {
+		logger.Error("tx commit failed", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+
+	logger.Info("review claim reassigned", logger.Uint64("id", id), logger.Uint64("to", form.UserID),
+		logger.Uint64("operator", uid), logger.String("remark", form.Remark), middleware.GCtxRequestIDField(c))
+	record.ClaimedBy, record.ClaimedAt, record.ClaimExpiresAt = &form.UserID, &now, &expiresAt
+	h.respondClaim(c, record, step.Stage)
+}
+
+// respondClaim 返回领取到的申请单、当前节点及租约
+func (h *loanBaseinfoHandler) respondClaim(c *gin.Context, record *model.LoanBaseinfo, stage *model.LoanWorkflowStages) {
+	data, err := convertSimpleLoanBaseinfo(record)
+	if err != nil {
+		response.Error(c, ecode.ErrGetByIDLoanBaseinfo)
+		return
+	}
+	response.Success(c, gin.H{
+		"application":    data,
+		"currentStage":   stage,
+		"receivedAt":     workflow.ReceivedAt(record),
+		"claimedAt":      record.ClaimedAt,
+		"claimExpiresAt": record.ClaimExpiresAt,
+	})
+}
+
+// review 审批申请单的当前节点；stageCode 不为空时要求当前节点与之一致(兼容初审/放款审核的独立接口)
+func (h *loanBaseinfoHandler) review(c *gin.Context, stageCode string) {
+	ctx := middleware.WrapCtx(c)
+
+	uid, ok := getUIDFromClaims(c)
+	if !ok || uid == 0 {
+		response.Out(c, ecode.Unauthorized)
+		return
+	}
+
+	form := &types.AuditRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
 
 	// 0) MFA 必须启用（不进事务）
 	u, err := h.userDao.GetByID(ctx, uid)
@@ -179,6 +586,21 @@ func (h *loanBaseinfoHandler) review(c *gin.Context, forcedAuditType int) {
 		return
 	}
 
+	// 审批流程定义与审批人权限（不进事务）
+	stages, err := h.loadWorkflow(ctx)
+	if err != nil {
+		logger.Error("load workflow error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrListLoanWorkflowStages)
+		return
+	}
+	perms, err := h.userDao.GetPermissionCodesByUserID(ctx, uid)
+	if err != nil {
+		logger.Error("GetPermissionCodesByUserID error", logger.Err(err), logger.Uint64("uid", uid), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+	sod := loadSoDPolicy(ctx, h.settingsDao)
+
 	// 2) 开事务
 	db := database.GetDB()
 	tx := db.WithContext(ctx).Begin()
@@ -208,43 +630,57 @@ func (h *loanBaseinfoHandler) review(c *gin.Context, forcedAuditType int) {
 		return
 	}
 
-	// 4) 状态机校验
-	if loanBaseinfoRecord.AuditStatus == -1 {
+	// 已被其他人员领取(租约未过期)的申请单只能由领取人审批
+	decidedAt := time.Now()
+	if err := workflow.CheckClaim(loanBaseinfoRecord, uid, decidedAt); err != nil {
 		_ = tx.Rollback().Error
-		response.Error(c, ecode.InvalidParams)
+		response.Error(c, ecode.ErrApplicationClaimed)
 		return
 	}
 
-	// 建议加顺序校验
-	if auditType == FinanceReviewType && loanBaseinfoRecord.AuditStatus != PreReviewType {
+	// 4) 按流程定义确定当前节点，并校验审批人权限
+	step, err := workflow.Next(stages, loanBaseinfoRecord.ApplicationAmount, loanBaseinfoRecord.AuditStatus)
+	if err != nil {
 		_ = tx.Rollback().Error
-		response.Error(c, ecode.InvalidParams)
+		switch {
+		case errors.Is(err, workflow.ErrRejected):
+			response.Error(c, ecode.ErrWorkflowRejected)
+		default:
+			response.Error(c, ecode.ErrWorkflowCompleted)
+		}
+		return
+	}
+	stage := step.Stage
+	if stageCode != "" && stage.Code != stageCode {
+		_ = tx.Rollback().Error
+		response.Error(c, ecode.ErrWorkflowStageMismatch.WithDetails(stage.Code))
+		return
+	}
+	if stage.Permission != "" && !slices.Contains(perms, stage.Permission) {
+		_ = tx.Rollback().Error
+		logger.Warn("no permission for workflow stage", logger.Uint64("uid", uid), logger.String("stage", stage.Code), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrWorkflowStageForbidden.WithDetails(stage.Code))
 		return
 	}
 
-	// 如果不允许重复初审通过，也可加
-	// if auditType == PreReviewType && loanBaseinfoRecord.AuditStatus == PreReviewType {
-	//     _ = tx.Rollback().Error
-	//     response.Error(c, ecode.InvalidParams)
-	//     return
-	// }
+	// 职责分离：已审批过本申请其他节点的人员、申请的推荐人不得审批
+	approvers, err := h.auditDao.GetApproverIDsByTx(ctx, tx, form.CustomerID)
+	if err != nil {
+		_ = tx.Rollback().Error
+		logger.Error("GetApproverIDsByTx error", logger.Err(err), logger.Uint64("customer_id", form.CustomerID), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+	if err = workflow.CheckSegregation(sod, uid, loanBaseinfoRecord.ReferrerUserID, approvers); err != nil {
+		_ = tx.Rollback().Error
+		logger.Warn("segregation of duties violation", logger.Err(err), logger.Uint64("customer_id", form.CustomerID), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrSegregationOfDuties.WithDetails(err.Error()))
+		return
+	}
 
-	// 5) 财务审核通过：放款 + 还款计划
+	// 5) 最终节点审批通过：生成待放款单
 	var createdDisbursementID uint64
-	if auditType == FinanceReviewType && form.AuditResult {
-		if form.PaymentChannelID == 0 {
-			_ = tx.Rollback().Error
-			response.Error(c, ecode.ErrPaymentChannel)
-			return
-		}
-
-		paymentChannelRecord, err := h.channelDao.GetByID(ctx, form.PaymentChannelID)
-		if err != nil || paymentChannelRecord == nil {
-			_ = tx.Rollback().Error
-			response.Error(c, ecode.ErrGetByIDLoanPaymentChannels)
-			return
-		}
-
+	if step.Final && form.AuditResult {
 		existing := &model.LoanDisbursements{}
 		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).
 			Where("baseinfo_id = ?", form.CustomerID).
@@ -260,31 +696,70 @@ func (h *loanBaseinfoHandler) review(c *gin.Context, forcedAuditType int) {
 				return
 			}
 
-			var feeRate = 0
-			if paymentChannelRecord.PayoutFeeRate != 0 {
-				feeRate = paymentChannelRecord.PayoutFeeRate
+			// 未关联产品的历史申请按到期一次还本、无利息处理
+			var product *model.LoanProducts
+			if loanBaseinfoRecord.ProductID != 0 {
+				product, err = h.productDao.GetByID(ctx, loanBaseinfoRecord.ProductID)
+				if err != nil {
+					_ = tx.Rollback().Error
+					logger.Warn("get loan product failed", logger.Err(err), logger.Uint64("product_id", loanBaseinfoRecord.ProductID), middleware.GCtxRequestIDField(c))
+					response.Error(c, ecode.ErrGetByIDLoanProducts)
+					return
+				}
 			}
 
 			applicationAmount := loanBaseinfoRecord.ApplicationAmount
-
-			feeAmount := int64(float64(applicationAmount) * float64(feeRate) / 100)
-			netAmount := applicationAmount - feeAmount
 			disburseAmount := applicationAmount
 
+			// 放款渠道：审核人指定时使用指定渠道，否则按优先级/权重/限额/当日额度自动路由。
+			// 到账金额 = 申请金额 - 渠道代付手续费(费率+固定费) - 产品前置服务费，到账金额需在渠道代付限额内
+			var route routing.Route
+			routeMode, routeReason := model.DisbursementRouteManual, ""
+			if form.PaymentChannelID != 0 {
+				route, err = h.router.Manual(ctx, form.PaymentChannelID, disburseAmount, finance.UpfrontFee(product, applicationAmount))
+				if err == nil {
+					routeReason = routing.ManualReason(route.Channel, "财务审核")
+				}
+			} else {
+				var plan *routing.Plan
+				if plan, err = h.router.Plan(ctx, disburseAmount, finance.UpfrontFee(product, applicationAmount)); err == nil {
+					route, err = plan.Best()
+					routeMode, routeReason = model.DisbursementRouteAuto, plan.Reason()
+				}
+			}
+			if err != nil {
+				_ = tx.Rollback().Error
+				respondPayoutRouteError(c, err, form.PaymentChannelID, disburseAmount)
+				return
+			}
+
 			now := time.Now()
 			currentTime := &now
 
+			// 提前校验产品分期配置；还款计划在放款成功后按实际放款时间生成
+			_, err = finance.GenerateSchedule(
+				finance.TermsFromProduct(product, disburseAmount, loanBaseinfoRecord.LoanDays, now))
+			if err != nil {
+				_ = tx.Rollback().Error
+				logger.Warn("generate repayment schedule failed", logger.Err(err), logger.Uint64("customer_id", form.CustomerID), middleware.GCtxRequestIDField(c))
+				response.Error(c, ecode.ErrCreateLoanRepaymentSchedules)
+				return
+			}
+
+			// 审核通过只生成待放款单，由异步任务提交代付网关，渠道受理后回填 PayoutOrderNo
 			disbursmentRecord := &model.LoanDisbursements{
 				BaseinfoID:           form.CustomerID,
 				DisburseAmount:       disburseAmount,
-				NetAmount:            netAmount,
-				Status:               1,
+				NetAmount:            route.Payout.NetAmount,
+				PayoutFee:            route.Payout.Fee,
+				Status:               model.DisbursementStatusPending,
 				SourceReferrerUserID: loanBaseinfoRecord.ReferrerUserID,
 				AuditorUserID:        uid,
-				PayoutChannelID:      form.PaymentChannelID,
+				PayoutChannelID:      route.Channel.ID,
 				AuditedAt:            currentTime,
-				DisbursedAt:          currentTime,
-				PayoutOrderNo:        generateOrderNo("PO"),
+				MerchantOrderNo:      generateOrderNo("PO"),
+				RouteMode:            routeMode,
+				RouteReason:          routeReason,
 			}
 
 			if _, err := h.disbursmentDao.CreateByTx(ctx, tx, disbursmentRecord); err != nil {
@@ -293,37 +768,6 @@ func (h *loanBaseinfoHandler) review(c *gin.Context, forcedAuditType int) {
 				return
 			}
 			createdDisbursementID = disbursmentRecord.ID
-
-			loanDays := loanBaseinfoRecord.LoanDays
-			if loanDays <= 0 {
-				loanDays = 30
-			}
-			dueDate := now.AddDate(0, 0, loanDays)
-			dueDateOnly := time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day(), 0, 0, 0, 0, dueDate.Location())
-			dueDatePtr := &dueDateOnly
-
-			scheduleRecord := &model.LoanRepaymentSchedules{
-				DisbursementID: int64(createdDisbursementID),
-				InstallmentNo:  1,
-				DueDate:        dueDatePtr,
-				PrincipalDue:   disburseAmount,
-				InterestDue:    0,
-				FeeDue:         disburseAmount,
-				PenaltyDue:     0,
-				TotalDue:       disburseAmount,
-				PaidPrincipal:  0,
-				PaidInterest:   0,
-				PaidFee:        0,
-				PaidPenalty:    0,
-				PaidTotal:      0,
-				Status:         0,
-			}
-
-			if _, err := h.repaymentScheduleDao.CreateByTx(ctx, tx, scheduleRecord); err != nil {
-				_ = tx.Rollback().Error
-				response.Error(c, ecode.ErrCreateLoanRepaymentSchedules)
-				return
-			}
 		} else {
 			_ = tx.Rollback().Error
 			response.Error(c, ecode.InternalServerError)
@@ -331,13 +775,13 @@ func (h *loanBaseinfoHandler) review(c *gin.Context, forcedAuditType int) {
 		}
 	}
 
-	// 6) 更新审核状态
+	// 6) 更新审核状态：通过记为当前节点序号，拒绝则终止流程
 	auditResult := -1
 	if form.AuditResult {
 		auditResult = 1
-		loanBaseinfoRecord.AuditStatus = auditType
+		loanBaseinfoRecord.AuditStatus = stage.StageNo
 	} else {
-		loanBaseinfoRecord.AuditStatus = -1
+		loanBaseinfoRecord.AuditStatus = workflow.AuditStatusRejected
 	}
 
 	if err := h.iDao.UpdateByTx(ctx, tx, loanBaseinfoRecord); err != nil {
@@ -345,14 +789,29 @@ func (h *loanBaseinfoHandler) review(c *gin.Context, forcedAuditType int) {
 		response.Error(c, ecode.ErrUpdateByIDLoanBaseinfo)
 		return
 	}
+	// 释放领取，下一节点从此刻开始待审
+	if err := h.iDao.FinishStageByTx(ctx, tx, form.CustomerID, decidedAt); err != nil {
+		_ = tx.Rollback().Error
+		response.Error(c, ecode.ErrUpdateByIDLoanBaseinfo)
+		return
+	}
 
-	// 7) 写审核记录
+	// 7) 写审核记录(含待审、领取、决定时间，用于审批时效统计)
+	receivedAt := workflow.ReceivedAt(loanBaseinfoRecord)
+	var claimedAt *time.Time
+	if holder, ok := workflow.ClaimHolder(loanBaseinfoRecord, decidedAt); ok && holder == uid {
+		claimedAt = loanBaseinfoRecord.ClaimedAt
+	}
 	record := &model.LoanAudits{
 		AuditResult:   auditResult,
-		AuditType:     auditType,
+		AuditType:     stage.StageNo,
+		StageCode:     stage.Code,
 		AuditComment:  form.Remark,
 		BaseinfoID:    form.CustomerID,
 		AuditorUserID: uid,
+		ReceivedAt:    &receivedAt,
+		ClaimedAt:     claimedAt,
+		DecidedAt:     &decidedAt,
 	}
 
 	if _, err := h.auditDao.CreateByTx(ctx, tx, record); err != nil {
@@ -368,7 +827,12 @@ func (h *loanBaseinfoHandler) review(c *gin.Context, forcedAuditType int) {
 		return
 	}
 
-	response.Success(c, gin.H{})
+	// 最终节点审批通过时返回放款单id，放款结果由异步代付任务更新
+	response.Success(c, gin.H{
+		"disbursementID": createdDisbursementID,
+		"stageCode":      stage.Code,
+		"completed":      step.Final && form.AuditResult,
+	})
 }
 
 // Create a new loanBaseinfo
@@ -406,6 +870,27 @@ func (h *loanBaseinfoHandler) Create(c *gin.Context) {
 	}
 
 	ctx := middleware.WrapCtx(c)
+
+	// 按所选产品校验申请金额、借款天数及产品有效期
+	product, err := h.productDao.GetByID(ctx, form.ProductID)
+	if err != nil {
+		logger.Warn("get loan product failed", logger.Err(err), logger.Uint64("product_id", form.ProductID), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrLoanProductInactive)
+		return
+	}
+	if err = finance.ValidateApplication(product, loanBaseinfo.ApplicationAmount, loanBaseinfo.LoanDays, time.Now()); err != nil {
+		logger.Warn("ValidateApplication error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		switch {
+		case errors.Is(err, finance.ErrAmountOutOfRange):
+			response.Error(c, ecode.ErrApplicationAmountOutOfRange)
+		case errors.Is(err, finance.ErrLoanDaysNotAllowed):
+			response.Error(c, ecode.ErrLoanDaysNotAllowed)
+		default:
+			response.Error(c, ecode.ErrLoanProductInactive)
+		}
+		return
+	}
+
 	err = h.iDao.Create(ctx, loanBaseinfo)
 	if err != nil {
 		logger.Error("Create error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
@@ -413,6 +898,18 @@ func (h *loanBaseinfoHandler) Create(c *gin.Context) {
 		return
 	}
 
+	// 信用评分：未启用评分卡或评分失败不影响提交，可在设备数据补传后重新评分
+	if _, err = h.scorer.score(ctx, loanBaseinfo.ID); err != nil && !errors.Is(err, errNoActiveScorecard) {
+		logger.Warn("credit scoring failed", logger.Err(err), logger.Uint64("id", loanBaseinfo.ID), middleware.GCtxRequestIDField(c))
+	}
+
+	// 自动预审：失败不影响提交，申请单保持待人工初审
+	if h.screener.enabled(ctx) {
+		if _, err = h.screener.run(ctx, loanBaseinfo.ID, model.ScreeningTriggerCreate); err != nil {
+			logger.Warn("screening failed, left for manual pre-review", logger.Err(err), logger.Uint64("id", loanBaseinfo.ID), middleware.GCtxRequestIDField(c))
+		}
+	}
+
 	response.Success(c, gin.H{"id": loanBaseinfo.ID})
 }
 
@@ -526,6 +1023,9 @@ func (h *loanBaseinfoHandler) GetByID(c *gin.Context) {
 		response.Error(c, ecode.ErrGetByIDLoanBaseinfo)
 		return
 	}
+	if loanBaseinfo.ScoreBreakdown != "" && json.Valid([]byte(loanBaseinfo.ScoreBreakdown)) {
+		data.CreditScoreBreakdown = json.RawMessage(loanBaseinfo.ScoreBreakdown)
+	}
 
 	response.Success(c, gin.H{"loanBaseinfo": data})
 }
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"loan/internal/cache"
+	"loan/internal/channel"
+	"loan/internal/dao"
+	"loan/internal/database"
+	"loan/internal/ecode"
+	"loan/internal/model"
+	"loan/internal/tool"
+)
+
+const (
+	minCallbackSecretLen = 16
+	maxCallbackBodySize  = 64 << 10
+)
+
+var _ LoanCallbacksHandler = (*loanCallbacksHandler)(nil)
+
+// LoanCallbacksHandler 支付渠道回调(webhook)，不走 JWT，按渠道密钥验签
+type LoanCallbacksHandler interface {
+	Collect(c *gin.Context)
+}
+
+type loanCallbacksHandler struct {
+	channelDao dao.LoanPaymentChannelsDao
+	settler    *repaymentSettler
+}
+
+// NewLoanCallbacksHandler creating the handler interface
+func NewLoanCallbacksHandler() LoanCallbacksHandler {
+	return &loanCallbacksHandler{
+		channelDao: dao.NewLoanPaymentChannelsDao(
+			database.GetDB(),
+			cache.NewLoanPaymentChannelsCache(database.GetCacheType()),
+		),
+		settler: newRepaymentSettler(),
+	}
+}
+
+// Collect 代收(回款)回调
+// @Summary payment channel collect callback
+// @Description Verifies the HMAC signature with the channel secret and books the repayment with the same allocation/settlement logic as manual entry. Repeated callbacks with the same payRef return the original transaction.
+// @Tags callbacks
+// @Accept json
+// @Produce json
+// @Param channelCode path string true "channel code"
+// @Param X-Signature header string true "hex(HMAC-SHA256(secret, timestamp + '.' + body))"
+// @Param X-Timestamp header string true "unix seconds"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/callbacks/collect/{channelCode} [post]
+func (h *loanCallbacksHandler) Collect(c *gin.Context) {
+	ctx := middleware.WrapCtx(c)
+	channelCode := strings.TrimSpace(c.Param("channelCode"))
+
+	// 1) 查渠道，需启用且支持代收并已配置回调密钥
+	ch, err := h.channelDao.GetByCode(ctx, channelCode)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("callback channel not found", logger.String("channel", channelCode), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrCallbackChannelUnavailable)
+		} else {
+			logger.Error("GetByCode error", logger.Err(err), logger.String("channel", channelCode), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	if ch.Status != 1 || ch.CanCollect != 1 || len(ch.CallbackKeyEnc) == 0 {
+		logger.Warn("callback channel unavailable", logger.String("channel", channelCode), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrCallbackChannelUnavailable)
+		return
+	}
+	secret, err := tool.DecryptSecretFromBytes(ch.CallbackKeyEnc)
+	if err != nil {
+		logger.Error("decrypt callback secret error", logger.Err(err), logger.String("channel", channelCode), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	// 2) 验签(签名基于原始请求体)
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxCallbackBodySize))
+	if err != nil {
+		response.Error(c, ecode.ErrCallbackPayload)
+		return
+	}
+	err = channel.Verify(secret, c.GetHeader(channel.HeaderTimestamp), c.GetHeader(channel.HeaderSignature), body, time.Now(), channel.DefaultSignatureTolerance)
+	if err != nil {
+		logger.Warn("callback signature verify failed", logger.Err(err), logger.String("channel", channelCode), middleware.GCtxRequestIDField(c))
+		response.Out(c, ecode.Unauthorized)
+		return
+	}
+
+	// 3) 解析报文
+	notification := &channel.CollectNotification{}
+	if err = json.Unmarshal(body, notification); err != nil {
+		logger.Warn("callback payload unmarshal error", logger.Err(err), logger.String("channel", channelCode), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrCallbackPayload)
+		return
+	}
+	if err = notification.Validate(); err != nil {
+		logger.Warn("callback payload invalid", logger.Err(err), logger.String("channel", channelCode), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrCallbackPayload)
+		return
+	}
+	if !notification.Succeeded() {
+		// 支付失败的回调只做记录并应答，不入账
+		logger.Info("callback payment not succeeded, ignored",
+			logger.String("channel", channelCode),
+			logger.String("pay_ref", notification.PayRef),
+			logger.String("status", notification.Status),
+			middleware.GCtxRequestIDField(c),
+		)
+		response.Success(c)
+		return
+	}
+
+	// 4) 映射为回款流水，入账逻辑与人工录入一致；渠道已实际收款，溢缴及超出代收限额均不拒绝
+	paidAt := time.Now()
+	if notification.PaidAt > 0 {
+		paidAt = time.Unix(notification.PaidAt, 0)
+	}
+	orderNo := strings.TrimSpace(notification.OrderNo)
+	if orderNo == "" {
+		orderNo = generateOrderNo("CB")
+	}
+	payMethod := strings.TrimSpace(notification.PayMethod)
+	if payMethod == "" {
+		payMethod = "CALLBACK"
+	}
+	record := &model.LoanRepaymentTransactions{
+		ScheduleID:       int64(notification.ScheduleID),
+		CollectChannelID: int64(ch.ID),
+		CollectOrderNo:   orderNo,
+		PayRef:           strings.TrimSpace(notification.PayRef),
+		PayAmount:        notification.Amount,
+		PayMethod:        payMethod,
+		PaidAt:           &paidAt,
+		Status:           model.TransactionStatusSuccess,
+		Remark:           "渠道回调入账 " + ch.Code,
+	}
+	result, err := h.settler.Settle(ctx, record, true)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("callback schedule not found", logger.Uint64("schedule_id", notification.ScheduleID), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrGetByIDLoanRepaymentSchedules)
+			return
+		}
+		// 返回 500 让渠道按其重试策略重推
+		logger.Error("callback settle error", logger.Err(err), logger.String("channel", channelCode), logger.String("pay_ref", record.PayRef), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	if result.Overpaid > 0 {
+		logger.Warn("callback overpaid",
+			logger.Uint64("id", result.ID),
+			logger.Int64("overpaid", result.Overpaid),
+			middleware.GCtxRequestIDField(c),
+		)
+	}
+
+	response.Success(c, gin.H{"id": result.ID, "duplicate": result.Duplicate})
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"loan/internal/cache"
+	"loan/internal/dao"
+	"loan/internal/database"
+	"loan/internal/ecode"
+	"loan/internal/report"
+	"loan/internal/types"
+)
+
+var _ LoanDashboardHandler = (*loanDashboardHandler)(nil)
+
+// LoanDashboardHandler 运营看板
+type LoanDashboardHandler interface {
+	KPIs(c *gin.Context)
+}
+
+type loanDashboardHandler struct {
+	iDao dao.LoanDashboardDao
+}
+
+// NewLoanDashboardHandler creating the handler interface
+func NewLoanDashboardHandler() LoanDashboardHandler {
+	return &loanDashboardHandler{
+		iDao: dao.NewLoanDashboardDao(
+			database.GetDB(),
+			cache.NewLoanDashboardCache(database.GetCacheType()),
+		),
+	}
+}
+
+// KPIs 运营看板指标：当日及本周/本月/本年累计
+// @Summary daily operations dashboard
+// @Description Returns today's and period-to-date KPIs in one call: applications received, pre-review and finance-review pass/reject counts, disbursed and net amounts, repayments collected, new overdue schedules and completed collection cases. Results are cached for a short time.
+// @Tags dashboard
+// @Produce json
+// @Param date query string false "统计日期 yyyy-mm-dd(默认当天)"
+// @Param period query string false "累计区间 week/month/year(默认 month)"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/dashboard/kpis [get]
+// @Security BearerAuth
+func (h *loanDashboardHandler) KPIs(c *gin.Context) {
+	form := &types.DashboardRequest{}
+	if err := c.ShouldBindQuery(form); err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	now := time.Now()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if d, err := report.ParseDay(form.Date); err != nil {
+		response.Error(c, ecode.InvalidParams.WithDetails(err.Error()))
+		return
+	} else if d != nil {
+		day = *d
+	}
+	if form.Period == "" {
+		form.Period = "month"
+	}
+	periodFrom := periodStart(day, form.Period)
+	next := day.AddDate(0, 0, 1)
+
+	ctx := middleware.WrapCtx(c)
+	today, err := h.iDao.GetKPIs(ctx, day, next)
+	if err != nil {
+		logger.Error("GetKPIs error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	periodToDate, err := h.iDao.GetKPIs(ctx, periodFrom, next)
+	if err != nil {
+		logger.Error("GetKPIs error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, &types.Dashboard{
+		Date:         day.Format(time.DateOnly),
+		Period:       form.Period,
+		PeriodFrom:   periodFrom.Format(time.DateOnly),
+		Today:        today,
+		PeriodToDate: periodToDate,
+	})
+}
+
+// periodStart 累计区间的起始日期，周以周一为第一天
+func periodStart(day time.Time, period string) time.Time {
+	switch period {
+	case "week":
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case "year":
+		return time.Date(day.Year(), 1, 1, 0, 0, 0, 0, day.Location())
+	default:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	}
+}
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -15,7 +17,10 @@ import (
 	"loan/internal/dao"
 	"loan/internal/database"
 	"loan/internal/ecode"
+	"loan/internal/fee"
 	"loan/internal/model"
+	"loan/internal/routing"
+	"loan/internal/tool"
 	"loan/internal/types"
 )
 
@@ -29,12 +34,18 @@ type LoanDisbursementsHandler interface {
 	GetByID(c *gin.Context)
 	List(c *gin.Context)
 	Overview(c *gin.Context)
+
+	Retry(c *gin.Context)
+	Cancel(c *gin.Context)
+	Attempts(c *gin.Context)
 }
 
 type loanDisbursementsHandler struct {
 	iDao        dao.LoanDisbursementsDao
 	baseinfoDao dao.LoanBaseinfoDao
 	auditDao    dao.LoanAuditsDao
+	attemptDao  dao.LoanDisbursementAttemptsDao
+	router      *routing.Router
 }
 
 // NewLoanDisbursementsHandler creating the handler interface
@@ -48,7 +59,40 @@ func NewLoanDisbursementsHandler() LoanDisbursementsHandler {
 			database.GetDB(),
 			cache.NewLoanBaseinfoCache(database.GetCacheType()),
 		),
-		auditDao: dao.NewLoanAuditsDao(database.GetDB(), cache.NewLoanAuditsCache(database.GetCacheType())),
+		auditDao:   dao.NewLoanAuditsDao(database.GetDB(), cache.NewLoanAuditsCache(database.GetCacheType())),
+		attemptDao: dao.NewLoanDisbursementAttemptsDao(database.GetDB()),
+		router:     newPayoutRouter(),
+	}
+}
+
+func newPayoutRouter() *routing.Router {
+	return &routing.Router{
+		ChannelDao: dao.NewLoanPaymentChannelsDao(
+			database.GetDB(),
+			cache.NewLoanPaymentChannelsCache(database.GetCacheType()),
+		),
+		DisbursementDao: dao.NewLoanDisbursementsDao(
+			database.GetDB(),
+			cache.NewLoanDisbursementsCache(database.GetCacheType()),
+		),
+	}
+}
+
+// respondPayoutRouteError 放款渠道选择/校验失败的统一响应
+func respondPayoutRouteError(c *gin.Context, err error, channelID uint64, amount int64) {
+	logger.Warn("payout route failed", logger.Err(err), logger.Uint64("channel_id", channelID), logger.Int64("amount", amount), middleware.GCtxRequestIDField(c))
+	switch {
+	case errors.Is(err, database.ErrRecordNotFound):
+		response.Error(c, ecode.ErrGetByIDLoanPaymentChannels)
+	case errors.Is(err, routing.ErrNoRoute):
+		response.Error(c, ecode.ErrNoPayoutRoute.WithDetails(err.Error()))
+	case errors.Is(err, routing.ErrChannelUnavailable):
+		response.Error(c, ecode.ErrPayoutGatewayUnavailable)
+	case errors.Is(err, fee.ErrBelowMin), errors.Is(err, fee.ErrAboveMax),
+		errors.Is(err, fee.ErrFeeExceedsAmount), errors.Is(err, fee.ErrInvalidAmount):
+		response.Error(c, ecode.ErrPayoutAmountOutOfLimit.WithDetails(err.Error()))
+	default:
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
 	}
 }
 
@@ -184,6 +228,8 @@ func (h *loanDisbursementsHandler) UpdateByID(c *gin.Context) {
 		return
 	}
 	// Note: if copier.Copy cannot assign a value to a field, add it here
+	// 放款状态只能由代付任务及重试/取消接口流转，这里忽略传入的状态
+	loanDisbursements.Status = 0
 
 	ctx := middleware.WrapCtx(c)
 	err = h.iDao.UpdateByID(ctx, loanDisbursements)
@@ -321,6 +367,214 @@ func (h *loanDisbursementsHandler) List(c *gin.Context) {
 	})
 }
 
+// Retry 放款失败后重试，可切换代付渠道
+// @Summary retry a failed disbursement
+// @Description Moves a failed disbursement to retrying with a new merchant order no, optionally on another payout channel. The payout job submits it again.
+// @Tags loanDisbursements
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.RetryLoanDisbursementRequest true "retry information"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/disbursement/{id}/retry [post]
+// @Security BearerAuth
+func (h *loanDisbursementsHandler) Retry(c *gin.Context) {
+	ctx := middleware.WrapCtx(c)
+
+	uid, ok := getUIDFromClaims(c)
+	if !ok || uid == 0 {
+		response.Out(c, ecode.Unauthorized)
+		return
+	}
+	_, id, isAbort := getLoanDisbursementsIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	form := &types.RetryLoanDisbursementRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	// 1) MFA 校验
+	ok, err := tool.ValidateMFA(c, uid, strings.TrimSpace(form.MfaCode))
+	if err != nil || !ok {
+		logger.Warn("ValidateMFA error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		if !c.Writer.Written() { // 查询 MFA 设备出错时 ValidateMFA 不写响应
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	// 2) 只有放款失败的单可以重试
+	record, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	if record.Status != model.DisbursementStatusFailed {
+		response.Error(c, ecode.ErrDisbursementNotRetryable)
+		return
+	}
+
+	// 3) 目标渠道：指定时按人工指定校验，否则沿用原渠道；按渠道当前配置重新计算代付手续费与到账金额
+	// (产品前置服务费等其它扣费不变)，并校验代付限额
+	channelID := record.PayoutChannelID
+	if form.PayoutChannelID != 0 {
+		channelID = form.PayoutChannelID
+	}
+	otherDeductions := record.DisburseAmount - record.NetAmount - record.PayoutFee
+	route, err := h.router.Manual(ctx, channelID, record.DisburseAmount, otherDeductions)
+	if err != nil {
+		respondPayoutRouteError(c, err, channelID, record.DisburseAmount)
+		return
+	}
+
+	// 4) 失败 -> 待重试，生成新的商户单号，由代付任务重新提交
+	update := map[string]interface{}{
+		"status":            model.DisbursementStatusRetrying,
+		"payout_channel_id": channelID,
+		"payout_fee":        route.Payout.Fee,
+		"net_amount":        route.Payout.NetAmount,
+		"merchant_order_no": generateOrderNo("PO"),
+		"payout_order_no":   "",
+		"fail_reason":       "",
+		"retry_by":          uid,
+	}
+	if form.PayoutChannelID != 0 {
+		update["route_mode"] = model.DisbursementRouteManual
+		update["route_reason"] = routing.ManualReason(route.Channel, "失败重试")
+	}
+	ok, err = h.iDao.TransitStatus(ctx, id, model.DisbursementStatusFailed, update)
+	if err != nil {
+		logger.Error("TransitStatus error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	if !ok {
+		response.Error(c, ecode.ErrDisbursementNotRetryable)
+		return
+	}
+
+	response.Success(c, gin.H{"id": id, "status": model.DisbursementStatusRetrying})
+}
+
+// Cancel 取消放款：仅待放款/待重试/放款失败的放款单可以取消，已提交渠道的需等待渠道结果
+// @Summary cancel a disbursement
+// @Description Cancels a disbursement that has not been submitted to a payout channel (pending, retrying or failed). Submitted payouts cannot be cancelled; wait for the channel result.
+// @Tags loanDisbursements
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.CancelLoanDisbursementRequest true "cancel information"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/disbursement/{id}/cancel [post]
+// @Security BearerAuth
+func (h *loanDisbursementsHandler) Cancel(c *gin.Context) {
+	ctx := middleware.WrapCtx(c)
+
+	uid, ok := getUIDFromClaims(c)
+	if !ok || uid == 0 {
+		response.Out(c, ecode.Unauthorized)
+		return
+	}
+	_, id, isAbort := getLoanDisbursementsIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	form := &types.CancelLoanDisbursementRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	// 1) MFA 校验
+	ok, err := tool.ValidateMFA(c, uid, strings.TrimSpace(form.MfaCode))
+	if err != nil || !ok {
+		logger.Warn("ValidateMFA error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		if !c.Writer.Written() { // 查询 MFA 设备出错时 ValidateMFA 不写响应
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	record, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	// 2) 只能取消尚未提交渠道的放款单。代付任务先提交"已提交"状态再调用网关，
+	// 已提交的放款单可能正在提交中，渠道查无此单也不代表不会出款
+	now := time.Now()
+	switch record.Status {
+	case model.DisbursementStatusPending, model.DisbursementStatusRetrying, model.DisbursementStatusFailed:
+	default:
+		response.Error(c, ecode.ErrDisbursementNotCancellable)
+		return
+	}
+
+	// 3) 取消放款单(按读取时的状态做条件更新，防止与代付任务并发流转)
+	ok, err = h.iDao.TransitStatus(ctx, id, record.Status, map[string]interface{}{
+		"status":        model.DisbursementStatusCancelled,
+		"cancel_reason": form.Reason,
+		"cancelled_by":  uid,
+		"cancelled_at":  now,
+	})
+	if err != nil {
+		logger.Error("TransitStatus error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	if !ok {
+		response.Error(c, ecode.ErrDisbursementNotCancellable)
+		return
+	}
+
+	response.Success(c, gin.H{"id": id, "status": model.DisbursementStatusCancelled})
+}
+
+// Attempts 放款单的渠道提交记录
+// @Summary list payout attempts of a disbursement
+// @Description Lists every submission of the disbursement to a payout channel, ordered by attempt no.
+// @Tags loanDisbursements
+// @Param id path string true "id"
+// @Produce json
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/disbursement/{id}/attempts [get]
+// @Security BearerAuth
+func (h *loanDisbursementsHandler) Attempts(c *gin.Context) {
+	_, id, isAbort := getLoanDisbursementsIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	attempts, err := h.attemptDao.GetByDisbursementID(ctx, id)
+	if err != nil {
+		logger.Error("GetByDisbursementID error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{"attempts": attempts})
+}
+
 func getLoanDisbursementsIDFromPath(c *gin.Context) (string, uint64, bool) {
 	idStr := c.Param("id")
 	id, err := utils.StrToUint64E(idStr)
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"loan/internal/dao"
+	"loan/internal/database"
+	"loan/internal/ecode"
+	"loan/internal/ledger"
+	"loan/internal/types"
+)
+
+var _ LoanLedgerHandler = (*loanLedgerHandler)(nil)
+
+// LoanLedgerHandler 总账查询：试算平衡、科目余额、分录明细
+type LoanLedgerHandler interface {
+	TrialBalance(c *gin.Context)
+	AccountBalance(c *gin.Context)
+	Accounts(c *gin.Context)
+	Entries(c *gin.Context)
+}
+
+type loanLedgerHandler struct {
+	iDao dao.LoanLedgerEntriesDao
+}
+
+// NewLoanLedgerHandler creating the handler interface
+func NewLoanLedgerHandler() LoanLedgerHandler {
+	return &loanLedgerHandler{
+		iDao: dao.NewLoanLedgerEntriesDao(database.GetDB()),
+	}
+}
+
+// TrialBalance 试算平衡：按科目汇总区间内借贷发生额及余额，借方合计应等于贷方合计
+// @Summary ledger trial balance
+// @Description Sums debits and credits per account for entries posted in the date range (both ends optional, inclusive) and reports whether total debits equal total credits.
+// @Tags ledger
+// @Produce json
+// @Param dateFrom query string false "yyyy-mm-dd"
+// @Param dateTo query string false "yyyy-mm-dd, inclusive"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/ledger/trial-balance [get]
+// @Security BearerAuth
+func (h *loanLedgerHandler) TrialBalance(c *gin.Context) {
+	form := &types.LedgerTrialBalanceRequest{}
+	if err := c.ShouldBindQuery(form); err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	from, to, err := ledger.ParsePeriod(form.DateFrom, form.DateTo)
+	if err != nil {
+		response.Error(c, ecode.ErrLedgerPeriod.WithDetails(err.Error()))
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	sums, err := h.iDao.SumByAccount(ctx, from, to)
+	if err != nil {
+		logger.Error("SumByAccount error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrLedgerQuery)
+		return
+	}
+
+	tb := ledger.BuildTrialBalance(sums)
+	if !tb.Balanced {
+		logger.Error("ledger trial balance is not balanced",
+			logger.Int64("total_debit", tb.TotalDebit),
+			logger.Int64("total_credit", tb.TotalCredit),
+			middleware.GCtxRequestIDField(c),
+		)
+	}
+	response.Success(c, tb)
+}
+
+// AccountBalance 科目截至某日(含)的累计余额
+// @Summary ledger account balance
+// @Description Returns the cumulative debit, credit and normal-side balance of an account up to and including the asOf date (defaults to now).
+// @Tags ledger
+// @Produce json
+// @Param account path string true "account code"
+// @Param asOf query string false "yyyy-mm-dd, inclusive"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/ledger/accounts/{account}/balance [get]
+// @Security BearerAuth
+func (h *loanLedgerHandler) AccountBalance(c *gin.Context) {
+	info, ok := ledger.Lookup(ledger.Account(strings.ToUpper(strings.TrimSpace(c.Param("account")))))
+	if !ok {
+		response.Error(c, ecode.ErrLedgerUnknownAccount)
+		return
+	}
+	form := &types.LedgerAccountBalanceRequest{}
+	if err := c.ShouldBindQuery(form); err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	_, to, err := ledger.ParsePeriod("", form.AsOf)
+	if err != nil {
+		response.Error(c, ecode.ErrLedgerPeriod.WithDetails(err.Error()))
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	sums, err := h.iDao.SumByAccount(ctx, nil, to)
+	if err != nil {
+		logger.Error("SumByAccount error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrLedgerQuery)
+		return
+	}
+
+	balance := ledger.NewBalance(info, 0, 0)
+	for _, s := range sums {
+		if s.Account == string(info.Code) {
+			balance = ledger.NewBalance(info, s.Debit, s.Credit)
+		}
+	}
+	response.Success(c, gin.H{
+		"balance": balance,
+		"asOf":    form.AsOf,
+	})
+}
+
+// Accounts 科目表
+func (h *loanLedgerHandler) Accounts(c *gin.Context) {
+	response.Success(c, gin.H{"accounts": ledger.Accounts})
+}
+
+// Entries 分录明细分页查询(可按 journal_no/ref_type/ref_id/account 等筛选)
+func (h *loanLedgerHandler) Entries(c *gin.Context) {
+	form := &types.ListLedgerEntriesRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	records, total, err := h.iDao.GetByColumns(ctx, &form.Params)
+	if err != nil {
+		logger.Error("GetByColumns error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrLedgerQuery)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"records": records,
+		"total":   total,
+	})
+}
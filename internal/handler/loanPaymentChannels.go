@@ -70,6 +70,19 @@ func (h *loanPaymentChannelsHandler) Create(c *gin.Context) {
 		return
 	}
 	// Note: if copier.Copy cannot assign a value to a field, add it here
+	if form.CallbackSecret != "" {
+		if len(form.CallbackSecret) < minCallbackSecretLen {
+			response.Error(c, ecode.InvalidParams)
+			return
+		}
+		loanPaymentChannels.CallbackKeyEnc, err = encryptSecretToBytes(form.CallbackSecret)
+		if err != nil {
+			logger.Error("encrypt callback secret error", logger.Err(err), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return
+		}
+		form.CallbackSecret = "" // 避免明文密钥写入日志
+	}
 
 	ctx := middleware.WrapCtx(c)
 	err = h.iDao.Create(ctx, loanPaymentChannels)
@@ -144,6 +157,19 @@ func (h *loanPaymentChannelsHandler) UpdateByID(c *gin.Context) {
 		return
 	}
 	// Note: if copier.Copy cannot assign a value to a field, add it here
+	if form.CallbackSecret != "" {
+		if len(form.CallbackSecret) < minCallbackSecretLen {
+			response.Error(c, ecode.InvalidParams)
+			return
+		}
+		loanPaymentChannels.CallbackKeyEnc, err = encryptSecretToBytes(form.CallbackSecret)
+		if err != nil {
+			logger.Error("encrypt callback secret error", logger.Err(err), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return
+		}
+		form.CallbackSecret = "" // 避免明文密钥写入日志
+	}
 
 	ctx := middleware.WrapCtx(c)
 	err = h.iDao.UpdateByID(ctx, loanPaymentChannels)
@@ -0,0 +1,308 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-dev-frame/sponge/pkg/copier"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+	jcopier "github.com/jinzhu/copier"
+
+	"loan/internal/cache"
+	"loan/internal/dao"
+	"loan/internal/database"
+	"loan/internal/ecode"
+	"loan/internal/finance"
+	"loan/internal/model"
+	"loan/internal/types"
+)
+
+var _ LoanProductsHandler = (*loanProductsHandler)(nil)
+
+// LoanProductsHandler defining the handler interface
+type LoanProductsHandler interface {
+	Create(c *gin.Context)
+	DeleteByID(c *gin.Context)
+	UpdateByID(c *gin.Context)
+	GetByID(c *gin.Context)
+	List(c *gin.Context)
+}
+
+type loanProductsHandler struct {
+	iDao dao.LoanProductsDao
+}
+
+// NewLoanProductsHandler creating the handler interface
+func NewLoanProductsHandler() LoanProductsHandler {
+	return &loanProductsHandler{
+		iDao: dao.NewLoanProductsDao(
+			database.GetDB(), // db driver is mysql
+			cache.NewLoanProductsCache(database.GetCacheType()),
+		),
+	}
+}
+
+// Create a new loanProducts
+// @Summary Create a new loanProducts
+// @Description Creates a new loanProducts entity using the provided data in the request body.
+// @Tags loanProducts
+// @Accept json
+// @Produce json
+// @Param data body types.CreateLoanProductsRequest true "loanProducts information"
+// @Success 200 {object} types.CreateLoanProductsReply{}
+// @Router /api/v1/products [post]
+// @Security BearerAuth
+func (h *loanProductsHandler) Create(c *gin.Context) {
+	form := &types.CreateLoanProductsRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	loanProducts := &model.LoanProducts{}
+	err = copier.Copy(loanProducts, form)
+	if err != nil {
+		response.Error(c, ecode.ErrCreateLoanProducts)
+		return
+	}
+	// Note: if copier.Copy cannot assign a value to a field, add it here
+	if loanProducts.FeeModel == "" {
+		loanProducts.FeeModel = model.FeeModelNone
+	}
+	if loanProducts.InstallmentCount == 0 {
+		loanProducts.InstallmentCount = 1
+	}
+	if err = finance.ValidateProduct(loanProducts); err != nil {
+		logger.Warn("ValidateProduct error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrInvalidLoanProduct)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err = h.iDao.Create(ctx, loanProducts)
+	if err != nil {
+		logger.Error("Create error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{"id": loanProducts.ID})
+}
+
+// DeleteByID delete a loanProducts by id
+// @Summary Delete a loanProducts by id
+// @Description Deletes a existing loanProducts identified by the given id in the path.
+// @Tags loanProducts
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.DeleteLoanProductsByIDReply{}
+// @Router /api/v1/products/{id} [delete]
+// @Security BearerAuth
+func (h *loanProductsHandler) DeleteByID(c *gin.Context) {
+	_, id, isAbort := getLoanProductsIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err := h.iDao.DeleteByID(ctx, id)
+	if err != nil {
+		logger.Error("DeleteByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c)
+}
+
+// UpdateByID update a loanProducts by id
+// @Summary Update a loanProducts by id
+// @Description Updates the specified loanProducts by given id in the path, support partial update.
+// @Tags loanProducts
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.UpdateLoanProductsByIDRequest true "loanProducts information"
+// @Success 200 {object} types.UpdateLoanProductsByIDReply{}
+// @Router /api/v1/products/{id} [put]
+// @Security BearerAuth
+func (h *loanProductsHandler) UpdateByID(c *gin.Context) {
+	_, id, isAbort := getLoanProductsIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	form := &types.UpdateLoanProductsByIDRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	form.ID = id
+
+	loanProducts := &model.LoanProducts{}
+	err = copier.Copy(loanProducts, form)
+	if err != nil {
+		response.Error(c, ecode.ErrUpdateByIDLoanProducts)
+		return
+	}
+	// Note: if copier.Copy cannot assign a value to a field, add it here
+
+	ctx := middleware.WrapCtx(c)
+
+	// 部分更新：合并到现有记录后再整体校验
+	existing, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	merged := *existing
+	if err = copier.CopyWithOption(&merged, loanProducts, jcopier.Option{IgnoreEmpty: true}); err != nil {
+		response.Error(c, ecode.ErrUpdateByIDLoanProducts)
+		return
+	}
+	if err = finance.ValidateProduct(&merged); err != nil {
+		logger.Warn("ValidateProduct error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrInvalidLoanProduct)
+		return
+	}
+
+	err = h.iDao.UpdateByID(ctx, loanProducts)
+	if err != nil {
+		logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c)
+}
+
+// GetByID get a loanProducts by id
+// @Summary Get a loanProducts by id
+// @Description Gets detailed information of a loanProducts specified by the given id in the path.
+// @Tags loanProducts
+// @Param id path string true "id"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.GetLoanProductsByIDReply{}
+// @Router /api/v1/products/{id} [get]
+// @Security BearerAuth
+func (h *loanProductsHandler) GetByID(c *gin.Context) {
+	_, id, isAbort := getLoanProductsIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	loanProducts, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("GetByID not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	data := &types.LoanProductsObjDetail{}
+	err = copier.Copy(data, loanProducts)
+	if err != nil {
+		response.Error(c, ecode.ErrGetByIDLoanProducts)
+		return
+	}
+	// Note: if copier.Copy cannot assign a value to a field, add it here
+
+	response.Success(c, gin.H{"loanProducts": data})
+}
+
+// List get a paginated list of loanProductss by custom conditions
+// @Summary Get a paginated list of loanProductss by custom conditions
+// @Description Returns a paginated list of loanProducts based on query filters, including page number and size.
+// @Tags loanProducts
+// @Accept json
+// @Produce json
+// @Param data body types.Params true "query parameters"
+// @Success 200 {object} types.ListLoanProductssReply{}
+// @Router /api/v1/products/list [post]
+// @Security BearerAuth
+func (h *loanProductsHandler) List(c *gin.Context) {
+	form := &types.ListLoanProductssRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	loanProductss, total, err := h.iDao.GetByColumns(ctx, &form.Params)
+	if err != nil {
+		logger.Error("GetByColumns error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	data, err := convertLoanProductss(loanProductss)
+	if err != nil {
+		response.Error(c, ecode.ErrListLoanProducts)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"records": data,
+		"total":   total,
+	})
+}
+
+func getLoanProductsIDFromPath(c *gin.Context) (string, uint64, bool) {
+	idStr := c.Param("id")
+	id, err := utils.StrToUint64E(idStr)
+	if err != nil || id == 0 {
+		logger.Warn("StrToUint64E error: ", logger.String("idStr", idStr), middleware.GCtxRequestIDField(c))
+		return "", 0, true
+	}
+
+	return idStr, id, false
+}
+
+func convertLoanProducts(loanProducts *model.LoanProducts) (*types.LoanProductsObjDetail, error) {
+	data := &types.LoanProductsObjDetail{}
+	err := copier.Copy(data, loanProducts)
+	if err != nil {
+		return nil, err
+	}
+	// Note: if copier.Copy cannot assign a value to a field, add it here
+
+	return data, nil
+}
+
+func convertLoanProductss(fromValues []*model.LoanProducts) ([]*types.LoanProductsObjDetail, error) {
+	toValues := []*types.LoanProductsObjDetail{}
+	for _, v := range fromValues {
+		data, err := convertLoanProducts(v)
+		if err != nil {
+			return nil, err
+		}
+		toValues = append(toValues, data)
+	}
+
+	return toValues, nil
+}
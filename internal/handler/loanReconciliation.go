@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"loan/internal/cache"
+	"loan/internal/dao"
+	"loan/internal/database"
+	"loan/internal/ecode"
+	"loan/internal/reconcile"
+	"loan/internal/types"
+)
+
+const maxSettlementFileSize = 20 << 20
+
+var _ LoanReconciliationHandler = (*loanReconciliationHandler)(nil)
+
+// LoanReconciliationHandler 渠道结算对账
+type LoanReconciliationHandler interface {
+	Settlement(c *gin.Context)
+}
+
+type loanReconciliationHandler struct {
+	channelDao dao.LoanPaymentChannelsDao
+	ledger     *reconcile.Ledger
+}
+
+// NewLoanReconciliationHandler creating the handler interface
+func NewLoanReconciliationHandler() LoanReconciliationHandler {
+	return &loanReconciliationHandler{
+		channelDao: dao.NewLoanPaymentChannelsDao(
+			database.GetDB(),
+			cache.NewLoanPaymentChannelsCache(database.GetCacheType()),
+		),
+		ledger: &reconcile.Ledger{
+			DisbursementDao: dao.NewLoanDisbursementsDao(
+				database.GetDB(),
+				cache.NewLoanDisbursementsCache(database.GetCacheType()),
+			),
+			TransactionDao: dao.NewLoanRepaymentTransactionsDao(
+				database.GetDB(),
+				cache.NewLoanRepaymentTransactionsCache(database.GetCacheType()),
+			),
+		},
+	}
+}
+
+// Settlement 上传渠道结算文件，与平台放款单/回款流水比对
+// @Summary reconcile a channel settlement file
+// @Description Parses the uploaded settlement file with the parser registered for the channel code (generic CSV by default), matches lines against disbursements (payout_order_no / merchant_order_no) and repayment transactions (pay_ref / collect_order_no) in the date range, and returns matched, missing-on-our-side, missing-on-channel and amount-mismatch items.
+// @Tags reconciliation
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "settlement file"
+// @Param channelID formData integer true "payment channel id"
+// @Param dateFrom formData string true "yyyy-mm-dd"
+// @Param dateTo formData string true "yyyy-mm-dd, inclusive"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/reconciliation/settlement [post]
+// @Security BearerAuth
+func (h *loanReconciliationHandler) Settlement(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSettlementFileSize)
+
+	form := &types.ReconcileSettlementRequest{}
+	if err := c.ShouldBind(form); err != nil {
+		logger.Warn("ShouldBind error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	from, to, err := reconcile.ParseWindow(form.DateFrom, form.DateTo)
+	if err != nil {
+		response.Error(c, ecode.ErrReconcileWindow.WithDetails(err.Error()))
+		return
+	}
+
+	// 1) 结算文件
+	file, fileHeader, err := c.Request.FormFile("file")
+	if err != nil {
+		response.Error(c, ecode.ErrSettlementFileRequired)
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	if ext := strings.ToLower(filepath.Ext(fileHeader.Filename)); ext != ".csv" && ext != ".txt" {
+		response.Error(c, ecode.UnsupportedFileType)
+		return
+	}
+
+	// 2) 渠道，按渠道编码选择解析器
+	ctx := middleware.WrapCtx(c)
+	ch, err := h.channelDao.GetByID(ctx, form.ChannelID)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+			return
+		}
+		logger.Error("GetByID error", logger.Err(err), logger.Any("channelID", form.ChannelID), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	// 3) 解析并比对
+	report, err := h.ledger.Run(ctx, reconcile.ParserFor(ch.Code), file, ch.ID, from, to)
+	if err != nil {
+		var pe *reconcile.ParseError
+		if errors.As(err, &pe) {
+			logger.Warn("parse settlement file error", logger.Err(err), logger.String("file", fileHeader.Filename), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrParseSettlementFile.WithDetails(pe.Err.Error()))
+			return
+		}
+		logger.Error("load reconciliation ledger error", logger.Err(err), logger.Any("channelID", ch.ID), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrReconcileLedger)
+		return
+	}
+
+	logger.Info("channel settlement reconciled",
+		logger.String("channel", ch.Code),
+		logger.String("file", fileHeader.Filename),
+		logger.Any("summary", report.Summary),
+		middleware.GCtxRequestIDField(c),
+	)
+	response.Success(c, gin.H{
+		"channelID":   ch.ID,
+		"channelCode": ch.Code,
+		"dateFrom":    form.DateFrom,
+		"dateTo":      form.DateTo,
+		"balanced":    report.Balanced(),
+		"report":      report,
+	})
+}
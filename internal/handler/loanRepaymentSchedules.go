@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"context"
 	"errors"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -15,7 +18,11 @@ import (
 	"loan/internal/dao"
 	"loan/internal/database"
 	"loan/internal/ecode"
+	"loan/internal/fee"
+	"loan/internal/finance"
+	"loan/internal/ledger"
 	"loan/internal/model"
+	"loan/internal/tool"
 	"loan/internal/types"
 )
 
@@ -29,10 +36,20 @@ type LoanRepaymentSchedulesHandler interface {
 	GetByID(c *gin.Context)
 	List(c *gin.Context)
 	Overview(c *gin.Context)
+	PenaltyAccruals(c *gin.Context)
+	RecomputePenalty(c *gin.Context)
+	PayoffQuote(c *gin.Context)
+	Extend(c *gin.Context)
+	Extensions(c *gin.Context)
 }
 
 type loanRepaymentSchedulesHandler struct {
-	iDao dao.LoanRepaymentSchedulesDao
+	iDao       dao.LoanRepaymentSchedulesDao
+	accrualDao dao.LoanPenaltyAccrualsDao
+	settler    *repaymentSettler
+
+	extensionDao dao.LoanScheduleExtensionsDao
+	waiverDao    dao.LoanWaiverRequestsDao
 }
 
 // NewLoanRepaymentSchedulesHandler creating the handler interface
@@ -42,7 +59,472 @@ func NewLoanRepaymentSchedulesHandler() LoanRepaymentSchedulesHandler {
 			database.GetDB(), // db driver is mysql
 			cache.NewLoanRepaymentSchedulesCache(database.GetCacheType()),
 		),
+		accrualDao: dao.NewLoanPenaltyAccrualsDao(database.GetDB()),
+		settler:    newRepaymentSettler(),
+
+		extensionDao: dao.NewLoanScheduleExtensionsDao(database.GetDB()),
+		waiverDao:    dao.NewLoanWaiverRequestsDao(database.GetDB()),
+	}
+}
+
+// Extend 展期：客户支付展期费(写一条分配到费用的回款流水)，该期及之后未结清期次的应还日期顺延 N 天，
+// 已产生的罚息按系统设置冻结或免除，并写展期记录；每笔借款的展期次数受系统设置限制
+func (h *loanRepaymentSchedulesHandler) Extend(c *gin.Context) {
+	ctx := middleware.WrapCtx(c)
+
+	uid, ok := getUIDFromClaims(c)
+	if !ok || uid == 0 {
+		response.Out(c, ecode.Unauthorized)
+		return
+	}
+
+	_, id, isAbort := getLoanRepaymentSchedulesIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	form := &types.ExtendLoanRepaymentSchedulesRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	// 1) MFA 校验（不进事务）
+	ok, err := tool.ValidateMFA(c, uid, strings.TrimSpace(form.MfaCode))
+	if err != nil || !ok {
+		logger.Warn("ValidateMFA failed", logger.Err(err), logger.Uint64("uid", uid), middleware.GCtxRequestIDField(c))
+		if !c.Writer.Written() { // 查询 MFA 设备出错时 ValidateMFA 不写响应
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	// 2) 读取展期规则并锁定放款单全部期次，展期次数校验与写展期记录在同一事务内，避免并发展期超限
+	policy := loadExtensionPolicy(ctx, h.settler.settingsDao)
+	schedule, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	disbursementID := uint64(schedule.DisbursementID)
+
+	tx := database.GetDB().WithContext(ctx).Begin()
+	if tx.Error != nil {
+		logger.Error("tx begin error", logger.Err(tx.Error), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			logger.Error("panic in Extend handler", logger.Any("recover", r), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.InternalServerError)
+		}
+	}()
+
+	schedules, err := h.iDao.GetByDisbursementIDForUpdate(ctx, tx, disbursementID)
+	if err != nil {
+		tx.Rollback()
+		logger.Error("GetByDisbursementIDForUpdate error", logger.Err(err), logger.Uint64("disbursement_id", disbursementID), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+	used, err := h.extensionDao.CountByDisbursementIDByTx(ctx, tx, disbursementID)
+	if err != nil {
+		tx.Rollback()
+		logger.Error("CountByDisbursementIDByTx error", logger.Err(err), logger.Uint64("disbursement_id", disbursementID), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+	var target *model.LoanRepaymentSchedules
+	for _, sc := range schedules {
+		if sc.ID == id {
+			target = sc
+		}
+	}
+	if target == nil {
+		tx.Rollback()
+		response.Error(c, ecode.NotFound)
+		return
+	}
+	if err = finance.CheckExtension(target, int(used), form.Days, policy); err != nil {
+		tx.Rollback()
+		respondExtensionError(c, err)
+		return
+	}
+
+	// 3) 展期费入账并过账：回款流水全部分配到费用，代收渠道需校验限额；同一渠道流水号不能重复使用
+	payRef := strings.TrimSpace(form.PayRef)
+	if payRef != "" {
+		_, err = h.settler.txDao.GetByChannelPayRef(ctx, tx, form.CollectChannelID, payRef)
+		if err == nil {
+			tx.Rollback()
+			response.Error(c, ecode.ErrDuplicatePayRef)
+			return
+		}
+		if !errors.Is(err, database.ErrRecordNotFound) {
+			tx.Rollback()
+			logger.Error("GetByChannelPayRef error", logger.Err(err), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.InternalServerError)
+			return
+		}
+	}
+	now := time.Now()
+	extensionFee := finance.ExtensionFee(target, form.Days, policy)
+	var transactionID uint64
+	if extensionFee > 0 {
+		record := &model.LoanRepaymentTransactions{
+			ScheduleID:       int64(target.ID),
+			CollectChannelID: form.CollectChannelID,
+			CollectOrderNo:   generateOrderNo("EX"),
+			PayRef:           payRef,
+			PayAmount:        int(extensionFee),
+			PayMethod:        "IMPORT",
+			PaidAt:           &now,
+			AllocFee:         int(extensionFee),
+			Status:           model.TransactionStatusSuccess,
+			VoucherFileName:  form.VoucherFileName,
+			Remark:           "展期费",
+			CreatedBy:        uid,
+		}
+		if err = h.settler.applyCollectFee(ctx, record, false); err != nil {
+			tx.Rollback()
+			respondExtensionError(c, err)
+			return
+		}
+		if transactionID, err = h.settler.txDao.CreateByTx(ctx, tx, record); err != nil {
+			tx.Rollback()
+			if isDuplicateKeyErr(err) {
+				response.Error(c, ecode.ErrDuplicatePayRef)
+				return
+			}
+			logger.Error("CreateByTx extension fee error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrCreateLoanRepaymentTransactions)
+			return
+		}
+		if err = h.settler.book.Post(ctx, tx, ledger.Repayment(record)); err != nil {
+			tx.Rollback()
+			logger.Error("ledger post extension fee error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.InternalServerError)
+			return
+		}
+	}
+
+	// 4) 顺延该期及之后未结清期次的应还日期
+	ext := finance.ApplyExtension(target, form.Days, extensionFee, policy, now)
+	shifted := 0
+	for _, sc := range schedules {
+		if sc.ID != target.ID {
+			if sc.InstallmentNo < target.InstallmentNo || sc.Status == model.ScheduleStatusSettled {
+				continue
+			}
+			finance.ShiftDueDate(sc, form.Days)
+			finance.RefreshScheduleStatus(sc, now)
+			shifted++
+		}
+		if err = h.iDao.UpdateExtensionByTx(ctx, tx, sc); err != nil {
+			tx.Rollback()
+			logger.Error("UpdateExtensionByTx error", logger.Err(err), logger.Uint64("schedule_id", sc.ID), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.InternalServerError)
+			return
+		}
+	}
+
+	// 5) 写展期记录，免除罚息时过账
+	record := &model.LoanScheduleExtensions{
+		ScheduleID:     target.ID,
+		DisbursementID: disbursementID,
+		ExtensionNo:    int(used) + 1,
+		Days:           form.Days,
+		DueDateBefore:  &ext.DueDateBefore,
+		DueDateAfter:   &ext.DueDateAfter,
+		FeeAmount:      ext.Fee,
+		TransactionID:  transactionID,
+		PenaltyMode:    policy.PenaltyMode,
+		PenaltyWaived:  ext.PenaltyWaived,
+		StatusBefore:   ext.StatusBefore,
+		ShiftedCount:   shifted,
+		CreatedBy:      uid,
+		Remark:         form.Remark,
+	}
+	if _, err = h.extensionDao.CreateByTx(ctx, tx, record); err != nil {
+		tx.Rollback()
+		logger.Error("CreateByTx extension error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+	if record.PenaltyWaived > 0 {
+		if err = h.settler.book.Post(ctx, tx, ledger.ExtensionWaiver(record, now)); err != nil {
+			tx.Rollback()
+			logger.Error("ledger post extension waiver error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.InternalServerError)
+			return
+		}
+	}
+
+	// 6) 提交
+	if err = tx.Commit().Error; err != nil {
+		logger.Error("tx commit failed", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"extension":      record,
+		"transactionID":  transactionID,
+		"scheduleStatus": target.Status,
+	})
+}
+
+// Extensions 查询期次所属借款的展期记录
+func (h *loanRepaymentSchedulesHandler) Extensions(c *gin.Context) {
+	_, id, isAbort := getLoanRepaymentSchedulesIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	schedule, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	extensions, err := h.extensionDao.GetByDisbursementID(ctx, uint64(schedule.DisbursementID))
+	if err != nil {
+		logger.Error("GetByDisbursementID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	policy := loadExtensionPolicy(ctx, h.settler.settingsDao)
+	response.Success(c, gin.H{
+		"extensions": extensions,
+		"maxCount":   policy.MaxCount,
+		"remaining":  max(policy.MaxCount-len(extensions), 0),
+	})
+}
+
+// loadExtensionPolicy 读取系统设置中的展期规则
+func loadExtensionPolicy(ctx context.Context, settingsDao dao.LoanSettingsDao) finance.ExtensionPolicy {
+	policy := finance.ExtensionPolicy{
+		MaxCount:    int(settingsDao.GetInt64ByName(ctx, model.SettingExtensionMaxCount, 0)),
+		MaxDays:     int(settingsDao.GetInt64ByName(ctx, model.SettingExtensionMaxDays, 0)),
+		FeeDailyBp:  settingsDao.GetInt64ByName(ctx, model.SettingExtensionFeeDailyBp, 0),
+		FeeFixed:    settingsDao.GetInt64ByName(ctx, model.SettingExtensionFeeFixed, 0),
+		PenaltyMode: model.ExtensionPenaltyFreeze,
+	}
+	if setting, err := settingsDao.GetByName(ctx, model.SettingExtensionPenaltyMode); err == nil &&
+		strings.EqualFold(strings.TrimSpace(setting.Value), model.ExtensionPenaltyWaive) {
+		policy.PenaltyMode = model.ExtensionPenaltyWaive
+	}
+	return policy
+}
+
+func respondExtensionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, finance.ErrExtensionDisabled):
+		response.Error(c, ecode.ErrExtensionDisabled)
+	case errors.Is(err, finance.ErrExtensionLimitReached):
+		response.Error(c, ecode.ErrExtensionLimitReached.WithDetails(err.Error()))
+	case errors.Is(err, finance.ErrInvalidExtensionDays):
+		response.Error(c, ecode.ErrInvalidExtensionDays.WithDetails(err.Error()))
+	case errors.Is(err, finance.ErrScheduleSettled):
+		response.Error(c, ecode.ErrScheduleAlreadySettled)
+	case errors.Is(err, fee.ErrInvalidAmount), errors.Is(err, fee.ErrBelowMin), errors.Is(err, fee.ErrAboveMax):
+		response.Error(c, ecode.ErrCollectAmountOutOfLimit.WithDetails(err.Error()))
+	case errors.Is(err, errCollectChannelNotFound):
+		response.Error(c, ecode.ErrGetByIDLoanPaymentChannels)
+	default:
+		logger.Error("extension failed", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+	}
+}
+
+// PayoffQuote 提前结清报价：汇总放款单全部未结清期次的剩余本金、截至当天已计利息/费用及罚息，
+// 按产品配置计算减免与提前结清手续费
+func (h *loanRepaymentSchedulesHandler) PayoffQuote(c *gin.Context) {
+	form := &types.PayoffQuoteRequest{}
+	if err := c.ShouldBindQuery(form); err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	quote, err := h.settler.Quote(ctx, form.DisbursementID, time.Now())
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.Error(c, ecode.ErrGetByIDLoanDisbursements)
+		case errors.Is(err, finance.ErrNothingToPayoff):
+			response.Error(c, ecode.ErrNothingToPayoff)
+		default:
+			logger.Error("Quote payoff error", logger.Err(err), logger.Uint64("disbursement_id", form.DisbursementID), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	response.Success(c, convertPayoffQuote(form.DisbursementID, quote))
+}
+
+// PenaltyAccruals 查询期次的罚息计提流水，并与期次当前应还罚息对账
+func (h *loanRepaymentSchedulesHandler) PenaltyAccruals(c *gin.Context) {
+	_, id, isAbort := getLoanRepaymentSchedulesIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	schedule, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	accruals, err := h.accrualDao.GetByScheduleID(ctx, id)
+	if err != nil {
+		logger.Error("GetByScheduleID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	var accruedTotal int64
+	for _, a := range accruals {
+		accruedTotal += a.Amount
+	}
+	waived, err := h.penaltyWaived(ctx, id)
+	if err != nil {
+		logger.Error("sum waived penalty error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	expected := max(accruedTotal-waived, 0)
+
+	response.Success(c, gin.H{
+		"accruals":     accruals,
+		"accruedTotal": accruedTotal,        // 计提流水合计
+		"waivedTotal":  waived,              // 减免审批及展期免除的罚息合计
+		"expectedDue":  expected,            // 按计提与减免重算的应还罚息
+		"penaltyDue":   schedule.PenaltyDue, // 期次当前应还罚息
+		"consistent":   expected == int64(schedule.PenaltyDue),
+	})
+}
+
+// RecomputePenalty 按计提流水合计减去已减免的罚息重算期次应还罚息，应还总额与状态同步调整
+// @Summary Recompute the penalty of a schedule
+// @Description Rebuilds penalty_due from the accrual records (sum of accrued penalty minus penalty waived by approved waiver requests and extensions). The total due and the schedule status are adjusted accordingly.
+// @Tags loanRepaymentSchedules
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/loanRepaymentSchedules/{id}/penalty-recompute [post]
+// @Security BearerAuth
+func (h *loanRepaymentSchedulesHandler) RecomputePenalty(c *gin.Context) {
+	_, id, isAbort := getLoanRepaymentSchedulesIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	ctx := middleware.WrapCtx(c)
+
+	tx := database.GetDB().WithContext(ctx).Begin()
+	if tx.Error != nil {
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	// 兜底：函数任何提前 return 都会回滚（Commit 后 Rollback 不会生效）
+	defer func() {
+		_ = tx.Rollback().Error
+	}()
+
+	// 1) 锁定期次，计提任务同样先锁期次再写流水，锁定后计提合计不会变化
+	schedule, err := h.iDao.GetByIDForUpdate(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByIDForUpdate error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	// 2) 计提合计与减免合计
+	accrued, err := h.accrualDao.SumAmountByScheduleID(ctx, id)
+	if err != nil {
+		logger.Error("SumAmountByScheduleID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
 	}
+	waived, err := h.penaltyWaived(ctx, id)
+	if err != nil {
+		logger.Error("sum waived penalty error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	// 3) 重算并落库
+	before := schedule.PenaltyDue
+	delta := finance.RecomputePenalty(schedule, accrued, waived, time.Now())
+	if delta != 0 {
+		if err = h.iDao.UpdatePenaltyByTx(ctx, tx, schedule); err != nil {
+			logger.Error("UpdatePenaltyByTx error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrUpdateByIDLoanRepaymentSchedules)
+			return
+		}
+	}
+	if err = tx.Commit().Error; err != nil {
+		logger.Error("tx commit failed", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	if delta != 0 {
+		logger.Info("penalty recomputed", logger.Uint64("schedule_id", id), logger.Int("before", before),
+			logger.Int("after", schedule.PenaltyDue), middleware.GCtxRequestIDField(c))
+	}
+
+	response.Success(c, gin.H{
+		"accruedTotal":  accrued,
+		"waivedTotal":   waived,
+		"penaltyBefore": before,
+		"penaltyDue":    schedule.PenaltyDue,
+		"adjusted":      delta,
+		"status":        schedule.Status,
+	})
+}
+
+// penaltyWaived 期次已免除的罚息：审批通过的罚息减免加展期免除的罚息
+func (h *loanRepaymentSchedulesHandler) penaltyWaived(ctx context.Context, id uint64) (int64, error) {
+	waived, err := h.waiverDao.SumApprovedByScheduleID(ctx, id, string(finance.ComponentPenalty))
+	if err != nil {
+		return 0, err
+	}
+	extended, err := h.extensionDao.SumPenaltyWaivedByScheduleID(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	return waived + extended, nil
 }
 
 // Overview 还款计划概览（分页查询）
@@ -324,3 +806,35 @@ func convertLoanRepaymentScheduless(fromValues []*model.LoanRepaymentSchedules)
 
 	return toValues, nil
 }
+
+func convertPayoffAmounts(b finance.Buckets) types.PayoffAmounts {
+	return types.PayoffAmounts{
+		Principal: b.Principal,
+		Interest:  b.Interest,
+		Fee:       b.Fee,
+		Penalty:   b.Penalty,
+		Total:     b.Total(),
+	}
+}
+
+func convertPayoffQuote(disbursementID uint64, q *finance.PayoffQuote) *types.PayoffQuoteDetail {
+	data := &types.PayoffQuoteDetail{
+		DisbursementID: disbursementID,
+		QuoteDate:      q.On.Format(time.DateOnly),
+		Outstanding:    convertPayoffAmounts(q.Outstanding),
+		Unaccrued:      convertPayoffAmounts(q.Unaccrued),
+		Discount:       q.Discount,
+		Fee:            q.Fee,
+		Total:          q.Total,
+		Items:          make([]*types.PayoffQuoteItem, 0, len(q.Items)),
+	}
+	for _, item := range q.Items {
+		data.Items = append(data.Items, &types.PayoffQuoteItem{
+			ScheduleID:    item.ScheduleID,
+			InstallmentNo: item.InstallmentNo,
+			Due:           convertPayoffAmounts(item.Due),
+			Waived:        convertPayoffAmounts(item.Waived),
+		})
+	}
+	return data
+}
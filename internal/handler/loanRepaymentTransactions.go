@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-dev-frame/sponge/pkg/copier"
@@ -23,6 +24,9 @@ import (
 	"loan/internal/dao"
 	"loan/internal/database"
 	"loan/internal/ecode"
+	"loan/internal/fee"
+	"loan/internal/finance"
+	"loan/internal/ledger"
 	"loan/internal/model"
 	"loan/internal/types"
 )
@@ -41,11 +45,14 @@ type LoanRepaymentTransactionsHandler interface {
 	History(c *gin.Context)
 	UploadVoucher(c *gin.Context)
 	GetVoucherBase64(c *gin.Context)
+	Reverse(c *gin.Context)
+	Payoff(c *gin.Context)
 }
 
 type loanRepaymentTransactionsHandler struct {
 	iDao                 dao.LoanRepaymentTransactionsDao
 	repaymentScheduleDao dao.LoanRepaymentSchedulesDao
+	settler              *repaymentSettler
 }
 
 // NewLoanRepaymentTransactionsHandler creating the handler interface
@@ -59,6 +66,7 @@ func NewLoanRepaymentTransactionsHandler() LoanRepaymentTransactionsHandler {
 			database.GetDB(),
 			cache.NewLoanRepaymentSchedulesCache(database.GetCacheType()),
 		),
+		settler: newRepaymentSettler(),
 	}
 }
 
@@ -231,6 +239,152 @@ func (h *loanRepaymentTransactionsHandler) History(c *gin.Context) {
 	response.Success(c, transactions)
 }
 
+// Reverse 冲正一笔回款流水：写入一条负数冲正流水，扣回期次已还金额并重新打开已结清的期次，原流水保持不变
+func (h *loanRepaymentTransactionsHandler) Reverse(c *gin.Context) {
+	ctx := middleware.WrapCtx(c)
+
+	uid, ok := getUIDFromClaims(c)
+	if !ok || uid == 0 {
+		response.Out(c, ecode.Unauthorized)
+		return
+	}
+
+	_, id, isAbort := getLoanRepaymentTransactionsIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	form := &types.ReverseLoanRepaymentTransactionRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	// 1) MFA 校验（不进事务）；校验失败时 ValidateMFA 已写响应，查询 MFA 设备出错时除外
+	ok, err := tool.ValidateMFA(c, uid, strings.TrimSpace(form.MfaCode))
+	if err != nil || !ok {
+		logger.Warn("ValidateMFA error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		if !c.Writer.Written() {
+			response.Error(c, ecode.InternalServerError)
+		}
+		return
+	}
+
+	// 2) 开事务
+	tx := database.GetDB().WithContext(ctx).Begin()
+	if tx.Error != nil {
+		logger.Error("tx begin error", logger.Err(tx.Error), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			logger.Error("panic in Reverse handler", logger.Any("recover", r), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.InternalServerError)
+		}
+	}()
+
+	// 3) 锁住原流水，只有成功的原始流水可以冲正
+	original, err := h.iDao.GetByIDForUpdate(ctx, tx, id)
+	if err != nil {
+		tx.Rollback()
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByIDForUpdate error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.InternalServerError)
+		}
+		return
+	}
+	if original.Status != model.TransactionStatusSuccess || original.ReversalOfID != nil {
+		tx.Rollback()
+		response.Error(c, ecode.ErrTransactionNotReversible)
+		return
+	}
+	if _, err = h.iDao.GetReversalByOriginalID(ctx, tx, original.ID); err == nil {
+		tx.Rollback()
+		response.Error(c, ecode.ErrTransactionAlreadyReversed)
+		return
+	} else if !errors.Is(err, database.ErrRecordNotFound) {
+		tx.Rollback()
+		logger.Error("GetReversalByOriginalID error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+
+	// 4) 锁住期次，扣回原流水的分配并重新计算状态
+	schedule, err := h.repaymentScheduleDao.GetByIDForUpdate(ctx, tx, uint64(original.ScheduleID))
+	if err != nil {
+		tx.Rollback()
+		logger.Error("GetByIDForUpdate LoanRepaymentSchedules error", logger.Err(err), logger.Int64("schedule_id", original.ScheduleID), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrGetByIDLoanRepaymentSchedules)
+		return
+	}
+	allocation := finance.TransactionAllocation(original)
+	if err = finance.RevertFromSchedule(schedule, allocation); err != nil {
+		tx.Rollback()
+		logger.Error("RevertFromSchedule error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrTransactionNotReversible)
+		return
+	}
+	now := time.Now()
+	finance.RefreshScheduleStatus(schedule, now)
+	if err = h.repaymentScheduleDao.UpdateRepaymentByTx(ctx, tx, schedule); err != nil {
+		tx.Rollback()
+		logger.Error("UpdateRepaymentByTx error", logger.Err(err), logger.Uint64("schedule_id", schedule.ID), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+
+	// 5) 写冲正流水，金额与分配均为原流水的相反数
+	originalID := original.ID
+	reversal := &model.LoanRepaymentTransactions{
+		ScheduleID:       original.ScheduleID,
+		CollectChannelID: original.CollectChannelID,
+		CollectOrderNo:   generateOrderNo("RV"),
+		PayRef:           original.PayRef,
+		PayAmount:        -original.PayAmount,
+		PayMethod:        original.PayMethod,
+		PaidAt:           &now,
+		AllocPrincipal:   -original.AllocPrincipal,
+		AllocInterest:    -original.AllocInterest,
+		AllocFee:         -original.AllocFee,
+		AllocPenalty:     -original.AllocPenalty,
+		Status:           model.TransactionStatusReversal,
+		Remark:           form.Reason,
+		CreatedBy:        uid,
+		ReversalOfID:     &originalID,
+		ReverseReason:    form.Reason,
+	}
+	reversalID, err := h.iDao.CreateByTx(ctx, tx, reversal)
+	if err != nil {
+		tx.Rollback()
+		logger.Error("CreateByTx reversal error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrCreateLoanRepaymentTransactions)
+		return
+	}
+
+	// 6) 冲正分录过账(与原回款分录方向相反)
+	if err = h.settler.book.Post(ctx, tx, ledger.Repayment(reversal)); err != nil {
+		tx.Rollback()
+		logger.Error("ledger post reversal error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+
+	// 7) 提交
+	if err = tx.Commit().Error; err != nil {
+		logger.Error("tx commit failed", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+
+	response.Success(c, gin.H{"id": reversalID, "scheduleStatus": schedule.Status})
+}
+
 // Create a new loanRepaymentTransactions
 // @Summary Create a new loanRepaymentTransactions
 // @Description Creates a new loanRepaymentTransactions entity using the provided data in the request body.
@@ -277,6 +431,11 @@ func (h *loanRepaymentTransactionsHandler) Create(c *gin.Context) {
 		return
 	}
 
+	if form.ScheduleID == 0 || form.PayAmount <= 0 {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
 	// 4. 初始化交易记录结构体
 	loanRepaymentTransactions := &model.LoanRepaymentTransactions{}
 	// 先拷贝表单数据，避免手动赋值的字段被覆盖
@@ -290,83 +449,139 @@ func (h *loanRepaymentTransactionsHandler) Create(c *gin.Context) {
 		return
 	}
 	// 手动赋值（放在拷贝后，避免被覆盖）
+	now := time.Now()
+	loanRepaymentTransactions.ScheduleID = int64(form.ScheduleID)
+	loanRepaymentTransactions.PayRef = strings.TrimSpace(form.PayRef)
 	loanRepaymentTransactions.CollectOrderNo = generateOrderNo("PI")
 	loanRepaymentTransactions.CreatedBy = uid
 	loanRepaymentTransactions.PayMethod = "IMPORT"
+	loanRepaymentTransactions.PaidAt = &now
 
-	// 5. 开启数据库事务
-	db := database.GetDB()
-	tx := db.WithContext(ctx).Begin()
-	if tx.Error != nil {
-		logger.Error(
-			"Begin transaction failed",
-			logger.Err(tx.Error),
+	// 5. 入账：锁定期次、按渠道流水号去重、冲销分配并更新期次，人工录入不允许超过剩余应还及渠道代收限额
+	result, err := h.settler.Settle(ctx, loanRepaymentTransactions, false)
+	if err != nil {
+		switch {
+		case errors.Is(err, errPayAmountExceedsOutstanding):
+			logger.Warn(
+				"pay amount exceeds outstanding",
+				logger.Uint64("schedule_id", form.ScheduleID),
+				logger.Int("pay_amount", form.PayAmount),
+			)
+			response.Error(c, ecode.ErrRepaymentExceedsOutstanding)
+		case errors.Is(err, fee.ErrInvalidAmount), errors.Is(err, fee.ErrBelowMin), errors.Is(err, fee.ErrAboveMax):
+			logger.Warn(
+				"pay amount is outside collect limits",
+				logger.Err(err),
+				logger.Int64("channel_id", form.CollectChannelID),
+				logger.Int("pay_amount", form.PayAmount),
+			)
+			response.Error(c, ecode.ErrCollectAmountOutOfLimit.WithDetails(err.Error()))
+		case errors.Is(err, errCollectChannelNotFound):
+			response.Error(c, ecode.ErrGetByIDLoanPaymentChannels)
+		case errors.Is(err, database.ErrRecordNotFound):
+			logger.Warn("schedule not found", logger.Uint64("schedule_id", form.ScheduleID))
+			response.Error(c, ecode.ErrGetByIDLoanRepaymentSchedules)
+		default:
+			logger.Error(
+				"Settle repayment failed",
+				logger.Err(err),
+				logger.Any("form", form),
+			)
+			response.Error(c, ecode.ErrCreateLoanRepaymentTransactions)
+		}
+		return
+	}
+	if result.Duplicate {
+		logger.Info("duplicate pay ref, return original transaction",
+			logger.Int64("collect_channel_id", form.CollectChannelID),
+			logger.String("pay_ref", loanRepaymentTransactions.PayRef),
+			logger.Uint64("id", result.ID),
 		)
-		response.Error(c, ecode.InternalServerError)
+		response.Success(c, gin.H{"id": result.ID, "duplicate": true})
 		return
 	}
-	// 定义事务回滚的延迟处理（兜底）
-	defer func() {
-		if r := recover(); r != nil {
-			tx.Rollback()
-			logger.Error("panic in Create handler", logger.Any("recover", r))
-		}
-	}()
+	response.Success(c, gin.H{"id": result.ID, "scheduleStatus": result.ScheduleStatus})
+}
 
-	// 6. 创建还款交易记录
-	var newID uint64
-	newID, err = h.iDao.CreateByTx(ctx, tx, loanRepaymentTransactions)
-	if err != nil {
-		tx.Rollback()
-		logger.Error(
-			"CreateByTx failed",
-			logger.Err(err),
-			logger.Uint64("newID", newID),
-			logger.Any("form", form),
-		)
-		response.Error(c, ecode.ErrCreateLoanRepaymentTransactions)
+// Payoff 提前结清：回款金额需等于当日报价(GET /repayment-schedule/payoff-quote)，
+// 在一个事务中结清放款单全部剩余期次，每期写一条回款流水(共用同一回款单号)
+func (h *loanRepaymentTransactionsHandler) Payoff(c *gin.Context) {
+	ctx := middleware.WrapCtx(c)
+
+	// 1. 获取用户ID
+	uid, ok := getUIDFromClaims(c)
+	if !ok || uid == 0 {
+		response.Out(c, ecode.Unauthorized)
 		return
 	}
 
-	// 7. 查询还款计划记录
-	repaymentScheduleRecord, err := h.repaymentScheduleDao.GetByID(ctx, form.ScheduleID)
-	if err != nil {
-		tx.Rollback() // 关键：查询失败也要回滚事务
-		logger.Error(
-			"GetByID LoanRepaymentSchedules failed",
-			logger.Err(err),
-			logger.Uint64("schedule_id", form.ScheduleID),
-		)
-		response.Error(c, ecode.ErrGetByIDLoanRepaymentSchedules)
+	// 2. 绑定并校验请求参数
+	form := &types.PayoffLoanRepaymentTransactionsRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if form.PayAmount <= 0 {
+		response.Error(c, ecode.InvalidParams)
 		return
 	}
 
-	// 8. 更新还款计划的已付总额（修复核心逻辑错误）
-	repaymentScheduleRecord.PaidTotal += form.PayAmount // 直接修改结构体字段
-	err = h.repaymentScheduleDao.UpdateByTx(ctx, tx, repaymentScheduleRecord)
-	if err != nil {
-		tx.Rollback()
-		logger.Error(
-			"UpdateByTx LoanRepaymentSchedules failed",
-			logger.Err(err),
-			logger.Uint64("schedule_id", form.ScheduleID),
-			logger.Int("pay_amount", form.PayAmount),
-		)
-		response.Error(c, ecode.InternalServerError)
+	// 3. 验证MFA验证码
+	ok, err := tool.ValidateMFA(c, uid, strings.TrimSpace(form.MfaCode))
+	if err != nil || !ok {
+		logger.Warn("ValidateMFA failed", logger.Err(err), logger.Uint64("uid", uid), middleware.GCtxRequestIDField(c))
+		if !c.Writer.Written() { // 查询 MFA 设备出错时 ValidateMFA 不写响应
+			response.Error(c, ecode.InternalServerError)
+		}
 		return
 	}
 
-	// 9. 提交事务
-	if err = tx.Commit().Error; err != nil {
-		logger.Error(
-			"Commit transaction failed",
-			logger.Err(err),
-			logger.Uint64("transaction_id", newID),
-		)
-		response.Error(c, ecode.InternalServerError)
+	// 4. 回款信息，各期流水共用
+	now := time.Now()
+	record := &model.LoanRepaymentTransactions{
+		CollectChannelID: form.CollectChannelID,
+		CollectOrderNo:   generateOrderNo("PF"),
+		PayRef:           strings.TrimSpace(form.PayRef),
+		PayAmount:        form.PayAmount,
+		PayMethod:        "IMPORT",
+		PaidAt:           &now,
+		Status:           model.TransactionStatusSuccess,
+		VoucherFileName:  form.VoucherFileName,
+		Remark:           form.Remark,
+		CreatedBy:        uid,
+	}
+
+	// 5. 按当日报价校验金额并结清全部剩余期次
+	result, err := h.settler.SettlePayoff(ctx, form.DisbursementID, record)
+	if err != nil {
+		switch {
+		case errors.Is(err, errPayoffAmountMismatch):
+			logger.Warn("payoff amount mismatch", logger.Err(err), logger.Uint64("disbursement_id", form.DisbursementID), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrPayoffAmountMismatch.WithDetails(err.Error()))
+		case errors.Is(err, finance.ErrNothingToPayoff):
+			response.Error(c, ecode.ErrNothingToPayoff)
+		case errors.Is(err, fee.ErrInvalidAmount), errors.Is(err, fee.ErrBelowMin), errors.Is(err, fee.ErrAboveMax):
+			response.Error(c, ecode.ErrCollectAmountOutOfLimit.WithDetails(err.Error()))
+		case errors.Is(err, errCollectChannelNotFound):
+			response.Error(c, ecode.ErrGetByIDLoanPaymentChannels)
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.Error(c, ecode.ErrGetByIDLoanDisbursements)
+		default:
+			logger.Error("Settle payoff failed", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrCreateLoanRepaymentTransactions)
+		}
+		return
+	}
+	if result.Duplicate {
+		response.Success(c, gin.H{"ids": result.IDs, "duplicate": true})
 		return
 	}
-	response.Success(c, gin.H{"id": newID}) // 用newID更可靠，和CreateByTx返回值一致
+	response.Success(c, gin.H{
+		"ids":            result.IDs,
+		"collectOrderNo": record.CollectOrderNo,
+		"quote":          convertPayoffQuote(form.DisbursementID, result.Quote),
+	})
 }
 
 // DeleteByID delete a loanRepaymentTransactions by id
@@ -386,15 +601,9 @@ func (h *loanRepaymentTransactionsHandler) DeleteByID(c *gin.Context) {
 		return
 	}
 
-	ctx := middleware.WrapCtx(c)
-	err := h.iDao.DeleteByID(ctx, id)
-	if err != nil {
-		logger.Error("DeleteByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
-		response.Output(c, ecode.InternalServerError.ToHTTPCode())
-		return
-	}
-
-	response.Success(c)
+	// 回款流水为审计凭据，不允许删除，撤销请使用冲正接口
+	logger.Warn("delete repayment transaction is not allowed", logger.Any("id", id), middleware.GCtxRequestIDField(c))
+	response.Error(c, ecode.ErrTransactionImmutable)
 }
 
 // UpdateByID update a loanRepaymentTransactions by id
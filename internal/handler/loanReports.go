@@ -0,0 +1,233 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"loan/internal/cache"
+	"loan/internal/dao"
+	"loan/internal/database"
+	"loan/internal/ecode"
+	"loan/internal/model"
+	"loan/internal/report"
+	"loan/internal/types"
+)
+
+var _ LoanReportsHandler = (*loanReportsHandler)(nil)
+
+// LoanReportsHandler 风控报表
+type LoanReportsHandler interface {
+	Aging(c *gin.Context)
+	Vintage(c *gin.Context)
+	ReviewerThroughput(c *gin.Context)
+}
+
+type loanReportsHandler struct {
+	scheduleDao dao.LoanRepaymentSchedulesDao
+	snapshotDao dao.LoanVintageSnapshotsDao
+	auditDao    dao.LoanAuditsDao
+	userDao     dao.LoanUsersDao
+	settingsDao dao.LoanSettingsDao
+}
+
+// NewLoanReportsHandler creating the handler interface
+func NewLoanReportsHandler() LoanReportsHandler {
+	return &loanReportsHandler{
+		scheduleDao: dao.NewLoanRepaymentSchedulesDao(
+			database.GetDB(),
+			cache.NewLoanRepaymentSchedulesCache(database.GetCacheType()),
+		),
+		snapshotDao: dao.NewLoanVintageSnapshotsDao(database.GetDB()),
+		auditDao: dao.NewLoanAuditsDao(
+			database.GetDB(),
+			cache.NewLoanAuditsCache(database.GetCacheType()),
+		),
+		userDao: dao.NewLoanUsersDao(
+			database.GetDB(),
+			cache.NewLoanUsersCache(database.GetCacheType()),
+		),
+		settingsDao: dao.NewLoanSettingsDao(
+			database.GetDB(),
+			cache.NewLoanSettingsCache(database.GetCacheType()),
+		),
+	}
+}
+
+// Aging 账龄报表：按逾期天数分档统计在贷借款笔数与剩余本金
+// @Summary portfolio aging report
+// @Description Groups outstanding loans into DPD buckets (current, 1-7, 8-30, 31-60, 61-90, 90+) by the oldest unsettled installment, with count and outstanding principal per bucket. Filterable by disbursement date range, payout channel, referrer and collector; format=csv downloads the report as CSV.
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param data body types.AgingReportRequest true "filters"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/reports/aging [post]
+// @Security BearerAuth
+func (h *loanReportsHandler) Aging(c *gin.Context) {
+	form := &types.AgingReportRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	from, err := report.ParseDay(form.DisbursedFrom)
+	if err != nil {
+		response.Error(c, ecode.ErrReportDateRange.WithDetails(err.Error()))
+		return
+	}
+	to, err := report.ParseDay(form.DisbursedTo)
+	if err != nil {
+		response.Error(c, ecode.ErrReportDateRange.WithDetails(err.Error()))
+		return
+	}
+	if to != nil {
+		next := to.AddDate(0, 0, 1)
+		to = &next
+	}
+	asOf := time.Now()
+	if day, err := report.ParseDay(form.AsOf); err != nil {
+		response.Error(c, ecode.ErrReportDateRange.WithDetails(err.Error()))
+		return
+	} else if day != nil {
+		asOf = *day
+	}
+
+	ctx := middleware.WrapCtx(c)
+	rows, err := h.scheduleDao.GetAgingLoans(ctx, form, from, to)
+	if err != nil {
+		logger.Error("GetAgingLoans error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrReportQuery)
+		return
+	}
+	result := report.Aging(rows, asOf)
+
+	if form.Format != "csv" {
+		response.Success(c, result)
+		return
+	}
+	buf := &bytes.Buffer{}
+	if err = report.WriteAgingCSV(buf, result); err != nil {
+		logger.Error("WriteAgingCSV error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrReportExport)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=aging_%s.csv", asOf.Format("20060102")))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// Vintage 放款批次(vintage)报表：按放款月份分组，各账龄的累计逾期率与回收率
+// @Summary vintage cohort report
+// @Description Loans grouped by disbursement month, with the cumulative overdue rate at DPD 1/30/60/90 and the principal recovery rate for each month on book (MOB). Data comes from the nightly snapshot table, so the latest figures are as of the last job run.
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param data body types.VintageReportRequest true "cohort range"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/reports/vintage [post]
+// @Security BearerAuth
+func (h *loanReportsHandler) Vintage(c *gin.Context) {
+	form := &types.VintageReportRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	for _, cohort := range []string{form.CohortFrom, form.CohortTo} {
+		if cohort == "" {
+			continue
+		}
+		if _, err := report.ParseCohort(cohort); err != nil {
+			response.Error(c, ecode.ErrReportCohort.WithDetails(err.Error()))
+			return
+		}
+	}
+
+	snaps, err := h.snapshotDao.GetByCohortRange(middleware.WrapCtx(c), form.CohortFrom, form.CohortTo)
+	if err != nil {
+		logger.Error("GetByCohortRange error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrReportQuery)
+		return
+	}
+	response.Success(c, report.BuildVintageReport(snaps))
+}
+
+// ReviewerThroughput 审批人员产能报表：按审批人员统计审批量、处理时长与时效达标率
+// @Summary reviewer throughput report
+// @Description Per-reviewer decisions (approved/rejected) within the date range from loan_audits, with average claim-to-decision time, average and maximum time from the stage becoming pending to the decision, and SLA breaches against the review_sla_minutes setting (default 240). Automatic screening decisions are reported under the system auditor (auditorUserID 0).
+// @Tags reports
+// @Accept json
+// @Produce json
+// @Param data body types.ReviewerThroughputRequest true "date range"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/reports/reviewer-throughput [post]
+// @Security BearerAuth
+func (h *loanReportsHandler) ReviewerThroughput(c *gin.Context) {
+	form := &types.ReviewerThroughputRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	now := time.Now()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if day, err := report.ParseDay(form.From); err != nil {
+		response.Error(c, ecode.ErrReportDateRange.WithDetails(err.Error()))
+		return
+	} else if day != nil {
+		from = *day
+	}
+	to := from
+	if day, err := report.ParseDay(form.To); err != nil {
+		response.Error(c, ecode.ErrReportDateRange.WithDetails(err.Error()))
+		return
+	} else if day != nil {
+		to = *day
+	}
+	if to.Before(from) {
+		response.Error(c, ecode.ErrReportDateRange.WithDetails("to is before from"))
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	audits, err := h.auditDao.GetDecidedBetween(ctx, from, to.AddDate(0, 0, 1), form.AuditorUserID)
+	if err != nil {
+		logger.Error("GetDecidedBetween error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrReportQuery)
+		return
+	}
+	slaMinutes := h.settingsDao.GetInt64ByName(ctx, model.SettingReviewSlaMinutes, 240)
+	reviewers, total := report.Throughput(audits, slaMinutes)
+
+	ids := make([]uint64, 0, len(reviewers))
+	for _, r := range reviewers {
+		ids = append(ids, r.AuditorUserID)
+	}
+	users, err := h.userDao.GetByIDs(ctx, ids)
+	if err != nil {
+		logger.Warn("GetByIDs error", logger.Err(err), middleware.GCtxRequestIDField(c))
+	}
+	for _, r := range reviewers {
+		if r.AuditorUserID == model.SystemAuditorUserID {
+			r.AuditorName = model.SystemAuditorName
+		} else if u, ok := users[r.AuditorUserID]; ok {
+			r.AuditorName = u.Username
+		}
+	}
+
+	response.Success(c, &report.ThroughputReport{
+		From:       from.Format(time.DateOnly),
+		To:         to.Format(time.DateOnly),
+		SLAMinutes: slaMinutes,
+		Reviewers:  reviewers,
+		Total:      total,
+	})
+}
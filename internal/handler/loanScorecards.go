@@ -0,0 +1,243 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"loan/internal/database"
+	"loan/internal/ecode"
+	"loan/internal/model"
+	"loan/internal/scoring"
+	"loan/internal/types"
+)
+
+var _ LoanScorecardsHandler = (*loanScorecardsHandler)(nil)
+
+// LoanScorecardsHandler 信用评分卡版本管理与申请单评分
+type LoanScorecardsHandler interface {
+	Create(c *gin.Context)
+	GetByID(c *gin.Context)
+	List(c *gin.Context)
+	UpdateStatus(c *gin.Context)
+	Score(c *gin.Context)
+}
+
+type loanScorecardsHandler struct {
+	*scorer
+}
+
+// NewLoanScorecardsHandler creating the handler interface
+func NewLoanScorecardsHandler() LoanScorecardsHandler {
+	return &loanScorecardsHandler{scorer: newScorer()}
+}
+
+// Create 保存评分卡的新版本
+// @Summary Create a scorecard version
+// @Description Saves a new version of the named scorecard (version = latest + 1). Definitions are immutable; the new version is disabled until it is activated.
+// @Tags scorecards
+// @Accept json
+// @Produce json
+// @Param data body types.CreateLoanScorecardsRequest true "scorecard"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/scorecards [post]
+// @Security BearerAuth
+func (h *loanScorecardsHandler) Create(c *gin.Context) {
+	form := &types.CreateLoanScorecardsRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	// 定义以压缩后的 JSON 保存
+	buf := &bytes.Buffer{}
+	if err := json.Compact(buf, form.Definition); err != nil {
+		response.Error(c, ecode.ErrScorecardInvalid.WithDetails(err.Error()))
+		return
+	}
+	if _, err := scoring.Parse(buf.String()); err != nil {
+		response.Error(c, ecode.ErrScorecardInvalid.WithDetails(err.Error()))
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	version, err := h.scorecardDao.GetLatestVersion(ctx, form.Name)
+	if err != nil {
+		logger.Error("GetLatestVersion error", logger.Err(err), logger.String("name", form.Name), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrCreateLoanScorecards)
+		return
+	}
+	record := &model.LoanScorecards{
+		Name:       form.Name,
+		Version:    version + 1,
+		Definition: buf.String(),
+		Status:     0,
+		Remark:     form.Remark,
+	}
+	if err = h.scorecardDao.Create(ctx, record); err != nil {
+		logger.Error("Create error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrCreateLoanScorecards)
+		return
+	}
+	response.Success(c, gin.H{"id": record.ID, "version": record.Version})
+}
+
+// GetByID 查询评分卡版本
+// @Summary Get a scorecard version
+// @Description Returns one scorecard version with its definition.
+// @Tags scorecards
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/scorecards/{id} [get]
+// @Security BearerAuth
+func (h *loanScorecardsHandler) GetByID(c *gin.Context) {
+	id, ok := getScreeningIDFromPath(c)
+	if !ok {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	record, err := h.scorecardDao.GetByID(middleware.WrapCtx(c), id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrGetByIDLoanScorecards)
+		}
+		return
+	}
+	response.Success(c, gin.H{"scorecard": convertScorecard(record)})
+}
+
+// List 查询全部评分卡版本及可用特征
+// @Summary List scorecard versions
+// @Description Returns every scorecard version (newest version first within a name), the active version id (0 if none) and the feature catalog a definition may use.
+// @Tags scorecards
+// @Produce json
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/scorecards [get]
+// @Security BearerAuth
+func (h *loanScorecardsHandler) List(c *gin.Context) {
+	records, err := h.scorecardDao.GetAll(middleware.WrapCtx(c))
+	if err != nil {
+		logger.Error("GetAll error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrListLoanScorecards)
+		return
+	}
+	var activeID uint64
+	list := make([]*types.LoanScorecardObjDetail, 0, len(records))
+	for _, r := range records {
+		if r.Status == 1 {
+			activeID = r.ID
+		}
+		list = append(list, convertScorecard(r))
+	}
+	response.Success(c, gin.H{
+		"scorecards": list,
+		"activeID":   activeID,
+		"features":   scoring.Features,
+	})
+}
+
+// UpdateStatus 启用或停用评分卡版本
+// @Summary Activate or deactivate a scorecard version
+// @Description Activating a version deactivates every other version, so new applications are always scored by exactly one version. With no active version applications are not scored.
+// @Tags scorecards
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.UpdateLoanScorecardStatusRequest true "status"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/scorecards/{id}/status [put]
+// @Security BearerAuth
+func (h *loanScorecardsHandler) UpdateStatus(c *gin.Context) {
+	id, ok := getScreeningIDFromPath(c)
+	if !ok {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	form := &types.UpdateLoanScorecardStatusRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	record, err := h.scorecardDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrGetByIDLoanScorecards)
+		}
+		return
+	}
+	// 启用前再次校验定义，避免启用后评分全部失败
+	if form.Status == 1 {
+		if _, err = scoring.Parse(record.Definition); err != nil {
+			response.Error(c, ecode.ErrScorecardInvalid.WithDetails(fmt.Sprintf("scorecard %d: %v", id, err)))
+			return
+		}
+	}
+	if err = h.scorecardDao.UpdateStatus(ctx, id, form.Status); err != nil {
+		logger.Error("UpdateStatus error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrUpdateStatusLoanScorecards)
+		return
+	}
+	response.Success(c)
+}
+
+// Score 用启用的评分卡重新为申请单评分(如设备数据补传之后)
+// @Summary Score an application
+// @Description Computes the credit score of the application with the active scorecard and saves the score and its per-feature breakdown on the application.
+// @Tags scorecards
+// @Produce json
+// @Param id path string true "application id"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/scorecards/score/{id} [post]
+// @Security BearerAuth
+func (h *loanScorecardsHandler) Score(c *gin.Context) {
+	id, ok := getScreeningIDFromPath(c)
+	if !ok {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	result, err := h.score(middleware.WrapCtx(c), id)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.Error(c, ecode.NotFound)
+		case errors.Is(err, errNoActiveScorecard):
+			response.Error(c, ecode.ErrNoActiveScorecard)
+		default:
+			logger.Error("credit scoring error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrCreditScoring)
+		}
+		return
+	}
+	response.Success(c, gin.H{"scoreBreakdown": result})
+}
+
+func convertScorecard(r *model.LoanScorecards) *types.LoanScorecardObjDetail {
+	d := &types.LoanScorecardObjDetail{
+		ID:        r.ID,
+		Name:      r.Name,
+		Version:   r.Version,
+		Status:    r.Status,
+		Remark:    r.Remark,
+		CreatedAt: r.CreatedAt,
+	}
+	if json.Valid([]byte(r.Definition)) {
+		d.Definition = json.RawMessage(r.Definition)
+	}
+	return d
+}
@@ -0,0 +1,407 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"loan/internal/database"
+	"loan/internal/ecode"
+	"loan/internal/model"
+	"loan/internal/report"
+	"loan/internal/screening"
+	"loan/internal/types"
+)
+
+// 规则试运行的默认范围与返回明细上限
+const (
+	dryRunDefaultDays  = 30
+	dryRunDefaultLimit = 1000
+	dryRunMaxDetails   = 200
+)
+
+var _ LoanScreeningRulesHandler = (*loanScreeningRulesHandler)(nil)
+
+// LoanScreeningRulesHandler 自动预审(决策引擎)规则管理、试运行与决策日志
+type LoanScreeningRulesHandler interface {
+	Create(c *gin.Context)
+	DeleteByID(c *gin.Context)
+	UpdateByID(c *gin.Context)
+	List(c *gin.Context)
+	DryRun(c *gin.Context)
+	Rerun(c *gin.Context)
+	Decisions(c *gin.Context)
+}
+
+type loanScreeningRulesHandler struct {
+	*screener
+}
+
+// NewLoanScreeningRulesHandler creating the handler interface
+func NewLoanScreeningRulesHandler() LoanScreeningRulesHandler {
+	return &loanScreeningRulesHandler{screener: newScreener()}
+}
+
+// Create 新增预审规则
+// @Summary Create a screening rule
+// @Description Adds a pre-screening rule. The condition is a JSON expression over application fields (see List for the field catalog); the action is REJECT, PASS, MANUAL or SCORE.
+// @Tags screening
+// @Accept json
+// @Produce json
+// @Param data body types.CreateLoanScreeningRulesRequest true "rule"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/screening/rules [post]
+// @Security BearerAuth
+func (h *loanScreeningRulesHandler) Create(c *gin.Context) {
+	form := &types.CreateLoanScreeningRulesRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	rule, err := screeningRuleFromForm(form)
+	if err != nil {
+		response.Error(c, ecode.ErrScreeningRuleInvalid.WithDetails(err.Error()))
+		return
+	}
+	if err = h.ruleDao.Create(middleware.WrapCtx(c), rule); err != nil {
+		logger.Error("Create error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrCreateLoanScreeningRules)
+		return
+	}
+	response.Success(c, gin.H{"id": rule.ID})
+}
+
+// DeleteByID 删除预审规则
+// @Summary Delete a screening rule
+// @Description Removes a screening rule; it no longer applies to new applications. Existing decision logs keep the rule hit.
+// @Tags screening
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/screening/rules/{id} [delete]
+// @Security BearerAuth
+func (h *loanScreeningRulesHandler) DeleteByID(c *gin.Context) {
+	id, ok := getScreeningIDFromPath(c)
+	if !ok {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if err := h.ruleDao.DeleteByID(middleware.WrapCtx(c), id); err != nil {
+		logger.Error("DeleteByID error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrDeleteByIDLoanScreeningRules)
+		return
+	}
+	response.Success(c)
+}
+
+// UpdateByID 修改预审规则
+// @Summary Update a screening rule
+// @Description Replaces all editable fields of a screening rule. The condition is validated before saving.
+// @Tags screening
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.UpdateLoanScreeningRulesByIDRequest true "rule"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/screening/rules/{id} [put]
+// @Security BearerAuth
+func (h *loanScreeningRulesHandler) UpdateByID(c *gin.Context) {
+	id, ok := getScreeningIDFromPath(c)
+	if !ok {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	form := &types.UpdateLoanScreeningRulesByIDRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	if _, err := h.ruleDao.GetByID(ctx, id); err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrGetByIDLoanScreeningRules)
+		}
+		return
+	}
+	rule, err := screeningRuleFromForm(&types.CreateLoanScreeningRulesRequest{
+		Name:      form.Name,
+		Priority:  form.Priority,
+		Condition: form.Condition,
+		Action:    form.Action,
+		Score:     form.Score,
+		Status:    form.Status,
+		Remark:    form.Remark,
+	})
+	if err != nil {
+		response.Error(c, ecode.ErrScreeningRuleInvalid.WithDetails(err.Error()))
+		return
+	}
+	rule.ID = id
+	if err = h.ruleDao.UpdateByID(ctx, rule); err != nil {
+		logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrUpdateByIDLoanScreeningRules)
+		return
+	}
+	response.Success(c)
+}
+
+// List 查询全部预审规则及可引用的字段
+// @Summary List screening rules
+// @Description Returns all screening rules (enabled and disabled) in execution order, the fields a condition may reference, and the current screening settings.
+// @Tags screening
+// @Produce json
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/screening/rules [get]
+// @Security BearerAuth
+func (h *loanScreeningRulesHandler) List(c *gin.Context) {
+	ctx := middleware.WrapCtx(c)
+	rules, err := h.ruleDao.GetAll(ctx)
+	if err != nil {
+		logger.Error("GetAll error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrListLoanScreeningRules)
+		return
+	}
+	response.Success(c, gin.H{
+		"rules":     rules,
+		"fields":    screening.Fields,
+		"enabled":   h.enabled(ctx),
+		"passScore": h.passScore(ctx),
+	})
+}
+
+// DryRun 用历史申请试运行规则，不修改任何数据
+// @Summary Dry-run screening rules against historical applications
+// @Description Evaluates the saved rules, or a candidate rule set passed in the body, against applications submitted in the date range. Returns outcome counts, hits per rule, and a comparison with the manual pre-review result (agreement rate and conflicts). Nothing is written.
+// @Tags screening
+// @Accept json
+// @Produce json
+// @Param data body types.ScreeningDryRunRequest true "candidate rules and range"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/screening/dry-run [post]
+// @Security BearerAuth
+func (h *loanScreeningRulesHandler) DryRun(c *gin.Context) {
+	form := &types.ScreeningDryRunRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	ctx := middleware.WrapCtx(c)
+
+	// 1) 规则：请求中的候选规则，或当前已保存的规则
+	var (
+		rules []*screening.Rule
+		err   error
+	)
+	if len(form.Rules) > 0 {
+		candidates := make([]*model.LoanScreeningRules, 0, len(form.Rules))
+		for i, r := range form.Rules {
+			rule, err := screeningRuleFromForm(r)
+			if err != nil {
+				response.Error(c, ecode.ErrScreeningRuleInvalid.WithDetails(fmt.Sprintf("rule %d: %v", i+1, err)))
+				return
+			}
+			rule.ID = uint64(i + 1)
+			candidates = append(candidates, rule)
+		}
+		rules, err = screening.Compile(candidates)
+	} else {
+		rules, err = h.rules(ctx)
+	}
+	if err != nil {
+		logger.Error("load screening rules error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrScreeningDryRun.WithDetails(err.Error()))
+		return
+	}
+	passScore := h.passScore(ctx)
+	if form.PassScore != nil {
+		passScore = *form.PassScore
+	}
+
+	// 2) 统计范围
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if day, err := report.ParseDay(form.To); err != nil {
+		response.Error(c, ecode.ErrReportDateRange.WithDetails(err.Error()))
+		return
+	} else if day != nil {
+		to = *day
+	}
+	from := to.AddDate(0, 0, -dryRunDefaultDays)
+	if day, err := report.ParseDay(form.From); err != nil {
+		response.Error(c, ecode.ErrReportDateRange.WithDetails(err.Error()))
+		return
+	} else if day != nil {
+		from = *day
+	}
+	limit := form.Limit
+	if limit == 0 {
+		limit = dryRunDefaultLimit
+	}
+
+	// 3) 历史申请与名单、设备数据
+	apps, err := h.baseinfoDao.GetCreatedBetween(ctx, from, to.AddDate(0, 0, 1), limit)
+	if err != nil {
+		logger.Error("GetCreatedBetween error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrScreeningDryRun)
+		return
+	}
+	extra, err := h.factsDao.GetExtraFacts(ctx, apps)
+	if err != nil {
+		logger.Error("GetExtraFacts error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrScreeningDryRun)
+		return
+	}
+
+	// 4) 逐笔评估并汇总
+	decisions := make([]screening.Decision, 0, len(apps))
+	actuals := make([]string, 0, len(apps))
+	details := make([]gin.H, 0)
+	for _, b := range apps {
+		d := screening.Evaluate(rules, screening.FactsOf(b, extra[b.ID]), passScore)
+		actual := screening.Actual(b.AuditStatus)
+		decisions = append(decisions, d)
+		actuals = append(actuals, actual)
+		if form.Details && len(details) < dryRunMaxDetails {
+			details = append(details, gin.H{
+				"baseinfoID": b.ID,
+				"createdAt":  b.CreatedAt,
+				"outcome":    d.Outcome,
+				"score":      d.Score,
+				"hits":       d.Hits,
+				"actual":     actual,
+			})
+		}
+	}
+
+	response.Success(c, gin.H{
+		"from":      from.Format(time.DateOnly),
+		"to":        to.Format(time.DateOnly),
+		"passScore": passScore,
+		"summary":   screening.Summarize(rules, decisions, actuals),
+		"details":   details,
+	})
+}
+
+// Rerun 对待初审的申请单重新执行预审(如设备数据补传之后)
+// @Summary Re-run screening for a pending application
+// @Description Runs the current rules again for an application still waiting for pre-review, applies the outcome and writes a decision log entry.
+// @Tags screening
+// @Produce json
+// @Param id path string true "application id"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/screening/run/{id} [post]
+// @Security BearerAuth
+func (h *loanScreeningRulesHandler) Rerun(c *gin.Context) {
+	id, ok := getScreeningIDFromPath(c)
+	if !ok {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	entry, err := h.run(middleware.WrapCtx(c), id, model.ScreeningTriggerRerun)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.Error(c, ecode.NotFound)
+		case errors.Is(err, errScreeningNotPending):
+			response.Error(c, ecode.ErrScreeningNotPending)
+		default:
+			logger.Error("screening run error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrScreeningRun)
+		}
+		return
+	}
+	response.Success(c, convertScreeningDecision(entry))
+}
+
+// Decisions 申请单的预审决策日志
+// @Summary Screening decision log of an application
+// @Description Lists every screening run for the application, newest first, with the rule hits and the data snapshot used.
+// @Tags screening
+// @Produce json
+// @Param id path string true "application id"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/screening/decisions/{id} [get]
+// @Security BearerAuth
+func (h *loanScreeningRulesHandler) Decisions(c *gin.Context) {
+	id, ok := getScreeningIDFromPath(c)
+	if !ok {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	records, err := h.decisionDao.ListByBaseinfoID(middleware.WrapCtx(c), id)
+	if err != nil {
+		logger.Error("ListByBaseinfoID error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrListLoanScreeningDecisions)
+		return
+	}
+	list := make([]*types.LoanScreeningDecisionDetail, 0, len(records))
+	for _, r := range records {
+		list = append(list, convertScreeningDecision(r))
+	}
+	response.Success(c, gin.H{"decisions": list})
+}
+
+// screeningRuleFromForm 请求转换为规则并校验条件表达式，条件以压缩后的 JSON 保存
+func screeningRuleFromForm(form *types.CreateLoanScreeningRulesRequest) (*model.LoanScreeningRules, error) {
+	buf := &bytes.Buffer{}
+	if err := json.Compact(buf, form.Condition); err != nil {
+		return nil, fmt.Errorf("%w: %v", screening.ErrInvalidRule, err)
+	}
+	rule := &model.LoanScreeningRules{
+		Name:      form.Name,
+		Priority:  form.Priority,
+		Condition: buf.String(),
+		Action:    form.Action,
+		Score:     form.Score,
+		Status:    form.Status,
+		Remark:    form.Remark,
+	}
+	if err := screening.ValidateRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func convertScreeningDecision(r *model.LoanScreeningDecisions) *types.LoanScreeningDecisionDetail {
+	d := &types.LoanScreeningDecisionDetail{
+		ID:         r.ID,
+		BaseinfoID: r.BaseinfoID,
+		Trigger:    r.Trigger,
+		Outcome:    r.Outcome,
+		Score:      r.Score,
+		Note:       r.Note,
+		CreatedAt:  r.CreatedAt,
+	}
+	if json.Valid([]byte(r.Hits)) {
+		d.Hits = json.RawMessage(r.Hits)
+	}
+	if json.Valid([]byte(r.Facts)) {
+		d.Facts = json.RawMessage(r.Facts)
+	}
+	return d
+}
+
+func getScreeningIDFromPath(c *gin.Context) (uint64, bool) {
+	idStr := c.Param("id")
+	id, err := utils.StrToUint64E(idStr)
+	if err != nil || id == 0 {
+		logger.Warn("StrToUint64E error: ", logger.String("idStr", idStr), middleware.GCtxRequestIDField(c))
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,361 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"loan/internal/cache"
+	"loan/internal/dao"
+	"loan/internal/database"
+	"loan/internal/ecode"
+	"loan/internal/finance"
+	"loan/internal/ledger"
+	"loan/internal/model"
+	"loan/internal/tool"
+	"loan/internal/types"
+)
+
+// waiverActionType 减免申请/审批写入催收跟进记录的动作类型
+const waiverActionType = "WAIVER"
+
+var _ LoanWaiverRequestsHandler = (*loanWaiverRequestsHandler)(nil)
+
+// LoanWaiverRequestsHandler 罚息/费用减免申请与审批
+type LoanWaiverRequestsHandler interface {
+	Create(c *gin.Context)
+	GetByID(c *gin.Context)
+	List(c *gin.Context)
+	Approve(c *gin.Context)
+	Reject(c *gin.Context)
+}
+
+type loanWaiverRequestsHandler struct {
+	iDao        dao.LoanWaiverRequestsDao
+	scheduleDao dao.LoanRepaymentSchedulesDao
+	caseDao     dao.LoanCollectionCasesDao
+	logDao      dao.LoanCollectionLogsDao
+	book        *ledger.Book
+}
+
+// NewLoanWaiverRequestsHandler creating the handler interface
+func NewLoanWaiverRequestsHandler() LoanWaiverRequestsHandler {
+	return &loanWaiverRequestsHandler{
+		iDao: dao.NewLoanWaiverRequestsDao(database.GetDB()),
+		scheduleDao: dao.NewLoanRepaymentSchedulesDao(
+			database.GetDB(),
+			cache.NewLoanRepaymentSchedulesCache(database.GetCacheType()),
+		),
+		caseDao: dao.NewLoanCollectionCasesDao(
+			database.GetDB(),
+			cache.NewLoanCollectionCasesCache(database.GetCacheType()),
+		),
+		logDao: dao.NewLoanCollectionLogsDao(
+			database.GetDB(),
+			cache.NewLoanCollectionLogsCache(database.GetCacheType()),
+		),
+		book: ledger.NewBook(database.GetDB()),
+	}
+}
+
+// Create 催收人员发起减免申请，申请时按期次当前剩余未还校验金额，审批通过时再次校验
+func (h *loanWaiverRequestsHandler) Create(c *gin.Context) {
+	uid, ok := getUIDFromClaims(c)
+	if !ok || uid == 0 {
+		response.Out(c, ecode.Unauthorized)
+		return
+	}
+
+	form := &types.CreateLoanWaiverRequestsRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	schedule, err := h.scheduleDao.GetByID(ctx, form.ScheduleID)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Uint64("schedule_id", form.ScheduleID), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	if err = finance.CheckWaiver(schedule, finance.Component(form.Component), form.Amount); err != nil {
+		respondWaiverError(c, err)
+		return
+	}
+	if form.CaseID > 0 {
+		collectionCase, err := h.caseDao.GetByID(ctx, form.CaseID)
+		if err != nil || collectionCase.ScheduleID != form.ScheduleID {
+			response.Error(c, ecode.ErrWaiverCaseMismatch)
+			return
+		}
+	}
+
+	record := &model.LoanWaiverRequests{
+		ScheduleID:  form.ScheduleID,
+		CaseID:      form.CaseID,
+		Component:   form.Component,
+		Amount:      form.Amount,
+		Reason:      strings.TrimSpace(form.Reason),
+		RequestedBy: uid,
+		Status:      model.WaiverStatusPending,
+	}
+	if err = h.iDao.Create(ctx, record); err != nil {
+		logger.Error("Create error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrCreateLoanWaiverRequests)
+		return
+	}
+
+	// 催收跟进记录仅用于留痕，写入失败不影响申请
+	if record.CaseID > 0 {
+		content := fmt.Sprintf("申请减免%s %d 分：%s", waiverComponentLabel(record.Component), record.Amount, record.Reason)
+		if err = h.logDao.Create(ctx, waiverCollectionLog(record.CaseID, uid, content)); err != nil {
+			logger.Warn("create waiver collection log error", logger.Err(err), logger.Uint64("waiver_id", record.ID), middleware.GCtxRequestIDField(c))
+		}
+	}
+
+	response.Success(c, gin.H{"id": record.ID})
+}
+
+// GetByID get a waiver request by id
+func (h *loanWaiverRequestsHandler) GetByID(c *gin.Context) {
+	_, id, isAbort := getLoanWaiverRequestsIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	record, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	response.Success(c, gin.H{"loanWaiverRequests": record})
+}
+
+// List get a paginated list of waiver requests by custom conditions
+func (h *loanWaiverRequestsHandler) List(c *gin.Context) {
+	form := &types.ListLoanWaiverRequestssRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	records, total, err := h.iDao.GetByColumns(ctx, &form.Params)
+	if err != nil {
+		logger.Error("GetByColumns error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrListLoanWaiverRequests)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"records": records,
+		"total":   total,
+	})
+}
+
+// Approve 主管审批通过(需 MFA，申请人不能审批自己的申请)：锁定申请与期次，按当前剩余未还再次校验后扣减应还罚息/费用并重算期次状态，
+// 审批结果写回申请单，关联催收任务时追加一条跟进记录
+func (h *loanWaiverRequestsHandler) Approve(c *gin.Context) {
+	h.review(c, true)
+}
+
+// Reject 主管驳回(需 MFA)，不调整期次
+func (h *loanWaiverRequestsHandler) Reject(c *gin.Context) {
+	h.review(c, false)
+}
+
+func (h *loanWaiverRequestsHandler) review(c *gin.Context, approve bool) {
+	uid, ok := getUIDFromClaims(c)
+	if !ok || uid == 0 {
+		response.Out(c, ecode.Unauthorized)
+		return
+	}
+
+	_, id, isAbort := getLoanWaiverRequestsIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	form := &types.ReviewLoanWaiverRequestsRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	// 1) MFA 校验（不进事务）
+	ok, err := tool.ValidateMFA(c, uid, strings.TrimSpace(form.MfaCode))
+	if err != nil || !ok {
+		logger.Warn("ValidateMFA failed", logger.Err(err), logger.Uint64("uid", uid), middleware.GCtxRequestIDField(c))
+		if !c.Writer.Written() { // 查询 MFA 设备出错时 ValidateMFA 不写响应
+			response.Error(c, ecode.InternalServerError)
+		}
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	tx := database.GetDB().WithContext(ctx).Begin()
+	if tx.Error != nil {
+		logger.Error("tx begin error", logger.Err(tx.Error), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+	// 兜底：函数任何提前 return 都会回滚（Commit 后 Rollback 不会生效）
+	defer func() {
+		_ = tx.Rollback().Error
+	}()
+
+	// 2) 锁定申请单，只有待审批的申请可以审批
+	record, err := h.iDao.GetByIDForUpdate(ctx, tx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByIDForUpdate error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.InternalServerError)
+		}
+		return
+	}
+	if record.Status != model.WaiverStatusPending {
+		response.Error(c, ecode.ErrWaiverNotPending)
+		return
+	}
+	// 减免需主管审批，申请人不能审批自己的申请
+	if record.RequestedBy == uid {
+		response.Error(c, ecode.ErrWaiverSelfReview)
+		return
+	}
+
+	now := time.Now()
+	record.ReviewedBy = uid
+	record.ReviewedAt = &now
+	record.ReviewNote = strings.TrimSpace(form.Note)
+	record.Status = model.WaiverStatusRejected
+
+	// 3) 审批通过：锁定期次并扣减应还，与回款入账、罚息计提互斥
+	if approve {
+		schedule, err := h.scheduleDao.GetByIDForUpdate(ctx, tx, record.ScheduleID)
+		if err != nil {
+			logger.Error("GetByIDForUpdate schedule error", logger.Err(err), logger.Uint64("schedule_id", record.ScheduleID), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrReviewLoanWaiverRequests)
+			return
+		}
+		waiver, err := finance.ApplyWaiver(schedule, finance.Component(record.Component), record.Amount, now)
+		if err != nil {
+			respondWaiverError(c, err)
+			return
+		}
+		if err = h.scheduleDao.UpdateWaiverByTx(ctx, tx, schedule); err != nil {
+			logger.Error("UpdateWaiverByTx error", logger.Err(err), logger.Uint64("schedule_id", schedule.ID), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrReviewLoanWaiverRequests)
+			return
+		}
+		record.Status = model.WaiverStatusApproved
+		record.DueBefore = waiver.DueBefore
+		record.DueAfter = waiver.DueAfter
+		record.StatusBefore = waiver.StatusBefore
+		record.StatusAfter = schedule.Status
+	}
+
+	// 4) 写回审批结果并过账，关联催收任务时同事务追加跟进记录
+	if err = h.iDao.UpdateReviewByTx(ctx, tx, record); err != nil {
+		logger.Error("UpdateReviewByTx error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrReviewLoanWaiverRequests)
+		return
+	}
+	if approve {
+		if err = h.book.Post(ctx, tx, ledger.WaiverRequest(record, now)); err != nil {
+			logger.Error("ledger post waiver error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrReviewLoanWaiverRequests)
+			return
+		}
+	}
+	if record.CaseID > 0 {
+		result := "已驳回"
+		if approve {
+			result = "已通过"
+		}
+		content := fmt.Sprintf("减免%s %d 分%s", waiverComponentLabel(record.Component), record.Amount, result)
+		if record.ReviewNote != "" {
+			content += "：" + record.ReviewNote
+		}
+		if _, err = h.logDao.CreateByTx(ctx, tx, waiverCollectionLog(record.CaseID, uid, content)); err != nil {
+			logger.Error("create waiver collection log error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrReviewLoanWaiverRequests)
+			return
+		}
+	}
+
+	// 5) 提交
+	if err = tx.Commit().Error; err != nil {
+		logger.Error("tx commit failed", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InternalServerError)
+		return
+	}
+
+	response.Success(c, gin.H{"loanWaiverRequests": record})
+}
+
+func respondWaiverError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, finance.ErrWaiverComponent):
+		response.Error(c, ecode.ErrWaiverComponent)
+	case errors.Is(err, finance.ErrWaiverExceedsOutstanding):
+		response.Error(c, ecode.ErrWaiverExceedsOutstanding.WithDetails(err.Error()))
+	case errors.Is(err, finance.ErrScheduleSettled):
+		response.Error(c, ecode.ErrScheduleAlreadySettled)
+	default:
+		logger.Error("waiver failed", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+	}
+}
+
+func waiverComponentLabel(component string) string {
+	if finance.Component(component) == finance.ComponentPenalty {
+		return "罚息"
+	}
+	return "费用"
+}
+
+func waiverCollectionLog(caseID uint64, uid uint64, content string) *model.LoanCollectionLogs {
+	return &model.LoanCollectionLogs{
+		CaseID:          caseID,
+		CollectorUserID: uid,
+		ActionType:      waiverActionType,
+		Content:         content,
+	}
+}
+
+func getLoanWaiverRequestsIDFromPath(c *gin.Context) (string, uint64, bool) {
+	idStr := c.Param("id")
+	id, err := utils.StrToUint64E(idStr)
+	if err != nil || id == 0 {
+		logger.Warn("StrToUint64E error: ", logger.String("idStr", idStr), middleware.GCtxRequestIDField(c))
+		return "", 0, true
+	}
+
+	return idStr, id, false
+}
@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"loan/internal/dao"
+	"loan/internal/database"
+	"loan/internal/ecode"
+	"loan/internal/model"
+	"loan/internal/types"
+	"loan/internal/workflow"
+)
+
+var _ LoanWorkflowStagesHandler = (*loanWorkflowStagesHandler)(nil)
+
+// LoanWorkflowStagesHandler 审批流程定义管理
+type LoanWorkflowStagesHandler interface {
+	Create(c *gin.Context)
+	DeleteByID(c *gin.Context)
+	UpdateByID(c *gin.Context)
+	List(c *gin.Context)
+}
+
+type loanWorkflowStagesHandler struct {
+	iDao dao.LoanWorkflowStagesDao
+}
+
+// NewLoanWorkflowStagesHandler creating the handler interface
+func NewLoanWorkflowStagesHandler() LoanWorkflowStagesHandler {
+	return &loanWorkflowStagesHandler{
+		iDao: dao.NewLoanWorkflowStagesDao(database.GetDB()),
+	}
+}
+
+// Create 新增审批节点
+// @Summary Create a workflow stage
+// @Description Adds a stage to an approval workflow. The whole definition is validated after the change: stage numbers and codes must be unique and at least one enabled stage must apply to all amounts.
+// @Tags workflow
+// @Accept json
+// @Produce json
+// @Param data body types.CreateLoanWorkflowStagesRequest true "stage"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/workflow-stages [post]
+// @Security BearerAuth
+func (h *loanWorkflowStagesHandler) Create(c *gin.Context) {
+	form := &types.CreateLoanWorkflowStagesRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	stage := &model.LoanWorkflowStages{
+		Workflow:   workflowOrDefault(form.Workflow),
+		StageNo:    form.StageNo,
+		Code:       strings.TrimSpace(form.Code),
+		Name:       form.Name,
+		Permission: strings.TrimSpace(form.Permission),
+		MinAmount:  form.MinAmount,
+		Status:     form.Status,
+	}
+
+	ctx := middleware.WrapCtx(c)
+	stages, err := h.iDao.GetByWorkflow(ctx, stage.Workflow)
+	if err != nil {
+		logger.Error("GetByWorkflow error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrListLoanWorkflowStages)
+		return
+	}
+	if err = workflow.Validate(append(stages, stage)); err != nil {
+		response.Error(c, ecode.ErrWorkflowDefinition.WithDetails(err.Error()))
+		return
+	}
+	if err = h.iDao.Create(ctx, stage); err != nil {
+		logger.Error("Create error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrCreateLoanWorkflowStages)
+		return
+	}
+	response.Success(c, gin.H{"id": stage.ID})
+}
+
+// DeleteByID 删除审批节点，进行中的申请按删除后的定义继续审批
+// @Summary Delete a workflow stage
+// @Description Removes a stage. Applications in progress continue with the remaining stages; if a workflow has no stages left the built-in pre-review and finance-review stages apply.
+// @Tags workflow
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/workflow-stages/{id} [delete]
+// @Security BearerAuth
+func (h *loanWorkflowStagesHandler) DeleteByID(c *gin.Context) {
+	id, ok := getLoanWorkflowStagesIDFromPath(c)
+	if !ok {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	ctx := middleware.WrapCtx(c)
+	stage, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		respondWorkflowStageGetError(c, err, id)
+		return
+	}
+	stages, err := h.iDao.GetByWorkflow(ctx, stage.Workflow)
+	if err != nil {
+		logger.Error("GetByWorkflow error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrListLoanWorkflowStages)
+		return
+	}
+	rest := make([]*model.LoanWorkflowStages, 0, len(stages))
+	for _, s := range stages {
+		if s.ID != id {
+			rest = append(rest, s)
+		}
+	}
+	if len(rest) > 0 {
+		if err = workflow.Validate(rest); err != nil {
+			response.Error(c, ecode.ErrWorkflowDefinition.WithDetails(err.Error()))
+			return
+		}
+	}
+	if err = h.iDao.DeleteByID(ctx, id); err != nil {
+		logger.Error("DeleteByID error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrDeleteByIDLoanWorkflowStages)
+		return
+	}
+	response.Success(c)
+}
+
+// UpdateByID 修改审批节点
+// @Summary Update a workflow stage
+// @Description Replaces all editable fields of a stage and validates the resulting workflow definition.
+// @Tags workflow
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.UpdateLoanWorkflowStagesByIDRequest true "stage"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/workflow-stages/{id} [put]
+// @Security BearerAuth
+func (h *loanWorkflowStagesHandler) UpdateByID(c *gin.Context) {
+	id, ok := getLoanWorkflowStagesIDFromPath(c)
+	if !ok {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	form := &types.UpdateLoanWorkflowStagesByIDRequest{}
+	if err := c.ShouldBindJSON(form); err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	stage, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		respondWorkflowStageGetError(c, err, id)
+		return
+	}
+	stage.StageNo = form.StageNo
+	stage.Code = strings.TrimSpace(form.Code)
+	stage.Name = form.Name
+	stage.Permission = strings.TrimSpace(form.Permission)
+	stage.MinAmount = form.MinAmount
+	stage.Status = form.Status
+
+	stages, err := h.iDao.GetByWorkflow(ctx, stage.Workflow)
+	if err != nil {
+		logger.Error("GetByWorkflow error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrListLoanWorkflowStages)
+		return
+	}
+	for i, s := range stages {
+		if s.ID == id {
+			stages[i] = stage
+		}
+	}
+	if err = workflow.Validate(stages); err != nil {
+		response.Error(c, ecode.ErrWorkflowDefinition.WithDetails(err.Error()))
+		return
+	}
+	if err = h.iDao.UpdateByID(ctx, stage); err != nil {
+		logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrUpdateByIDLoanWorkflowStages)
+		return
+	}
+	response.Success(c)
+}
+
+// List 查询流程定义，未配置时返回内置的两级审批
+// @Summary List workflow stages
+// @Description Returns the stages of a workflow ordered by stage number. When none are configured the built-in stages are returned with configured=false.
+// @Tags workflow
+// @Produce json
+// @Param workflow query string false "流程代码(默认 loan_application)"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/workflow-stages [get]
+// @Security BearerAuth
+func (h *loanWorkflowStagesHandler) List(c *gin.Context) {
+	form := &types.ListLoanWorkflowStagesRequest{}
+	if err := c.ShouldBindQuery(form); err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	stages, err := h.iDao.GetByWorkflow(middleware.WrapCtx(c), workflowOrDefault(form.Workflow))
+	if err != nil {
+		logger.Error("GetByWorkflow error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrListLoanWorkflowStages)
+		return
+	}
+	configured := len(stages) > 0
+	if !configured {
+		stages = workflow.DefaultStages()
+	}
+	response.Success(c, gin.H{"stages": stages, "configured": configured})
+}
+
+func workflowOrDefault(name string) string {
+	if name = strings.TrimSpace(name); name == "" {
+		return model.WorkflowLoanApplication
+	}
+	return name
+}
+
+func getLoanWorkflowStagesIDFromPath(c *gin.Context) (uint64, bool) {
+	idStr := c.Param("id")
+	id, err := utils.StrToUint64E(idStr)
+	if err != nil || id == 0 {
+		logger.Warn("StrToUint64E error: ", logger.String("idStr", idStr), middleware.GCtxRequestIDField(c))
+		return 0, false
+	}
+	return id, true
+}
+
+func respondWorkflowStageGetError(c *gin.Context, err error, id uint64) {
+	if errors.Is(err, database.ErrRecordNotFound) {
+		response.Error(c, ecode.NotFound)
+		return
+	}
+	logger.Error("GetByID error", logger.Err(err), logger.Uint64("id", id), middleware.GCtxRequestIDField(c))
+	response.Error(c, ecode.ErrGetByIDLoanWorkflowStages)
+}
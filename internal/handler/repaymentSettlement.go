@@ -0,0 +1,414 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"loan/internal/cache"
+	"loan/internal/dao"
+	"loan/internal/database"
+	"loan/internal/fee"
+	"loan/internal/finance"
+	"loan/internal/ledger"
+	"loan/internal/model"
+)
+
+var (
+	// errPayAmountExceedsOutstanding 回款金额超过期次剩余应还(仅人工录入时返回)
+	errPayAmountExceedsOutstanding = errors.New("pay amount exceeds outstanding")
+	// errCollectChannelNotFound 回款渠道不存在(仅人工录入时返回)
+	errCollectChannelNotFound = errors.New("collect channel not found")
+	// errPayoffAmountMismatch 提前结清回款金额与当日报价不一致
+	errPayoffAmountMismatch = errors.New("pay amount does not match the payoff quote")
+)
+
+// repaymentSettler 回款入账：锁定期次、按渠道流水号去重、按冲销顺序分配、写回款流水并更新期次状态。
+// 人工录入(Create)和渠道回调(callbacks)共用同一套入账逻辑，回款流水在同一事务内过账到总账。
+type repaymentSettler struct {
+	txDao       dao.LoanRepaymentTransactionsDao
+	scheduleDao dao.LoanRepaymentSchedulesDao
+	settingsDao dao.LoanSettingsDao
+	productDao  dao.LoanProductsDao
+	channelDao  dao.LoanPaymentChannelsDao
+
+	disbursementDao dao.LoanDisbursementsDao
+	book            *ledger.Book
+}
+
+// settleResult 入账结果
+type settleResult struct {
+	ID             uint64 // 回款流水id，重复提交时为原流水id
+	Duplicate      bool   // 同一渠道流水号已入账
+	ScheduleStatus int    // 入账后期次状态
+	Overpaid       int64  // 超出剩余应还、未分配的金额(分)
+}
+
+func newRepaymentSettler() *repaymentSettler {
+	return &repaymentSettler{
+		txDao: dao.NewLoanRepaymentTransactionsDao(
+			database.GetDB(),
+			cache.NewLoanRepaymentTransactionsCache(database.GetCacheType()),
+		),
+		scheduleDao: dao.NewLoanRepaymentSchedulesDao(
+			database.GetDB(),
+			cache.NewLoanRepaymentSchedulesCache(database.GetCacheType()),
+		),
+		settingsDao: dao.NewLoanSettingsDao(
+			database.GetDB(),
+			cache.NewLoanSettingsCache(database.GetCacheType()),
+		),
+		productDao: dao.NewLoanProductsDao(
+			database.GetDB(),
+			cache.NewLoanProductsCache(database.GetCacheType()),
+		),
+		channelDao: dao.NewLoanPaymentChannelsDao(
+			database.GetDB(),
+			cache.NewLoanPaymentChannelsCache(database.GetCacheType()),
+		),
+		disbursementDao: dao.NewLoanDisbursementsDao(
+			database.GetDB(),
+			cache.NewLoanDisbursementsCache(database.GetCacheType()),
+		),
+		book: ledger.NewBook(database.GetDB()),
+	}
+}
+
+// Settle 在一个事务中完成回款入账。record 需已填好 ScheduleID、PayAmount、PaidAt 等字段，分配字段及代收手续费由本方法写入。
+// channelConfirmed=false(人工录入)时回款超过剩余应还返回 errPayAmountExceedsOutstanding，超出渠道代收限额返回 fee 包的限额错误；
+// channelConfirmed=true(渠道已实际收款)时不拒绝：按剩余应还分配，超出部分记入 Overpaid 并写入备注，超限仅记录告警。
+func (s *repaymentSettler) Settle(ctx context.Context, record *model.LoanRepaymentTransactions, channelConfirmed bool) (*settleResult, error) {
+	scheduleID := uint64(record.ScheduleID)
+
+	// 渠道代收限额与手续费
+	if err := s.applyCollectFee(ctx, record, channelConfirmed); err != nil {
+		return nil, err
+	}
+
+	// 冲销顺序在事务外读取，读取失败时使用默认顺序
+	allocationOrder := s.allocationOrderForSchedule(ctx, scheduleID)
+
+	// 1) 开启事务
+	tx := database.GetDB().WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			logger.Error("panic in repayment settle", logger.Any("recover", r))
+		}
+	}()
+
+	// 2) 锁定还款计划记录，避免并发回款重复冲销
+	schedule, err := s.scheduleDao.GetByIDForUpdate(ctx, tx, scheduleID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// 3) 同一渠道流水号已入账时直接返回原流水，避免重复提交/渠道重复回调导致重复入账
+	if record.PayRef != "" {
+		existing, err := s.txDao.GetByChannelPayRef(ctx, tx, record.CollectChannelID, record.PayRef)
+		if err == nil {
+			tx.Rollback()
+			return &settleResult{ID: existing.ID, Duplicate: true, ScheduleStatus: schedule.Status}, nil
+		}
+		if !errors.Is(err, database.ErrRecordNotFound) {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// 4) 按冲销顺序分配本次回款
+	allocation := finance.Allocate(int64(record.PayAmount), finance.ScheduleOutstanding(schedule), allocationOrder)
+	if allocation.Remainder > 0 {
+		if !channelConfirmed {
+			tx.Rollback()
+			return nil, errPayAmountExceedsOutstanding
+		}
+		overpaid := fmt.Sprintf("溢缴%d分", allocation.Remainder)
+		if record.Remark == "" {
+			record.Remark = overpaid
+		} else {
+			record.Remark += "；" + overpaid
+		}
+	}
+	now := time.Now()
+	finance.ApplyToTransaction(record, allocation)
+	finance.ApplyToSchedule(schedule, allocation)
+	schedule.LastPaidAt = &now
+	finance.RefreshScheduleStatus(schedule, now)
+
+	// 5) 写回款流水
+	newID, err := s.txDao.CreateByTx(ctx, tx, record)
+	if err != nil {
+		tx.Rollback()
+		// 并发提交同一渠道流水号时由 uk_channel_pay_ref 兜底，返回先入账的原流水
+		if isDuplicateKeyErr(err) && record.PayRef != "" {
+			existing, getErr := s.txDao.GetByChannelPayRef(ctx, database.GetDB(), record.CollectChannelID, record.PayRef)
+			if getErr == nil {
+				return &settleResult{ID: existing.ID, Duplicate: true, ScheduleStatus: schedule.Status}, nil
+			}
+		}
+		return nil, err
+	}
+
+	// 6) 更新还款计划的已还科目及状态(还清则结清)
+	if err = s.scheduleDao.UpdateRepaymentByTx(ctx, tx, schedule); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// 7) 过账
+	if err = s.book.Post(ctx, tx, ledger.Repayment(record)); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// 8) 提交事务
+	if err = tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return &settleResult{ID: newID, ScheduleStatus: schedule.Status, Overpaid: allocation.Remainder}, nil
+}
+
+// payoffResult 提前结清入账结果
+type payoffResult struct {
+	IDs       []uint64 // 各期次的回款流水id，重复提交时为原流水id
+	Duplicate bool     // 同一渠道流水号已入账
+	Quote     *finance.PayoffQuote
+}
+
+// Quote 放款单在 now 当天的提前结清报价
+func (s *repaymentSettler) Quote(ctx context.Context, disbursementID uint64, now time.Time) (*finance.PayoffQuote, error) {
+	start, policy, err := s.payoffTerms(ctx, disbursementID)
+	if err != nil {
+		return nil, err
+	}
+	schedules, err := s.scheduleDao.GetByDisbursementID(ctx, disbursementID)
+	if err != nil {
+		return nil, err
+	}
+	return finance.QuotePayoff(schedules, payoffStart(start, schedules), now, policy)
+}
+
+// SettlePayoff 提前结清入账：在一个事务中锁定放款单全部期次，按当日报价校验回款金额，
+// 调整各期应还(免收未计提利息/费用及减免，计入手续费)并逐期写回款流水、结清期次。
+// record 为回款信息模板(渠道、渠道流水号、单号、回款时间等)，PayAmount 为回款总额；
+// 各期流水共用同一回款单号，渠道流水号与代收手续费只记在第一条流水上。
+func (s *repaymentSettler) SettlePayoff(ctx context.Context, disbursementID uint64, record *model.LoanRepaymentTransactions) (*payoffResult, error) {
+	start, policy, err := s.payoffTerms(ctx, disbursementID)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.applyCollectFee(ctx, record, false); err != nil {
+		return nil, err
+	}
+
+	// 1) 开启事务
+	tx := database.GetDB().WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			logger.Error("panic in repayment payoff", logger.Any("recover", r))
+		}
+	}()
+
+	// 2) 锁定放款单全部期次，避免与单期回款、罚息计提并发
+	schedules, err := s.scheduleDao.GetByDisbursementIDForUpdate(ctx, tx, disbursementID)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// 3) 同一渠道流水号已入账时直接返回原流水
+	if record.PayRef != "" {
+		existing, err := s.txDao.GetByChannelPayRef(ctx, tx, record.CollectChannelID, record.PayRef)
+		if err == nil {
+			tx.Rollback()
+			return &payoffResult{IDs: []uint64{existing.ID}, Duplicate: true}, nil
+		}
+		if !errors.Is(err, database.ErrRecordNotFound) {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// 4) 按锁定后的期次重新报价，回款金额必须与报价一致
+	now := time.Now()
+	quote, err := finance.QuotePayoff(schedules, payoffStart(start, schedules), now, policy)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if int64(record.PayAmount) != quote.Total {
+		tx.Rollback()
+		return nil, fmt.Errorf("%w: quote total %d, pay amount %d", errPayoffAmountMismatch, quote.Total, record.PayAmount)
+	}
+
+	// 5) 逐期调整应还、结清期次，写回款流水并过账，累计按比例减免
+	byID := make(map[uint64]*model.LoanRepaymentSchedules, len(schedules))
+	for _, sc := range schedules {
+		byID[sc.ID] = sc
+	}
+	result := &payoffResult{Quote: quote}
+	var discount ledger.Waived
+	first := true
+	for _, item := range quote.Items {
+		schedule := byID[item.ScheduleID]
+		allocation := finance.ApplyPayoff(schedule, item, now)
+		if err = s.scheduleDao.UpdatePayoffByTx(ctx, tx, schedule); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		discount.Interest += item.Discount.Interest
+		discount.Fee += item.Discount.Fee
+		if allocation.Total() == 0 {
+			continue
+		}
+
+		t := *record
+		t.ScheduleID = int64(schedule.ID)
+		t.PayAmount = int(allocation.Total())
+		if !first {
+			t.PayRef, t.CollectFee = "", 0
+		}
+		t.Remark = fmt.Sprintf("提前结清 第%d期", schedule.InstallmentNo)
+		if record.Remark != "" {
+			t.Remark += "；" + record.Remark
+		}
+		finance.ApplyToTransaction(&t, allocation)
+		newID, err := s.txDao.CreateByTx(ctx, tx, &t)
+		if err != nil {
+			tx.Rollback()
+			// 并发提交同一渠道流水号时由 uk_channel_pay_ref 兜底，返回先入账的原流水
+			if isDuplicateKeyErr(err) && record.PayRef != "" {
+				existing, getErr := s.txDao.GetByChannelPayRef(ctx, database.GetDB(), record.CollectChannelID, record.PayRef)
+				if getErr == nil {
+					return &payoffResult{IDs: []uint64{existing.ID}, Duplicate: true}, nil
+				}
+			}
+			return nil, err
+		}
+		if err = s.book.Post(ctx, tx, ledger.Repayment(&t)); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		result.IDs = append(result.IDs, newID)
+		first = false
+	}
+
+	// 6) 按比例减免合计过一笔账，凭证按本次结清的第一笔流水生成(冲正后再次结清不会重复凭证号)
+	if discount.Total() > 0 && len(result.IDs) > 0 {
+		if err = s.book.Post(ctx, tx, ledger.PayoffDiscount(result.IDs[0], now, discount)); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
+	// 7) 提交事务
+	if err = tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// payoffTerms 读取放款单的起息日及所属产品的提前结清规则
+func (s *repaymentSettler) payoffTerms(ctx context.Context, disbursementID uint64) (*time.Time, finance.PayoffPolicy, error) {
+	d, err := s.disbursementDao.GetByID(ctx, disbursementID)
+	if err != nil {
+		return nil, finance.PayoffPolicy{}, err
+	}
+	productID, err := s.disbursementDao.GetProductIDByID(ctx, disbursementID)
+	if err != nil || productID == 0 {
+		return d.DisbursedAt, finance.PayoffPolicy{}, err
+	}
+	product, err := s.productDao.GetByID(ctx, productID)
+	if err != nil {
+		return nil, finance.PayoffPolicy{}, err
+	}
+	return d.DisbursedAt, finance.PayoffPolicyFromProduct(product), nil
+}
+
+// payoffStart 起息日取实际放款时间，历史放款单没有放款时间时取还款计划生成时间
+func payoffStart(disbursedAt *time.Time, schedules []*model.LoanRepaymentSchedules) time.Time {
+	if disbursedAt != nil {
+		return *disbursedAt
+	}
+	for _, sc := range schedules {
+		if !sc.CreatedAt.IsZero() {
+			return sc.CreatedAt
+		}
+	}
+	return time.Now()
+}
+
+// applyCollectFee 按回款渠道的代收配置校验限额并写入代收手续费，未指定渠道的人工录入不收费
+func (s *repaymentSettler) applyCollectFee(ctx context.Context, record *model.LoanRepaymentTransactions, channelConfirmed bool) error {
+	if record.CollectChannelID <= 0 {
+		return nil
+	}
+	ch, err := s.channelDao.GetByID(ctx, uint64(record.CollectChannelID))
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) && !channelConfirmed {
+			return errCollectChannelNotFound
+		}
+		return err
+	}
+
+	rule := fee.CollectRule(ch)
+	collectFee, err := fee.Collect(rule, int64(record.PayAmount))
+	if err != nil {
+		if !channelConfirmed {
+			return err
+		}
+		logger.Warn("collected amount is outside channel limits",
+			logger.Err(err),
+			logger.String("channel", ch.Code),
+			logger.String("pay_ref", record.PayRef),
+			logger.Int("pay_amount", record.PayAmount),
+		)
+		collectFee = rule.Fee(int64(record.PayAmount))
+	}
+	record.CollectFee = int(collectFee)
+	return nil
+}
+
+// allocationOrderForSchedule 优先使用期次所属产品配置的冲销顺序，否则使用系统设置
+func (s *repaymentSettler) allocationOrderForSchedule(ctx context.Context, scheduleID uint64) []finance.Component {
+	productID, err := s.scheduleDao.GetProductIDByScheduleID(ctx, scheduleID)
+	if err == nil && productID != 0 {
+		product, err := s.productDao.GetByID(ctx, productID)
+		if err == nil && product.AllocationOrder != "" {
+			if order, err := finance.ParseAllocationOrder(product.AllocationOrder); err == nil {
+				return order
+			}
+			logger.Warn("invalid product allocation order, fall back to setting", logger.Uint64("product_id", productID))
+		}
+	}
+	return loadAllocationOrder(ctx, s.settingsDao)
+}
+
+// loadAllocationOrder 读取系统设置中的还款冲销顺序，未配置或配置非法时使用默认顺序
+func loadAllocationOrder(ctx context.Context, settingsDao dao.LoanSettingsDao) []finance.Component {
+	setting, err := settingsDao.GetByName(ctx, model.SettingRepaymentAllocationOrder)
+	if err != nil {
+		if !errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("get allocation order setting failed, use default", logger.Err(err))
+		}
+		return finance.DefaultAllocationOrder
+	}
+	order, err := finance.ParseAllocationOrder(setting.Value)
+	if err != nil {
+		logger.Warn("invalid allocation order setting, use default", logger.Err(err), logger.String("value", setting.Value))
+		return finance.DefaultAllocationOrder
+	}
+	return order
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"loan/internal/cache"
+	"loan/internal/dao"
+	"loan/internal/database"
+	"loan/internal/model"
+	"loan/internal/scoring"
+)
+
+// errNoActiveScorecard 没有启用的评分卡，不评分
+var errNoActiveScorecard = errors.New("no active scorecard")
+
+// scoreBreakdown 保存在申请单上的评分明细：评分卡版本与各特征得分
+type scoreBreakdown struct {
+	ScorecardID uint64 `json:"scorecardID"`
+	Name        string `json:"name"`
+	Version     int    `json:"version"`
+	scoring.Result
+}
+
+// scorer 信用评分：用启用的评分卡版本计算申请单的信用分，并把分数与明细保存到申请单
+type scorer struct {
+	scorecardDao dao.LoanScorecardsDao
+	dataDao      dao.LoanScoringDataDao
+	baseinfoDao  dao.LoanBaseinfoDao
+}
+
+func newScorer() *scorer {
+	return &scorer{
+		scorecardDao: dao.NewLoanScorecardsDao(database.GetDB()),
+		dataDao:      dao.NewLoanScoringDataDao(database.GetDB()),
+		baseinfoDao: dao.NewLoanBaseinfoDao(
+			database.GetDB(),
+			cache.NewLoanBaseinfoCache(database.GetCacheType()),
+		),
+	}
+}
+
+// active 当前启用的评分卡版本及解析后的定义
+func (s *scorer) active(ctx context.Context) (*model.LoanScorecards, *scoring.Scorecard, error) {
+	record, err := s.scorecardDao.GetActive(ctx)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			return nil, nil, errNoActiveScorecard
+		}
+		return nil, nil, err
+	}
+	card, err := scoring.Parse(record.Definition)
+	if err != nil {
+		return nil, nil, err
+	}
+	return record, card, nil
+}
+
+// score 用启用的评分卡为申请单评分并保存，重复评分以最后一次为准(如设备数据补传之后)
+func (s *scorer) score(ctx context.Context, id uint64) (*scoreBreakdown, error) {
+	// 1) 评分卡
+	record, card, err := s.active(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2) 申请单与设备采集数据
+	b, err := s.baseinfoDao.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := s.dataDao.GetDeviceData(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3) 计算并保存
+	result := &scoreBreakdown{
+		ScorecardID: record.ID,
+		Name:        record.Name,
+		Version:     record.Version,
+		Result:      card.Score(card.Extract(b, data)),
+	}
+	detail, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.baseinfoDao.UpdateScore(ctx, id, result.Score, record.ID, string(detail), time.Now()); err != nil {
+		return nil, err
+	}
+
+	logger.Info("application scored", logger.Uint64("id", id), logger.Int("score", result.Score),
+		logger.String("scorecard", record.Name), logger.Int("version", record.Version))
+	return result, nil
+}
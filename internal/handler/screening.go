@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"loan/internal/cache"
+	"loan/internal/dao"
+	"loan/internal/database"
+	"loan/internal/model"
+	"loan/internal/screening"
+	"loan/internal/workflow"
+)
+
+// errScreeningNotPending 申请单已不在待初审状态(已审批或已拒绝)，不再执行自动预审
+var errScreeningNotPending = errors.New("application is not waiting for pre-review")
+
+// screener 自动预审：执行规则，写入决策日志，并按结论自动拒绝或自动通过初审
+type screener struct {
+	ruleDao     dao.LoanScreeningRulesDao
+	decisionDao dao.LoanScreeningDecisionsDao
+	factsDao    dao.LoanScreeningFactsDao
+	baseinfoDao dao.LoanBaseinfoDao
+	auditDao    dao.LoanAuditsDao
+	stageDao    dao.LoanWorkflowStagesDao
+	settingsDao dao.LoanSettingsDao
+}
+
+func newScreener() *screener {
+	return &screener{
+		ruleDao:     dao.NewLoanScreeningRulesDao(database.GetDB()),
+		decisionDao: dao.NewLoanScreeningDecisionsDao(database.GetDB()),
+		factsDao:    dao.NewLoanScreeningFactsDao(database.GetDB()),
+		baseinfoDao: dao.NewLoanBaseinfoDao(
+			database.GetDB(),
+			cache.NewLoanBaseinfoCache(database.GetCacheType()),
+		),
+		auditDao: dao.NewLoanAuditsDao(
+			database.GetDB(),
+			cache.NewLoanAuditsCache(database.GetCacheType()),
+		),
+		stageDao: dao.NewLoanWorkflowStagesDao(database.GetDB()),
+		settingsDao: dao.NewLoanSettingsDao(
+			database.GetDB(),
+			cache.NewLoanSettingsCache(database.GetCacheType()),
+		),
+	}
+}
+
+// enabled 提交申请时是否自动执行预审
+func (s *screener) enabled(ctx context.Context) bool {
+	return s.settingsDao.GetInt64ByName(ctx, model.SettingScreeningEnabled, 1) != 0
+}
+
+// passScore 按分数自动通过初审的分数线，0 表示不按分数自动通过
+func (s *screener) passScore(ctx context.Context) int {
+	return int(s.settingsDao.GetInt64ByName(ctx, model.SettingScreeningPassScore, 0))
+}
+
+// rules 读取并解析启用的规则
+func (s *screener) rules(ctx context.Context) ([]*screening.Rule, error) {
+	list, err := s.ruleDao.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return screening.Compile(list)
+}
+
+// run 对待初审的申请单执行预审并落库：
+// REJECT 将申请单置为已拒绝；PASS 视为通过首个审批节点，进入下一节点待审；MANUAL 保持待初审。
+// 自动通过与自动拒绝以系统审核人员在首个节点写审核记录，与人工审批一样计入看板与审批产能统计
+func (s *screener) run(ctx context.Context, id uint64, trigger string) (*model.LoanScreeningDecisions, error) {
+	// 1) 规则、分数线、审批流程（不进事务）
+	rules, err := s.rules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	passScore := s.passScore(ctx)
+	stages, err := loadLoanWorkflow(ctx, s.stageDao)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := database.GetDB().WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	// 兜底：函数任何提前 return 都会回滚（Commit 后 Rollback 不会生效）
+	defer func() {
+		_ = tx.Rollback().Error
+	}()
+
+	// 2) 锁定申请单，只处理待初审的申请
+	record, err := s.baseinfoDao.GetByIDForUpdate(ctx, tx, id)
+	if err != nil {
+		return nil, err
+	}
+	if record.AuditStatus != 0 {
+		return nil, errScreeningNotPending
+	}
+	extra, err := s.factsDao.GetExtraFacts(ctx, []*model.LoanBaseinfo{record})
+	if err != nil {
+		return nil, err
+	}
+	facts := screening.FactsOf(record, extra[record.ID])
+	decision := screening.Evaluate(rules, facts, passScore)
+
+	// 3) 按结论更新申请单：自动通过只跳过首个节点，首个节点即最终节点时(通过后直接生成放款单)仍转人工
+	now := time.Now()
+	receivedAt := workflow.ReceivedAt(record)
+	step, stepErr := workflow.Next(stages, record.ApplicationAmount, record.AuditStatus)
+	var note string
+	switch decision.Outcome {
+	case model.ScreeningOutcomePass:
+		if stepErr != nil || step.Final {
+			decision.Outcome, note = model.ScreeningOutcomeManual, "first approval stage is final, auto pass skipped"
+			break
+		}
+		record.AuditStatus = step.Stage.StageNo
+	case model.ScreeningOutcomeReject:
+		record.AuditStatus = workflow.AuditStatusRejected
+	}
+	if record.AuditStatus != 0 {
+		if err = s.baseinfoDao.UpdateByTx(ctx, tx, record); err != nil {
+			return nil, err
+		}
+		if err = s.baseinfoDao.FinishStageByTx(ctx, tx, record.ID, now); err != nil {
+			return nil, err
+		}
+		if stepErr == nil {
+			auditResult := model.AuditResultPass
+			if decision.Outcome == model.ScreeningOutcomeReject {
+				auditResult = model.AuditResultReject
+			}
+			audit := &model.LoanAudits{
+				BaseinfoID:    record.ID,
+				AuditResult:   auditResult,
+				AuditComment:  fmt.Sprintf("auto screening %s, score %d", decision.Outcome, decision.Score),
+				AuditorUserID: model.SystemAuditorUserID,
+				AuditType:     step.Stage.StageNo,
+				StageCode:     step.Stage.Code,
+				ReceivedAt:    &receivedAt,
+				DecidedAt:     &now,
+			}
+			if _, err = s.auditDao.CreateByTx(ctx, tx, audit); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	// 4) 决策日志
+	hits, _ := json.Marshal(decision.Hits)
+	snapshot, _ := json.Marshal(facts)
+	entry := &model.LoanScreeningDecisions{
+		BaseinfoID: record.ID,
+		Trigger:    trigger,
+		Outcome:    decision.Outcome,
+		Score:      decision.Score,
+		Hits:       string(hits),
+		Facts:      string(snapshot),
+		Note:       note,
+	}
+	if _, err = s.decisionDao.CreateByTx(ctx, tx, entry); err != nil {
+		return nil, err
+	}
+	if err = tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	logger.Info("application screened", logger.Uint64("id", record.ID), logger.String("outcome", entry.Outcome),
+		logger.Int("score", entry.Score), logger.String("trigger", trigger))
+	return entry, nil
+}
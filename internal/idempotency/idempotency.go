@@ -0,0 +1,224 @@
+// Package idempotency 基于 Idempotency-Key 请求头的幂等中间件，重复提交直接回放首次响应。
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/jwt"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"gorm.io/gorm"
+
+	"loan/internal/dao"
+	"loan/internal/database"
+	"loan/internal/ecode"
+	"loan/internal/model"
+)
+
+const (
+	// HeaderKey 客户端传入的幂等键请求头
+	HeaderKey = "Idempotency-Key"
+	// HeaderReplayed 回放响应时附带的响应头
+	HeaderReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 128
+	defaultTTL   = 24 * time.Hour
+)
+
+// volatileFields 每次提交都会变化、不参与请求摘要的字段(如 MFA 动态码)
+var volatileFields = []string{"mfaCode"}
+
+// Guard 幂等中间件，需放在 middleware.Auth() 之后使用。
+// 未携带 Idempotency-Key 时直接放行；携带时同一用户、同一接口、同一个键只执行一次：
+// 首次请求成功(code=0)后保存响应，重复请求直接回放；首次请求失败则释放键，允许用同一个键重试。
+// 业务成功但保存响应失败时不释放键，键保持处理中直到过期，避免重试再次执行。
+func Guard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimSpace(c.GetHeader(HeaderKey))
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxKeyLength {
+			response.Error(c, ecode.ErrInvalidIdempotencyKey)
+			c.Abort()
+			return
+		}
+
+		uid, ok := uidFromClaims(c)
+		if !ok {
+			response.Out(c, ecode.Unauthorized)
+			c.Abort()
+			return
+		}
+
+		// 1) 读取请求体计算摘要，并放回供后续 handler 绑定
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.Error(c, ecode.InvalidParams)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := middleware.WrapCtx(c)
+		keysDao := dao.NewLoanIdempotencyKeysDao(database.GetDB())
+		now := time.Now()
+		expiresAt := now.Add(defaultTTL)
+		record := &model.LoanIdempotencyKeys{
+			UserID:      uid,
+			Scope:       c.Request.Method + " " + c.FullPath(),
+			IdemKey:     key,
+			RequestHash: RequestHash(body),
+			Status:      model.IdempotencyStatusProcessing,
+			ExpiresAt:   &expiresAt,
+		}
+
+		// 2) 占用幂等键，已被占用时按已有记录的状态处理
+		acquired, err := keysDao.Acquire(ctx, record)
+		if err != nil {
+			logger.Error("Acquire idempotency key error", logger.Err(err), logger.String("key", key), middleware.GCtxRequestIDField(c))
+			response.Out(c, ecode.InternalServerError)
+			c.Abort()
+			return
+		}
+		if !acquired {
+			existing, err := keysDao.GetByKey(ctx, uid, record.Scope, key)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					// 占用与查询之间键被释放，按处理中返回，客户端稍后重试
+					response.Error(c, ecode.ErrIdempotencyKeyInProgress)
+				} else {
+					logger.Error("GetByKey idempotency key error", logger.Err(err), logger.String("key", key), middleware.GCtxRequestIDField(c))
+					response.Out(c, ecode.InternalServerError)
+				}
+				c.Abort()
+				return
+			}
+			// 已过期的键释放后重新占用
+			if existing.ExpiresAt != nil && existing.ExpiresAt.Before(now) {
+				if err = keysDao.Release(ctx, existing.ID); err == nil {
+					acquired, err = keysDao.Acquire(ctx, record)
+				}
+				if err != nil || !acquired {
+					response.Error(c, ecode.ErrIdempotencyKeyInProgress)
+					c.Abort()
+					return
+				}
+			} else {
+				replay(c, existing, record.RequestHash)
+				c.Abort()
+				return
+			}
+		}
+
+		// 3) 执行业务 handler，同时记录响应
+		writer := &bodyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		succeeded := false
+		defer func() {
+			// handler panic 或业务失败时释放键，避免客户端在过期前无法重试
+			if !succeeded {
+				if err := keysDao.Release(ctx, record.ID); err != nil {
+					logger.Warn("Release idempotency key error", logger.Err(err), logger.Uint64("id", record.ID), middleware.GCtxRequestIDField(c))
+				}
+			}
+		}()
+		c.Next()
+
+		// 4) 仅保存成功响应(http 200 且业务码为 0)
+		if writer.Status() != http.StatusOK || !isSuccessBody(writer.body.Bytes()) {
+			return
+		}
+		// 业务已提交：此后无论保存响应是否成功都不能释放键，否则重试会重复入账
+		succeeded = true
+		if err := keysDao.Complete(ctx, record.ID, writer.Status(), writer.body.String()); err != nil {
+			logger.Error("Complete idempotency key error, key stays in progress until it expires", logger.Err(err),
+				logger.Uint64("id", record.ID), middleware.GCtxRequestIDField(c))
+		}
+	}
+}
+
+// replay 根据已有记录回放首次响应
+func replay(c *gin.Context, existing *model.LoanIdempotencyKeys, requestHash string) {
+	if existing.RequestHash != requestHash {
+		response.Error(c, ecode.ErrIdempotencyKeyReused)
+		return
+	}
+	if existing.Status != model.IdempotencyStatusCompleted {
+		response.Error(c, ecode.ErrIdempotencyKeyInProgress)
+		return
+	}
+	c.Header(HeaderReplayed, "true")
+	c.Data(existing.HTTPStatus, "application/json; charset=utf-8", []byte(existing.ResponseBody))
+}
+
+// RequestHash 计算请求体摘要。JSON 对象会去掉易变字段并按键排序后再计算，其它内容按原始字节计算
+func RequestHash(body []byte) string {
+	payload := body
+	var obj map[string]interface{}
+	if err := json.Unmarshal(body, &obj); err == nil && obj != nil {
+		for _, f := range volatileFields {
+			delete(obj, f)
+		}
+		if b, err := json.Marshal(obj); err == nil {
+			payload = b
+		}
+	}
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
+
+// isSuccessBody 判断响应体是否为业务成功(code=0)
+func isSuccessBody(body []byte) bool {
+	var result struct {
+		Code *int `json:"code"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil || result.Code == nil {
+		return false
+	}
+	return *result.Code == 0
+}
+
+func uidFromClaims(c *gin.Context) (uint64, bool) {
+	v, ok := c.Get("claims")
+	if !ok || v == nil {
+		return 0, false
+	}
+	claims, ok := v.(*jwt.Claims)
+	if !ok || claims == nil {
+		return 0, false
+	}
+	uid, err := strconv.ParseUint(claims.UID, 10, 64)
+	if err != nil || uid == 0 {
+		return 0, false
+	}
+	return uid, true
+}
+
+// bodyWriter 在写出响应的同时保留一份响应体
+type bodyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,26 @@
+package idempotency
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestHash(t *testing.T) {
+	a := RequestHash([]byte(`{"scheduleID":1,"payAmount":100,"mfaCode":"123456"}`))
+	b := RequestHash([]byte(`{"payAmount":100, "mfaCode":"654321", "scheduleID":1}`))
+	assert.Equal(t, a, b, "mfaCode and key order should not affect the hash")
+
+	c := RequestHash([]byte(`{"scheduleID":1,"payAmount":200,"mfaCode":"123456"}`))
+	assert.NotEqual(t, a, c)
+
+	assert.Len(t, RequestHash([]byte("not json")), 64)
+	assert.NotEqual(t, RequestHash([]byte("a")), RequestHash([]byte("b")))
+}
+
+func TestIsSuccessBody(t *testing.T) {
+	assert.True(t, isSuccessBody([]byte(`{"code":0,"msg":"ok","data":{"id":1}}`)))
+	assert.False(t, isSuccessBody([]byte(`{"code":10001,"msg":"error"}`)))
+	assert.False(t, isSuccessBody([]byte(`{"msg":"ok"}`)))
+	assert.False(t, isSuccessBody([]byte(`<html>`)))
+}
@@ -0,0 +1,45 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/gocron"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"loan/internal/dao"
+	"loan/internal/database"
+)
+
+const idempotencyCleanupBatchSize = 1000
+
+func init() {
+	tasks = append(tasks, &gocron.Task{
+		Name:     "idempotency-key-cleanup",
+		TimeSpec: "15 3 * * *", // 每天 03:15
+		Fn:       runIdempotencyCleanup,
+	})
+}
+
+// runIdempotencyCleanup 物理删除已过期的幂等键
+func runIdempotencyCleanup() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	keysDao := dao.NewLoanIdempotencyKeysDao(database.GetDB())
+	now := time.Now()
+
+	var total int64
+	for {
+		n, err := keysDao.DeleteExpired(ctx, now, idempotencyCleanupBatchSize)
+		if err != nil {
+			logger.Error("idempotency key cleanup failed", logger.Err(err), logger.Int64("deleted", total))
+			return
+		}
+		total += n
+		if n < idempotencyCleanupBatchSize {
+			break
+		}
+	}
+	logger.Info("idempotency key cleanup done", logger.Int64("deleted", total))
+}
@@ -0,0 +1,25 @@
+// Package job 后台定时任务，在 cmd/loan/initial 中随服务启动和关闭。
+package job
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/gocron"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+)
+
+// 各任务文件在 init() 中注册，参考 routers 中 apiV1RouterFns 的用法
+var tasks []*gocron.Task
+
+// Init 启动所有已注册的定时任务，需在数据库初始化之后调用
+func Init() error {
+	err := gocron.Init(gocron.WithLog(logger.Get(), true))
+	if err != nil {
+		return err
+	}
+	return gocron.Run(tasks...)
+}
+
+// Close 停止所有定时任务
+func Close() error {
+	gocron.Stop()
+	return nil
+}
@@ -0,0 +1,381 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/gocron"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"gorm.io/gorm"
+
+	"loan/internal/cache"
+	"loan/internal/dao"
+	"loan/internal/database"
+	"loan/internal/finance"
+	"loan/internal/ledger"
+	"loan/internal/model"
+	"loan/internal/payout"
+	"loan/internal/routing"
+)
+
+const payoutDispatchBatchSize = 100
+
+// payoutDispatchRunning 上一轮未结束时跳过本轮，避免同一实例内并发提交
+var payoutDispatchRunning atomic.Bool
+
+func init() {
+	tasks = append(tasks, &gocron.Task{
+		Name:     "payout-dispatch",
+		TimeSpec: "@every 1m",
+		Fn:       runPayoutDispatch,
+	})
+}
+
+type payoutDispatchJob struct {
+	disbursementDao dao.LoanDisbursementsDao
+	attemptDao      dao.LoanDisbursementAttemptsDao
+	channelDao      dao.LoanPaymentChannelsDao
+	baseinfoDao     dao.LoanBaseinfoDao
+	productDao      dao.LoanProductsDao
+	scheduleDao     dao.LoanRepaymentSchedulesDao
+	router          *routing.Router
+	book            *ledger.Book
+}
+
+// runPayoutDispatch 放款单异步流转：待放款/待重试 -> 提交代付网关(已提交) -> 查询渠道结果(已放款/放款失败)。
+// 放款成功时按实际放款时间生成还款计划，放款成功前不存在可还款的期次；
+// 自动路由的放款单失败时切换到下一个未失败过的可用渠道(待重试)，没有可用渠道时才置为放款失败。
+func runPayoutDispatch() {
+	if !payoutDispatchRunning.CompareAndSwap(false, true) {
+		return
+	}
+	defer payoutDispatchRunning.Store(false)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	j := &payoutDispatchJob{
+		disbursementDao: dao.NewLoanDisbursementsDao(
+			database.GetDB(),
+			cache.NewLoanDisbursementsCache(database.GetCacheType()),
+		),
+		channelDao: dao.NewLoanPaymentChannelsDao(
+			database.GetDB(),
+			cache.NewLoanPaymentChannelsCache(database.GetCacheType()),
+		),
+		baseinfoDao: dao.NewLoanBaseinfoDao(
+			database.GetDB(),
+			cache.NewLoanBaseinfoCache(database.GetCacheType()),
+		),
+		attemptDao: dao.NewLoanDisbursementAttemptsDao(database.GetDB()),
+		productDao: dao.NewLoanProductsDao(
+			database.GetDB(),
+			cache.NewLoanProductsCache(database.GetCacheType()),
+		),
+		scheduleDao: dao.NewLoanRepaymentSchedulesDao(
+			database.GetDB(),
+			cache.NewLoanRepaymentSchedulesCache(database.GetCacheType()),
+		),
+		book: ledger.NewBook(database.GetDB()),
+	}
+	j.router = &routing.Router{ChannelDao: j.channelDao, DisbursementDao: j.disbursementDao}
+
+	submitted := j.forEach(ctx, model.DisbursementStatusPending, j.submit)
+	submitted += j.forEach(ctx, model.DisbursementStatusRetrying, j.submit)
+	polled := j.forEach(ctx, model.DisbursementStatusSubmitted, j.poll)
+	if submitted > 0 || polled > 0 {
+		logger.Info("payout dispatch done", logger.Int("submitted", submitted), logger.Int("polled", polled))
+	}
+}
+
+// forEach 分批处理指定状态的放款单，返回处理条数
+func (j *payoutDispatchJob) forEach(ctx context.Context, status int, fn func(context.Context, *model.LoanDisbursements) error) int {
+	var lastID uint64
+	var n int
+	for {
+		records, err := j.disbursementDao.GetByStatus(ctx, status, lastID, payoutDispatchBatchSize)
+		if err != nil {
+			logger.Error("get disbursements by status failed", logger.Err(err), logger.Int("status", status))
+			return n
+		}
+		for _, d := range records {
+			if err = fn(ctx, d); err != nil {
+				logger.Warn("payout dispatch failed", logger.Err(err), logger.Uint64("disbursement_id", d.ID), logger.Int("status", status))
+				continue
+			}
+			n++
+		}
+		if len(records) < payoutDispatchBatchSize {
+			return n
+		}
+		lastID = records[len(records)-1].ID
+	}
+}
+
+func (j *payoutDispatchJob) gateway(ctx context.Context, d *model.LoanDisbursements) (payout.Gateway, *model.LoanPaymentChannels, error) {
+	ch, err := j.channelDao.GetByID(ctx, d.PayoutChannelID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get payout channel %d: %w", d.PayoutChannelID, err)
+	}
+	gw, err := payout.Get(ch.Code)
+	if err != nil {
+		return nil, nil, fmt.Errorf("channel %s: %w", ch.Code, err)
+	}
+	return gw, ch, nil
+}
+
+// submit 抢占待放款/待重试的放款单，写提交记录并提交代付网关。
+// 提交结果未知(网络错误)时保持已提交状态，由 poll 查询后决定是否重新提交
+func (j *payoutDispatchJob) submit(ctx context.Context, d *model.LoanDisbursements) error {
+	gw, ch, err := j.gateway(ctx, d)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	update := map[string]interface{}{
+		"status":        model.DisbursementStatusSubmitted,
+		"submitted_at":  now,
+		"attempt_count": d.AttemptCount + 1,
+	}
+	if d.MerchantOrderNo == "" {
+		// 历史/手工创建的放款单没有商户单号，按 id 生成，保证重复提交时单号不变
+		d.MerchantOrderNo = fmt.Sprintf("PO%s%08d", now.Format("20060102"), d.ID)
+		update["merchant_order_no"] = d.MerchantOrderNo
+	}
+	operator := d.AuditorUserID
+	if d.Status == model.DisbursementStatusRetrying && d.RetryBy != 0 {
+		operator = d.RetryBy
+	}
+
+	// 抢占放款单与写提交记录在同一事务中，已被其它实例抢占时直接跳过
+	tx := database.GetDB().WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	ok, err := j.disbursementDao.TransitStatusByTx(ctx, tx, d.ID, d.Status, update)
+	if err != nil || !ok {
+		tx.Rollback()
+		return err
+	}
+	_, err = j.attemptDao.InsertIgnoreByTx(ctx, tx, &model.LoanDisbursementAttempts{
+		DisbursementID:  d.ID,
+		AttemptNo:       d.AttemptCount + 1,
+		PayoutChannelID: d.PayoutChannelID,
+		MerchantOrderNo: d.MerchantOrderNo,
+		Amount:          d.NetAmount,
+		Status:          model.DisbursementAttemptProcessing,
+		SubmittedAt:     &now,
+		OperatorUserID:  operator,
+	})
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err = tx.Commit().Error; err != nil {
+		return err
+	}
+
+	return j.submitToGateway(ctx, gw, ch, d)
+}
+
+// poll 查询已提交放款单的渠道结果，渠道侧查无此单时用同一商户单号重新提交
+func (j *payoutDispatchJob) poll(ctx context.Context, d *model.LoanDisbursements) error {
+	gw, ch, err := j.gateway(ctx, d)
+	if err != nil {
+		return err
+	}
+
+	res, err := gw.Query(ctx, d.MerchantOrderNo)
+	if errors.Is(err, payout.ErrOrderNotFound) {
+		return j.submitToGateway(ctx, gw, ch, d)
+	}
+	if err != nil {
+		return err
+	}
+	return j.apply(ctx, d, res)
+}
+
+func (j *payoutDispatchJob) submitToGateway(ctx context.Context, gw payout.Gateway, ch *model.LoanPaymentChannels, d *model.LoanDisbursements) error {
+	baseinfo, err := j.baseinfoDao.GetByID(ctx, d.BaseinfoID)
+	if err != nil {
+		return fmt.Errorf("get baseinfo %d: %w", d.BaseinfoID, err)
+	}
+
+	res, err := gw.Submit(ctx, &payout.Request{
+		MerchantOrderNo: d.MerchantOrderNo,
+		MerchantNo:      ch.MerchantNo,
+		Amount:          d.NetAmount,
+		AccountNo:       baseinfo.BankNo,
+		AccountName:     strings.TrimSpace(baseinfo.FirstName + " " + baseinfo.SecondName),
+		Mobile:          baseinfo.Mobile,
+		Remark:          fmt.Sprintf("loan disbursement %d", d.ID),
+	})
+	if err != nil {
+		return fmt.Errorf("submit payout %s: %w", d.MerchantOrderNo, err)
+	}
+	return j.apply(ctx, d, res)
+}
+
+// apply 将渠道结果写回已提交的放款单及对应的提交记录，放款成功时同时生成还款计划并过账
+func (j *payoutDispatchJob) apply(ctx context.Context, d *model.LoanDisbursements, res *payout.Result) error {
+	update := map[string]interface{}{}
+	attemptUpdate := map[string]interface{}{}
+	if res.PayoutOrderNo != "" {
+		update["payout_order_no"] = res.PayoutOrderNo
+		attemptUpdate["payout_order_no"] = res.PayoutOrderNo
+	}
+
+	finishedAt := time.Now()
+	if res.FinishedAt != nil {
+		finishedAt = *res.FinishedAt
+	}
+	switch res.Status {
+	case payout.StatusProcessing:
+		if len(update) == 0 || res.PayoutOrderNo == d.PayoutOrderNo {
+			return nil
+		}
+	case payout.StatusSuccess:
+		update["status"] = model.DisbursementStatusSuccess
+		update["disbursed_at"] = finishedAt
+		attemptUpdate["status"] = model.DisbursementAttemptSuccess
+		attemptUpdate["finished_at"] = finishedAt
+	case payout.StatusFailed, payout.StatusCancelled:
+		update["status"] = model.DisbursementStatusFailed
+		update["fail_reason"] = res.FailReason
+		attemptUpdate["status"] = model.DisbursementAttemptFailed
+		if res.Status == payout.StatusCancelled {
+			attemptUpdate["status"] = model.DisbursementAttemptCancelled
+		}
+		attemptUpdate["fail_reason"] = res.FailReason
+		attemptUpdate["finished_at"] = finishedAt
+		if res.Status == payout.StatusFailed && d.RouteMode == model.DisbursementRouteAuto {
+			j.fallback(ctx, d, res, update)
+		}
+	default:
+		return fmt.Errorf("unknown payout status %d", res.Status)
+	}
+
+	tx := database.GetDB().WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			logger.Error("panic in payout apply", logger.Any("recover", r), logger.Uint64("disbursement_id", d.ID))
+		}
+	}()
+
+	ok, err := j.disbursementDao.TransitStatusByTx(ctx, tx, d.ID, model.DisbursementStatusSubmitted, update)
+	if err != nil || !ok {
+		tx.Rollback()
+		return err // 状态已被其它实例/人工操作变更
+	}
+	if err = j.attemptDao.UpdateByMerchantOrderNoByTx(ctx, tx, d.MerchantOrderNo, attemptUpdate); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if res.Status == payout.StatusSuccess {
+		if err = j.activateSchedules(ctx, tx, d, finishedAt); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err = j.book.Post(ctx, tx, ledger.Disbursement(d, finishedAt)); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	if err = tx.Commit().Error; err != nil {
+		return err
+	}
+
+	if res.Status != payout.StatusProcessing {
+		logger.Info("payout finished",
+			logger.Uint64("disbursement_id", d.ID),
+			logger.String("merchant_order_no", d.MerchantOrderNo),
+			logger.String("payout_order_no", res.PayoutOrderNo),
+			logger.Any("status", update["status"]),
+		)
+	}
+	return nil
+}
+
+// fallback 自动路由的放款单失败后，排除已失败过的渠道重新路由；有可用渠道时改为待重试并切换渠道，
+// 重新计算手续费与到账金额，由下一轮任务提交
+func (j *payoutDispatchJob) fallback(ctx context.Context, d *model.LoanDisbursements, res *payout.Result, update map[string]interface{}) {
+	attempts, err := j.attemptDao.GetByDisbursementID(ctx, d.ID)
+	if err != nil {
+		logger.Warn("get disbursement attempts failed, skip fallback", logger.Err(err), logger.Uint64("disbursement_id", d.ID))
+		return
+	}
+	failed := []uint64{d.PayoutChannelID}
+	for _, a := range attempts {
+		failed = append(failed, a.PayoutChannelID)
+	}
+
+	otherDeductions := d.DisburseAmount - d.NetAmount - d.PayoutFee
+	plan, err := j.router.Plan(ctx, d.DisburseAmount, otherDeductions, failed...)
+	if err != nil {
+		logger.Warn("payout fallback route failed", logger.Err(err), logger.Uint64("disbursement_id", d.ID))
+		return
+	}
+	next, err := plan.Best()
+	if err != nil {
+		logger.Info("no fallback payout channel", logger.Uint64("disbursement_id", d.ID), logger.String("reason", plan.Reason()))
+		return
+	}
+
+	// 保留失败原因、清空渠道单号；商户单号按放款单id+提交次数生成，重复回退不会冲突
+	update["status"] = model.DisbursementStatusRetrying
+	update["payout_channel_id"] = next.Channel.ID
+	update["payout_fee"] = next.Payout.Fee
+	update["net_amount"] = next.Payout.NetAmount
+	update["merchant_order_no"] = fmt.Sprintf("PO%s%dR%d", time.Now().Format("20060102150405"), d.ID, d.AttemptCount+1)
+	update["payout_order_no"] = ""
+	update["route_reason"] = routing.FallbackReason(channelCode(ctx, j.channelDao, d.PayoutChannelID), res.FailReason, plan)
+}
+
+func channelCode(ctx context.Context, channelDao dao.LoanPaymentChannelsDao, id uint64) string {
+	ch, err := channelDao.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Sprintf("#%d", id)
+	}
+	return ch.Code
+}
+
+// activateSchedules 放款成功后按实际放款时间生成还款计划，已生成(历史数据)时跳过
+func (j *payoutDispatchJob) activateSchedules(ctx context.Context, tx *gorm.DB, d *model.LoanDisbursements, disbursedAt time.Time) error {
+	n, err := j.scheduleDao.CountByDisbursementIDByTx(ctx, tx, d.ID)
+	if err != nil || n > 0 {
+		return err
+	}
+
+	baseinfo, err := j.baseinfoDao.GetByID(ctx, d.BaseinfoID)
+	if err != nil {
+		return fmt.Errorf("get baseinfo %d: %w", d.BaseinfoID, err)
+	}
+	// 未关联产品的历史申请按到期一次还本、无利息处理
+	var product *model.LoanProducts
+	if baseinfo.ProductID != 0 {
+		if product, err = j.productDao.GetByID(ctx, baseinfo.ProductID); err != nil {
+			return fmt.Errorf("get product %d: %w", baseinfo.ProductID, err)
+		}
+	}
+
+	installments, err := finance.GenerateSchedule(
+		finance.TermsFromProduct(product, d.DisburseAmount, baseinfo.LoanDays, disbursedAt))
+	if err != nil {
+		return err
+	}
+	for _, record := range finance.ScheduleRecords(d.ID, installments) {
+		if _, err = j.scheduleDao.CreateByTx(ctx, tx, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/gocron"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"loan/internal/cache"
+	"loan/internal/dao"
+	"loan/internal/database"
+	"loan/internal/finance"
+	"loan/internal/model"
+)
+
+const penaltyAccrualBatchSize = 200
+
+func init() {
+	tasks = append(tasks, &gocron.Task{
+		Name:     "penalty-accrual",
+		TimeSpec: "30 0 * * *", // 每天 00:30，在逾期标记之后
+		Fn:       runPenaltyAccrual,
+	})
+}
+
+type penaltyAccrualJob struct {
+	scheduleDao dao.LoanRepaymentSchedulesDao
+	accrualDao  dao.LoanPenaltyAccrualsDao
+	productDao  dao.LoanProductsDao
+	policy      finance.PenaltyPolicy // 系统设置中的默认规则
+	accrualDate time.Time
+	productRate map[uint64]int64 // 产品罚息率缓存，本次运行内有效
+}
+
+// runPenaltyAccrual 对逾期期次按日计提罚息，同一期次同一天只计提一次；
+// 任务漏跑(发布、停机)的日期在下次运行时补提
+func runPenaltyAccrual() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	settingsDao := dao.NewLoanSettingsDao(database.GetDB(), cache.NewLoanSettingsCache(database.GetCacheType()))
+	policy := finance.PenaltyPolicy{
+		DailyRateBp: settingsDao.GetInt64ByName(ctx, model.SettingPenaltyDailyRateBp, 0),
+		GraceDays:   int(settingsDao.GetInt64ByName(ctx, model.SettingPenaltyGraceDays, 0)),
+		CapPercent:  settingsDao.GetInt64ByName(ctx, model.SettingPenaltyCapPercent, 100),
+	}
+	now := time.Now()
+	j := &penaltyAccrualJob{
+		scheduleDao: dao.NewLoanRepaymentSchedulesDao(
+			database.GetDB(),
+			cache.NewLoanRepaymentSchedulesCache(database.GetCacheType()),
+		),
+		accrualDao: dao.NewLoanPenaltyAccrualsDao(database.GetDB()),
+		productDao: dao.NewLoanProductsDao(
+			database.GetDB(),
+			cache.NewLoanProductsCache(database.GetCacheType()),
+		),
+		policy:      policy,
+		accrualDate: finance.DateOnly(now, now.Location()),
+		productRate: map[uint64]int64{},
+	}
+
+	var lastID uint64
+	var accrued, total int64
+	for {
+		ids, err := j.scheduleDao.GetOverdueIDs(ctx, lastID, penaltyAccrualBatchSize)
+		if err != nil {
+			logger.Error("get overdue schedules failed", logger.Err(err), logger.Uint64("last_id", lastID))
+			return
+		}
+		for _, id := range ids {
+			amount, err := j.accrue(ctx, id)
+			if err != nil {
+				logger.Error("penalty accrual failed", logger.Err(err), logger.Uint64("schedule_id", id))
+				continue
+			}
+			if amount > 0 {
+				accrued++
+				total += amount
+			}
+		}
+		if len(ids) < penaltyAccrualBatchSize {
+			break
+		}
+		lastID = ids[len(ids)-1]
+	}
+	logger.Info("penalty accrual done", logger.Int64("schedules", accrued), logger.Int64("amount", total))
+}
+
+// policyFor 产品配置了罚息率时覆盖系统默认罚息率
+func (j *penaltyAccrualJob) policyFor(ctx context.Context, scheduleID uint64) finance.PenaltyPolicy {
+	policy := j.policy
+	productID, err := j.scheduleDao.GetProductIDByScheduleID(ctx, scheduleID)
+	if err != nil || productID == 0 {
+		return policy
+	}
+	rate, ok := j.productRate[productID]
+	if !ok {
+		if product, err := j.productDao.GetByID(ctx, productID); err == nil {
+			rate = int64(product.PenaltyDailyRateBp)
+		}
+		j.productRate[productID] = rate
+	}
+	if rate > 0 {
+		policy.DailyRateBp = rate
+	}
+	return policy
+}
+
+// accrue 在一个事务内锁定期次，补提上次计提之后(没有计提流水时从应还日次日起)至计提日的罚息，
+// 写计提流水并累加罚息，返回实际计提金额
+func (j *penaltyAccrualJob) accrue(ctx context.Context, scheduleID uint64) (int64, error) {
+	policy := j.policyFor(ctx, scheduleID)
+	if policy.DailyRateBp <= 0 {
+		return 0, nil
+	}
+
+	tx := database.GetDB().WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			tx.Rollback()
+			logger.Error("panic in penalty accrual", logger.Any("recover", r), logger.Uint64("schedule_id", scheduleID))
+		}
+	}()
+
+	schedule, err := j.scheduleDao.GetByIDForUpdate(ctx, tx, scheduleID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if schedule.Status != model.ScheduleStatusOverdue || schedule.DueDate == nil {
+		tx.Rollback()
+		return 0, nil
+	}
+
+	from := schedule.DueDate.AddDate(0, 0, 1)
+	last, err := j.accrualDao.GetLastAccrualDateByTx(ctx, tx, scheduleID)
+	if err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+	if last != nil {
+		from = last.AddDate(0, 0, 1)
+	}
+
+	var total int64
+	for _, result := range finance.BackfillPenalties(schedule, from, j.accrualDate, policy) {
+		accrualDate := result.Date
+		before := int64(schedule.PenaltyDue) + total
+		inserted, err := j.accrualDao.InsertIgnoreByTx(ctx, tx, &model.LoanPenaltyAccruals{
+			ScheduleID:    scheduleID,
+			AccrualDate:   &accrualDate,
+			OverdueDays:   result.OverdueDays,
+			BaseAmount:    result.BaseAmount,
+			DailyRateBp:   int(policy.DailyRateBp),
+			Amount:        result.Amount,
+			PenaltyBefore: before,
+			PenaltyAfter:  before + result.Amount,
+		})
+		if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+		if inserted { // 当天未计提过
+			total += result.Amount
+		}
+	}
+	if total == 0 {
+		tx.Rollback()
+		return 0, nil
+	}
+
+	if err = j.scheduleDao.AccruePenaltyByTx(ctx, tx, scheduleID, total); err != nil {
+		tx.Rollback()
+		return 0, err
+	}
+
+	if err = tx.Commit().Error; err != nil {
+		return 0, err
+	}
+	return total, nil
+}
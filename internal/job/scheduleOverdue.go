@@ -0,0 +1,51 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/gocron"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"loan/internal/cache"
+	"loan/internal/dao"
+	"loan/internal/database"
+)
+
+const overdueSweepBatchSize = 500
+
+func init() {
+	tasks = append(tasks, &gocron.Task{
+		Name:     "schedule-overdue-sweep",
+		TimeSpec: "5 0 * * *", // 每天 00:05
+		Fn:       runScheduleOverdueSweep,
+	})
+}
+
+// runScheduleOverdueSweep 把已过应还日期且未还清的期次标记为逾期
+func runScheduleOverdueSweep() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	scheduleDao := dao.NewLoanRepaymentSchedulesDao(
+		database.GetDB(),
+		cache.NewLoanRepaymentSchedulesCache(database.GetCacheType()),
+	)
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	var total int64
+	for {
+		n, err := scheduleDao.MarkOverdue(ctx, today, overdueSweepBatchSize)
+		if err != nil {
+			logger.Error("schedule overdue sweep failed", logger.Err(err), logger.Int64("marked", total))
+			return
+		}
+		total += n
+		if n < overdueSweepBatchSize {
+			break
+		}
+	}
+	logger.Info("schedule overdue sweep done", logger.Int64("marked", total))
+}
@@ -0,0 +1,128 @@
+package job
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/gocron"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"loan/internal/cache"
+	"loan/internal/dao"
+	"loan/internal/database"
+	"loan/internal/model"
+	"loan/internal/report"
+)
+
+func init() {
+	tasks = append(tasks, &gocron.Task{
+		Name:     "vintage-snapshot",
+		TimeSpec: "45 1 * * *", // 每天 01:45，在逾期标记与罚息计提之后
+		Fn:       runVintageSnapshot,
+	})
+}
+
+type vintageSnapshotJob struct {
+	disbursementDao dao.LoanDisbursementsDao
+	scheduleDao     dao.LoanRepaymentSchedulesDao
+	txDao           dao.LoanRepaymentTransactionsDao
+	snapshotDao     dao.LoanVintageSnapshotsDao
+}
+
+// runVintageSnapshot 按放款月份重算放款批次(vintage)表现快照。
+// 放款超过 VintageMaxMOB+1 个月的批次所有观察点都已过去，已有快照时不再重算。
+func runVintageSnapshot() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	j := &vintageSnapshotJob{
+		disbursementDao: dao.NewLoanDisbursementsDao(
+			database.GetDB(),
+			cache.NewLoanDisbursementsCache(database.GetCacheType()),
+		),
+		scheduleDao: dao.NewLoanRepaymentSchedulesDao(
+			database.GetDB(),
+			cache.NewLoanRepaymentSchedulesCache(database.GetCacheType()),
+		),
+		txDao: dao.NewLoanRepaymentTransactionsDao(
+			database.GetDB(),
+			cache.NewLoanRepaymentTransactionsCache(database.GetCacheType()),
+		),
+		snapshotDao: dao.NewLoanVintageSnapshotsDao(database.GetDB()),
+	}
+
+	first, err := j.disbursementDao.GetFirstDisbursedAt(ctx)
+	if err != nil {
+		logger.Error("get first disbursed time failed", logger.Err(err))
+		return
+	}
+	if first == nil {
+		return
+	}
+	cohorts, err := j.snapshotDao.GetCohorts(ctx)
+	if err != nil {
+		logger.Error("get vintage cohorts failed", logger.Err(err))
+		return
+	}
+	done := map[string]bool{}
+	for _, c := range cohorts {
+		done[c] = true
+	}
+
+	now := time.Now()
+	frozenBefore := now.AddDate(0, -(report.VintageMaxMOB + 1), 0)
+	start := first.In(time.Local)
+	var built int
+	for month := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, time.Local); !month.After(now); month = month.AddDate(0, 1, 0) {
+		cohort := report.CohortOf(month)
+		if done[cohort] && month.AddDate(0, 1, 0).Before(frozenBefore) {
+			continue
+		}
+		if err := j.build(ctx, cohort, month, now); err != nil {
+			logger.Error("vintage snapshot failed", logger.Err(err), logger.String("cohort", cohort))
+			continue
+		}
+		built++
+	}
+	logger.Info("vintage snapshot done", logger.Int("cohorts", built))
+}
+
+// build 计算一个放款月份的快照并整批替换
+func (j *vintageSnapshotJob) build(ctx context.Context, cohort string, month time.Time, now time.Time) error {
+	disbursements, err := j.disbursementDao.GetDisbursedBetween(ctx, month, month.AddDate(0, 1, 0))
+	if err != nil {
+		return err
+	}
+	ids := make([]uint64, 0, len(disbursements))
+	for _, d := range disbursements {
+		ids = append(ids, d.ID)
+	}
+	schedules, err := j.scheduleDao.GetByDisbursementIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	schedulesOf := map[uint64][]*model.LoanRepaymentSchedules{}
+	disbursementOf := map[uint64]uint64{}
+	scheduleIDs := make([]uint64, 0, len(schedules))
+	for _, s := range schedules {
+		did := uint64(s.DisbursementID)
+		schedulesOf[did] = append(schedulesOf[did], s)
+		disbursementOf[s.ID] = did
+		scheduleIDs = append(scheduleIDs, s.ID)
+	}
+	txs, err := j.txDao.GetPostedByScheduleIDs(ctx, scheduleIDs)
+	if err != nil {
+		return err
+	}
+	txsOf := map[uint64][]*model.LoanRepaymentTransactions{}
+	for _, t := range txs {
+		did := disbursementOf[uint64(t.ScheduleID)]
+		txsOf[did] = append(txsOf[did], t)
+	}
+
+	loans := make([]report.VintageLoan, 0, len(disbursements))
+	for _, d := range disbursements {
+		loans = append(loans, report.NewVintageLoan(d, schedulesOf[d.ID], txsOf[d.ID]))
+	}
+	return j.snapshotDao.ReplaceCohort(ctx, cohort, report.Vintage(cohort, loans, now))
+}
@@ -0,0 +1,60 @@
+package ledger
+
+// Account 科目代码(loan_ledger_entries.account)
+type Account string
+
+// 科目
+const (
+	AccountCash                Account = "CASH"                 // 现金及银行存款(线下回款)
+	AccountChannelClearing     Account = "CHANNEL_CLEARING"     // 渠道清算款(代付/代收在途，渠道结算后转入现金)
+	AccountLoanReceivable      Account = "LOAN_RECEIVABLE"      // 贷款应收本金
+	AccountCustomerOverpayment Account = "CUSTOMER_OVERPAYMENT" // 客户溢缴款
+	AccountInterestIncome      Account = "INTEREST_INCOME"      // 利息收入
+	AccountFeeIncome           Account = "FEE_INCOME"           // 费用收入(放款预扣费用、期次费用、提前结清/展期手续费)
+	AccountPenaltyIncome       Account = "PENALTY_INCOME"       // 罚息收入
+	AccountChannelFeeExpense   Account = "CHANNEL_FEE_EXPENSE"  // 渠道手续费支出(代收手续费)
+	AccountWaiverExpense       Account = "WAIVER_EXPENSE"       // 减免支出(利息/费用/罚息减免)
+)
+
+// 科目类别
+const (
+	TypeAsset     = "ASSET"
+	TypeLiability = "LIABILITY"
+	TypeIncome    = "INCOME"
+	TypeExpense   = "EXPENSE"
+)
+
+// AccountInfo 科目信息
+type AccountInfo struct {
+	Code Account `json:"code"`
+	Name string  `json:"name"`
+	Type string  `json:"type"`
+}
+
+// DebitNormal 资产、费用类科目余额在借方，负债、收入类在贷方
+func (a AccountInfo) DebitNormal() bool {
+	return a.Type == TypeAsset || a.Type == TypeExpense
+}
+
+// Accounts 科目表，试算平衡按此顺序输出
+var Accounts = []AccountInfo{
+	{Code: AccountCash, Name: "现金及银行存款", Type: TypeAsset},
+	{Code: AccountChannelClearing, Name: "渠道清算款", Type: TypeAsset},
+	{Code: AccountLoanReceivable, Name: "贷款应收本金", Type: TypeAsset},
+	{Code: AccountCustomerOverpayment, Name: "客户溢缴款", Type: TypeLiability},
+	{Code: AccountInterestIncome, Name: "利息收入", Type: TypeIncome},
+	{Code: AccountFeeIncome, Name: "费用收入", Type: TypeIncome},
+	{Code: AccountPenaltyIncome, Name: "罚息收入", Type: TypeIncome},
+	{Code: AccountChannelFeeExpense, Name: "渠道手续费支出", Type: TypeExpense},
+	{Code: AccountWaiverExpense, Name: "减免支出", Type: TypeExpense},
+}
+
+// Lookup 按科目代码查找科目
+func Lookup(code Account) (AccountInfo, bool) {
+	for _, a := range Accounts {
+		if a.Code == code {
+			return a, true
+		}
+	}
+	return AccountInfo{}, false
+}
@@ -0,0 +1,89 @@
+package ledger
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"loan/internal/model"
+)
+
+// Balance 科目借贷发生额及余额，余额按科目正常方向计算(资产/费用为借减贷，负债/收入为贷减借)
+type Balance struct {
+	AccountInfo
+	Debit   int64 `json:"debit"`
+	Credit  int64 `json:"credit"`
+	Balance int64 `json:"balance"`
+}
+
+// TrialBalance 试算平衡表
+type TrialBalance struct {
+	Accounts    []Balance `json:"accounts"`
+	TotalDebit  int64     `json:"totalDebit"`
+	TotalCredit int64     `json:"totalCredit"`
+	Balanced    bool      `json:"balanced"`
+}
+
+// NewBalance 根据借贷发生额计算科目余额
+func NewBalance(info AccountInfo, debit int64, credit int64) Balance {
+	b := Balance{AccountInfo: info, Debit: debit, Credit: credit}
+	if info.DebitNormal() {
+		b.Balance = debit - credit
+	} else {
+		b.Balance = credit - debit
+	}
+	return b
+}
+
+// BuildTrialBalance 按科目表汇总各科目发生额，没有分录的科目以 0 列出；科目表外的科目(历史数据)追加在末尾
+func BuildTrialBalance(sums []*model.LoanLedgerAccountSums) *TrialBalance {
+	byAccount := make(map[Account]*model.LoanLedgerAccountSums, len(sums))
+	for _, s := range sums {
+		byAccount[Account(s.Account)] = s
+	}
+
+	tb := &TrialBalance{}
+	add := func(info AccountInfo, s *model.LoanLedgerAccountSums) {
+		var debit, credit int64
+		if s != nil {
+			debit, credit = s.Debit, s.Credit
+		}
+		tb.Accounts = append(tb.Accounts, NewBalance(info, debit, credit))
+		tb.TotalDebit += debit
+		tb.TotalCredit += credit
+	}
+	for _, info := range Accounts {
+		add(info, byAccount[info.Code])
+	}
+	for _, s := range sums {
+		if _, ok := Lookup(Account(s.Account)); !ok {
+			add(AccountInfo{Code: Account(s.Account), Name: s.Account, Type: TypeAsset}, s)
+		}
+	}
+	tb.Balanced = tb.TotalDebit == tb.TotalCredit
+	return tb
+}
+
+// ParsePeriod 解析 yyyy-mm-dd 日期区间(均含当天)为 [from, to)，为空的一端不限
+func ParsePeriod(dateFrom string, dateTo string) (*time.Time, *time.Time, error) {
+	var from, to *time.Time
+	if s := strings.TrimSpace(dateFrom); s != "" {
+		t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid date %q", dateFrom)
+		}
+		from = &t
+	}
+	if s := strings.TrimSpace(dateTo); s != "" {
+		t, err := time.ParseInLocation(time.DateOnly, s, time.Local)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid date %q", dateTo)
+		}
+		t = t.AddDate(0, 0, 1)
+		to = &t
+	}
+	if from != nil && to != nil && !to.After(*from) {
+		return nil, nil, fmt.Errorf("date to %s is before date from %s", dateTo, dateFrom)
+	}
+	return from, to, nil
+}
@@ -0,0 +1,27 @@
+package ledger
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"loan/internal/dao"
+)
+
+// Book 总账过账，业务方在自己的事务内调用 Post，分录与业务数据同时提交或回滚
+type Book struct {
+	Dao dao.LoanLedgerEntriesDao
+}
+
+// NewBook 使用给定数据库创建总账
+func NewBook(db *gorm.DB) *Book {
+	return &Book{Dao: dao.NewLoanLedgerEntriesDao(db)}
+}
+
+// Post 校验凭证借贷平衡后在 tx 内写入分录
+func (b *Book) Post(ctx context.Context, tx *gorm.DB, p Posting) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	return b.Dao.CreateByTx(ctx, tx, p.Entries())
+}
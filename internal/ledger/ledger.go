@@ -0,0 +1,228 @@
+// Package ledger 复式记账总账：放款、回款、冲正、减免在业务所在的数据库事务内过账，分录只追加不修改，每张凭证借贷相等。
+// 利息、费用、罚息按收付实现制在实际回款时确认收入，罚息计提、展期顺延只调整期次应还，不产生分录。
+// 减免(减免审批、展期免罚息、提前结清按比例减免)按总额法入账：确认被减免的收入，同时确认等额的减免支出，
+// 净损益为 0，与收付实现制一致，总账中保留每笔减免的金额与科目。
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"loan/internal/finance"
+	"loan/internal/model"
+)
+
+// 业务类型(loan_ledger_entries.event_type)
+const (
+	EventDisbursement    = "DISBURSEMENT"     // 放款成功
+	EventRepayment       = "REPAYMENT"        // 回款入账(含提前结清、展期费)
+	EventReversal        = "REVERSAL"         // 回款冲正
+	EventWaiver          = "WAIVER"           // 减免申请审批通过
+	EventExtensionWaiver = "EXTENSION_WAIVER" // 展期免除罚息
+	EventPayoffDiscount  = "PAYOFF_DISCOUNT"  // 提前结清按比例减免已计利息/费用
+)
+
+// 业务单据表(loan_ledger_entries.ref_type)
+const (
+	RefDisbursement = "loan_disbursements"
+	RefTransaction  = "loan_repayment_transactions"
+	RefWaiver       = "loan_waiver_requests"
+	RefExtension    = "loan_schedule_extensions"
+)
+
+var (
+	// ErrEmptyPosting 凭证没有分录
+	ErrEmptyPosting = errors.New("posting has no lines")
+	// ErrUnbalanced 凭证借贷不相等
+	ErrUnbalanced = errors.New("posting is not balanced")
+	// ErrInvalidLine 分录金额为负或同时有借贷方金额
+	ErrInvalidLine = errors.New("invalid posting line")
+)
+
+// Line 一条分录，Debit 与 Credit 只有一个大于 0
+type Line struct {
+	Account Account
+	Debit   int64
+	Credit  int64
+}
+
+// Posting 一张凭证，对应一笔业务
+type Posting struct {
+	EventType string
+	RefType   string
+	RefID     uint64
+	PostedAt  time.Time
+	Memo      string
+	Lines     []Line
+}
+
+// JournalNo 凭证号，同一业务单据只能过账一次(uk_journal_line 保证)
+func (p *Posting) JournalNo() string {
+	return fmt.Sprintf("%s-%d", p.EventType, p.RefID)
+}
+
+// Validate 校验凭证：至少一条分录、金额非负且每行只有借或贷、借贷合计相等
+func (p *Posting) Validate() error {
+	if len(p.Lines) == 0 {
+		return ErrEmptyPosting
+	}
+	var debit, credit int64
+	for _, l := range p.Lines {
+		if l.Debit < 0 || l.Credit < 0 || (l.Debit > 0) == (l.Credit > 0) {
+			return fmt.Errorf("%w: %s debit %d credit %d", ErrInvalidLine, l.Account, l.Debit, l.Credit)
+		}
+		if _, ok := Lookup(l.Account); !ok {
+			return fmt.Errorf("%w: unknown account %s", ErrInvalidLine, l.Account)
+		}
+		debit += l.Debit
+		credit += l.Credit
+	}
+	if debit != credit {
+		return fmt.Errorf("%w: %s debit %d credit %d", ErrUnbalanced, p.JournalNo(), debit, credit)
+	}
+	return nil
+}
+
+// Entries 转换为待写入的分录行
+func (p *Posting) Entries() []*model.LoanLedgerEntries {
+	journalNo := p.JournalNo()
+	entries := make([]*model.LoanLedgerEntries, 0, len(p.Lines))
+	for i, l := range p.Lines {
+		entries = append(entries, &model.LoanLedgerEntries{
+			JournalNo: journalNo,
+			LineNo:    i + 1,
+			EventType: p.EventType,
+			RefType:   p.RefType,
+			RefID:     p.RefID,
+			Account:   string(l.Account),
+			Debit:     l.Debit,
+			Credit:    l.Credit,
+			PostedAt:  p.PostedAt,
+			Memo:      p.Memo,
+		})
+	}
+	return entries
+}
+
+// debit 记借方，金额为负时记入贷方(冲正流水金额为负数)，0 不记
+func (p *Posting) debit(account Account, amount int64) {
+	switch {
+	case amount > 0:
+		p.Lines = append(p.Lines, Line{Account: account, Debit: amount})
+	case amount < 0:
+		p.Lines = append(p.Lines, Line{Account: account, Credit: -amount})
+	}
+}
+
+// credit 记贷方，金额为负时记入借方，0 不记
+func (p *Posting) credit(account Account, amount int64) {
+	p.debit(account, -amount)
+}
+
+// Disbursement 放款成功：借 贷款应收(放款金额)；贷 渠道清算(到账金额+代付手续费，渠道代付出的款项)，
+// 贷 费用收入(放款时预扣的其它费用)。代付手续费已从到账金额中扣除，由借款人承担。
+func Disbursement(d *model.LoanDisbursements, disbursedAt time.Time) Posting {
+	p := Posting{
+		EventType: EventDisbursement,
+		RefType:   RefDisbursement,
+		RefID:     d.ID,
+		PostedAt:  disbursedAt,
+		Memo:      fmt.Sprintf("放款 %s", d.MerchantOrderNo),
+	}
+	p.debit(AccountLoanReceivable, d.DisburseAmount)
+	p.credit(AccountChannelClearing, d.NetAmount+d.PayoutFee)
+	p.credit(AccountFeeIncome, d.DisburseAmount-d.NetAmount-d.PayoutFee)
+	return p
+}
+
+// Repayment 回款或冲正流水：借 渠道清算/现金(回款金额)；贷 贷款应收/利息收入/费用收入/罚息收入(按分配)，
+// 未分配的溢缴部分贷 客户溢缴款；代收手续费由平台承担：借 渠道手续费；贷 渠道清算。
+// 冲正流水的金额与分配均为负数，生成方向相反的分录。
+func Repayment(t *model.LoanRepaymentTransactions) Posting {
+	p := Posting{
+		EventType: EventRepayment,
+		RefType:   RefTransaction,
+		RefID:     t.ID,
+		Memo:      fmt.Sprintf("回款 %s", t.CollectOrderNo),
+	}
+	if t.PaidAt != nil {
+		p.PostedAt = *t.PaidAt
+	}
+	if t.Status == model.TransactionStatusReversal {
+		p.EventType = EventReversal
+		p.Memo = fmt.Sprintf("冲正 %s", t.CollectOrderNo)
+		if t.ReversalOfID != nil {
+			p.Memo += fmt.Sprintf("(原流水%d)", *t.ReversalOfID)
+		}
+	}
+
+	received := AccountCash
+	if t.CollectChannelID > 0 {
+		received = AccountChannelClearing
+	}
+	allocated := int64(t.AllocPrincipal + t.AllocInterest + t.AllocFee + t.AllocPenalty)
+	p.debit(received, int64(t.PayAmount))
+	p.credit(AccountLoanReceivable, int64(t.AllocPrincipal))
+	p.credit(AccountInterestIncome, int64(t.AllocInterest))
+	p.credit(AccountFeeIncome, int64(t.AllocFee))
+	p.credit(AccountPenaltyIncome, int64(t.AllocPenalty))
+	p.credit(AccountCustomerOverpayment, int64(t.PayAmount)-allocated)
+	if t.CollectFee != 0 {
+		p.debit(AccountChannelFeeExpense, int64(t.CollectFee))
+		p.credit(received, int64(t.CollectFee))
+	}
+	return p
+}
+
+// Waived 被减免的利息/费用/罚息(分)
+type Waived struct {
+	Interest int64
+	Fee      int64
+	Penalty  int64
+}
+
+// Total 减免合计
+func (w Waived) Total() int64 {
+	return w.Interest + w.Fee + w.Penalty
+}
+
+// waiver 减免：借 减免支出(减免合计)；贷 利息收入/费用收入/罚息收入(被减免的部分)
+func waiver(eventType string, refType string, refID uint64, postedAt time.Time, memo string, w Waived) Posting {
+	p := Posting{
+		EventType: eventType,
+		RefType:   refType,
+		RefID:     refID,
+		PostedAt:  postedAt,
+		Memo:      memo,
+	}
+	p.debit(AccountWaiverExpense, w.Total())
+	p.credit(AccountInterestIncome, w.Interest)
+	p.credit(AccountFeeIncome, w.Fee)
+	p.credit(AccountPenaltyIncome, w.Penalty)
+	return p
+}
+
+// WaiverRequest 减免申请审批通过，按申请的减免科目入账
+func WaiverRequest(r *model.LoanWaiverRequests, approvedAt time.Time) Posting {
+	var w Waived
+	switch finance.Component(r.Component) {
+	case finance.ComponentPenalty:
+		w.Penalty = r.Amount
+	case finance.ComponentFee:
+		w.Fee = r.Amount
+	}
+	return waiver(EventWaiver, RefWaiver, r.ID, approvedAt, fmt.Sprintf("减免 期次%d", r.ScheduleID), w)
+}
+
+// ExtensionWaiver 展期时免除期次的剩余罚息
+func ExtensionWaiver(e *model.LoanScheduleExtensions, extendedAt time.Time) Posting {
+	return waiver(EventExtensionWaiver, RefExtension, e.ID, extendedAt,
+		fmt.Sprintf("展期免罚息 期次%d 第%d次展期", e.ScheduleID, e.ExtensionNo), Waived{Penalty: e.PenaltyWaived})
+}
+
+// PayoffDiscount 提前结清时按比例减免各期次已计提的利息/费用，w 为本次结清的减免合计。
+// 期次冲正后可再次结清，凭证按本次结清写入的第一笔回款流水生成，不按期次生成
+func PayoffDiscount(transactionID uint64, paidAt time.Time, w Waived) Posting {
+	return waiver(EventPayoffDiscount, RefTransaction, transactionID, paidAt, fmt.Sprintf("提前结清减免 流水%d", transactionID), w)
+}
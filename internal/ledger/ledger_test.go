@@ -0,0 +1,270 @@
+package ledger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan/internal/model"
+)
+
+func lineMap(p Posting) map[Account][2]int64 {
+	m := map[Account][2]int64{}
+	for _, l := range p.Lines {
+		v := m[l.Account]
+		m[l.Account] = [2]int64{v[0] + l.Debit, v[1] + l.Credit}
+	}
+	return m
+}
+
+func TestDisbursement(t *testing.T) {
+	d := &model.LoanDisbursements{DisburseAmount: 100000, NetAmount: 88000, PayoutFee: 2000, MerchantOrderNo: "PO1"}
+	d.ID = 7
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	p := Disbursement(d, at)
+	require.NoError(t, p.Validate())
+	assert.Equal(t, "DISBURSEMENT-7", p.JournalNo())
+	assert.Equal(t, at, p.PostedAt)
+	assert.Equal(t, map[Account][2]int64{
+		AccountLoanReceivable:  {100000, 0},
+		AccountChannelClearing: {0, 90000},
+		AccountFeeIncome:       {0, 10000},
+	}, lineMap(p))
+
+	// 没有预扣费用时不产生费用收入分录
+	d.NetAmount = 98000
+	p = Disbursement(d, at)
+	require.NoError(t, p.Validate())
+	assert.Len(t, p.Lines, 2)
+}
+
+func TestRepayment(t *testing.T) {
+	paidAt := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	tx := &model.LoanRepaymentTransactions{
+		CollectChannelID: 3,
+		CollectOrderNo:   "RP1",
+		PayAmount:        12000,
+		CollectFee:       150,
+		PaidAt:           &paidAt,
+		AllocPrincipal:   10000,
+		AllocInterest:    1000,
+		AllocFee:         300,
+		AllocPenalty:     200,
+		Status:           model.TransactionStatusSuccess,
+	}
+	tx.ID = 11
+
+	p := Repayment(tx)
+	require.NoError(t, p.Validate())
+	assert.Equal(t, EventRepayment, p.EventType)
+	assert.Equal(t, paidAt, p.PostedAt)
+	assert.Equal(t, map[Account][2]int64{
+		AccountChannelClearing:     {12000, 150},
+		AccountLoanReceivable:      {0, 10000},
+		AccountInterestIncome:      {0, 1000},
+		AccountFeeIncome:           {0, 300},
+		AccountPenaltyIncome:       {0, 200},
+		AccountCustomerOverpayment: {0, 500},
+		AccountChannelFeeExpense:   {150, 0},
+	}, lineMap(p))
+
+	// 线下回款记现金
+	tx.CollectChannelID, tx.CollectFee = 0, 0
+	p = Repayment(tx)
+	require.NoError(t, p.Validate())
+	assert.Equal(t, [2]int64{12000, 0}, lineMap(p)[AccountCash])
+	assert.NotContains(t, lineMap(p), AccountChannelClearing)
+}
+
+func TestReversal(t *testing.T) {
+	originalID := uint64(11)
+	now := time.Now()
+	rv := &model.LoanRepaymentTransactions{
+		CollectChannelID: 3,
+		CollectOrderNo:   "RV1",
+		PayAmount:        -11500,
+		PaidAt:           &now,
+		AllocPrincipal:   -10000,
+		AllocInterest:    -1000,
+		AllocFee:         -300,
+		AllocPenalty:     -200,
+		Status:           model.TransactionStatusReversal,
+		ReversalOfID:     &originalID,
+	}
+	rv.ID = 12
+
+	p := Repayment(rv)
+	require.NoError(t, p.Validate())
+	assert.Equal(t, EventReversal, p.EventType)
+	assert.Equal(t, "REVERSAL-12", p.JournalNo())
+	assert.Contains(t, p.Memo, "原流水11")
+	assert.Equal(t, map[Account][2]int64{
+		AccountChannelClearing: {0, 11500},
+		AccountLoanReceivable:  {10000, 0},
+		AccountInterestIncome:  {1000, 0},
+		AccountFeeIncome:       {300, 0},
+		AccountPenaltyIncome:   {200, 0},
+	}, lineMap(p))
+}
+
+func TestWaivers(t *testing.T) {
+	at := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+
+	r := &model.LoanWaiverRequests{ScheduleID: 9, Component: "fee", Amount: 300}
+	r.ID = 4
+	p := WaiverRequest(r, at)
+	require.NoError(t, p.Validate())
+	assert.Equal(t, "WAIVER-4", p.JournalNo())
+	assert.Equal(t, at, p.PostedAt)
+	assert.Equal(t, map[Account][2]int64{
+		AccountWaiverExpense: {300, 0},
+		AccountFeeIncome:     {0, 300},
+	}, lineMap(p))
+
+	e := &model.LoanScheduleExtensions{ScheduleID: 9, ExtensionNo: 1, PenaltyWaived: 150}
+	e.ID = 2
+	p = ExtensionWaiver(e, at)
+	require.NoError(t, p.Validate())
+	assert.Equal(t, "EXTENSION_WAIVER-2", p.JournalNo())
+	assert.Equal(t, map[Account][2]int64{
+		AccountWaiverExpense: {150, 0},
+		AccountPenaltyIncome: {0, 150},
+	}, lineMap(p))
+
+	p = PayoffDiscount(21, at, Waived{Interest: 80, Fee: 150})
+	require.NoError(t, p.Validate())
+	assert.Equal(t, "PAYOFF_DISCOUNT-21", p.JournalNo())
+	assert.Equal(t, RefTransaction, p.RefType)
+	assert.Equal(t, map[Account][2]int64{
+		AccountWaiverExpense:  {230, 0},
+		AccountInterestIncome: {0, 80},
+		AccountFeeIncome:      {0, 150},
+	}, lineMap(p))
+
+	// 没有减免金额时凭证为空，调用方不应过账
+	p = PayoffDiscount(21, at, Waived{})
+	assert.ErrorIs(t, p.Validate(), ErrEmptyPosting)
+}
+
+// 提前结清 -> 冲正 -> 再次提前结清，每次过账的凭证号都不能重复(uk_journal_line)
+func TestPayoffReversePayoff(t *testing.T) {
+	at := time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC)
+	originalID := uint64(21)
+	payoff := func(id uint64) *model.LoanRepaymentTransactions {
+		tx := &model.LoanRepaymentTransactions{
+			CollectChannelID: 3,
+			PayAmount:        10770,
+			PaidAt:           &at,
+			AllocPrincipal:   10000,
+			AllocInterest:    620,
+			AllocFee:         150,
+			Status:           model.TransactionStatusSuccess,
+		}
+		tx.ID = id
+		return tx
+	}
+	rv := &model.LoanRepaymentTransactions{
+		CollectChannelID: 3,
+		PayAmount:        -10770,
+		PaidAt:           &at,
+		AllocPrincipal:   -10000,
+		AllocInterest:    -620,
+		AllocFee:         -150,
+		Status:           model.TransactionStatusReversal,
+		ReversalOfID:     &originalID,
+	}
+	rv.ID = 22
+	discount := Waived{Interest: 80, Fee: 150}
+
+	postings := []Posting{
+		Repayment(payoff(21)),
+		PayoffDiscount(21, at, discount),
+		Repayment(rv),
+		Repayment(payoff(23)),
+		PayoffDiscount(23, at, discount),
+	}
+	seen := map[string]bool{}
+	for _, p := range postings {
+		require.NoError(t, p.Validate())
+		assert.False(t, seen[p.JournalNo()], "duplicate journal no %s", p.JournalNo())
+		seen[p.JournalNo()] = true
+	}
+	assert.Contains(t, seen, "PAYOFF_DISCOUNT-21")
+	assert.Contains(t, seen, "PAYOFF_DISCOUNT-23")
+}
+
+func TestValidate(t *testing.T) {
+	p := Posting{EventType: EventRepayment, RefID: 1}
+	assert.ErrorIs(t, p.Validate(), ErrEmptyPosting)
+
+	p.Lines = []Line{{Account: AccountCash, Debit: 100}, {Account: AccountFeeIncome, Credit: 90}}
+	assert.ErrorIs(t, p.Validate(), ErrUnbalanced)
+
+	p.Lines = []Line{{Account: AccountCash, Debit: 100, Credit: 100}}
+	assert.ErrorIs(t, p.Validate(), ErrInvalidLine)
+
+	p.Lines = []Line{{Account: "UNKNOWN", Debit: 100}, {Account: AccountFeeIncome, Credit: 100}}
+	assert.ErrorIs(t, p.Validate(), ErrInvalidLine)
+}
+
+func TestEntries(t *testing.T) {
+	at := time.Now()
+	p := Posting{
+		EventType: EventRepayment,
+		RefType:   RefTransaction,
+		RefID:     5,
+		PostedAt:  at,
+		Lines:     []Line{{Account: AccountCash, Debit: 100}, {Account: AccountFeeIncome, Credit: 100}},
+	}
+	entries := p.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "REPAYMENT-5", entries[1].JournalNo)
+	assert.Equal(t, 2, entries[1].LineNo)
+	assert.Equal(t, "FEE_INCOME", entries[1].Account)
+	assert.Equal(t, int64(100), entries[1].Credit)
+	assert.Equal(t, at, entries[1].PostedAt)
+}
+
+func TestBuildTrialBalance(t *testing.T) {
+	tb := BuildTrialBalance([]*model.LoanLedgerAccountSums{
+		{Account: "LOAN_RECEIVABLE", Debit: 100000, Credit: 10000},
+		{Account: "CHANNEL_CLEARING", Debit: 11500, Credit: 90000},
+		{Account: "FEE_INCOME", Credit: 10000},
+		{Account: "INTEREST_INCOME", Credit: 1500},
+	})
+	assert.True(t, tb.Balanced)
+	assert.Equal(t, int64(111500), tb.TotalDebit)
+	assert.Len(t, tb.Accounts, len(Accounts))
+
+	byCode := map[Account]Balance{}
+	for _, b := range tb.Accounts {
+		byCode[b.Code] = b
+	}
+	assert.Equal(t, int64(90000), byCode[AccountLoanReceivable].Balance)
+	assert.Equal(t, int64(-78500), byCode[AccountChannelClearing].Balance)
+	assert.Equal(t, int64(1500), byCode[AccountInterestIncome].Balance)
+	assert.Equal(t, int64(0), byCode[AccountCash].Balance)
+
+	tb = BuildTrialBalance([]*model.LoanLedgerAccountSums{{Account: "LEGACY", Debit: 1}})
+	assert.False(t, tb.Balanced)
+	assert.Equal(t, Account("LEGACY"), tb.Accounts[len(tb.Accounts)-1].Code)
+}
+
+func TestParsePeriod(t *testing.T) {
+	from, to, err := ParsePeriod("", "")
+	require.NoError(t, err)
+	assert.Nil(t, from)
+	assert.Nil(t, to)
+
+	from, to, err = ParsePeriod("2026-03-01", "2026-03-01")
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, to.Sub(*from))
+
+	_, _, err = ParsePeriod("2026-03-02", "2026-03-01")
+	assert.Error(t, err)
+	_, _, err = ParsePeriod("03/01/2026", "")
+	assert.Error(t, err)
+}
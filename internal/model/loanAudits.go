@@ -1,18 +1,24 @@
 package model
 
 import (
+	"time"
+
 	"github.com/go-dev-frame/sponge/pkg/sgorm"
 )
 
-// LoanAudits 申请审核记录表(审核时间即 created_at)
+// LoanAudits 申请审核记录表(审核时间即 created_at，与 decided_at 一致)
 type LoanAudits struct {
 	sgorm.Model `gorm:"embedded"` // embed id and time
 
-	BaseinfoID    uint64 `gorm:"column:baseinfo_id;type:int(11);not null" json:"baseinfoID"`           // 关联申请单 loan_baseinfo.id
-	AuditResult   int    `gorm:"column:audit_result;type:tinyint(4);not null" json:"auditResult"`      // 审核结果：1通过 -1拒绝
-	AuditComment  string `gorm:"column:audit_comment;type:varchar(255)" json:"auditComment"`           // 审核备注/原因
-	AuditorUserID uint64 `gorm:"column:auditor_user_id;type:bigint(20);not null" json:"auditorUserID"` // 审核人员(loan_users.id)
-	AuditType     int    `gorm:"column:audit_type;type:tinyint(4);not null" json:"auditType"`          // '审核类型(初审0、放款审核1、回款审核2)',
+	BaseinfoID    uint64     `gorm:"column:baseinfo_id;type:int(11);not null" json:"baseinfoID"`           // 关联申请单 loan_baseinfo.id
+	AuditResult   int        `gorm:"column:audit_result;type:tinyint(4);not null" json:"auditResult"`      // 审核结果：1通过 -1拒绝
+	AuditComment  string     `gorm:"column:audit_comment;type:varchar(255)" json:"auditComment"`           // 审核备注/原因
+	AuditorUserID uint64     `gorm:"column:auditor_user_id;type:bigint(20);not null" json:"auditorUserID"` // 审核人员(loan_users.id)
+	AuditType     int        `gorm:"column:audit_type;type:tinyint(4);not null" json:"auditType"`          // 审批节点序号 loan_workflow_stages.stage_no(初审1、放款审核2)
+	StageCode     string     `gorm:"column:stage_code;type:varchar(32)" json:"stageCode"`                  // 审批节点代码 loan_workflow_stages.code
+	ReceivedAt    *time.Time `gorm:"column:received_at;type:datetime" json:"receivedAt"`                   // 节点开始待审时间
+	ClaimedAt     *time.Time `gorm:"column:claimed_at;type:datetime" json:"claimedAt"`                     // 审批人领取时间(未经工作队列领取时为空)
+	DecidedAt     *time.Time `gorm:"column:decided_at;type:datetime" json:"decidedAt"`                     // 审批决定时间
 
 	// 新增字段：审核人员真实姓名（非数据库字段，仅用于返回）
 	AuditorName string `gorm:"-" json:"auditorName"` // gorm:"-" 表示不映射数据库字段
@@ -26,7 +32,21 @@ var LoanAuditsColumnNames = map[string]bool{
 	"deleted_at":      true,
 	"baseinfo_id":     true,
 	"audit_type":      true,
+	"stage_code":      true,
 	"audit_result":    true,
 	"audit_comment":   true,
 	"auditor_user_id": true,
+	"decided_at":      true,
 }
+
+// 审核结果
+const (
+	AuditResultPass   = 1  // 通过
+	AuditResultReject = -1 // 拒绝
+)
+
+// 系统自动审批(自动预审)的审核人员，loan_users 中没有对应用户
+const (
+	SystemAuditorUserID = 0
+	SystemAuditorName   = "系统"
+)
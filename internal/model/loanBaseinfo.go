@@ -30,12 +30,21 @@ type LoanBaseinfo struct {
 	HasCar            int        `gorm:"column:has_car;type:tinyint(4)" json:"hasCar"` // 是否有車
 	CarCertificate    string     `gorm:"column:car_certificate;type:varchar(255)" json:"carCertificate"`
 	ApplicationAmount int64      `gorm:"column:application_amount;type:bigint(20)" json:"applicationAmount"` // 申請金額 单位：分
-	AuditStatus       int        `gorm:"column:audit_status;type:tinyint(4);default:0" json:"auditStatus"`   // 審核情況 0待審核 1審核通過 -1 審核拒絕
+	AuditStatus       int        `gorm:"column:audit_status;type:tinyint(4);default:0" json:"auditStatus"`   // 審核情況 0待審核 N已通過第N個審批節點(loan_workflow_stages.stage_no) -1 審核拒絕
 	BankNo            string     `gorm:"column:bank_no;type:varchar(255)" json:"bankNo"`                     // 銀行卡號
 	ClientIP          string     `gorm:"column:client_ip;type:varbinary(16)" json:"clientIP"`                // 客户端IP地址(IPv4/IPv6)
 	ReferrerUserID    *int64     `gorm:"column:referrer_user_id;type:bigint(20)" json:"referrerUserID"`      // 邀请人/分享人(loan_users.id)
 	RefCode           string     `gorm:"column:ref_code;type:varchar(32)" json:"refCode"`                    // 访问时携带的ref(冗余存储便于排查)
 	LoanDays          int        `gorm:"column:loan_days;type:smallint(6);not null" json:"loanDays"`         // 借款天数(单位：天)
+	ProductID         uint64     `gorm:"column:product_id;type:bigint(20);default:0" json:"productID"`       // 贷款产品 loan_products.id(0表示未关联产品)
+	ClaimedBy         *uint64    `gorm:"column:claimed_by;type:bigint(20)" json:"claimedBy"`                 // 领取审批的人员 loan_users.id(审批工作队列)
+	ClaimedAt         *time.Time `gorm:"column:claimed_at;type:datetime" json:"claimedAt"`                   // 领取时间
+	ClaimExpiresAt    *time.Time `gorm:"column:claim_expires_at;type:datetime" json:"claimExpiresAt"`        // 领取租约到期时间，过期后其他人员可重新领取
+	StageReceivedAt   *time.Time `gorm:"column:stage_received_at;type:datetime" json:"stageReceivedAt"`      // 当前审批节点开始待审的时间(为空表示申请提交时间)
+	CreditScore       *int       `gorm:"column:credit_score;type:int(11)" json:"creditScore"`                // 信用分(为空表示未评分)
+	ScorecardID       uint64     `gorm:"column:scorecard_id;type:bigint(20);default:0" json:"scorecardID"`   // 评分使用的评分卡版本 loan_scorecards.id(0表示未评分)
+	ScoreBreakdown    string     `gorm:"column:score_breakdown;type:text" json:"-"`                          // 评分明细 JSON(各特征的值、分箱与得分)
+	ScoredAt          *time.Time `gorm:"column:scored_at;type:datetime" json:"scoredAt"`                     // 评分时间
 	RiskListStatus    int        `gorm:"-" json:"riskListStatus"`                                            // 名单状态：0正常 1白名单 2黑名单
 	RiskListReason    string     `gorm:"-" json:"riskListReason"`                                            // 名单原因/来源说明
 	RiskListMarkedAt  *time.Time `gorm:"-" json:"riskListMarkedAt"`                                          // 名单标记时间
@@ -77,6 +86,10 @@ var LoanBaseinfoColumnNames = map[string]bool{
 	"marital_status":     true,
 	"has_house":          true,
 	"has_car":            true,
+	"claimed_by":         true,
+	"claim_expires_at":   true,
+	"credit_score":       true,
+	"scorecard_id":       true,
 	"application_amount": true,
 	"audit_status":       true,
 	"bank_no":            true,
@@ -84,4 +97,5 @@ var LoanBaseinfoColumnNames = map[string]bool{
 	"referrer_user_id":   true,
 	"ref_code":           true,
 	"loan_days":          true,
+	"product_id":         true,
 }
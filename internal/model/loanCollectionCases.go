@@ -32,3 +32,11 @@ var LoanCollectionCasesColumnNames = map[string]bool{
 	"completed_at":        true,
 	"completed_note":      true,
 }
+
+// 催收任务状态
+const (
+	CollectionCaseStatusPending   = 0 // 待处理
+	CollectionCaseStatusFollowing = 1 // 跟进中
+	CollectionCaseStatusCompleted = 2 // 已完成
+	CollectionCaseStatusCancelled = 3 // 已取消
+)
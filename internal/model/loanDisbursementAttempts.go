@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+)
+
+// LoanDisbursementAttempts 放款提交记录表(放款单每次提交代付渠道一条，记录渠道、单号及结果)
+type LoanDisbursementAttempts struct {
+	sgorm.Model `gorm:"embedded"` // embed id and time
+
+	DisbursementID  uint64     `gorm:"column:disbursement_id;type:bigint(20);not null" json:"disbursementID"`     // 关联放款单 loan_disbursements.id
+	AttemptNo       int        `gorm:"column:attempt_no;type:int(11);not null" json:"attemptNo"`                  // 第几次提交(从1开始)
+	PayoutChannelID uint64     `gorm:"column:payout_channel_id;type:bigint(20);not null" json:"payoutChannelID"`  // 本次使用的代付渠道 loan_payment_channels.id
+	MerchantOrderNo string     `gorm:"column:merchant_order_no;type:varchar(64);not null" json:"merchantOrderNo"` // 本次提交的商户单号(唯一)
+	PayoutOrderNo   string     `gorm:"column:payout_order_no;type:varchar(128)" json:"payoutOrderNo"`             // 渠道代付单号
+	Amount          int64      `gorm:"column:amount;type:bigint(20);not null" json:"amount"`                      // 代付金额(分)
+	Status          int        `gorm:"column:status;type:tinyint(4);default:0;not null" json:"status"`            // 0处理中 1成功 2失败 3已撤销
+	FailReason      string     `gorm:"column:fail_reason;type:varchar(255)" json:"failReason"`                    // 失败/撤销原因
+	SubmittedAt     *time.Time `gorm:"column:submitted_at;type:datetime" json:"submittedAt"`                      // 提交时间
+	FinishedAt      *time.Time `gorm:"column:finished_at;type:datetime" json:"finishedAt"`                        // 终态时间
+	OperatorUserID  uint64     `gorm:"column:operator_user_id;type:bigint(20);default:0" json:"operatorUserID"`   // 触发本次提交的操作人(首次提交为审核人，重试为重试操作人)
+}
+
+// 放款提交记录状态(loan_disbursement_attempts.status)
+const (
+	DisbursementAttemptProcessing = 0 // 处理中
+	DisbursementAttemptSuccess    = 1 // 成功
+	DisbursementAttemptFailed     = 2 // 失败
+	DisbursementAttemptCancelled  = 3 // 已撤销
+)
+
+// LoanDisbursementAttemptsColumnNames Whitelist for custom query fields to prevent sql injection attacks
+var LoanDisbursementAttemptsColumnNames = map[string]bool{
+	"id":                true,
+	"created_at":        true,
+	"updated_at":        true,
+	"deleted_at":        true,
+	"disbursement_id":   true,
+	"attempt_no":        true,
+	"payout_channel_id": true,
+	"merchant_order_no": true,
+	"payout_order_no":   true,
+	"amount":            true,
+	"status":            true,
+	"fail_reason":       true,
+	"submitted_at":      true,
+	"finished_at":       true,
+	"operator_user_id":  true,
+}
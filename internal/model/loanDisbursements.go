@@ -6,22 +6,49 @@ import (
 	"github.com/go-dev-frame/sponge/pkg/sgorm"
 )
 
-// LoanDisbursements 放款单/待放款任务表(审核通过后生成，状态待放款->已放款)
+// LoanDisbursements 放款单/待放款任务表(审核通过后生成，状态待放款->已提交渠道->已放款/放款失败，失败可重试或取消)
 type LoanDisbursements struct {
 	sgorm.Model `gorm:"embedded"` // embed id and time
 
 	BaseinfoID           uint64     `gorm:"column:baseinfo_id;type:int(11);not null" json:"baseinfoID"`                 // 关联申请单 loan_baseinfo.id
 	DisburseAmount       int64      `gorm:"column:disburse_amount;type:bigint(20);not null" json:"disburseAmount"`      // 放款金额(单位按你的系统：元/分，建议统一)
 	NetAmount            int64      `gorm:"column:net_amount;type:bigint(20);not null" json:"netAmount"`                // 到账金额(扣除费用后实际到账)
-	Status               int        `gorm:"column:status;type:tinyint(4);default:0;not null" json:"status"`             // 放款状态：0待放款 1已放款
+	PayoutFee            int64      `gorm:"column:payout_fee;type:bigint(20);default:0;not null" json:"payoutFee"`      // 代付渠道手续费(分，费率+固定费，已从到账金额中扣除)
+	Status               int        `gorm:"column:status;type:tinyint(4);default:0;not null" json:"status"`             // 放款状态：0待放款 1已放款 2已提交渠道 3放款失败 4待重试 5已取消
 	SourceReferrerUserID *int64     `gorm:"column:source_referrer_user_id;type:bigint(20)" json:"sourceReferrerUserID"` // 用户来源(分享人 loan_users.id，冗余快照，便于查询)
 	AuditorUserID        uint64     `gorm:"column:auditor_user_id;type:bigint(20)" json:"auditorUserID"`                // 审核人员(loan_users.id)
 	AuditedAt            *time.Time `gorm:"column:audited_at;type:datetime" json:"auditedAt"`                           // 审核通过时间
 	PayoutChannelID      uint64     `gorm:"column:payout_channel_id;type:bigint(20)" json:"payoutChannelID"`            // 放款渠道(代付) loan_payment_channels.id
-	PayoutOrderNo        string     `gorm:"column:payout_order_no;type:varchar(128)" json:"payoutOrderNo"`              // 放款订单号/三方代付单号
+	PayoutOrderNo        string     `gorm:"column:payout_order_no;type:varchar(128)" json:"payoutOrderNo"`              // 放款订单号/三方代付单号(渠道受理后回填)
+	RouteMode            string     `gorm:"column:route_mode;type:varchar(16)" json:"routeMode"`                        // 放款渠道选择方式：AUTO自动路由 MANUAL人工指定
+	RouteReason          string     `gorm:"column:route_reason;type:varchar(255)" json:"routeReason"`                   // 渠道选择原因(选中渠道、跳过的渠道及原因、失败切换记录)
 	DisbursedAt          *time.Time `gorm:"column:disbursed_at;type:datetime" json:"disbursedAt"`                       // 放款时间
+	MerchantOrderNo      string     `gorm:"column:merchant_order_no;type:varchar(64)" json:"merchantOrderNo"`           // 平台代付商户单号(提交/查询渠道用，唯一)
+	SubmittedAt          *time.Time `gorm:"column:submitted_at;type:datetime" json:"submittedAt"`                       // 提交渠道时间
+	FailReason           string     `gorm:"column:fail_reason;type:varchar(255)" json:"failReason"`                     // 放款失败原因
+	AttemptCount         int        `gorm:"column:attempt_count;type:int(11);default:0;not null" json:"attemptCount"`   // 已提交渠道次数
+	RetryBy              uint64     `gorm:"column:retry_by;type:bigint(20)" json:"retryBy"`                             // 最近一次发起重试的操作人(loan_users.id)
+	CancelReason         string     `gorm:"column:cancel_reason;type:varchar(255)" json:"cancelReason"`                 // 取消原因
+	CancelledBy          uint64     `gorm:"column:cancelled_by;type:bigint(20)" json:"cancelledBy"`                     // 取消人(loan_users.id)
+	CancelledAt          *time.Time `gorm:"column:cancelled_at;type:datetime" json:"cancelledAt"`                       // 取消时间
 }
 
+// 放款状态(loan_disbursements.status)，1 沿用原"已放款"含义
+const (
+	DisbursementStatusPending   = 0 // 待放款(审核通过，等待提交渠道)
+	DisbursementStatusSuccess   = 1 // 已放款
+	DisbursementStatusSubmitted = 2 // 已提交渠道，等待渠道结果
+	DisbursementStatusFailed    = 3 // 放款失败，可重试或取消
+	DisbursementStatusRetrying  = 4 // 待重试(已生成新的商户单号，等待重新提交渠道)
+	DisbursementStatusCancelled = 5 // 已取消(终态)
+)
+
+// 放款渠道选择方式(loan_disbursements.route_mode)
+const (
+	DisbursementRouteAuto   = "AUTO"   // 自动路由，放款失败时自动切换到下一个可用渠道
+	DisbursementRouteManual = "MANUAL" // 人工指定渠道
+)
+
 // LoanDisbursementsColumnNames Whitelist for custom query fields to prevent sql injection attacks
 var LoanDisbursementsColumnNames = map[string]bool{
 	"id":                      true,
@@ -31,11 +58,22 @@ var LoanDisbursementsColumnNames = map[string]bool{
 	"baseinfo_id":             true,
 	"disburse_amount":         true,
 	"net_amount":              true,
+	"payout_fee":              true,
 	"status":                  true,
 	"source_referrer_user_id": true,
 	"auditor_user_id":         true,
 	"audited_at":              true,
 	"payout_channel_id":       true,
 	"payout_order_no":         true,
+	"route_mode":              true,
+	"route_reason":            true,
 	"disbursed_at":            true,
+	"merchant_order_no":       true,
+	"submitted_at":            true,
+	"fail_reason":             true,
+	"attempt_count":           true,
+	"retry_by":                true,
+	"cancel_reason":           true,
+	"cancelled_by":            true,
+	"cancelled_at":            true,
 }
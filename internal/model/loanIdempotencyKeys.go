@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+)
+
+// LoanIdempotencyKeys 幂等键表(同一用户同一接口同一 Idempotency-Key 只执行一次，重复请求回放首次响应)
+type LoanIdempotencyKeys struct {
+	sgorm.Model `gorm:"embedded"` // embed id and time
+
+	UserID       uint64     `gorm:"column:user_id;type:bigint(20);not null" json:"userID"`                // 发起请求的用户 loan_users.id
+	Scope        string     `gorm:"column:scope;type:varchar(128);not null" json:"scope"`                 // 作用域: 请求方法+路由模板，如 POST /api/v1/repayment-transaction/
+	IdemKey      string     `gorm:"column:idem_key;type:varchar(128);not null" json:"idemKey"`            // 客户端传入的 Idempotency-Key
+	RequestHash  string     `gorm:"column:request_hash;type:char(64);not null" json:"requestHash"`        // 请求体摘要(sha256)，同键不同请求体视为误用
+	Status       int        `gorm:"column:status;type:tinyint(4);default:0;not null" json:"status"`       // 0处理中 1已完成
+	HTTPStatus   int        `gorm:"column:http_status;type:int(11);default:0;not null" json:"httpStatus"` // 首次响应的 http 状态码
+	ResponseBody string     `gorm:"column:response_body;type:mediumtext" json:"responseBody"`             // 首次响应体，用于回放
+	ExpiresAt    *time.Time `gorm:"column:expires_at;type:datetime;not null" json:"expiresAt"`            // 过期时间，过期后同键可重新执行
+}
+
+// 幂等键状态(loan_idempotency_keys.status)
+const (
+	IdempotencyStatusProcessing = 0 // 处理中
+	IdempotencyStatusCompleted  = 1 // 已完成，可回放
+)
+
+// LoanIdempotencyKeysColumnNames Whitelist for custom query fields to prevent sql injection attacks
+var LoanIdempotencyKeysColumnNames = map[string]bool{
+	"id":            true,
+	"created_at":    true,
+	"updated_at":    true,
+	"deleted_at":    true,
+	"user_id":       true,
+	"scope":         true,
+	"idem_key":      true,
+	"request_hash":  true,
+	"status":        true,
+	"http_status":   true,
+	"response_body": true,
+	"expires_at":    true,
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+)
+
+// LoanLedgerEntries 总账分录表(只追加不修改，同一凭证号下借方合计等于贷方合计)
+type LoanLedgerEntries struct {
+	sgorm.Model `gorm:"embedded"` // embed id and time
+
+	JournalNo string    `gorm:"column:journal_no;type:varchar(40);not null" json:"journalNo"` // 凭证号(按业务类型+单据id生成，同一笔业务的分录共用)
+	LineNo    int       `gorm:"column:line_no;type:int(11);not null" json:"lineNo"`           // 凭证内行号(从1开始)
+	EventType string    `gorm:"column:event_type;type:varchar(32);not null" json:"eventType"` // 业务类型：DISBURSEMENT放款 REPAYMENT回款 REVERSAL冲正 WAIVER减免 EXTENSION_WAIVER展期免罚息 PAYOFF_DISCOUNT提前结清减免
+	RefType   string    `gorm:"column:ref_type;type:varchar(64);not null" json:"refType"`     // 业务单据表名
+	RefID     uint64    `gorm:"column:ref_id;type:bigint(20);not null" json:"refID"`          // 业务单据id
+	Account   string    `gorm:"column:account;type:varchar(32);not null" json:"account"`      // 科目代码
+	Debit     int64     `gorm:"column:debit;type:bigint(20);default:0" json:"debit"`          // 借方金额(分)
+	Credit    int64     `gorm:"column:credit;type:bigint(20);default:0" json:"credit"`        // 贷方金额(分)
+	PostedAt  time.Time `gorm:"column:posted_at;type:datetime;not null" json:"postedAt"`      // 记账时间(业务发生时间)
+	Memo      string    `gorm:"column:memo;type:varchar(255)" json:"memo"`                    // 摘要
+}
+
+// LoanLedgerAccountSums 按科目汇总的借贷发生额(查询结果，非数据库表)
+type LoanLedgerAccountSums struct {
+	Account string `gorm:"column:account" json:"account"`
+	Debit   int64  `gorm:"column:debit" json:"debit"`
+	Credit  int64  `gorm:"column:credit" json:"credit"`
+}
+
+// LoanLedgerEntriesColumnNames Whitelist for custom query fields to prevent sql injection attacks
+var LoanLedgerEntriesColumnNames = map[string]bool{
+	"id":         true,
+	"created_at": true,
+	"updated_at": true,
+	"deleted_at": true,
+	"journal_no": true,
+	"line_no":    true,
+	"event_type": true,
+	"ref_type":   true,
+	"ref_id":     true,
+	"account":    true,
+	"debit":      true,
+	"credit":     true,
+	"posted_at":  true,
+	"memo":       true,
+}
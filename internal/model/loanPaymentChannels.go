@@ -24,6 +24,10 @@ type LoanPaymentChannels struct {
 	PayoutMaxAmount  int    `gorm:"column:payout_max_amount;type:int(11)" json:"payoutMaxAmount"`            // 最大代付金额(分)
 	SettlementCycle  string `gorm:"column:settlement_cycle;type:varchar(32)" json:"settlementCycle"`         // 结算周期(如 T0/T1/D1/W1/M1，可按你们渠道定义)
 	SettlementDesc   string `gorm:"column:settlement_desc;type:varchar(255)" json:"settlementDesc"`          // 结算说明/备注
+	CallbackKeyEnc   []byte `gorm:"column:callback_key_enc;type:varbinary(255)" json:"-"`                    // 回调验签密钥(AES-GCM 加密存储，不对外返回)
+	RoutePriority    int    `gorm:"column:route_priority;type:int(11)" json:"routePriority"`                 // 自动路由优先级(越小越优先)
+	RouteWeight      int    `gorm:"column:route_weight;type:int(11);default:1;not null" json:"routeWeight"`  // 自动路由权重(同优先级内按权重随机，<=0按1)
+	PayoutDailyCap   int64  `gorm:"column:payout_daily_cap;type:bigint(20)" json:"payoutDailyCap"`           // 当日代付额度(分)，0不限
 }
 
 // LoanPaymentChannelsColumnNames Whitelist for custom query fields to prevent sql injection attacks
@@ -48,4 +52,8 @@ var LoanPaymentChannelsColumnNames = map[string]bool{
 	"payout_max_amount":  true,
 	"settlement_cycle":   true,
 	"settlement_desc":    true,
+	"callback_key_enc":   true,
+	"route_priority":     true,
+	"route_weight":       true,
+	"payout_daily_cap":   true,
 }
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+)
+
+// LoanPenaltyAccruals 罚息计提流水表(同一期次每个计提日最多一条，用于审计和重算罚息)
+type LoanPenaltyAccruals struct {
+	sgorm.Model `gorm:"embedded"` // embed id and time
+
+	ScheduleID    uint64     `gorm:"column:schedule_id;type:bigint(20);not null" json:"scheduleID"`       // 关联期次 loan_repayment_schedules.id
+	AccrualDate   *time.Time `gorm:"column:accrual_date;type:date;not null" json:"accrualDate"`           // 计提日期
+	OverdueDays   int        `gorm:"column:overdue_days;type:int(11);not null" json:"overdueDays"`        // 计提日的逾期天数
+	BaseAmount    int64      `gorm:"column:base_amount;type:bigint(20);not null" json:"baseAmount"`       // 计提基数=剩余未还本金(分)
+	DailyRateBp   int        `gorm:"column:daily_rate_bp;type:int(11);not null" json:"dailyRateBp"`       // 日罚息率(万分比)
+	Amount        int64      `gorm:"column:amount;type:bigint(20);not null" json:"amount"`                // 本次计提罚息(分，已按上限截断)
+	PenaltyBefore int64      `gorm:"column:penalty_before;type:bigint(20);not null" json:"penaltyBefore"` // 计提前期次应还罚息(分)
+	PenaltyAfter  int64      `gorm:"column:penalty_after;type:bigint(20);not null" json:"penaltyAfter"`   // 计提后期次应还罚息(分)
+}
+
+// LoanPenaltyAccrualsColumnNames Whitelist for custom query fields to prevent sql injection attacks
+var LoanPenaltyAccrualsColumnNames = map[string]bool{
+	"id":             true,
+	"created_at":     true,
+	"updated_at":     true,
+	"deleted_at":     true,
+	"schedule_id":    true,
+	"accrual_date":   true,
+	"overdue_days":   true,
+	"base_amount":    true,
+	"daily_rate_bp":  true,
+	"amount":         true,
+	"penalty_before": true,
+	"penalty_after":  true,
+}
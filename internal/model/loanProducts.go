@@ -0,0 +1,77 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+)
+
+// LoanProducts 贷款产品表(期限、期数、利率、费用模式及还款方式)
+type LoanProducts struct {
+	sgorm.Model `gorm:"embedded"` // embed id and time
+
+	Code               string     `gorm:"column:code;type:varchar(32);not null" json:"code"`                                      // 产品编码(唯一)
+	Name               string     `gorm:"column:name;type:varchar(64);not null" json:"name"`                                      // 产品名称
+	Status             int        `gorm:"column:status;type:tinyint(4);default:1;not null" json:"status"`                         // 状态：1上架 0下架
+	RepaymentMethod    string     `gorm:"column:repayment_method;type:varchar(32);not null" json:"repaymentMethod"`               // 还款方式：BULLET到期一次还本付息 EQUAL_INSTALLMENT等额本息 EQUAL_PRINCIPAL等额本金
+	InstallmentCount   int        `gorm:"column:installment_count;type:int(11);default:1;not null" json:"installmentCount"`       // 期数
+	InstallmentDays    int        `gorm:"column:installment_days;type:int(11);default:0;not null" json:"installmentDays"`         // 每期天数，0表示按借款天数/期数平分
+	InterestRateBp     int        `gorm:"column:interest_rate_bp;type:int(11);default:0;not null" json:"interestRateBp"`          // 日利率(万分比)
+	FeeModel           string     `gorm:"column:fee_model;type:varchar(32);not null" json:"feeModel"`                             // 服务费收取方式：NONE不收 UPFRONT放款时扣除 INSTALLMENT按期收取
+	ServiceFeeBp       int        `gorm:"column:service_fee_bp;type:int(11);default:0;not null" json:"serviceFeeBp"`              // 服务费率(占本金万分比)
+	ServiceFeeFixed    int64      `gorm:"column:service_fee_fixed;type:bigint(20);default:0;not null" json:"serviceFeeFixed"`     // 固定服务费(分)
+	MinAmount          int64      `gorm:"column:min_amount;type:bigint(20);default:0;not null" json:"minAmount"`                  // 最低申请金额(分)，0不限
+	MaxAmount          int64      `gorm:"column:max_amount;type:bigint(20);default:0;not null" json:"maxAmount"`                  // 最高申请金额(分)，0不限
+	AllowedDays        string     `gorm:"column:allowed_days;type:varchar(64)" json:"allowedDays"`                                // 可选借款天数，逗号分隔如 7,14,30，空不限
+	PenaltyDailyRateBp int        `gorm:"column:penalty_daily_rate_bp;type:int(11);default:0;not null" json:"penaltyDailyRateBp"` // 逾期日罚息率(万分比)，0使用系统设置
+	AllocationOrder    string     `gorm:"column:allocation_order;type:varchar(64)" json:"allocationOrder"`                        // 还款冲销顺序，空使用系统设置
+	PayoffFeeBp        int        `gorm:"column:payoff_fee_bp;type:int(11);default:0;not null" json:"payoffFeeBp"`                // 提前结清手续费率(占剩余本金万分比)
+	PayoffFeeFixed     int64      `gorm:"column:payoff_fee_fixed;type:bigint(20);default:0" json:"payoffFeeFixed"`                // 提前结清固定手续费(分)
+	PayoffDiscountBp   int        `gorm:"column:payoff_discount_bp;type:int(11);default:0" json:"payoffDiscountBp"`               // 提前结清减免比例(占已计利息及费用万分比)
+	ActiveFrom         *time.Time `gorm:"column:active_from;type:datetime" json:"activeFrom"`                                     // 生效开始时间，空不限
+	ActiveTo           *time.Time `gorm:"column:active_to;type:datetime" json:"activeTo"`                                         // 生效结束时间，空不限
+	Remark             string     `gorm:"column:remark;type:varchar(255)" json:"remark"`                                          // 备注
+}
+
+// 还款方式(loan_products.repayment_method)
+const (
+	RepaymentMethodBullet           = "BULLET"            // 到期一次还本付息
+	RepaymentMethodEqualInstallment = "EQUAL_INSTALLMENT" // 等额本息
+	RepaymentMethodEqualPrincipal   = "EQUAL_PRINCIPAL"   // 等额本金
+)
+
+// 服务费收取方式(loan_products.fee_model)
+const (
+	FeeModelNone        = "NONE"        // 不收服务费
+	FeeModelUpfront     = "UPFRONT"     // 放款时从到账金额中扣除
+	FeeModelInstallment = "INSTALLMENT" // 平摊到各期应还费用
+)
+
+// LoanProductsColumnNames Whitelist for custom query fields to prevent sql injection attacks
+var LoanProductsColumnNames = map[string]bool{
+	"id":                    true,
+	"created_at":            true,
+	"updated_at":            true,
+	"deleted_at":            true,
+	"code":                  true,
+	"name":                  true,
+	"status":                true,
+	"repayment_method":      true,
+	"installment_count":     true,
+	"installment_days":      true,
+	"interest_rate_bp":      true,
+	"fee_model":             true,
+	"service_fee_bp":        true,
+	"service_fee_fixed":     true,
+	"min_amount":            true,
+	"max_amount":            true,
+	"allowed_days":          true,
+	"penalty_daily_rate_bp": true,
+	"allocation_order":      true,
+	"payoff_fee_bp":         true,
+	"payoff_fee_fixed":      true,
+	"payoff_discount_bp":    true,
+	"active_from":           true,
+	"active_to":             true,
+	"remark":                true,
+}
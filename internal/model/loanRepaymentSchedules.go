@@ -27,6 +27,13 @@ type LoanRepaymentSchedules struct {
 	SettledAt      *time.Time `gorm:"column:settled_at;type:datetime" json:"settledAt"`                            // 结清时间(本期还清时)
 }
 
+// 还款计划期次状态(loan_repayment_schedules.status)
+const (
+	ScheduleStatusUnpaid  = 0 // 未还清
+	ScheduleStatusSettled = 1 // 已还清
+	ScheduleStatusOverdue = 2 // 逾期
+)
+
 // LoanRepaymentSchedulesColumnNames Whitelist for custom query fields to prevent sql injection attacks
 var LoanRepaymentSchedulesColumnNames = map[string]bool{
 	"id":              true,
@@ -12,8 +12,9 @@ type LoanRepaymentTransactions struct {
 	ScheduleID       int64      `gorm:"column:schedule_id;type:bigint(20)" json:"scheduleID"`                         // 关联期次 loan_repayment_schedules.id(可空：先入账后分配/未分期)
 	CollectChannelID int64      `gorm:"column:collect_channel_id;type:bigint(20)" json:"collectChannelID"`            // 回款渠道(代收) loan_payment_channels.id
 	CollectOrderNo   string     `gorm:"column:collect_order_no;type:varchar(128)" json:"collectOrderNo"`              // 回款订单号/三方代收单号(商户单号)
-	PayRef           string     `gorm:"column:pay_ref;type:varchar(128)" json:"payRef"`                               // 支付渠道流水号/交易号(三方transaction id)
+	PayRef           string     `gorm:"column:pay_ref;type:varchar(128)" json:"payRef"`                               // 支付渠道流水号/交易号(三方transaction id)，同一渠道成功流水唯一
 	PayAmount        int        `gorm:"column:pay_amount;type:int(11);not null" json:"payAmount"`                     // 本次回款金额(分)
+	CollectFee       int        `gorm:"column:collect_fee;type:int(11);default:0;not null" json:"collectFee"`         // 代收渠道手续费(分，平台承担，不冲抵借款人应还)
 	PayMethod        string     `gorm:"column:pay_method;type:varchar(32)" json:"payMethod"`                          // 回款方式(如 BANK_TRANSFER/CARD/WALLET/CASH)
 	PaidAt           *time.Time `gorm:"column:paid_at;type:datetime;not null" json:"paidAt"`                          // 回款时间(交易成功时间)
 	AllocPrincipal   int        `gorm:"column:alloc_principal;type:int(11);default:0;not null" json:"allocPrincipal"` // 本次分配到本金(分)
@@ -24,8 +25,17 @@ type LoanRepaymentTransactions struct {
 	VoucherFileName  string     `gorm:"column:voucher_file_name;type:varchar(64)" json:"voucherFileName"`
 	Remark           string     `gorm:"column:remark;type:varchar(255)" json:"remark"` // 备注
 	CreatedBy        uint64     `gorm:"column:created_by;type:bigint(20)" json:"createdBy"`
+	ReversalOfID     *uint64    `gorm:"column:reversal_of_id;type:bigint(20)" json:"reversalOfID"`    // 冲正流水指向被冲正的原流水id(唯一)，原流水为空
+	ReverseReason    string     `gorm:"column:reverse_reason;type:varchar(255)" json:"reverseReason"` // 冲正原因
 }
 
+// 回款流水状态(loan_repayment_transactions.status)
+const (
+	TransactionStatusFailed   = 0 // 失败
+	TransactionStatusSuccess  = 1 // 成功
+	TransactionStatusReversal = 2 // 冲正/撤销(冲正流水，金额为负数)
+)
+
 var LoanRepaymentTransactionsColumnNames = map[string]bool{
 	"id":                 true,
 	"created_at":         true,
@@ -37,6 +47,7 @@ var LoanRepaymentTransactionsColumnNames = map[string]bool{
 	"collect_order_no":   true,
 	"pay_ref":            true,
 	"pay_amount":         true,
+	"collect_fee":        true,
 	"pay_method":         true,
 	"paid_at":            true,
 	"alloc_principal":    true,
@@ -45,4 +56,6 @@ var LoanRepaymentTransactionsColumnNames = map[string]bool{
 	"alloc_penalty":      true,
 	"status":             true,
 	"remark":             true,
+	"created_by":         true,
+	"reversal_of_id":     true,
 }
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+)
+
+// LoanScheduleExtensions 展期记录表(每次展期一条，记录顺延天数、展期费及罚息处理)
+type LoanScheduleExtensions struct {
+	sgorm.Model `gorm:"embedded"` // embed id and time
+
+	ScheduleID     uint64     `gorm:"column:schedule_id;type:bigint(20);not null" json:"scheduleID"`         // 关联期次 loan_repayment_schedules.id
+	DisbursementID uint64     `gorm:"column:disbursement_id;type:bigint(20);not null" json:"disbursementID"` // 关联放款单 loan_disbursements.id
+	ExtensionNo    int        `gorm:"column:extension_no;type:int(11);not null" json:"extensionNo"`          // 该笔借款的第几次展期(从1开始)
+	Days           int        `gorm:"column:days;type:int(11);not null" json:"days"`                         // 顺延天数
+	DueDateBefore  *time.Time `gorm:"column:due_date_before;type:date;not null" json:"dueDateBefore"`        // 展期前应还日期
+	DueDateAfter   *time.Time `gorm:"column:due_date_after;type:date;not null" json:"dueDateAfter"`          // 展期后应还日期
+	FeeAmount      int64      `gorm:"column:fee_amount;type:bigint(20);not null" json:"feeAmount"`           // 展期费(分)
+	TransactionID  uint64     `gorm:"column:transaction_id;type:bigint(20)" json:"transactionID"`            // 展期费回款流水 loan_repayment_transactions.id，免费展期为0
+	PenaltyMode    string     `gorm:"column:penalty_mode;type:varchar(16);not null" json:"penaltyMode"`      // 罚息处理方式：FREEZE冻结 WAIVE免除
+	PenaltyWaived  int64      `gorm:"column:penalty_waived;type:bigint(20);default:0" json:"penaltyWaived"`  // 免除的罚息(分)
+	StatusBefore   int        `gorm:"column:status_before;type:tinyint(4);not null" json:"statusBefore"`     // 展期前期次状态
+	ShiftedCount   int        `gorm:"column:shifted_count;type:int(11);default:0" json:"shiftedCount"`       // 同时顺延的后续期次数
+	CreatedBy      uint64     `gorm:"column:created_by;type:bigint(20);not null" json:"createdBy"`           // 操作人
+	Remark         string     `gorm:"column:remark;type:varchar(255)" json:"remark"`                         // 备注
+}
+
+// 展期时已产生罚息的处理方式(loan_schedule_extensions.penalty_mode，loan_settings.extension_penalty_mode)
+const (
+	ExtensionPenaltyFreeze = "FREEZE" // 保留未还罚息，展期后期次不再逾期，不再计提
+	ExtensionPenaltyWaive  = "WAIVE"  // 免除未还罚息
+)
+
+// LoanScheduleExtensionsColumnNames Whitelist for custom query fields to prevent sql injection attacks
+var LoanScheduleExtensionsColumnNames = map[string]bool{
+	"id":              true,
+	"created_at":      true,
+	"updated_at":      true,
+	"deleted_at":      true,
+	"schedule_id":     true,
+	"disbursement_id": true,
+	"extension_no":    true,
+	"days":            true,
+	"due_date_before": true,
+	"due_date_after":  true,
+	"fee_amount":      true,
+	"transaction_id":  true,
+	"penalty_mode":    true,
+	"penalty_waived":  true,
+	"status_before":   true,
+	"shifted_count":   true,
+	"created_by":      true,
+	"remark":          true,
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+)
+
+// LoanScorecards 信用评分卡。同名评分卡按版本号递增保存，定义保存后不再修改，同一时间只启用一个版本
+type LoanScorecards struct {
+	sgorm.Model `gorm:"embedded"` // embed id and time
+
+	Name       string `gorm:"column:name;type:varchar(64);not null" json:"name"`              // 评分卡名称
+	Version    int    `gorm:"column:version;type:int(11);not null" json:"version"`            // 版本号，同名评分卡从 1 开始递增
+	Definition string `gorm:"column:definition;type:text;not null" json:"definition"`         // 评分卡定义 JSON：基础分、分数上下限、识别关键字、特征分箱与分值
+	Status     int    `gorm:"column:status;type:tinyint(4);default:0;not null" json:"status"` // 状态：1启用(提交申请时用于评分) 0停用
+	Remark     string `gorm:"column:remark;type:varchar(255)" json:"remark"`                  // 备注(版本变更说明)
+}
+
+// LoanScorecardsColumnNames Whitelist for custom query fields to prevent sql injection attacks
+var LoanScorecardsColumnNames = map[string]bool{
+	"id":         true,
+	"created_at": true,
+	"updated_at": true,
+	"deleted_at": true,
+	"name":       true,
+	"version":    true,
+	"status":     true,
+}
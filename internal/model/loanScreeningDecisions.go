@@ -0,0 +1,30 @@
+package model
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+)
+
+// LoanScreeningDecisions 自动预审决策日志，每次执行规则记录一条
+type LoanScreeningDecisions struct {
+	sgorm.Model `gorm:"embedded"` // embed id and time
+
+	BaseinfoID uint64 `gorm:"column:baseinfo_id;type:bigint(20);not null" json:"baseinfoID"` // 申请单 loan_baseinfo.id
+	Trigger    string `gorm:"column:trigger_type;type:varchar(16);not null" json:"trigger"`  // 触发方式：CREATE 提交申请 RERUN 人工重新执行
+	Outcome    string `gorm:"column:outcome;type:varchar(16);not null" json:"outcome"`       // 预审结果：REJECT PASS MANUAL
+	Score      int    `gorm:"column:score;type:int(11);default:0;not null" json:"score"`     // 命中规则的分数合计
+	Hits       string `gorm:"column:hits;type:text" json:"hits"`                             // 命中规则 JSON 数组(规则id、名称、动作、分数)
+	Facts      string `gorm:"column:facts;type:text" json:"facts"`                           // 执行时的申请单数据快照 JSON
+	Note       string `gorm:"column:note;type:varchar(255)" json:"note"`                     // 说明，如自动通过被降级为人工的原因
+}
+
+// LoanScreeningDecisionsColumnNames Whitelist for custom query fields to prevent sql injection attacks
+var LoanScreeningDecisionsColumnNames = map[string]bool{
+	"id":           true,
+	"created_at":   true,
+	"updated_at":   true,
+	"deleted_at":   true,
+	"baseinfo_id":  true,
+	"trigger_type": true,
+	"outcome":      true,
+	"score":        true,
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+)
+
+// LoanScreeningRules 申请自动预审(决策引擎)规则，条件为针对申请单字段的 JSON 表达式
+type LoanScreeningRules struct {
+	sgorm.Model `gorm:"embedded"` // embed id and time
+
+	Name      string `gorm:"column:name;type:varchar(64);not null" json:"name"`               // 规则名称
+	Priority  int    `gorm:"column:priority;type:int(11);default:0;not null" json:"priority"` // 执行顺序(升序)，仅影响命中记录的排列
+	Condition string `gorm:"column:conditions;type:text;not null" json:"condition"`           // 条件表达式 JSON，如 {"field":"age","op":"<","value":18}
+	Action    string `gorm:"column:action;type:varchar(16);not null" json:"action"`           // 命中后动作：REJECT 自动拒绝 PASS 自动通过初审 MANUAL 转人工 SCORE 仅计分
+	Score     int    `gorm:"column:score;type:int(11);default:0;not null" json:"score"`       // 命中后累加的分数(可为负)
+	Status    int    `gorm:"column:status;type:tinyint(4);default:1;not null" json:"status"`  // 状态：1启用 0停用
+	Remark    string `gorm:"column:remark;type:varchar(255)" json:"remark"`                   // 备注
+}
+
+// LoanScreeningRulesColumnNames Whitelist for custom query fields to prevent sql injection attacks
+var LoanScreeningRulesColumnNames = map[string]bool{
+	"id":         true,
+	"created_at": true,
+	"updated_at": true,
+	"deleted_at": true,
+	"name":       true,
+	"priority":   true,
+	"action":     true,
+	"score":      true,
+	"status":     true,
+}
+
+// 规则动作与预审结果
+const (
+	ScreeningActionReject = "REJECT" // 自动拒绝
+	ScreeningActionPass   = "PASS"   // 自动通过初审，进入下一审批节点
+	ScreeningActionManual = "MANUAL" // 强制转人工审核
+	ScreeningActionScore  = "SCORE"  // 仅累加分数
+
+	ScreeningOutcomeReject = "REJECT" // 自动拒绝
+	ScreeningOutcomePass   = "PASS"   // 自动通过初审
+	ScreeningOutcomeManual = "MANUAL" // 人工初审
+
+	ScreeningTriggerCreate = "CREATE" // 申请提交时执行
+	ScreeningTriggerRerun  = "RERUN"  // 人工重新执行
+)
@@ -22,3 +22,22 @@ var LoanSettingsColumnNames = map[string]bool{
 	"value":      true,
 	"remark":     true,
 }
+
+// 系统设置项名称(loan_settings.name)
+const (
+	SettingRepaymentAllocationOrder = "repayment_allocation_order" // 还款冲销顺序，如 penalty,fee,interest,principal
+	SettingPenaltyDailyRateBp       = "penalty_daily_rate_bp"      // 逾期日罚息率(万分比，按剩余本金计提)，0 表示不计提
+	SettingPenaltyGraceDays         = "penalty_grace_days"         // 罚息宽限天数
+	SettingPenaltyCapPercent        = "penalty_cap_percent"        // 罚息上限(占应还本金的百分比)，0 表示不设上限
+	SettingExtensionMaxCount        = "extension_max_count"        // 每笔借款最多展期次数，0 表示不允许展期
+	SettingExtensionMaxDays         = "extension_max_days"         // 单次最多展期天数，0 表示不限
+	SettingExtensionFeeDailyBp      = "extension_fee_daily_bp"     // 展期日费率(万分比，按剩余本金*展期天数计收)
+	SettingExtensionFeeFixed        = "extension_fee_fixed"        // 每次展期固定费用(分)
+	SettingExtensionPenaltyMode     = "extension_penalty_mode"     // 展期时已产生罚息的处理方式：FREEZE(默认)/WAIVE
+	SettingSodDistinctApprovers     = "sod_distinct_approvers"     // 同一申请单的各审批节点须由不同人员审批：1启用(默认) 0关闭
+	SettingSodBlockReferrer         = "sod_block_referrer"         // 禁止审批人审批自己推荐(referrer_user_id)的申请：1启用(默认) 0关闭
+	SettingReviewClaimLeaseMinutes  = "review_claim_lease_minutes" // 审批工作队列领取租约(分钟)，默认30
+	SettingReviewSlaMinutes         = "review_sla_minutes"         // 审批节点时效(分钟，从待审到审批决定)，默认240
+	SettingScreeningEnabled         = "screening_enabled"          // 提交申请时执行自动预审规则：1启用(默认) 0关闭
+	SettingScreeningPassScore       = "screening_pass_score"       // 命中规则分数合计达到该值时自动通过初审，0 表示不按分数自动通过
+)
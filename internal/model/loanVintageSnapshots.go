@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+)
+
+// LoanVintageSnapshots 放款批次(vintage)表现快照，每晚按放款月份、账龄月数(MOB)重算
+type LoanVintageSnapshots struct {
+	sgorm.Model `gorm:"embedded"` // embed id and time
+
+	Cohort             string    `gorm:"column:cohort;type:char(7);not null" json:"cohort"`                              // 放款月份 yyyy-mm
+	Mob                int       `gorm:"column:mob;type:int(11);not null" json:"mob"`                                    // 账龄月数(放款后第N个月的观察点)
+	CohortLoans        int64     `gorm:"column:cohort_loans;type:bigint(20);default:0" json:"cohortLoans"`               // 批次放款笔数
+	CohortPrincipal    int64     `gorm:"column:cohort_principal;type:bigint(20);default:0" json:"cohortPrincipal"`       // 批次放款本金(分)
+	ObservedLoans      int64     `gorm:"column:observed_loans;type:bigint(20);default:0" json:"observedLoans"`           // 已到达该观察点的借款笔数
+	ObservedPrincipal  int64     `gorm:"column:observed_principal;type:bigint(20);default:0" json:"observedPrincipal"`   // 已到达该观察点的放款本金(分)
+	Dpd1Loans          int64     `gorm:"column:dpd1_loans;type:bigint(20);default:0" json:"dpd1Loans"`                   // 截至观察点曾逾期1天以上的笔数
+	Dpd1Principal      int64     `gorm:"column:dpd1_principal;type:bigint(20);default:0" json:"dpd1Principal"`           // 截至观察点曾逾期1天以上的放款本金(分)
+	Dpd30Loans         int64     `gorm:"column:dpd30_loans;type:bigint(20);default:0" json:"dpd30Loans"`                 // 曾逾期30天以上的笔数
+	Dpd30Principal     int64     `gorm:"column:dpd30_principal;type:bigint(20);default:0" json:"dpd30Principal"`         // 曾逾期30天以上的放款本金(分)
+	Dpd60Loans         int64     `gorm:"column:dpd60_loans;type:bigint(20);default:0" json:"dpd60Loans"`                 // 曾逾期60天以上的笔数
+	Dpd60Principal     int64     `gorm:"column:dpd60_principal;type:bigint(20);default:0" json:"dpd60Principal"`         // 曾逾期60天以上的放款本金(分)
+	Dpd90Loans         int64     `gorm:"column:dpd90_loans;type:bigint(20);default:0" json:"dpd90Loans"`                 // 曾逾期90天以上的笔数
+	Dpd90Principal     int64     `gorm:"column:dpd90_principal;type:bigint(20);default:0" json:"dpd90Principal"`         // 曾逾期90天以上的放款本金(分)
+	CollectedPrincipal int64     `gorm:"column:collected_principal;type:bigint(20);default:0" json:"collectedPrincipal"` // 截至观察点累计回收本金(分，已扣冲正)
+	CollectedAmount    int64     `gorm:"column:collected_amount;type:bigint(20);default:0" json:"collectedAmount"`       // 截至观察点累计回款总额(分，已扣冲正)
+	SnapshotAt         time.Time `gorm:"column:snapshot_at;type:datetime;not null" json:"snapshotAt"`                    // 快照计算时间
+}
+
+// LoanVintageSnapshotsColumnNames Whitelist for custom query fields to prevent sql injection attacks
+var LoanVintageSnapshotsColumnNames = map[string]bool{
+	"id":                  true,
+	"created_at":          true,
+	"updated_at":          true,
+	"deleted_at":          true,
+	"cohort":              true,
+	"mob":                 true,
+	"cohort_loans":        true,
+	"cohort_principal":    true,
+	"observed_loans":      true,
+	"observed_principal":  true,
+	"dpd1_loans":          true,
+	"dpd1_principal":      true,
+	"dpd30_loans":         true,
+	"dpd30_principal":     true,
+	"dpd60_loans":         true,
+	"dpd60_principal":     true,
+	"dpd90_loans":         true,
+	"dpd90_principal":     true,
+	"collected_principal": true,
+	"collected_amount":    true,
+	"snapshot_at":         true,
+}
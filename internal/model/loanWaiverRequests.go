@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+)
+
+// LoanWaiverRequests 罚息/费用减免申请表(催收人员发起，主管审批通过后调整期次应还)
+type LoanWaiverRequests struct {
+	sgorm.Model `gorm:"embedded"` // embed id and time
+
+	ScheduleID   uint64     `gorm:"column:schedule_id;type:bigint(20);not null" json:"scheduleID"`      // 关联期次 loan_repayment_schedules.id
+	CaseID       uint64     `gorm:"column:case_id;type:bigint(20)" json:"caseID"`                       // 关联催收任务 loan_collection_cases.id(可选)
+	Component    string     `gorm:"column:component;type:varchar(16);not null" json:"component"`        // 减免科目：penalty罚息 fee费用
+	Amount       int64      `gorm:"column:amount;type:bigint(20);not null" json:"amount"`               // 申请减免金额(分)
+	Reason       string     `gorm:"column:reason;type:varchar(255);not null" json:"reason"`             // 减免原因
+	RequestedBy  uint64     `gorm:"column:requested_by;type:bigint(20);not null" json:"requestedBy"`    // 申请人 loan_users.id
+	Status       int        `gorm:"column:status;type:tinyint(4);default:0;not null" json:"status"`     // 状态：0待审批 1已通过 2已驳回
+	ReviewedBy   uint64     `gorm:"column:reviewed_by;type:bigint(20)" json:"reviewedBy"`               // 审批人 loan_users.id
+	ReviewedAt   *time.Time `gorm:"column:reviewed_at;type:datetime" json:"reviewedAt"`                 // 审批时间
+	ReviewNote   string     `gorm:"column:review_note;type:varchar(255)" json:"reviewNote"`             // 审批备注
+	DueBefore    int64      `gorm:"column:due_before;type:bigint(20);default:0" json:"dueBefore"`       // 审批通过时该科目减免前应还(分)
+	DueAfter     int64      `gorm:"column:due_after;type:bigint(20);default:0" json:"dueAfter"`         // 审批通过时该科目减免后应还(分)
+	StatusBefore int        `gorm:"column:status_before;type:tinyint(4);default:0" json:"statusBefore"` // 审批通过时期次原状态
+	StatusAfter  int        `gorm:"column:status_after;type:tinyint(4);default:0" json:"statusAfter"`   // 审批通过后期次状态
+}
+
+// 减免申请状态(loan_waiver_requests.status)
+const (
+	WaiverStatusPending  = 0 // 待审批
+	WaiverStatusApproved = 1 // 已通过
+	WaiverStatusRejected = 2 // 已驳回
+)
+
+// LoanWaiverRequestsColumnNames Whitelist for custom query fields to prevent sql injection attacks
+var LoanWaiverRequestsColumnNames = map[string]bool{
+	"id":            true,
+	"created_at":    true,
+	"updated_at":    true,
+	"deleted_at":    true,
+	"schedule_id":   true,
+	"case_id":       true,
+	"component":     true,
+	"amount":        true,
+	"reason":        true,
+	"requested_by":  true,
+	"status":        true,
+	"reviewed_by":   true,
+	"reviewed_at":   true,
+	"review_note":   true,
+	"due_before":    true,
+	"due_after":     true,
+	"status_before": true,
+	"status_after":  true,
+}
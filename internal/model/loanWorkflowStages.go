@@ -0,0 +1,41 @@
+package model
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+)
+
+// LoanWorkflowStages 审批流程节点定义，申请单按 stage_no 顺序逐级审批
+type LoanWorkflowStages struct {
+	sgorm.Model `gorm:"embedded"` // embed id and time
+
+	Workflow   string `gorm:"column:workflow;type:varchar(32);not null" json:"workflow"`             // 流程代码，借款申请为 loan_application
+	StageNo    int    `gorm:"column:stage_no;type:int(11);not null" json:"stageNo"`                  // 节点序号(升序审批)，审批通过后写入 loan_baseinfo.audit_status
+	Code       string `gorm:"column:code;type:varchar(32);not null" json:"code"`                     // 节点代码，如 PRE_REVIEW FINANCE_REVIEW MANAGER_REVIEW
+	Name       string `gorm:"column:name;type:varchar(64);not null" json:"name"`                     // 节点名称
+	Permission string `gorm:"column:permission;type:varchar(64)" json:"permission"`                  // 审批该节点所需的权限码，为空表示不额外校验
+	MinAmount  int64  `gorm:"column:min_amount;type:bigint(20);default:0;not null" json:"minAmount"` // 申请金额达到该值(分)才需要本节点，0 表示所有申请都需要
+	Status     int    `gorm:"column:status;type:tinyint(4);default:1;not null" json:"status"`        // 状态：1启用 0停用
+}
+
+// LoanWorkflowStagesColumnNames Whitelist for custom query fields to prevent sql injection attacks
+var LoanWorkflowStagesColumnNames = map[string]bool{
+	"id":         true,
+	"created_at": true,
+	"updated_at": true,
+	"deleted_at": true,
+	"workflow":   true,
+	"stage_no":   true,
+	"code":       true,
+	"name":       true,
+	"permission": true,
+	"min_amount": true,
+	"status":     true,
+}
+
+// 审批流程与内置节点
+const (
+	WorkflowLoanApplication = "loan_application" // 借款申请审批
+
+	StageCodePreReview     = "PRE_REVIEW"     // 初审
+	StageCodeFinanceReview = "FINANCE_REVIEW" // 放款审核
+)
@@ -0,0 +1,57 @@
+// Package payout 代付(放款)网关抽象：按 loan_payment_channels.code 选择具体渠道实现。
+package payout
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Status 代付单在渠道侧的状态
+type Status int
+
+const (
+	StatusProcessing Status = iota // 渠道已受理，处理中
+	StatusSuccess                  // 代付成功，资金已出款
+	StatusFailed                   // 代付失败(终态)
+	StatusCancelled                // 已撤销(终态)
+)
+
+var (
+	// ErrGatewayNotFound 渠道编码未注册代付网关
+	ErrGatewayNotFound = errors.New("payout gateway not found")
+	// ErrOrderNotFound 渠道侧查无此单(提交未到达渠道)，可用同一商户单号重新提交
+	ErrOrderNotFound = errors.New("payout order not found")
+	// ErrNotCancellable 渠道侧已出款或已终态，不能撤销
+	ErrNotCancellable = errors.New("payout order is not cancellable")
+)
+
+// Request 代付请求
+type Request struct {
+	MerchantOrderNo string // 平台商户单号(同一单号重复提交渠道需幂等)
+	MerchantNo      string // 渠道分配给平台的商户号
+	Amount          int64  // 代付金额(分)
+	AccountNo       string // 收款账号
+	AccountName     string // 收款人姓名
+	Mobile          string // 收款人手机号
+	Remark          string
+}
+
+// Result 渠道返回的代付结果
+type Result struct {
+	MerchantOrderNo string     // 平台商户单号
+	PayoutOrderNo   string     // 渠道代付单号
+	Status          Status     // 渠道侧状态
+	FailReason      string     // 失败/撤销原因
+	FinishedAt      *time.Time // 终态时间(成功为出款时间)
+}
+
+// Gateway 代付网关，同一实现需支持并发调用
+type Gateway interface {
+	// Submit 提交代付，渠道同步拒绝时返回 Status=StatusFailed 的结果而不是 error；error 仅表示结果未知(网络/超时)
+	Submit(ctx context.Context, req *Request) (*Result, error)
+	// Query 按商户单号查询代付状态，渠道侧不存在时返回 ErrOrderNotFound
+	Query(ctx context.Context, merchantOrderNo string) (*Result, error)
+	// Cancel 撤销尚未出款的代付，已出款时返回 ErrNotCancellable
+	Cancel(ctx context.Context, merchantOrderNo string) (*Result, error)
+}
@@ -0,0 +1,94 @@
+package payout
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MockChannelCode 模拟代付渠道编码，配置 loan_payment_channels.code=MOCK 即可在本地走完整放款流程
+const MockChannelCode = "MOCK"
+
+// mockFailAmountSuffix 金额(分)以该值结尾的代付会被模拟渠道拒绝，便于测试失败流程
+const mockFailAmountSuffix = 13
+
+func init() {
+	Register(MockChannelCode, NewMockGateway())
+}
+
+// MockGateway 内存实现的模拟代付网关：提交即受理，首次查询返回成功；金额以 13 分结尾时提交被拒绝
+type MockGateway struct {
+	mu     sync.Mutex
+	orders map[string]*Result
+	Now    func() time.Time
+}
+
+// NewMockGateway create a mock gateway
+func NewMockGateway() *MockGateway {
+	return &MockGateway{orders: map[string]*Result{}, Now: time.Now}
+}
+
+// Submit 同一商户单号重复提交返回已有结果
+func (m *MockGateway) Submit(_ context.Context, req *Request) (*Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if r, ok := m.orders[req.MerchantOrderNo]; ok {
+		return copyResult(r), nil
+	}
+	r := &Result{
+		MerchantOrderNo: req.MerchantOrderNo,
+		PayoutOrderNo:   "MOCK" + strings.TrimPrefix(req.MerchantOrderNo, "PO"),
+		Status:          StatusProcessing,
+	}
+	if req.Amount <= 0 || req.Amount%100 == mockFailAmountSuffix {
+		now := m.Now()
+		r.Status = StatusFailed
+		r.FailReason = "mock channel rejected"
+		r.FinishedAt = &now
+	}
+	m.orders[req.MerchantOrderNo] = r
+	return copyResult(r), nil
+}
+
+// Query 处理中的订单在查询时置为成功
+func (m *MockGateway) Query(_ context.Context, merchantOrderNo string) (*Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, ok := m.orders[merchantOrderNo]
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+	if r.Status == StatusProcessing {
+		now := m.Now()
+		r.Status = StatusSuccess
+		r.FinishedAt = &now
+	}
+	return copyResult(r), nil
+}
+
+// Cancel 仅处理中的订单可撤销
+func (m *MockGateway) Cancel(_ context.Context, merchantOrderNo string) (*Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	r, ok := m.orders[merchantOrderNo]
+	if !ok {
+		return nil, ErrOrderNotFound
+	}
+	if r.Status != StatusProcessing {
+		return nil, ErrNotCancellable
+	}
+	now := m.Now()
+	r.Status = StatusCancelled
+	r.FailReason = "cancelled"
+	r.FinishedAt = &now
+	return copyResult(r), nil
+}
+
+func copyResult(r *Result) *Result {
+	c := *r
+	return &c
+}
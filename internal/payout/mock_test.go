@@ -0,0 +1,70 @@
+package payout
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	gw, err := Get(" mock ")
+	require.NoError(t, err)
+	assert.IsType(t, &MockGateway{}, gw)
+
+	_, err = Get("BANK_UNKNOWN")
+	assert.ErrorIs(t, err, ErrGatewayNotFound)
+
+	custom := NewMockGateway()
+	Register("bank_t", custom)
+	gw, err = Get("BANK_T")
+	require.NoError(t, err)
+	assert.Same(t, custom, gw)
+}
+
+func TestMockGatewayLifecycle(t *testing.T) {
+	ctx := context.Background()
+	gw := NewMockGateway()
+
+	_, err := gw.Query(ctx, "PO1")
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+
+	r, err := gw.Submit(ctx, &Request{MerchantOrderNo: "PO1", Amount: 10000})
+	require.NoError(t, err)
+	assert.Equal(t, StatusProcessing, r.Status)
+	assert.Equal(t, "MOCK1", r.PayoutOrderNo)
+
+	// 重复提交幂等
+	again, err := gw.Submit(ctx, &Request{MerchantOrderNo: "PO1", Amount: 10000})
+	require.NoError(t, err)
+	assert.Equal(t, r.PayoutOrderNo, again.PayoutOrderNo)
+
+	r, err = gw.Query(ctx, "PO1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusSuccess, r.Status)
+	assert.NotNil(t, r.FinishedAt)
+
+	_, err = gw.Cancel(ctx, "PO1")
+	assert.ErrorIs(t, err, ErrNotCancellable)
+}
+
+func TestMockGatewayRejectAndCancel(t *testing.T) {
+	ctx := context.Background()
+	gw := NewMockGateway()
+
+	r, err := gw.Submit(ctx, &Request{MerchantOrderNo: "PO2", Amount: 10013})
+	require.NoError(t, err)
+	assert.Equal(t, StatusFailed, r.Status)
+	assert.NotEmpty(t, r.FailReason)
+
+	_, err = gw.Submit(ctx, &Request{MerchantOrderNo: "PO3", Amount: 5000})
+	require.NoError(t, err)
+	r, err = gw.Cancel(ctx, "PO3")
+	require.NoError(t, err)
+	assert.Equal(t, StatusCancelled, r.Status)
+
+	r, err = gw.Query(ctx, "PO3")
+	require.NoError(t, err)
+	assert.Equal(t, StatusCancelled, r.Status)
+}
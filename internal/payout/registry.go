@@ -0,0 +1,33 @@
+package payout
+
+import (
+	"strings"
+	"sync"
+)
+
+var (
+	mu       sync.RWMutex
+	gateways = map[string]Gateway{}
+)
+
+// Register 按渠道编码(loan_payment_channels.code)注册代付网关，重复注册会覆盖
+func Register(channelCode string, gw Gateway) {
+	mu.Lock()
+	defer mu.Unlock()
+	gateways[normalizeCode(channelCode)] = gw
+}
+
+// Get 根据渠道编码获取代付网关，未注册返回 ErrGatewayNotFound
+func Get(channelCode string) (Gateway, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	gw, ok := gateways[normalizeCode(channelCode)]
+	if !ok {
+		return nil, ErrGatewayNotFound
+	}
+	return gw, nil
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
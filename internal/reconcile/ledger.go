@@ -0,0 +1,107 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"loan/internal/dao"
+)
+
+// maxWindowDays 单次对账最多覆盖的天数，避免一次加载过多平台记录
+const maxWindowDays = 31
+
+// Ledger 平台侧账务查询，接口上传与命令行对账共用
+type Ledger struct {
+	DisbursementDao dao.LoanDisbursementsDao
+	TransactionDao  dao.LoanRepaymentTransactionsDao
+}
+
+// Records 查询渠道在 [from, to) 内的已放款单与成功回款流水
+func (l *Ledger) Records(ctx context.Context, channelID uint64, from time.Time, to time.Time) ([]*Record, error) {
+	disbursements, err := l.DisbursementDao.GetSettledByChannel(ctx, channelID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	transactions, err := l.TransactionDao.GetSettledByChannel(ctx, int64(channelID), from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*Record, 0, len(disbursements)+len(transactions))
+	for _, d := range disbursements {
+		records = append(records, &Record{
+			Kind:       KindPayout,
+			ID:         d.ID,
+			OrderNo:    d.MerchantOrderNo,
+			ChannelRef: d.PayoutOrderNo,
+			Amount:     d.NetAmount,
+			At:         d.DisbursedAt,
+		})
+	}
+	// 提前结清一笔回款按期次拆成多条流水(共用回款单号)，对账时合并为一条
+	byOrderNo := map[string]*Record{}
+	for _, t := range transactions {
+		if r, ok := byOrderNo[t.CollectOrderNo]; ok && t.CollectOrderNo != "" {
+			r.Amount += int64(t.PayAmount)
+			if r.ChannelRef == "" {
+				r.ChannelRef = t.PayRef
+			}
+			continue
+		}
+		r := &Record{
+			Kind:       KindCollect,
+			ID:         t.ID,
+			OrderNo:    t.CollectOrderNo,
+			ChannelRef: t.PayRef,
+			Amount:     int64(t.PayAmount),
+			At:         t.PaidAt,
+		}
+		byOrderNo[t.CollectOrderNo] = r
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// Run 解析结算文件并与平台记录比对
+func (l *Ledger) Run(ctx context.Context, p Parser, file io.Reader, channelID uint64, from time.Time, to time.Time) (*Report, error) {
+	lines, err := p.Parse(file)
+	if err != nil {
+		return nil, &ParseError{Err: err}
+	}
+	records, err := l.Records(ctx, channelID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return Reconcile(lines, records), nil
+}
+
+// ParseError 结算文件格式错误(区别于查询平台记录失败)
+type ParseError struct {
+	Err error
+}
+
+func (e *ParseError) Error() string { return "parse settlement file: " + e.Err.Error() }
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// ParseWindow 解析对账日期区间(yyyy-mm-dd，含首尾两天)，返回 [from, to)
+func ParseWindow(dateFrom string, dateTo string) (time.Time, time.Time, error) {
+	from, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(dateFrom), time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date %q", dateFrom)
+	}
+	to, err := time.ParseInLocation(time.DateOnly, strings.TrimSpace(dateTo), time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date %q", dateTo)
+	}
+	if to.Before(from) {
+		return time.Time{}, time.Time{}, fmt.Errorf("date to %s is before date from %s", dateTo, dateFrom)
+	}
+	if to.Sub(from) > maxWindowDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("date range exceeds %d days", maxWindowDays)
+	}
+	return from, to.AddDate(0, 0, 1), nil
+}
@@ -0,0 +1,261 @@
+package reconcile
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Parser 渠道结算文件解析器，不同渠道文件格式不同，按 loan_payment_channels.code 注册
+type Parser interface {
+	Parse(r io.Reader) ([]*Line, error)
+}
+
+var (
+	parsersMu sync.RWMutex
+	parsers   = map[string]Parser{}
+)
+
+// Register 为渠道注册结算文件解析器，同一渠道重复注册会覆盖
+func Register(channelCode string, p Parser) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	parsers[strings.ToUpper(strings.TrimSpace(channelCode))] = p
+}
+
+// ParserFor 获取渠道的解析器，未注册时使用通用 CSV 格式(DefaultCSVParser)
+func ParserFor(channelCode string) Parser {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	if p, ok := parsers[strings.ToUpper(strings.TrimSpace(channelCode))]; ok {
+		return p
+	}
+	return DefaultCSVParser
+}
+
+// AmountUnit 结算文件金额单位
+type AmountUnit int
+
+const (
+	AmountYuan AmountUnit = iota // 元，最多两位小数，如 1234.50
+	AmountCent                   // 分，整数
+)
+
+// CSVColumns 结算文件表头名(不区分大小写)，OrderNo 与 ChannelRef 至少配置一个
+type CSVColumns struct {
+	Kind       string
+	OrderNo    string
+	ChannelRef string
+	Amount     string
+	SettledAt  string
+}
+
+// CSVParser 按表头取列的 CSV 结算文件解析器
+type CSVParser struct {
+	Columns    CSVColumns
+	Unit       AmountUnit
+	TimeLayout string // 交易时间格式，空为 2006-01-02 15:04:05
+	Location   *time.Location
+	Comma      rune            // 分隔符，0 为逗号
+	KindValues map[string]Kind // 方向列取值映射(不区分大小写)，空使用 defaultKindValues
+}
+
+var defaultKindValues = map[string]Kind{
+	"PAYOUT":   KindPayout,
+	"DISBURSE": KindPayout,
+	"代付":       KindPayout,
+	"放款":       KindPayout,
+	"COLLECT":  KindCollect,
+	"REPAY":    KindCollect,
+	"代收":       KindCollect,
+	"回款":       KindCollect,
+}
+
+// DefaultCSVParser 通用结算文件格式：
+//
+//	type,order_no,channel_ref,amount,settled_at
+//	PAYOUT,PO20260101120000123456,CH0001,1000.00,2026-01-01 12:00:05
+var DefaultCSVParser = &CSVParser{
+	Columns: CSVColumns{
+		Kind:       "type",
+		OrderNo:    "order_no",
+		ChannelRef: "channel_ref",
+		Amount:     "amount",
+		SettledAt:  "settled_at",
+	},
+	Unit: AmountYuan,
+}
+
+// ErrEmptyFile 结算文件没有表头
+var ErrEmptyFile = errors.New("settlement file is empty")
+
+// Parse 解析 CSV，任意一行格式错误即返回带行号的错误，不做部分导入
+func (p *CSVParser) Parse(r io.Reader) ([]*Line, error) {
+	br := bufio.NewReader(r)
+	// 去掉 Excel 导出的 UTF-8 BOM
+	if b, err := br.Peek(3); err == nil && string(b) == "\xef\xbb\xbf" {
+		_, _ = br.Discard(3)
+	}
+
+	cr := csv.NewReader(br)
+	if p.Comma != 0 {
+		cr.Comma = p.Comma
+	}
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, ErrEmptyFile
+	}
+	if err != nil {
+		return nil, err
+	}
+	idx, err := p.columnIndex(header)
+	if err != nil {
+		return nil, err
+	}
+
+	layout := p.TimeLayout
+	if layout == "" {
+		layout = time.DateTime
+	}
+	loc := p.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	kinds := p.KindValues
+	if len(kinds) == 0 {
+		kinds = defaultKindValues
+	}
+
+	lines := []*Line{}
+	for {
+		row, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err // csv.ParseError 已包含行号
+		}
+		lineNo, _ := cr.FieldPos(0)
+		if isBlankRow(row) {
+			continue
+		}
+		field := func(col string) string {
+			i, ok := idx[col]
+			if !ok || i >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[i])
+		}
+
+		l := &Line{
+			LineNo:     lineNo,
+			OrderNo:    field(p.Columns.OrderNo),
+			ChannelRef: field(p.Columns.ChannelRef),
+		}
+		kind, ok := kinds[strings.ToUpper(field(p.Columns.Kind))]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown transaction type %q", lineNo, field(p.Columns.Kind))
+		}
+		l.Kind = kind
+		if l.OrderNo == "" && l.ChannelRef == "" {
+			return nil, fmt.Errorf("line %d: order no and channel ref are both empty", lineNo)
+		}
+		if l.Amount, err = parseAmount(field(p.Columns.Amount), p.Unit); err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if s := field(p.Columns.SettledAt); s != "" {
+			t, err := time.ParseInLocation(layout, s, loc)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid settled time %q", lineNo, s)
+			}
+			l.SettledAt = &t
+		}
+		lines = append(lines, l)
+	}
+	return lines, nil
+}
+
+func (p *CSVParser) columnIndex(header []string) (map[string]int, error) {
+	idx := make(map[string]int, len(header))
+	for i, h := range header {
+		idx[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	out := map[string]int{}
+	required := []string{p.Columns.Kind, p.Columns.Amount}
+	for _, col := range required {
+		i, ok := idx[strings.ToLower(col)]
+		if !ok {
+			return nil, fmt.Errorf("missing column %q", col)
+		}
+		out[col] = i
+	}
+	for _, col := range []string{p.Columns.OrderNo, p.Columns.ChannelRef, p.Columns.SettledAt} {
+		if col == "" {
+			continue
+		}
+		if i, ok := idx[strings.ToLower(col)]; ok {
+			out[col] = i
+		}
+	}
+	_, hasOrderNo := out[p.Columns.OrderNo]
+	_, hasRef := out[p.Columns.ChannelRef]
+	if !hasOrderNo && !hasRef {
+		return nil, fmt.Errorf("missing column %q or %q", p.Columns.OrderNo, p.Columns.ChannelRef)
+	}
+	return out, nil
+}
+
+func isBlankRow(row []string) bool {
+	for _, v := range row {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseAmount 金额转为分，元按字符串精确解析，避免浮点误差
+func parseAmount(s string, unit AmountUnit) (int64, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	if s == "" {
+		return 0, errors.New("amount is empty")
+	}
+	if unit == AmountCent {
+		v, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+		return v, nil
+	}
+
+	neg := strings.HasPrefix(s, "-")
+	digits := strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	intPart, fracPart, _ := strings.Cut(digits, ".")
+	if intPart == "" || len(fracPart) > 2 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	fracPart += strings.Repeat("0", 2-len(fracPart))
+	yuan, err := strconv.ParseUint(intPart, 10, 63)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	cent, err := strconv.ParseUint(fracPart, 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	v := int64(yuan)*100 + int64(cent)
+	if neg {
+		v = -v
+	}
+	return v, nil
+}
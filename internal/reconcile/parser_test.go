@@ -0,0 +1,88 @@
+package reconcile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultCSVParser(t *testing.T) {
+	data := "\xef\xbb\xbfType, Order_No ,channel_ref,amount,settled_at\n" +
+		"PAYOUT,PO1,CH1,\"1,000.50\",2026-01-01 12:00:05\n" +
+		"\n" +
+		"代收,,TX1,200,\n" +
+		"collect,RP2,,0.5,2026-01-01 23:59:59\n"
+
+	lines, err := DefaultCSVParser.Parse(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, lines, 3)
+
+	assert.Equal(t, KindPayout, lines[0].Kind)
+	assert.Equal(t, "PO1", lines[0].OrderNo)
+	assert.Equal(t, int64(100050), lines[0].Amount)
+	require.NotNil(t, lines[0].SettledAt)
+	assert.Equal(t, 2, lines[0].LineNo)
+
+	assert.Equal(t, KindCollect, lines[1].Kind)
+	assert.Equal(t, "TX1", lines[1].ChannelRef)
+	assert.Equal(t, int64(20000), lines[1].Amount)
+	assert.Nil(t, lines[1].SettledAt)
+	assert.Equal(t, 4, lines[1].LineNo)
+
+	assert.Equal(t, int64(50), lines[2].Amount)
+}
+
+func TestCSVParserErrors(t *testing.T) {
+	cases := map[string]string{
+		"empty":          "",
+		"missing column": "type,order_no\nPAYOUT,PO1\n",
+		"unknown type":   "type,order_no,amount\nREFUND,PO1,1.00\n",
+		"no order no":    "type,order_no,channel_ref,amount\nPAYOUT,,,1.00\n",
+		"bad amount":     "type,order_no,amount\nPAYOUT,PO1,1.005\n",
+		"bad time":       "type,order_no,amount,settled_at\nPAYOUT,PO1,1.00,01/01/2026\n",
+	}
+	for name, data := range cases {
+		_, err := DefaultCSVParser.Parse(strings.NewReader(data))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestParserRegistry(t *testing.T) {
+	assert.Same(t, DefaultCSVParser, ParserFor("UNKNOWN"))
+
+	p := &CSVParser{
+		Columns: CSVColumns{Kind: "方向", ChannelRef: "流水号", Amount: "金额(分)"},
+		Unit:    AmountCent,
+		Comma:   ';',
+	}
+	Register("bank_r", p)
+	assert.Same(t, p, ParserFor(" BANK_R "))
+
+	lines, err := ParserFor("BANK_R").Parse(strings.NewReader("方向;流水号;金额(分)\n放款;CH9;12345\n"))
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	assert.Equal(t, KindPayout, lines[0].Kind)
+	assert.Equal(t, int64(12345), lines[0].Amount)
+}
+
+func TestParseAmount(t *testing.T) {
+	cases := map[string]int64{
+		"0":        0,
+		"1":        100,
+		"1.2":      120,
+		"-3.05":    -305,
+		"+10.00":   1000,
+		"1,234.56": 123456,
+	}
+	for s, want := range cases {
+		got, err := parseAmount(s, AmountYuan)
+		require.NoError(t, err, s)
+		assert.Equal(t, want, got, s)
+	}
+	for _, s := range []string{"", ".5", "1.234", "abc", "1.x"} {
+		_, err := parseAmount(s, AmountYuan)
+		assert.Error(t, err, s)
+	}
+}
@@ -0,0 +1,171 @@
+// Package reconcile 渠道结算对账：解析渠道结算文件，与平台放款单/回款流水逐笔比对，输出对账报告。
+package reconcile
+
+import (
+	"sort"
+	"time"
+)
+
+// Kind 交易方向
+type Kind string
+
+const (
+	KindPayout  Kind = "PAYOUT"  // 代付(放款)，对应 loan_disbursements
+	KindCollect Kind = "COLLECT" // 代收(回款)，对应 loan_repayment_transactions
+)
+
+// Line 渠道结算文件中的一笔交易
+type Line struct {
+	LineNo     int        `json:"lineNo"`     // 文件行号(含表头，从1开始)
+	Kind       Kind       `json:"kind"`       // 交易方向
+	OrderNo    string     `json:"orderNo"`    // 商户单号(平台提交给渠道的单号)
+	ChannelRef string     `json:"channelRef"` // 渠道单号/渠道流水号
+	Amount     int64      `json:"amount"`     // 结算金额(分)
+	SettledAt  *time.Time `json:"settledAt"`  // 交易/结算时间
+}
+
+// Record 平台侧账务记录
+type Record struct {
+	Kind       Kind       `json:"kind"`
+	ID         uint64     `json:"id"`         // loan_disbursements.id / loan_repayment_transactions.id
+	OrderNo    string     `json:"orderNo"`    // 放款: merchant_order_no；回款: collect_order_no
+	ChannelRef string     `json:"channelRef"` // 放款: payout_order_no；回款: pay_ref
+	Amount     int64      `json:"amount"`     // 放款: net_amount；回款: pay_amount(分)
+	At         *time.Time `json:"at"`         // 放款: disbursed_at；回款: paid_at
+}
+
+// ItemStatus 对账结果
+type ItemStatus string
+
+const (
+	StatusMatched          ItemStatus = "MATCHED"            // 双方一致
+	StatusMissingOurSide   ItemStatus = "MISSING_OUR_SIDE"   // 渠道有、平台无(长款)
+	StatusMissingOnChannel ItemStatus = "MISSING_ON_CHANNEL" // 平台有、渠道无(短款)
+	StatusAmountMismatch   ItemStatus = "AMOUNT_MISMATCH"    // 双方均有但金额不一致
+)
+
+// Item 对账明细
+type Item struct {
+	Status ItemStatus `json:"status"`
+	Kind   Kind       `json:"kind"`
+	Line   *Line      `json:"line,omitempty"`   // 渠道侧，平台有渠道无时为空
+	Record *Record    `json:"record,omitempty"` // 平台侧，渠道有平台无时为空
+	Diff   int64      `json:"diff"`             // 渠道金额 - 平台金额(分)
+}
+
+// Summary 对账汇总
+type Summary struct {
+	ChannelCount     int   `json:"channelCount"`     // 渠道文件笔数
+	ChannelAmount    int64 `json:"channelAmount"`    // 渠道文件金额合计(分)
+	OurCount         int   `json:"ourCount"`         // 平台记录笔数
+	OurAmount        int64 `json:"ourAmount"`        // 平台记录金额合计(分)
+	Matched          int   `json:"matched"`          // 一致笔数
+	MissingOurSide   int   `json:"missingOurSide"`   // 渠道有平台无笔数
+	MissingOnChannel int   `json:"missingOnChannel"` // 平台有渠道无笔数
+	AmountMismatch   int   `json:"amountMismatch"`   // 金额不一致笔数
+}
+
+// Report 对账报告
+type Report struct {
+	Summary          Summary `json:"summary"`
+	Matched          []*Item `json:"matched"`
+	MissingOurSide   []*Item `json:"missingOurSide"`
+	MissingOnChannel []*Item `json:"missingOnChannel"`
+	AmountMismatch   []*Item `json:"amountMismatch"`
+}
+
+// Balanced 是否完全平账
+func (r *Report) Balanced() bool {
+	return r.Summary.MissingOurSide == 0 && r.Summary.MissingOnChannel == 0 && r.Summary.AmountMismatch == 0
+}
+
+type matchKey struct {
+	kind Kind
+	val  string
+}
+
+// Reconcile 逐笔比对渠道结算明细与平台记录。
+// 匹配优先用渠道单号(放款 payout_order_no / 回款 pay_ref)，其次用商户单号；一条平台记录只匹配一次，
+// 渠道文件中重复出现的同一笔按"渠道有平台无"处理。
+func Reconcile(lines []*Line, records []*Record) *Report {
+	report := &Report{
+		Matched:          []*Item{},
+		MissingOurSide:   []*Item{},
+		MissingOnChannel: []*Item{},
+		AmountMismatch:   []*Item{},
+	}
+
+	byRef := make(map[matchKey]*Record, len(records))
+	byOrderNo := make(map[matchKey]*Record, len(records))
+	for _, r := range records {
+		report.Summary.OurCount++
+		report.Summary.OurAmount += r.Amount
+		if r.ChannelRef != "" {
+			byRef[matchKey{r.Kind, r.ChannelRef}] = r
+		}
+		if r.OrderNo != "" {
+			byOrderNo[matchKey{r.Kind, r.OrderNo}] = r
+		}
+	}
+
+	used := make(map[*Record]bool, len(records))
+	lookup := func(l *Line) *Record {
+		if l.ChannelRef != "" {
+			if r, ok := byRef[matchKey{l.Kind, l.ChannelRef}]; ok && !used[r] {
+				return r
+			}
+		}
+		if l.OrderNo != "" {
+			if r, ok := byOrderNo[matchKey{l.Kind, l.OrderNo}]; ok && !used[r] {
+				return r
+			}
+		}
+		return nil
+	}
+
+	for _, l := range lines {
+		report.Summary.ChannelCount++
+		report.Summary.ChannelAmount += l.Amount
+
+		r := lookup(l)
+		if r == nil {
+			report.MissingOurSide = append(report.MissingOurSide, &Item{
+				Status: StatusMissingOurSide, Kind: l.Kind, Line: l, Diff: l.Amount,
+			})
+			continue
+		}
+		used[r] = true
+
+		item := &Item{Kind: l.Kind, Line: l, Record: r, Diff: l.Amount - r.Amount}
+		if item.Diff == 0 {
+			item.Status = StatusMatched
+			report.Matched = append(report.Matched, item)
+		} else {
+			item.Status = StatusAmountMismatch
+			report.AmountMismatch = append(report.AmountMismatch, item)
+		}
+	}
+
+	for _, r := range records {
+		if used[r] {
+			continue
+		}
+		report.MissingOnChannel = append(report.MissingOnChannel, &Item{
+			Status: StatusMissingOnChannel, Kind: r.Kind, Record: r, Diff: -r.Amount,
+		})
+	}
+	// 平台侧未匹配记录按方向(放款在前)、ID 排序，便于人工核对
+	sort.SliceStable(report.MissingOnChannel, func(i, j int) bool {
+		a, b := report.MissingOnChannel[i].Record, report.MissingOnChannel[j].Record
+		if a.Kind != b.Kind {
+			return a.Kind > b.Kind
+		}
+		return a.ID < b.ID
+	})
+
+	report.Summary.Matched = len(report.Matched)
+	report.Summary.MissingOurSide = len(report.MissingOurSide)
+	report.Summary.MissingOnChannel = len(report.MissingOnChannel)
+	report.Summary.AmountMismatch = len(report.AmountMismatch)
+	return report
+}
@@ -0,0 +1,80 @@
+package reconcile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReconcile(t *testing.T) {
+	records := []*Record{
+		{Kind: KindPayout, ID: 1, OrderNo: "PO1", ChannelRef: "CH1", Amount: 100000},
+		{Kind: KindPayout, ID: 2, OrderNo: "PO2", ChannelRef: "CH2", Amount: 50000},
+		{Kind: KindPayout, ID: 3, OrderNo: "PO3", ChannelRef: "CH3", Amount: 30000},
+		{Kind: KindCollect, ID: 10, OrderNo: "RP10", ChannelRef: "TX10", Amount: 20000},
+		{Kind: KindCollect, ID: 11, OrderNo: "", ChannelRef: "TX11", Amount: 15000},
+	}
+	lines := []*Line{
+		{LineNo: 2, Kind: KindPayout, OrderNo: "PO1", ChannelRef: "CH1", Amount: 100000},
+		{LineNo: 3, Kind: KindPayout, OrderNo: "PO2", Amount: 49900},                  // 仅商户单号匹配，金额不一致
+		{LineNo: 4, Kind: KindCollect, ChannelRef: "TX10", Amount: 20000},             // 仅渠道流水号匹配
+		{LineNo: 5, Kind: KindCollect, ChannelRef: "TX10", Amount: 20000},             // 重复
+		{LineNo: 6, Kind: KindCollect, ChannelRef: "TX99", Amount: 8800},              // 平台无
+		{LineNo: 7, Kind: KindCollect, OrderNo: "PO3", ChannelRef: "", Amount: 30000}, // 方向不同不能匹配
+	}
+
+	r := Reconcile(lines, records)
+	assert.Equal(t, Summary{
+		ChannelCount:     6,
+		ChannelAmount:    228700,
+		OurCount:         5,
+		OurAmount:        215000,
+		Matched:          2,
+		MissingOurSide:   3,
+		MissingOnChannel: 2,
+		AmountMismatch:   1,
+	}, r.Summary)
+	assert.False(t, r.Balanced())
+
+	require.Len(t, r.AmountMismatch, 1)
+	assert.Equal(t, uint64(2), r.AmountMismatch[0].Record.ID)
+	assert.Equal(t, int64(-100), r.AmountMismatch[0].Diff)
+
+	missingLines := []int{}
+	for _, it := range r.MissingOurSide {
+		assert.Nil(t, it.Record)
+		missingLines = append(missingLines, it.Line.LineNo)
+	}
+	assert.Equal(t, []int{5, 6, 7}, missingLines)
+
+	require.Len(t, r.MissingOnChannel, 2)
+	assert.Equal(t, uint64(3), r.MissingOnChannel[0].Record.ID) // 放款在前
+	assert.Equal(t, uint64(11), r.MissingOnChannel[1].Record.ID)
+	assert.Equal(t, int64(-15000), r.MissingOnChannel[1].Diff)
+}
+
+func TestReconcileBalanced(t *testing.T) {
+	r := Reconcile(
+		[]*Line{{Kind: KindPayout, ChannelRef: "CH1", Amount: 100}},
+		[]*Record{{Kind: KindPayout, ID: 1, ChannelRef: "CH1", Amount: 100}},
+	)
+	assert.True(t, r.Balanced())
+
+	r = Reconcile(nil, nil)
+	assert.True(t, r.Balanced())
+	assert.NotNil(t, r.Matched)
+}
+
+func TestParseWindow(t *testing.T) {
+	from, to, err := ParseWindow("2026-01-01", "2026-01-01")
+	require.NoError(t, err)
+	assert.Equal(t, 24, int(to.Sub(from).Hours()))
+
+	_, _, err = ParseWindow("2026-01-02", "2026-01-01")
+	assert.Error(t, err)
+	_, _, err = ParseWindow("2026-01-01", "2026-03-01")
+	assert.Error(t, err)
+	_, _, err = ParseWindow("20260101", "2026-01-01")
+	assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan/internal/types"
+)
+
+func TestBucketOf(t *testing.T) {
+	cases := map[int]string{
+		0: "current", 1: "dpd1_7", 7: "dpd1_7", 8: "dpd8_30", 30: "dpd8_30", 31: "dpd31_60",
+		60: "dpd31_60", 61: "dpd61_90", 90: "dpd61_90", 91: "dpd90_plus", 400: "dpd90_plus",
+	}
+	for dpd, key := range cases {
+		assert.Equal(t, key, AgingBuckets[BucketOf(dpd)].Key, "dpd %d", dpd)
+	}
+}
+
+func agingRows() []*types.AgingLoanRow {
+	day := func(m time.Month, d int) *time.Time {
+		t := time.Date(2026, m, d, 0, 0, 0, 0, time.UTC)
+		return &t
+	}
+	return []*types.AgingLoanRow{
+		{DisbursementID: 1, OutstandingPrincipal: 50000, OldestDueDate: day(6, 1)},  // 未到期
+		{DisbursementID: 2, OutstandingPrincipal: 20000, OldestDueDate: day(5, 1)},  // 应还日当天
+		{DisbursementID: 3, OutstandingPrincipal: 10000, OldestDueDate: day(4, 24)}, // 7天
+		{DisbursementID: 4, OutstandingPrincipal: 15000, OldestDueDate: day(3, 31)}, // 31天
+		{DisbursementID: 5, OutstandingPrincipal: 5000, OldestDueDate: day(1, 1)},   // 120天
+		{DisbursementID: 6, OutstandingPrincipal: 0, OldestDueDate: day(1, 1)},      // 仅剩罚息，不计入
+	}
+}
+
+func TestAging(t *testing.T) {
+	r := Aging(agingRows(), time.Date(2026, 5, 1, 15, 0, 0, 0, time.UTC))
+	require.Len(t, r.Buckets, len(AgingBuckets))
+	assert.Equal(t, "2026-05-01", r.AsOf)
+	assert.Equal(t, int64(5), r.Count)
+	assert.Equal(t, int64(100000), r.OutstandingPrincipal)
+
+	got := map[string][2]int64{}
+	for _, b := range r.Buckets {
+		got[b.Key] = [2]int64{b.Count, b.OutstandingPrincipal}
+	}
+	assert.Equal(t, map[string][2]int64{
+		"current":    {2, 70000},
+		"dpd1_7":     {1, 10000},
+		"dpd8_30":    {0, 0},
+		"dpd31_60":   {1, 15000},
+		"dpd61_90":   {0, 0},
+		"dpd90_plus": {1, 5000},
+	}, got)
+	assert.Equal(t, 70.0, r.Buckets[0].PrincipalRatio)
+	assert.Equal(t, 5.0, r.Buckets[5].PrincipalRatio)
+}
+
+func TestWriteAgingCSV(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteAgingCSV(buf, Aging(agingRows(), time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC))))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, len(AgingBuckets)+2)
+	assert.Equal(t, "bucket,label,count,outstanding_principal,principal_ratio", lines[0])
+	assert.Equal(t, "current,正常,2,70000,70.00", lines[1])
+	assert.Equal(t, "total,合计,5,100000,100.00", lines[len(lines)-1])
+
+	buf.Reset()
+	require.NoError(t, WriteAgingCSV(buf, Aging(nil, time.Now())))
+	assert.Contains(t, buf.String(), "total,合计,0,0,0.00")
+}
+
+func TestParseDay(t *testing.T) {
+	d, err := ParseDay(" ")
+	require.NoError(t, err)
+	assert.Nil(t, d)
+
+	d, err = ParseDay("2026-05-01")
+	require.NoError(t, err)
+	assert.Equal(t, 1, d.Day())
+
+	_, err = ParseDay("2026/05/01")
+	assert.Error(t, err)
+}
@@ -0,0 +1,66 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan/internal/model"
+)
+
+func audit(uid uint64, result int, received, claimed *time.Time, decided time.Time) *model.LoanAudits {
+	a := &model.LoanAudits{AuditorUserID: uid, AuditResult: result, ReceivedAt: received, ClaimedAt: claimed, DecidedAt: &decided}
+	a.CreatedAt = decided
+	return a
+}
+
+func TestThroughput(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2026, 5, 1, h, m, 0, 0, time.UTC) }
+	audits := []*model.LoanAudits{
+		audit(1, model.AuditResultPass, ptr(at(9, 0)), ptr(at(9, 30)), at(10, 0)),
+		audit(1, model.AuditResultReject, ptr(at(9, 0)), nil, at(14, 0)),
+		audit(2, model.AuditResultPass, ptr(at(8, 0)), ptr(at(8, 10)), at(8, 20)),
+		audit(2, model.AuditResultPass, ptr(at(8, 0)), ptr(at(8, 20)), at(8, 50)),
+		audit(2, model.AuditResultPass, ptr(at(8, 0)), nil, at(9, 0)),
+	}
+	// 历史记录：没有 received_at / decided_at
+	legacy := &model.LoanAudits{AuditorUserID: 3, AuditResult: model.AuditResultPass}
+	legacy.CreatedAt = at(12, 0)
+	audits = append(audits, legacy)
+
+	list, total := Throughput(audits, 120)
+	require.Len(t, list, 3)
+
+	r2 := list[0]
+	assert.Equal(t, uint64(2), r2.AuditorUserID)
+	assert.Equal(t, int64(3), r2.Decisions)
+	assert.Equal(t, int64(2), r2.Claimed)
+	assert.Equal(t, 20.0, r2.AvgHandleMinutes)
+	assert.Equal(t, 43.33, r2.AvgTurnaroundMinutes)
+	assert.Equal(t, 100.0, r2.SLAMetRate)
+
+	r1 := list[1]
+	assert.Equal(t, int64(1), r1.Approved)
+	assert.Equal(t, int64(1), r1.Rejected)
+	assert.Equal(t, 30.0, r1.AvgHandleMinutes)
+	assert.Equal(t, 300.0, r1.MaxTurnaroundMinutes)
+	assert.Equal(t, int64(1), r1.SLABreaches)
+	assert.Equal(t, 50.0, r1.SLAMetRate)
+
+	r3 := list[2]
+	assert.Equal(t, int64(1), r3.Decisions)
+	assert.Equal(t, 0.0, r3.AvgTurnaroundMinutes)
+
+	assert.Equal(t, int64(6), total.Decisions)
+	assert.Equal(t, int64(5), total.Approved)
+	assert.Equal(t, int64(1), total.SLABreaches)
+	assert.Equal(t, 80.0, total.SLAMetRate)
+}
+
+func TestThroughputEmpty(t *testing.T) {
+	list, total := Throughput(nil, 120)
+	assert.Empty(t, list)
+	assert.Equal(t, int64(0), total.Decisions)
+}
@@ -0,0 +1,253 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"loan/internal/finance"
+	"loan/internal/model"
+)
+
+// CohortLayout 放款批次(vintage)按放款月份分组，格式 yyyy-mm
+const CohortLayout = "2006-01"
+
+// VintageMaxMOB 最长观察账龄(月)，超过该账龄的批次快照不再变化
+const VintageMaxMOB = 36
+
+// VintageThresholds 累计逾期率统计的逾期天数阈值，与快照表 dpdN_loans/dpdN_principal 列对应
+var VintageThresholds = []int{1, 30, 60, 90}
+
+// VintageInstallment 期次的应还日期与结清时间
+type VintageInstallment struct {
+	DueDate   time.Time
+	SettledAt *time.Time
+}
+
+// VintagePayment 一笔回款(冲正为负数)
+type VintagePayment struct {
+	PaidAt    time.Time
+	Principal int64 // 分配到本金(分)
+	Amount    int64 // 回款总额(分)
+}
+
+// VintageLoan 参与批次统计的一笔借款
+type VintageLoan struct {
+	DisbursedAt  time.Time
+	Principal    int64 // 放款本金(分)
+	Installments []VintageInstallment
+	Payments     []VintagePayment
+}
+
+// NewVintageLoan 由放款单及其期次、回款流水组装批次统计数据
+func NewVintageLoan(d *model.LoanDisbursements, schedules []*model.LoanRepaymentSchedules, txs []*model.LoanRepaymentTransactions) VintageLoan {
+	loan := VintageLoan{Principal: d.DisburseAmount}
+	if d.DisbursedAt != nil {
+		loan.DisbursedAt = *d.DisbursedAt
+	}
+	for _, s := range schedules {
+		if s.DueDate == nil {
+			continue
+		}
+		loan.Installments = append(loan.Installments, VintageInstallment{DueDate: *s.DueDate, SettledAt: s.SettledAt})
+	}
+	for _, t := range txs {
+		if t.PaidAt == nil {
+			continue
+		}
+		loan.Payments = append(loan.Payments, VintagePayment{
+			PaidAt:    *t.PaidAt,
+			Principal: int64(t.AllocPrincipal),
+			Amount:    int64(t.PayAmount),
+		})
+	}
+	return loan
+}
+
+// MaxDPDAt 截至 at 时借款曾达到的最大逾期天数：每期按应还日期到结清时间(未结清则到 at)计算，取最大值
+func (l VintageLoan) MaxDPDAt(at time.Time) int {
+	maxDPD := 0
+	for _, in := range l.Installments {
+		end := at
+		if in.SettledAt != nil && in.SettledAt.Before(at) {
+			end = *in.SettledAt
+		}
+		maxDPD = max(maxDPD, finance.OverdueDays(in.DueDate, end))
+	}
+	return maxDPD
+}
+
+// collectedAt 截至 at(含)累计回收的本金与回款总额
+func (l VintageLoan) collectedAt(at time.Time) (principal int64, amount int64) {
+	for _, p := range l.Payments {
+		if !p.PaidAt.After(at) {
+			principal += p.Principal
+			amount += p.Amount
+		}
+	}
+	return principal, amount
+}
+
+// CohortOf 放款时间所属批次
+func CohortOf(t time.Time) string {
+	return t.In(time.Local).Format(CohortLayout)
+}
+
+// ParseCohort 解析 yyyy-mm 批次，返回该月第一天(本地时间)
+func ParseCohort(s string) (time.Time, error) {
+	t, err := time.ParseInLocation(CohortLayout, strings.TrimSpace(s), time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid cohort %q", s)
+	}
+	return t, nil
+}
+
+// Vintage 计算一个放款批次各账龄(MOB)的表现快照。
+// 第 m 个观察点为每笔借款放款时间加 m 个月，只有观察点不晚于 asOf 的借款计入该 MOB；
+// 逾期为累计口径(截至观察点曾达到过该逾期天数)，回收为截至观察点累计回收的本金(已扣冲正)。
+// 期次状态取当前值，展期顺延后的应还日期按顺延后计算。
+func Vintage(cohort string, loans []VintageLoan, asOf time.Time) []*model.LoanVintageSnapshots {
+	var cohortLoans, cohortPrincipal int64
+	for _, l := range loans {
+		cohortLoans++
+		cohortPrincipal += l.Principal
+	}
+
+	var snaps []*model.LoanVintageSnapshots
+	for mob := 1; mob <= VintageMaxMOB; mob++ {
+		snap := &model.LoanVintageSnapshots{
+			Cohort:          cohort,
+			Mob:             mob,
+			CohortLoans:     cohortLoans,
+			CohortPrincipal: cohortPrincipal,
+			SnapshotAt:      asOf,
+		}
+		for _, l := range loans {
+			at := l.DisbursedAt.AddDate(0, mob, 0)
+			if at.After(asOf) {
+				continue
+			}
+			snap.ObservedLoans++
+			snap.ObservedPrincipal += l.Principal
+
+			dpd := l.MaxDPDAt(at)
+			for _, th := range VintageThresholds {
+				if dpd >= th {
+					loansPtr, principalPtr := dpdColumns(snap, th)
+					*loansPtr++
+					*principalPtr += l.Principal
+				}
+			}
+			principal, amount := l.collectedAt(at)
+			snap.CollectedPrincipal += principal
+			snap.CollectedAmount += amount
+		}
+		if snap.ObservedLoans == 0 {
+			break
+		}
+		snaps = append(snaps, snap)
+	}
+	return snaps
+}
+
+// dpdColumns 逾期阈值对应的快照字段
+func dpdColumns(s *model.LoanVintageSnapshots, threshold int) (*int64, *int64) {
+	switch threshold {
+	case 1:
+		return &s.Dpd1Loans, &s.Dpd1Principal
+	case 30:
+		return &s.Dpd30Loans, &s.Dpd30Principal
+	case 60:
+		return &s.Dpd60Loans, &s.Dpd60Principal
+	default:
+		return &s.Dpd90Loans, &s.Dpd90Principal
+	}
+}
+
+// VintageRate 一个逾期阈值的累计逾期情况
+type VintageRate struct {
+	MinDPD        int     `json:"minDPD"`
+	Loans         int64   `json:"loans"`
+	Principal     int64   `json:"principal"`
+	LoanRate      float64 `json:"loanRate"`      // 按笔数(%，两位小数)
+	PrincipalRate float64 `json:"principalRate"` // 按放款本金(%，两位小数)
+}
+
+// VintagePoint 批次在一个账龄观察点的表现
+type VintagePoint struct {
+	Mob                int           `json:"mob"`
+	ObservedLoans      int64         `json:"observedLoans"`
+	ObservedPrincipal  int64         `json:"observedPrincipal"`
+	Overdue            []VintageRate `json:"overdue"`
+	CollectedPrincipal int64         `json:"collectedPrincipal"`
+	CollectedAmount    int64         `json:"collectedAmount"`
+	RecoveryRate       float64       `json:"recoveryRate"` // 累计回收本金/观察本金(%，两位小数)
+}
+
+// VintageCohort 一个放款批次的表现曲线
+type VintageCohort struct {
+	Cohort     string          `json:"cohort"`
+	Loans      int64           `json:"loans"`
+	Principal  int64           `json:"principal"`
+	SnapshotAt string          `json:"snapshotAt"`
+	Points     []*VintagePoint `json:"points"`
+}
+
+// VintageReport 放款批次报表
+type VintageReport struct {
+	Thresholds []int            `json:"thresholds"`
+	Cohorts    []*VintageCohort `json:"cohorts"`
+}
+
+// BuildVintageReport 把快照按批次汇总成表现曲线，批次按月份、观察点按 MOB 升序
+func BuildVintageReport(snaps []*model.LoanVintageSnapshots) *VintageReport {
+	r := &VintageReport{Thresholds: VintageThresholds, Cohorts: []*VintageCohort{}}
+	byCohort := map[string]*VintageCohort{}
+	for _, s := range snaps {
+		c, ok := byCohort[s.Cohort]
+		if !ok {
+			c = &VintageCohort{
+				Cohort:     s.Cohort,
+				Loans:      s.CohortLoans,
+				Principal:  s.CohortPrincipal,
+				SnapshotAt: s.SnapshotAt.Format(time.DateTime),
+			}
+			byCohort[s.Cohort] = c
+			r.Cohorts = append(r.Cohorts, c)
+		}
+		p := &VintagePoint{
+			Mob:                s.Mob,
+			ObservedLoans:      s.ObservedLoans,
+			ObservedPrincipal:  s.ObservedPrincipal,
+			CollectedPrincipal: s.CollectedPrincipal,
+			CollectedAmount:    s.CollectedAmount,
+			RecoveryRate:       percent(s.CollectedPrincipal, s.ObservedPrincipal),
+		}
+		for _, th := range VintageThresholds {
+			loans, principal := dpdColumns(s, th)
+			p.Overdue = append(p.Overdue, VintageRate{
+				MinDPD:        th,
+				Loans:         *loans,
+				Principal:     *principal,
+				LoanRate:      percent(*loans, s.ObservedLoans),
+				PrincipalRate: percent(*principal, s.ObservedPrincipal),
+			})
+		}
+		c.Points = append(c.Points, p)
+	}
+	sort.Slice(r.Cohorts, func(i, j int) bool { return r.Cohorts[i].Cohort < r.Cohorts[j].Cohort })
+	for _, c := range r.Cohorts {
+		sort.Slice(c.Points, func(i, j int) bool { return c.Points[i].Mob < c.Points[j].Mob })
+	}
+	return r
+}
+
+// percent a/b 的百分比，保留两位小数
+func percent(a int64, b int64) float64 {
+	if b <= 0 {
+		return 0
+	}
+	return math.Round(float64(a)*10000/float64(b)) / 100
+}
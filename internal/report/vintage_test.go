@@ -0,0 +1,144 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan/internal/model"
+)
+
+func date(m time.Month, d int) time.Time {
+	return time.Date(2026, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func ptr(t time.Time) *time.Time { return &t }
+
+// vintageLoans 2026-01 批次：按时还清、逾期45天后还清、一直未还各一笔，以及 1 月底放款的一笔
+func vintageLoans() []VintageLoan {
+	return []VintageLoan{
+		{
+			DisbursedAt:  date(1, 5),
+			Principal:    10000,
+			Installments: []VintageInstallment{{DueDate: date(2, 4), SettledAt: ptr(date(2, 3))}},
+			Payments:     []VintagePayment{{PaidAt: date(2, 3), Principal: 10000, Amount: 11000}},
+		},
+		{
+			DisbursedAt:  date(1, 10),
+			Principal:    20000,
+			Installments: []VintageInstallment{{DueDate: date(2, 9), SettledAt: ptr(date(3, 26))}},
+			Payments: []VintagePayment{
+				{PaidAt: date(2, 20), Principal: 5000, Amount: 5000},
+				{PaidAt: date(2, 21), Principal: -5000, Amount: -5000}, // 冲正
+				{PaidAt: date(3, 26), Principal: 20000, Amount: 23000},
+			},
+		},
+		{
+			DisbursedAt:  date(1, 15),
+			Principal:    30000,
+			Installments: []VintageInstallment{{DueDate: date(2, 14)}},
+		},
+		{
+			DisbursedAt:  date(1, 31),
+			Principal:    40000,
+			Installments: []VintageInstallment{{DueDate: date(3, 2)}},
+		},
+	}
+}
+
+func TestMaxDPDAt(t *testing.T) {
+	loans := vintageLoans()
+	assert.Equal(t, 0, loans[0].MaxDPDAt(date(6, 1)))
+	assert.Equal(t, 20, loans[1].MaxDPDAt(date(3, 1)))
+	// 已结清的期次逾期天数停在结清日
+	assert.Equal(t, 45, loans[1].MaxDPDAt(date(6, 1)))
+	assert.Equal(t, 0, loans[2].MaxDPDAt(date(2, 14)))
+	assert.Equal(t, 1, loans[2].MaxDPDAt(date(2, 15)))
+}
+
+func TestVintage(t *testing.T) {
+	// 1-31 放款的借款 MOB2 观察点为 3-31，晚于 asOf
+	snaps := Vintage("2026-01", vintageLoans(), date(3, 20))
+	require.Len(t, snaps, 2)
+
+	mob1 := snaps[0]
+	assert.Equal(t, 1, mob1.Mob)
+	assert.Equal(t, int64(4), mob1.CohortLoans)
+	assert.Equal(t, int64(100000), mob1.CohortPrincipal)
+	// MOB1 观察点：2-5、2-10、2-15、3-3(1-31 加一个月按 time.AddDate 规则顺延)
+	assert.Equal(t, int64(4), mob1.ObservedLoans)
+	assert.Equal(t, int64(3), mob1.Dpd1Loans)
+	assert.Equal(t, int64(90000), mob1.Dpd1Principal)
+	assert.Equal(t, int64(0), mob1.Dpd30Loans)
+	assert.Equal(t, int64(10000), mob1.CollectedPrincipal)
+	assert.Equal(t, int64(11000), mob1.CollectedAmount)
+
+	mob2 := snaps[1]
+	// MOB2 观察点：3-5、3-10、3-15
+	assert.Equal(t, int64(3), mob2.ObservedLoans)
+	assert.Equal(t, int64(60000), mob2.ObservedPrincipal)
+	assert.Equal(t, int64(2), mob2.Dpd1Loans)
+	assert.Equal(t, int64(0), mob2.Dpd30Loans) // 3-10 逾期29天
+	assert.Equal(t, int64(10000), mob2.CollectedPrincipal)
+}
+
+func TestVintageLaterSnapshot(t *testing.T) {
+	snaps := Vintage("2026-01", vintageLoans(), date(8, 1))
+	require.Len(t, snaps, 6)
+
+	mob3 := snaps[2]
+	assert.Equal(t, int64(4), mob3.ObservedLoans)
+	// MOB3 观察点：4-5、4-10(已于逾期45天时结清)、4-15(逾期60天)、5-1(逾期60天)
+	assert.Equal(t, int64(3), mob3.Dpd1Loans)
+	assert.Equal(t, int64(3), mob3.Dpd30Loans)
+	assert.Equal(t, int64(90000), mob3.Dpd30Principal)
+	assert.Equal(t, int64(2), mob3.Dpd60Loans)
+	assert.Equal(t, int64(70000), mob3.Dpd60Principal)
+	assert.Equal(t, int64(0), mob3.Dpd90Loans)
+	assert.Equal(t, int64(30000), mob3.CollectedPrincipal)
+	assert.Equal(t, int64(34000), mob3.CollectedAmount)
+
+	mob6 := snaps[5]
+	assert.Equal(t, int64(2), mob6.Dpd90Loans)
+	assert.Equal(t, int64(70000), mob6.Dpd90Principal)
+}
+
+func TestVintageEmpty(t *testing.T) {
+	assert.Empty(t, Vintage("2026-01", nil, date(8, 1)))
+	// 放款不足一个月，没有观察点
+	assert.Empty(t, Vintage("2026-01", vintageLoans(), date(2, 1)))
+}
+
+func TestBuildVintageReport(t *testing.T) {
+	snaps := Vintage("2026-01", vintageLoans(), date(3, 20))
+	snaps = append(snaps, &model.LoanVintageSnapshots{Cohort: "2025-12", Mob: 1, CohortLoans: 1, ObservedLoans: 1})
+	// 乱序传入
+	snaps[0], snaps[1] = snaps[1], snaps[0]
+
+	r := BuildVintageReport(snaps)
+	assert.Equal(t, []int{1, 30, 60, 90}, r.Thresholds)
+	require.Len(t, r.Cohorts, 2)
+	assert.Equal(t, "2025-12", r.Cohorts[0].Cohort)
+
+	c := r.Cohorts[1]
+	assert.Equal(t, "2026-01", c.Cohort)
+	assert.Equal(t, int64(100000), c.Principal)
+	require.Len(t, c.Points, 2)
+	assert.Equal(t, 1, c.Points[0].Mob)
+	assert.Equal(t, VintageRate{MinDPD: 1, Loans: 3, Principal: 90000, LoanRate: 75, PrincipalRate: 90}, c.Points[0].Overdue[0])
+	assert.Equal(t, 10.0, c.Points[0].RecoveryRate)
+	assert.Equal(t, 66.67, c.Points[1].Overdue[0].LoanRate)
+	assert.Equal(t, 16.67, c.Points[1].RecoveryRate)
+}
+
+func TestParseCohort(t *testing.T) {
+	c, err := ParseCohort("2026-03")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-03", CohortOf(c))
+	assert.Equal(t, 1, c.Day())
+
+	_, err = ParseCohort("2026-3-1")
+	assert.Error(t, err)
+}
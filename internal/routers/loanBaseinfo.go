@@ -3,6 +3,7 @@ package routers
 import (
 	"loan/internal/authz"
 	"loan/internal/handler"
+	"loan/internal/idempotency"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
@@ -30,8 +31,15 @@ func loanBaseinfoRouter(group *gin.RouterGroup, h handler.LoanBaseinfoHandler) {
 	g.GET("/:id", middleware.Auth(), authz.RequirePerm("customer:view"), h.GetByID)
 	g.POST("/list", middleware.Auth(), authz.RequirePerm("customer:view"), h.List)
 
-	g.POST("/pre-review", middleware.Auth(), authz.RequirePerm("loan:pre_review"), h.PreReview)
-	g.POST("/finance-review", middleware.Auth(), authz.RequirePerm("loan:finance_review"), h.FinanceReview)
+	g.POST("/pre-review", middleware.Auth(), authz.RequirePerm("loan:pre_review"), idempotency.Guard(), h.PreReview)
+	g.POST("/finance-review", middleware.Auth(), authz.RequirePerm("loan:finance_review"), idempotency.Guard(), h.FinanceReview)
+	// 按审批流程定义审批当前节点，节点所需权限在 handler 中校验
+	g.POST("/review", middleware.Auth(), idempotency.Guard(), h.Review)
+	g.GET("/:id/review-progress", middleware.Auth(), authz.RequirePerm("customer:view"), h.ReviewProgress)
+	// 审批工作队列：领取下一张可审批的申请单(节点权限在 handler 中校验)、释放、改派
+	g.POST("/claim", middleware.Auth(), h.Claim)
+	g.POST("/:id/release", middleware.Auth(), h.ReleaseClaim)
+	g.POST("/:id/reassign", middleware.Auth(), authz.RequirePerm("customer:reassign"), h.ReassignClaim)
 
 	g.POST("/withAuditRecord/list", middleware.Auth(), authz.RequirePerm("customer:view"), h.WithAuditRecordList)
 
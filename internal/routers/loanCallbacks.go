@@ -0,0 +1,21 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"loan/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		loanCallbacksRouter(group, handler.NewLoanCallbacksHandler())
+	})
+}
+
+func loanCallbacksRouter(group *gin.RouterGroup, h handler.LoanCallbacksHandler) {
+	g := group.Group("/callbacks")
+
+	// 渠道回调为公开接口，不使用 jwt 认证，由 handler 按渠道回调密钥校验 HMAC 签名
+
+	g.POST("/collect/:channelCode", h.Collect) // [post] /api/v1/callbacks/collect/:channelCode
+}
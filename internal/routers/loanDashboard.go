@@ -0,0 +1,23 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+
+	"loan/internal/authz"
+	"loan/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		loanDashboardRouter(group, handler.NewLoanDashboardHandler())
+	})
+}
+
+func loanDashboardRouter(group *gin.RouterGroup, h handler.LoanDashboardHandler) {
+	g := group.Group("/dashboard")
+
+	g.Use(middleware.Auth())
+
+	g.GET("/kpis", authz.RequirePerm("dashboard:view"), h.KPIs) // [get] /api/v1/dashboard/kpis
+}
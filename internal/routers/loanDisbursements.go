@@ -6,6 +6,7 @@ import (
 
 	"loan/internal/authz"
 	"loan/internal/handler"
+	"loan/internal/idempotency"
 )
 
 func init() {
@@ -25,11 +26,14 @@ func loanDisbursementsRouter(group *gin.RouterGroup, h handler.LoanDisbursements
 	// If jwt authentication is not required for all routes, authentication middleware can be added
 	// separately for only certain routes. In this case, g.Use(middleware.Auth()) above should not be used.
 
-	g.POST("/", authz.RequirePerm("disbursement:add"), h.Create)             // [post] /api/v1/loanDisbursements
-	g.DELETE("/:id", authz.RequirePerm("disbursement:delete"), h.DeleteByID) // [delete] /api/v1/loanDisbursements/:id
-	g.PUT("/:id", authz.RequirePerm("disbursement:update"), h.UpdateByID)    // [put] /api/v1/loanDisbursements/:id
-	g.GET("/:id", authz.RequirePerm("disbursement:view"), h.GetByID)         // [get] /api/v1/loanDisbursements/:id
-	g.POST("/list", authz.RequirePerm("disbursement:view"), h.List)          // [post] /api/v1/loanDisbursements/list
+	g.POST("/", authz.RequirePerm("disbursement:add"), idempotency.Guard(), h.Create) // [post] /api/v1/loanDisbursements
+	g.DELETE("/:id", authz.RequirePerm("disbursement:delete"), h.DeleteByID)          // [delete] /api/v1/loanDisbursements/:id
+	g.PUT("/:id", authz.RequirePerm("disbursement:update"), h.UpdateByID)             // [put] /api/v1/loanDisbursements/:id
+	g.GET("/:id", authz.RequirePerm("disbursement:view"), h.GetByID)                  // [get] /api/v1/loanDisbursements/:id
+	g.POST("/list", authz.RequirePerm("disbursement:view"), h.List)                   // [post] /api/v1/loanDisbursements/list
 	g.POST("/overview", authz.RequirePerm("disbursement:view"), h.Overview)
+	g.POST("/:id/retry", authz.RequirePerm("disbursement:retry"), idempotency.Guard(), h.Retry)
+	g.POST("/:id/cancel", authz.RequirePerm("disbursement:cancel"), idempotency.Guard(), h.Cancel)
+	g.GET("/:id/attempts", authz.RequirePerm("disbursement:view"), h.Attempts)
 
 }
@@ -0,0 +1,26 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+
+	"loan/internal/authz"
+	"loan/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		loanLedgerRouter(group, handler.NewLoanLedgerHandler())
+	})
+}
+
+func loanLedgerRouter(group *gin.RouterGroup, h handler.LoanLedgerHandler) {
+	g := group.Group("/ledger")
+
+	g.Use(middleware.Auth())
+
+	g.GET("/trial-balance", authz.RequirePerm("ledger:view"), h.TrialBalance)               // [get] /api/v1/ledger/trial-balance
+	g.GET("/accounts", authz.RequirePerm("ledger:view"), h.Accounts)                        // [get] /api/v1/ledger/accounts
+	g.GET("/accounts/:account/balance", authz.RequirePerm("ledger:view"), h.AccountBalance) // [get] /api/v1/ledger/accounts/:account/balance
+	g.POST("/entries/list", authz.RequirePerm("ledger:view"), h.Entries)                    // [post] /api/v1/ledger/entries/list
+}
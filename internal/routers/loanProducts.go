@@ -0,0 +1,31 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+
+	"loan/internal/authz"
+	"loan/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		loanProductsRouter(group, handler.NewLoanProductsHandler())
+	})
+}
+
+func loanProductsRouter(group *gin.RouterGroup, h handler.LoanProductsHandler) {
+	g := group.Group("/products")
+
+	// All the following routes use jwt authentication, you also can use middleware.Auth(middleware.WithExtraVerify(fn))
+	g.Use(middleware.Auth())
+
+	// If jwt authentication is not required for all routes, authentication middleware can be added
+	// separately for only certain routes. In this case, g.Use(middleware.Auth()) above should not be used.
+
+	g.POST("/", authz.RequirePerm("product:add"), h.Create)             // [post] /api/v1/products
+	g.DELETE("/:id", authz.RequirePerm("product:delete"), h.DeleteByID) // [delete] /api/v1/products/:id
+	g.PUT("/:id", authz.RequirePerm("product:update"), h.UpdateByID)    // [put] /api/v1/products/:id
+	g.GET("/:id", authz.RequirePerm("product:view"), h.GetByID)         // [get] /api/v1/products/:id
+	g.POST("/list", authz.RequirePerm("product:view"), h.List)          // [post] /api/v1/products/list
+}
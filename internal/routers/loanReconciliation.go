@@ -0,0 +1,23 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+
+	"loan/internal/authz"
+	"loan/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		loanReconciliationRouter(group, handler.NewLoanReconciliationHandler())
+	})
+}
+
+func loanReconciliationRouter(group *gin.RouterGroup, h handler.LoanReconciliationHandler) {
+	g := group.Group("/reconciliation")
+
+	g.Use(middleware.Auth())
+
+	g.POST("/settlement", authz.RequirePerm("reconciliation:run"), h.Settlement) // [post] /api/v1/reconciliation/settlement
+}
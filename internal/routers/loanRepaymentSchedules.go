@@ -6,6 +6,7 @@ import (
 
 	"loan/internal/authz"
 	"loan/internal/handler"
+	"loan/internal/idempotency"
 )
 
 func init() {
@@ -30,5 +31,10 @@ func loanRepaymentSchedulesRouter(group *gin.RouterGroup, h handler.LoanRepaymen
 	g.PUT("/:id", authz.RequirePerm("repayment-schedule:update"), h.UpdateByID)    // [put] /api/v1/loanRepaymentSchedules/:id
 	g.GET("/:id", authz.RequirePerm("repayment-schedule:view"), h.GetByID)         // [get] /api/v1/loanRepaymentSchedules/:id
 	g.POST("/list", authz.RequirePerm("repayment-schedule:view"), h.List)          // [post] /api/v1/loanRepaymentSchedules/list
+	g.GET("/:id/penalty-accruals", authz.RequirePerm("repayment-schedule:view"), h.PenaltyAccruals)
+	g.POST("/:id/penalty-recompute", authz.RequirePerm("repayment-schedule:update"), h.RecomputePenalty)
+	g.GET("/payoff-quote", authz.RequirePerm("repayment-schedule:view"), h.PayoffQuote)
+	g.POST("/:id/extend", authz.RequirePerm("repayment-schedule:update"), idempotency.Guard(), h.Extend)
+	g.GET("/:id/extensions", authz.RequirePerm("repayment-schedule:view"), h.Extensions)
 
 }
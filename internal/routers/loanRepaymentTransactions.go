@@ -6,6 +6,7 @@ import (
 
 	"loan/internal/authz"
 	"loan/internal/handler"
+	"loan/internal/idempotency"
 )
 
 func init() {
@@ -27,14 +28,16 @@ func loanRepaymentTransactionsRouter(group *gin.RouterGroup, h handler.LoanRepay
 	// If jwt authentication is not required for all routes, authentication middleware can be added
 	// separately for only certain routes. In this case, g.Use(middleware.Auth()) above should not be used.
 
-	g.POST("/", authz.RequirePerm("repayment-transaction:add"), h.Create)             // [post] /api/v1/loanRepaymentTransactions
-	g.DELETE("/:id", authz.RequirePerm("repayment-transaction:delete"), h.DeleteByID) // [delete] /api/v1/loanRepaymentTransactions/:id
-	g.PUT("/:id", authz.RequirePerm("repayment-transaction:update"), h.UpdateByID)    // [put] /api/v1/loanRepaymentTransactions/:id
-	g.GET("/:id", authz.RequirePerm("repayment-transaction:view"), h.GetByID)         // [get] /api/v1/loanRepaymentTransactions/:id
-	g.POST("/list", authz.RequirePerm("repayment-transaction:view"), h.List)          // [post] /api/v1/loanRepaymentTransactions/list
+	g.POST("/", authz.RequirePerm("repayment-transaction:add"), idempotency.Guard(), h.Create) // [post] /api/v1/loanRepaymentTransactions
+	g.DELETE("/:id", authz.RequirePerm("repayment-transaction:delete"), h.DeleteByID)          // [delete] /api/v1/loanRepaymentTransactions/:id
+	g.PUT("/:id", authz.RequirePerm("repayment-transaction:update"), h.UpdateByID)             // [put] /api/v1/loanRepaymentTransactions/:id
+	g.GET("/:id", authz.RequirePerm("repayment-transaction:view"), h.GetByID)                  // [get] /api/v1/loanRepaymentTransactions/:id
+	g.POST("/list", authz.RequirePerm("repayment-transaction:view"), h.List)                   // [post] /api/v1/loanRepaymentTransactions/list
 
 	g.POST("/loan-info", authz.RequirePerm("repayment-transaction:view"), h.DetailByScheduleID)
 	g.POST("/history", authz.RequirePerm("repayment-transaction:view"), h.History)
 	g.POST("/upload-voucher", authz.RequirePerm("repayment-transaction:upload"), h.UploadVoucher)
 	g.GET("/upload-voucher/:file_name", authz.RequirePerm("repayment-transaction:view"), h.GetVoucherBase64)
+	g.POST("/:id/reverse", authz.RequirePerm("repayment-transaction:reverse"), idempotency.Guard(), h.Reverse)
+	g.POST("/payoff", authz.RequirePerm("repayment-transaction:add"), idempotency.Guard(), h.Payoff)
 }
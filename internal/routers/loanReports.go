@@ -0,0 +1,25 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+
+	"loan/internal/authz"
+	"loan/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		loanReportsRouter(group, handler.NewLoanReportsHandler())
+	})
+}
+
+func loanReportsRouter(group *gin.RouterGroup, h handler.LoanReportsHandler) {
+	g := group.Group("/reports")
+
+	g.Use(middleware.Auth())
+
+	g.POST("/aging", authz.RequirePerm("report:view"), h.Aging)                            // [post] /api/v1/reports/aging
+	g.POST("/vintage", authz.RequirePerm("report:view"), h.Vintage)                        // [post] /api/v1/reports/vintage
+	g.POST("/reviewer-throughput", authz.RequirePerm("report:view"), h.ReviewerThroughput) // [post] /api/v1/reports/reviewer-throughput
+}
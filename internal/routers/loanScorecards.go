@@ -0,0 +1,27 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+
+	"loan/internal/authz"
+	"loan/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		loanScorecardsRouter(group, handler.NewLoanScorecardsHandler())
+	})
+}
+
+func loanScorecardsRouter(group *gin.RouterGroup, h handler.LoanScorecardsHandler) {
+	g := group.Group("/scorecards")
+
+	g.Use(middleware.Auth())
+
+	g.POST("", authz.RequirePerm("scorecard:manage"), h.Create)                 // [post] /api/v1/scorecards
+	g.GET("", authz.RequirePerm("scorecard:view"), h.List)                      // [get] /api/v1/scorecards
+	g.GET("/:id", authz.RequirePerm("scorecard:view"), h.GetByID)               // [get] /api/v1/scorecards/:id
+	g.PUT("/:id/status", authz.RequirePerm("scorecard:manage"), h.UpdateStatus) // [put] /api/v1/scorecards/:id/status
+	g.POST("/score/:id", authz.RequirePerm("scorecard:manage"), h.Score)        // [post] /api/v1/scorecards/score/:id
+}
@@ -0,0 +1,29 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+
+	"loan/internal/authz"
+	"loan/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		loanScreeningRulesRouter(group, handler.NewLoanScreeningRulesHandler())
+	})
+}
+
+func loanScreeningRulesRouter(group *gin.RouterGroup, h handler.LoanScreeningRulesHandler) {
+	g := group.Group("/screening")
+
+	g.Use(middleware.Auth())
+
+	g.POST("/rules", authz.RequirePerm("screening:manage"), h.Create)           // [post] /api/v1/screening/rules
+	g.DELETE("/rules/:id", authz.RequirePerm("screening:manage"), h.DeleteByID) // [delete] /api/v1/screening/rules/:id
+	g.PUT("/rules/:id", authz.RequirePerm("screening:manage"), h.UpdateByID)    // [put] /api/v1/screening/rules/:id
+	g.GET("/rules", authz.RequirePerm("screening:view"), h.List)                // [get] /api/v1/screening/rules
+	g.POST("/dry-run", authz.RequirePerm("screening:view"), h.DryRun)           // [post] /api/v1/screening/dry-run
+	g.POST("/run/:id", authz.RequirePerm("screening:manage"), h.Rerun)          // [post] /api/v1/screening/run/:id
+	g.GET("/decisions/:id", authz.RequirePerm("customer:view"), h.Decisions)    // [get] /api/v1/screening/decisions/:id
+}
@@ -0,0 +1,28 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+
+	"loan/internal/authz"
+	"loan/internal/handler"
+	"loan/internal/idempotency"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		loanWaiverRequestsRouter(group, handler.NewLoanWaiverRequestsHandler())
+	})
+}
+
+func loanWaiverRequestsRouter(group *gin.RouterGroup, h handler.LoanWaiverRequestsHandler) {
+	g := group.Group("/waiver-request")
+
+	g.Use(middleware.Auth())
+
+	g.POST("/", authz.RequirePerm("waiver-request:add"), h.Create)                               // [post] /api/v1/waiver-request
+	g.GET("/:id", authz.RequirePerm("waiver-request:view"), h.GetByID)                           // [get] /api/v1/waiver-request/:id
+	g.POST("/list", authz.RequirePerm("waiver-request:view"), h.List)                            // [post] /api/v1/waiver-request/list
+	g.POST("/:id/approve", authz.RequirePerm("repayment:waive"), idempotency.Guard(), h.Approve) // [post] /api/v1/waiver-request/:id/approve
+	g.POST("/:id/reject", authz.RequirePerm("repayment:waive"), h.Reject)                        // [post] /api/v1/waiver-request/:id/reject
+}
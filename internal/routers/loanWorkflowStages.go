@@ -0,0 +1,26 @@
+package routers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+
+	"loan/internal/authz"
+	"loan/internal/handler"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		loanWorkflowStagesRouter(group, handler.NewLoanWorkflowStagesHandler())
+	})
+}
+
+func loanWorkflowStagesRouter(group *gin.RouterGroup, h handler.LoanWorkflowStagesHandler) {
+	g := group.Group("/workflow-stages")
+
+	g.Use(middleware.Auth())
+
+	g.POST("/", authz.RequirePerm("workflow:manage"), h.Create)          // [post] /api/v1/workflow-stages
+	g.DELETE("/:id", authz.RequirePerm("workflow:manage"), h.DeleteByID) // [delete] /api/v1/workflow-stages/:id
+	g.PUT("/:id", authz.RequirePerm("workflow:manage"), h.UpdateByID)    // [put] /api/v1/workflow-stages/:id
+	g.GET("/", authz.RequirePerm("workflow:view"), h.List)               // [get] /api/v1/workflow-stages
+}
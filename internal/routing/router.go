@@ -0,0 +1,52 @@
+package routing
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"loan/internal/dao"
+)
+
+// Router 读取渠道配置与当日放款额度生成路由计划，审核放款、人工重试与代付任务失败回退共用
+type Router struct {
+	ChannelDao      dao.LoanPaymentChannelsDao
+	DisbursementDao dao.LoanDisbursementsDao
+}
+
+// Plan 为放款金额生成自动路由计划，exclude 为需要排除的渠道(如已失败的渠道)
+func (r *Router) Plan(ctx context.Context, disburseAmount int64, otherDeductions int64, exclude ...uint64) (*Plan, error) {
+	channels, err := r.ChannelDao.GetPayoutEnabled(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	used, err := r.DisbursementDao.SumPayoutVolumeSince(ctx, dayStart)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := make([]Candidate, 0, len(channels))
+	for _, ch := range channels {
+		candidates = append(candidates, Candidate{Channel: ch, UsedToday: used[ch.ID]})
+	}
+	excluded := make(map[uint64]bool, len(exclude))
+	for _, id := range exclude {
+		excluded[id] = true
+	}
+	return Build(candidates, disburseAmount, otherDeductions, excluded, rand.Float64), nil
+}
+
+// Manual 人工指定渠道：校验渠道可用、单笔限额并计算手续费，不受当日额度与权重限制
+func (r *Router) Manual(ctx context.Context, channelID uint64, disburseAmount int64, otherDeductions int64) (Route, error) {
+	ch, err := r.ChannelDao.GetByID(ctx, channelID)
+	if err != nil {
+		return Route{}, err
+	}
+	p, err := Check(ch, disburseAmount, otherDeductions)
+	if err != nil {
+		return Route{}, err
+	}
+	return Route{Channel: ch, Payout: p}, nil
+}
@@ -0,0 +1,192 @@
+// Package routing 代付渠道自动路由：在启用且支持代付的渠道中，按优先级、权重、单笔限额和当日额度为放款单选择渠道，
+// 并给出按顺序排列的备选渠道，放款失败时可依次回退。
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"loan/internal/fee"
+	"loan/internal/model"
+	"loan/internal/payout"
+)
+
+// maxReasonLen route_reason 字段长度
+const maxReasonLen = 255
+
+var (
+	// ErrNoRoute 没有满足条件的代付渠道
+	ErrNoRoute = errors.New("no payout channel available")
+	// ErrChannelUnavailable 渠道未启用、不支持代付或未接入代付网关
+	ErrChannelUnavailable = errors.New("payout channel is unavailable")
+)
+
+// Candidate 参与路由的渠道
+type Candidate struct {
+	Channel   *model.LoanPaymentChannels
+	UsedToday int64 // 当日已占用的代付额度(分)
+}
+
+// Route 一个可用的放款渠道及其计费结果
+type Route struct {
+	Channel *model.LoanPaymentChannels
+	Payout  fee.Payout
+}
+
+// Skip 未参与路由的渠道及原因
+type Skip struct {
+	Code   string
+	Reason string
+}
+
+// Plan 路由结果，Routes 按尝试顺序排列，第一个为选中渠道，其余为失败回退顺序
+type Plan struct {
+	Routes  []Route
+	Skipped []Skip
+}
+
+// Best 选中的渠道
+func (p *Plan) Best() (Route, error) {
+	if len(p.Routes) == 0 {
+		return Route{}, fmt.Errorf("%w: %s", ErrNoRoute, p.skippedText())
+	}
+	return p.Routes[0], nil
+}
+
+// Reason 选中渠道的说明，写入 loan_disbursements.route_reason
+func (p *Plan) Reason() string {
+	if len(p.Routes) == 0 {
+		return truncate("自动路由无可用渠道；" + p.skippedText())
+	}
+	ch := p.Routes[0].Channel
+	s := fmt.Sprintf("自动路由选中 %s(优先级%d 权重%d)", ch.Code, ch.RoutePriority, weightOf(ch))
+	if len(p.Routes) > 1 {
+		codes := make([]string, 0, len(p.Routes)-1)
+		for _, r := range p.Routes[1:] {
+			codes = append(codes, r.Channel.Code)
+		}
+		s += "；备选 " + strings.Join(codes, ",")
+	}
+	if len(p.Skipped) > 0 {
+		s += "；跳过 " + p.skippedText()
+	}
+	return truncate(s)
+}
+
+func (p *Plan) skippedText() string {
+	parts := make([]string, 0, len(p.Skipped))
+	for _, sk := range p.Skipped {
+		parts = append(parts, sk.Code+":"+sk.Reason)
+	}
+	return strings.Join(parts, ",")
+}
+
+// ManualReason 人工指定渠道的说明
+func ManualReason(ch *model.LoanPaymentChannels, note string) string {
+	s := "人工指定 " + ch.Code
+	if note != "" {
+		s += "；" + note
+	}
+	return truncate(s)
+}
+
+// FallbackReason 放款失败后自动切换渠道的说明
+func FallbackReason(failedCode string, failReason string, p *Plan) string {
+	return truncate(fmt.Sprintf("%s 放款失败(%s)后切换；%s", failedCode, failReason, p.Reason()))
+}
+
+// Check 校验渠道可用于代付并计算手续费与到账金额，人工指定与自动路由共用
+func Check(ch *model.LoanPaymentChannels, disburseAmount int64, otherDeductions int64) (fee.Payout, error) {
+	if ch.Status != 1 || ch.CanPayout != 1 {
+		return fee.Payout{}, fmt.Errorf("%w: %s is disabled or does not support payout", ErrChannelUnavailable, ch.Code)
+	}
+	if _, err := payout.Get(ch.Code); err != nil {
+		return fee.Payout{}, fmt.Errorf("%w: %v", ErrChannelUnavailable, err)
+	}
+	return fee.CalcPayout(fee.PayoutRule(ch), disburseAmount, otherDeductions)
+}
+
+// Build 生成路由计划：过滤不可用、超出限额、超出当日额度及 exclude 中的渠道，
+// 按优先级升序，同优先级内按权重做加权随机排序(rnd 返回 [0,1) 随机数)。
+func Build(candidates []Candidate, disburseAmount int64, otherDeductions int64, exclude map[uint64]bool, rnd func() float64) *Plan {
+	type ranked struct {
+		route Route
+		key   float64
+	}
+	plan := &Plan{}
+	list := []ranked{}
+	for _, c := range candidates {
+		ch := c.Channel
+		if exclude[ch.ID] {
+			plan.Skipped = append(plan.Skipped, Skip{Code: ch.Code, Reason: "已失败"})
+			continue
+		}
+		if ch.Status != 1 || ch.CanPayout != 1 {
+			plan.Skipped = append(plan.Skipped, Skip{Code: ch.Code, Reason: "未启用代付"})
+			continue
+		}
+		p, err := Check(ch, disburseAmount, otherDeductions)
+		if err != nil {
+			plan.Skipped = append(plan.Skipped, Skip{Code: ch.Code, Reason: skipReason(err)})
+			continue
+		}
+		if ch.PayoutDailyCap > 0 && c.UsedToday+p.NetAmount > ch.PayoutDailyCap {
+			plan.Skipped = append(plan.Skipped, Skip{Code: ch.Code, Reason: "超出当日额度"})
+			continue
+		}
+		// 加权随机排序(Efraimidis-Spirakis)：key = u^(1/w)，key 越大越靠前
+		u := rnd()
+		if u <= 0 {
+			u = math.SmallestNonzeroFloat64
+		}
+		list = append(list, ranked{
+			route: Route{Channel: ch, Payout: p},
+			key:   math.Pow(u, 1/float64(weightOf(ch))),
+		})
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		pi, pj := list[i].route.Channel.RoutePriority, list[j].route.Channel.RoutePriority
+		if pi != pj {
+			return pi < pj
+		}
+		return list[i].key > list[j].key
+	})
+	for _, r := range list {
+		plan.Routes = append(plan.Routes, r.route)
+	}
+	return plan
+}
+
+func weightOf(ch *model.LoanPaymentChannels) int {
+	if ch.RouteWeight <= 0 {
+		return 1
+	}
+	return ch.RouteWeight
+}
+
+func skipReason(err error) string {
+	switch {
+	case errors.Is(err, ErrChannelUnavailable):
+		return "未接入代付网关"
+	case errors.Is(err, fee.ErrBelowMin):
+		return "低于单笔最小金额"
+	case errors.Is(err, fee.ErrAboveMax):
+		return "超过单笔最大金额"
+	case errors.Is(err, fee.ErrFeeExceedsAmount):
+		return "手续费超过放款金额"
+	default:
+		return err.Error()
+	}
+}
+
+func truncate(s string) string {
+	r := []rune(s)
+	if len(r) <= maxReasonLen {
+		return s
+	}
+	return string(r[:maxReasonLen-1]) + "…"
+}
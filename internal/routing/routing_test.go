@@ -0,0 +1,180 @@
+package routing
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan/internal/model"
+	"loan/internal/payout"
+)
+
+func init() {
+	payout.Register("ROUTE_A", payout.NewMockGateway())
+	payout.Register("ROUTE_B", payout.NewMockGateway())
+	payout.Register("ROUTE_C", payout.NewMockGateway())
+}
+
+func channel(id uint64, code string, priority int, weight int) *model.LoanPaymentChannels {
+	ch := &model.LoanPaymentChannels{
+		Code:          code,
+		Status:        1,
+		CanPayout:     1,
+		RoutePriority: priority,
+		RouteWeight:   weight,
+	}
+	ch.ID = id
+	return ch
+}
+
+func codes(p *Plan) []string {
+	out := []string{}
+	for _, r := range p.Routes {
+		out = append(out, r.Channel.Code)
+	}
+	return out
+}
+
+func fixedRnd(vals ...float64) func() float64 {
+	i := 0
+	return func() float64 {
+		v := vals[i%len(vals)]
+		i++
+		return v
+	}
+}
+
+func TestBuildFilters(t *testing.T) {
+	disabled := channel(1, "ROUTE_A", 0, 1)
+	disabled.Status = 0
+	noGateway := channel(2, "UNKNOWN", 0, 1)
+	tooSmall := channel(3, "ROUTE_B", 0, 1)
+	tooSmall.PayoutMinAmount = 200000
+	capped := channel(4, "ROUTE_C", 0, 1)
+	capped.PayoutDailyCap = 150000
+	failed := channel(5, "MOCK", 0, 1)
+	ok := channel(6, "ROUTE_A", 1, 1)
+	ok.PayoutFeeRate, ok.PayoutFeeFixed = 2, 100
+
+	plan := Build([]Candidate{
+		{Channel: disabled},
+		{Channel: noGateway},
+		{Channel: tooSmall},
+		{Channel: capped, UsedToday: 60000},
+		{Channel: failed},
+		{Channel: ok},
+	}, 100000, 0, map[uint64]bool{5: true}, fixedRnd(0.5))
+
+	require.Len(t, plan.Routes, 1)
+	best, err := plan.Best()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(6), best.Channel.ID)
+	assert.Equal(t, int64(2100), best.Payout.Fee)
+	assert.Equal(t, int64(97900), best.Payout.NetAmount)
+
+	reasons := map[string]string{}
+	for _, sk := range plan.Skipped {
+		reasons[sk.Code] = sk.Reason
+	}
+	assert.Equal(t, "未启用代付", reasons["ROUTE_A"])
+	assert.Equal(t, "未接入代付网关", reasons["UNKNOWN"])
+	assert.Equal(t, "低于单笔最小金额", reasons["ROUTE_B"])
+	assert.Equal(t, "超出当日额度", reasons["ROUTE_C"])
+	assert.Equal(t, "已失败", reasons["MOCK"])
+	assert.Len(t, plan.Skipped, 5)
+}
+
+func TestBuildDailyCapBoundary(t *testing.T) {
+	ch := channel(1, "ROUTE_A", 0, 1)
+	ch.PayoutDailyCap = 100000
+
+	plan := Build([]Candidate{{Channel: ch, UsedToday: 50000}}, 50000, 0, nil, fixedRnd(0.5))
+	assert.Len(t, plan.Routes, 1)
+
+	plan = Build([]Candidate{{Channel: ch, UsedToday: 50001}}, 50000, 0, nil, fixedRnd(0.5))
+	assert.Empty(t, plan.Routes)
+}
+
+func TestBuildPriorityAndWeight(t *testing.T) {
+	a := channel(1, "ROUTE_A", 2, 1)
+	b := channel(2, "ROUTE_B", 1, 1)
+	c := channel(3, "ROUTE_C", 1, 9)
+	candidates := []Candidate{{Channel: a}, {Channel: b}, {Channel: c}}
+
+	// 同优先级：key = u^(1/w)，B=0.5，C=0.5^(1/9)≈0.926，权重大的更靠前
+	plan := Build(candidates, 100000, 0, nil, fixedRnd(0.5))
+	assert.Equal(t, []string{"ROUTE_C", "ROUTE_B", "ROUTE_A"}, codes(plan))
+
+	// 随机数足够小时低权重渠道也可能排在前面，但不会越过优先级
+	plan = Build(candidates, 100000, 0, nil, fixedRnd(0.9, 0.99, 0.01))
+	assert.Equal(t, []string{"ROUTE_B", "ROUTE_C", "ROUTE_A"}, codes(plan))
+}
+
+func TestWeightDistribution(t *testing.T) {
+	light := channel(1, "ROUTE_A", 0, 1)
+	heavy := channel(2, "ROUTE_B", 0, 3)
+	candidates := []Candidate{{Channel: light}, {Channel: heavy}}
+
+	// 均匀取样 u，权重 1:3 的渠道被选中的比例约为 1:3
+	const n = 400
+	hits := map[string]int{}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			u1, u2 := (float64(i)+0.5)/n, (float64(j)+0.5)/n
+			plan := Build(candidates, 100000, 0, nil, fixedRnd(u1, u2))
+			hits[plan.Routes[0].Channel.Code]++
+		}
+	}
+	ratio := float64(hits["ROUTE_B"]) / float64(n*n)
+	assert.InDelta(t, 0.75, ratio, 0.01)
+}
+
+func TestPlanBestNoRoute(t *testing.T) {
+	ch := channel(1, "ROUTE_A", 0, 1)
+	ch.PayoutMaxAmount = 1000
+	plan := Build([]Candidate{{Channel: ch}}, 100000, 0, nil, fixedRnd(0.5))
+
+	_, err := plan.Best()
+	assert.ErrorIs(t, err, ErrNoRoute)
+	assert.Contains(t, err.Error(), "ROUTE_A:超过单笔最大金额")
+	assert.Equal(t, "自动路由无可用渠道；ROUTE_A:超过单笔最大金额", plan.Reason())
+}
+
+func TestCheck(t *testing.T) {
+	ch := channel(1, "ROUTE_A", 0, 1)
+	ch.CanPayout = 0
+	_, err := Check(ch, 100000, 0)
+	assert.ErrorIs(t, err, ErrChannelUnavailable)
+
+	ch = channel(2, "NOT_REGISTERED", 0, 1)
+	_, err = Check(ch, 100000, 0)
+	assert.ErrorIs(t, err, ErrChannelUnavailable)
+
+	ch = channel(3, "ROUTE_A", 0, 1)
+	ch.PayoutFeeRate = 10
+	p, err := Check(ch, 100000, 5000)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10000), p.Fee)
+	assert.Equal(t, int64(85000), p.NetAmount)
+}
+
+func TestReasons(t *testing.T) {
+	a := channel(1, "ROUTE_A", 0, 3)
+	b := channel(2, "ROUTE_B", 1, 0)
+	off := channel(3, "ROUTE_C", 0, 1)
+	off.Status = 0
+	plan := Build([]Candidate{{Channel: a}, {Channel: b}, {Channel: off}}, 100000, 0, nil, fixedRnd(0.5))
+
+	assert.Equal(t, "自动路由选中 ROUTE_A(优先级0 权重3)；备选 ROUTE_B；跳过 ROUTE_C:未启用代付", plan.Reason())
+	assert.Equal(t, "人工指定 ROUTE_A；财务审核", ManualReason(a, "财务审核"))
+	assert.Equal(t, "人工指定 ROUTE_A", ManualReason(a, ""))
+
+	fb := FallbackReason("ROUTE_C", "余额不足", plan)
+	assert.True(t, strings.HasPrefix(fb, "ROUTE_C 放款失败(余额不足)后切换；自动路由选中 ROUTE_A"))
+
+	long := FallbackReason("ROUTE_C", strings.Repeat("错", 300), plan)
+	assert.Equal(t, maxReasonLen, len([]rune(long)))
+	assert.True(t, strings.HasSuffix(long, "…"))
+}
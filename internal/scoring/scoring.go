@@ -0,0 +1,240 @@
+// Package scoring 信用评分卡：由申请单字段与设备采集数据(通讯录、通话记录、短信、已安装应用)
+// 计算特征值，按评分卡中每个特征的分箱给分，得到信用分及每个特征的得分明细。评分卡定义为 JSON：
+//
+//	{"baseScore":500,"minScore":300,"maxScore":850,
+//	 "loanAppKeywords":["loan","credit"],"salaryKeywords":["salary","工资"],
+//	 "features":[{"feature":"contacts_count","missingPoints":-20,
+//	   "bins":[{"max":20,"points":-30},{"min":20,"max":100,"points":0},{"min":100,"points":25}]}]}
+//
+// 分箱区间为 [min, max)，min/max 为空表示无下限/无上限。
+package scoring
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"loan/internal/model"
+	"loan/internal/types"
+)
+
+// ErrInvalidScorecard 评分卡定义不合法
+var ErrInvalidScorecard = errors.New("invalid scorecard")
+
+// Features 评分卡可使用的特征及说明
+var Features = map[string]string{
+	"age":                   "年龄",
+	"salary":                "薪资",
+	"has_house":             "是否有房(1是 0否)",
+	"has_car":               "是否有车(1是 0否)",
+	"application_amount":    "申请金额(分)",
+	"loan_days":             "借款天数",
+	"contacts_count":        "通讯录条数(未采集设备数据时缺失)",
+	"call_records_count":    "通话记录条数(未采集设备数据时缺失)",
+	"unanswered_call_ratio": "未接/拒接通话占比 0~1(无通话记录时缺失)",
+	"sms_records_count":     "短信条数(未采集设备数据时缺失)",
+	"salary_sms_count":      "收到的工资短信条数(按 salaryKeywords 识别，未采集设备数据时缺失)",
+	"has_salary_sms":        "是否收到工资短信(1是 0否，未采集设备数据时缺失)",
+	"device_apps_count":     "已安装应用数(未采集设备数据时缺失)",
+	"loan_app_count":        "已安装借贷类应用数(按 loanAppKeywords 识别包名，未采集设备数据时缺失)",
+}
+
+// Bin 特征分箱，区间 [Min, Max)
+type Bin struct {
+	Min    *float64 `json:"min,omitempty"`
+	Max    *float64 `json:"max,omitempty"`
+	Points int      `json:"points"`
+}
+
+func (b *Bin) contains(v float64) bool {
+	return (b.Min == nil || v >= *b.Min) && (b.Max == nil || v < *b.Max)
+}
+
+func (b *Bin) String() string {
+	lower, upper := "-inf", "+inf"
+	if b.Min != nil {
+		lower = strconv.FormatFloat(*b.Min, 'f', -1, 64)
+	}
+	if b.Max != nil {
+		upper = strconv.FormatFloat(*b.Max, 'f', -1, 64)
+	}
+	return "[" + lower + ", " + upper + ")"
+}
+
+// Feature 评分卡中的一个特征：按分箱给分，特征缺失时给 MissingPoints
+type Feature struct {
+	Name          string `json:"feature"`
+	MissingPoints int    `json:"missingPoints"`
+	Bins          []*Bin `json:"bins"`
+}
+
+// Scorecard 评分卡定义
+type Scorecard struct {
+	BaseScore       int        `json:"baseScore"`                 // 基础分
+	MinScore        *int       `json:"minScore,omitempty"`        // 分数下限(为空不限制)
+	MaxScore        *int       `json:"maxScore,omitempty"`        // 分数上限(为空不限制)
+	LoanAppKeywords []string   `json:"loanAppKeywords,omitempty"` // 借贷类应用包名关键字(不区分大小写)
+	SalaryKeywords  []string   `json:"salaryKeywords,omitempty"`  // 工资短信关键字(不区分大小写)
+	Features        []*Feature `json:"features"`
+}
+
+// Parse 解析并校验评分卡定义
+func Parse(s string) (*Scorecard, error) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(s)))
+	dec.DisallowUnknownFields()
+	c := &Scorecard{}
+	if err := dec.Decode(c); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidScorecard, err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Scorecard) validate() error {
+	if c.MinScore != nil && c.MaxScore != nil && *c.MinScore > *c.MaxScore {
+		return fmt.Errorf("%w: minScore greater than maxScore", ErrInvalidScorecard)
+	}
+	if len(c.Features) == 0 {
+		return fmt.Errorf("%w: no features", ErrInvalidScorecard)
+	}
+	seen := make(map[string]bool, len(c.Features))
+	for _, f := range c.Features {
+		if f == nil {
+			return fmt.Errorf("%w: empty feature", ErrInvalidScorecard)
+		}
+		if _, ok := Features[f.Name]; !ok {
+			return fmt.Errorf("%w: unknown feature %s", ErrInvalidScorecard, f.Name)
+		}
+		if seen[f.Name] {
+			return fmt.Errorf("%w: duplicate feature %s", ErrInvalidScorecard, f.Name)
+		}
+		seen[f.Name] = true
+		if len(f.Bins) == 0 {
+			return fmt.Errorf("%w: %s has no bins", ErrInvalidScorecard, f.Name)
+		}
+		// 分箱按升序排列且互不重叠：只有第一个分箱可以无下限，只有最后一个分箱可以无上限
+		for i, b := range f.Bins {
+			if b == nil {
+				return fmt.Errorf("%w: %s has an empty bin", ErrInvalidScorecard, f.Name)
+			}
+			if b.Min != nil && b.Max != nil && *b.Min >= *b.Max {
+				return fmt.Errorf("%w: %s bin %s is empty", ErrInvalidScorecard, f.Name, b)
+			}
+			if i == 0 {
+				continue
+			}
+			prev := f.Bins[i-1]
+			if prev.Max == nil || b.Min == nil || *b.Min < *prev.Max {
+				return fmt.Errorf("%w: %s bins %s and %s overlap or are out of order", ErrInvalidScorecard, f.Name, prev, b)
+			}
+		}
+	}
+	return nil
+}
+
+// Values 特征值，缺失的特征不在 map 中
+type Values map[string]float64
+
+// Extract 由申请单与设备采集数据计算特征值，未采集任何设备数据时设备类特征缺失
+func (c *Scorecard) Extract(b *model.LoanBaseinfo, d *types.ScoringDeviceData) Values {
+	v := Values{
+		"age":                float64(b.Age),
+		"salary":             float64(b.Salary),
+		"has_house":          float64(b.HasHouse),
+		"has_car":            float64(b.HasCar),
+		"application_amount": float64(b.ApplicationAmount),
+		"loan_days":          float64(b.LoanDays),
+	}
+	if d == nil || d.Contacts+d.CallRecords+d.SmsRecords+d.DeviceApps == 0 {
+		return v
+	}
+
+	v["contacts_count"] = float64(d.Contacts)
+	v["call_records_count"] = float64(d.CallRecords)
+	if d.CallRecords > 0 {
+		v["unanswered_call_ratio"] = float64(d.UnansweredCalls) / float64(d.CallRecords)
+	}
+	v["sms_records_count"] = float64(d.SmsRecords)
+	salarySms := 0
+	for _, body := range d.InboxSms {
+		if containsAny(body, c.SalaryKeywords) {
+			salarySms++
+		}
+	}
+	v["salary_sms_count"] = float64(salarySms)
+	v["has_salary_sms"] = 0
+	if salarySms > 0 {
+		v["has_salary_sms"] = 1
+	}
+	v["device_apps_count"] = float64(d.DeviceApps)
+	loanApps := 0
+	for _, pkg := range d.Packages {
+		if containsAny(pkg, c.LoanAppKeywords) {
+			loanApps++
+		}
+	}
+	v["loan_app_count"] = float64(loanApps)
+	return v
+}
+
+func containsAny(s string, keywords []string) bool {
+	s = strings.ToLower(s)
+	for _, k := range keywords {
+		if k != "" && strings.Contains(s, strings.ToLower(k)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contribution 单个特征的得分明细
+type Contribution struct {
+	Feature string   `json:"feature"`
+	Value   *float64 `json:"value"` // 特征值，为空表示缺失
+	Bin     string   `json:"bin"`   // 命中的分箱，missing 表示缺失，none 表示未落入任何分箱(0分)
+	Points  int      `json:"points"`
+}
+
+// Result 评分结果
+type Result struct {
+	BaseScore     int            `json:"baseScore"`
+	RawScore      int            `json:"rawScore"` // 基础分加各特征得分，未按上下限截断
+	Score         int            `json:"score"`
+	Contributions []Contribution `json:"contributions"`
+}
+
+// Score 按评分卡给分，明细按评分卡中特征的顺序排列
+func (c *Scorecard) Score(v Values) Result {
+	r := Result{BaseScore: c.BaseScore, RawScore: c.BaseScore, Contributions: make([]Contribution, 0, len(c.Features))}
+	for _, f := range c.Features {
+		item := Contribution{Feature: f.Name, Bin: "none"}
+		value, ok := v[f.Name]
+		if !ok {
+			item.Bin, item.Points = "missing", f.MissingPoints
+		} else {
+			item.Value = &value
+			for _, b := range f.Bins {
+				if b.contains(value) {
+					item.Bin, item.Points = b.String(), b.Points
+					break
+				}
+			}
+		}
+		r.RawScore += item.Points
+		r.Contributions = append(r.Contributions, item)
+	}
+
+	r.Score = r.RawScore
+	if c.MinScore != nil && r.Score < *c.MinScore {
+		r.Score = *c.MinScore
+	}
+	if c.MaxScore != nil && r.Score > *c.MaxScore {
+		r.Score = *c.MaxScore
+	}
+	return r
+}
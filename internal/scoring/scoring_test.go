@@ -0,0 +1,118 @@
+package scoring
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan/internal/model"
+	"loan/internal/types"
+)
+
+const definition = `{
+	"baseScore": 500, "minScore": 300, "maxScore": 850,
+	"loanAppKeywords": ["loan", "credit"], "salaryKeywords": ["salary", "工资"],
+	"features": [
+		{"feature": "age", "bins": [{"max": 22, "points": -20}, {"min": 22, "max": 55, "points": 10}, {"min": 55, "points": -10}]},
+		{"feature": "contacts_count", "missingPoints": -30, "bins": [{"max": 20, "points": -20}, {"min": 20, "points": 20}]},
+		{"feature": "unanswered_call_ratio", "missingPoints": -5, "bins": [{"max": 0.3, "points": 15}, {"min": 0.3, "points": -25}]},
+		{"feature": "loan_app_count", "missingPoints": 0, "bins": [{"max": 1, "points": 10}, {"min": 1, "max": 3, "points": -10}, {"min": 3, "points": -40}]},
+		{"feature": "has_salary_sms", "missingPoints": 0, "bins": [{"min": 1, "points": 30}]}
+	]
+}`
+
+func parse(t *testing.T) *Scorecard {
+	c, err := Parse(definition)
+	require.NoError(t, err)
+	return c
+}
+
+func contribution(r Result, feature string) Contribution {
+	for _, c := range r.Contributions {
+		if c.Feature == feature {
+			return c
+		}
+	}
+	return Contribution{}
+}
+
+func TestExtract(t *testing.T) {
+	c := parse(t)
+	b := &model.LoanBaseinfo{Age: 30, Salary: 9000, LoanDays: 14}
+
+	v := c.Extract(b, nil)
+	assert.Equal(t, 30.0, v["age"])
+	_, ok := v["contacts_count"]
+	assert.False(t, ok)
+
+	v = c.Extract(b, &types.ScoringDeviceData{
+		Contacts:        50,
+		CallRecords:     10,
+		UnansweredCalls: 4,
+		SmsRecords:      3,
+		DeviceApps:      4,
+		InboxSms:        []string{"Your SALARY has been credited", "验证码 1234", "本月工资已到账"},
+		Packages:        []string{"com.fast.loan", "com.bank.Credit", "com.tencent.mm", "com.android.chrome"},
+	})
+	assert.Equal(t, 50.0, v["contacts_count"])
+	assert.InDelta(t, 0.4, v["unanswered_call_ratio"], 1e-9)
+	assert.Equal(t, 2.0, v["salary_sms_count"])
+	assert.Equal(t, 1.0, v["has_salary_sms"])
+	assert.Equal(t, 2.0, v["loan_app_count"])
+	assert.Equal(t, 4.0, v["device_apps_count"])
+
+	// 没有通话记录时未接占比缺失
+	v = c.Extract(b, &types.ScoringDeviceData{Contacts: 5})
+	_, ok = v["unanswered_call_ratio"]
+	assert.False(t, ok)
+	assert.Equal(t, 0.0, v["has_salary_sms"])
+}
+
+func TestScore(t *testing.T) {
+	c := parse(t)
+
+	r := c.Score(Values{"age": 30, "contacts_count": 50, "unanswered_call_ratio": 0.1, "loan_app_count": 0, "has_salary_sms": 1})
+	assert.Equal(t, 500+10+20+15+10+30, r.Score)
+	require.Len(t, r.Contributions, 5)
+	assert.Equal(t, "age", r.Contributions[0].Feature)
+	assert.Equal(t, "[22, 55)", contribution(r, "age").Bin)
+	assert.Equal(t, "[1, +inf)", contribution(r, "has_salary_sms").Bin)
+
+	// 缺失特征给 missingPoints，未落入分箱给 0 分
+	r = c.Score(Values{"age": 20, "has_salary_sms": 0})
+	assert.Equal(t, 500-20-30-5+0+0, r.Score)
+	assert.Equal(t, "missing", contribution(r, "contacts_count").Bin)
+	assert.Nil(t, contribution(r, "contacts_count").Value)
+	assert.Equal(t, "none", contribution(r, "has_salary_sms").Bin)
+	assert.Equal(t, "[-inf, 22)", contribution(r, "age").Bin)
+}
+
+func TestScoreClamp(t *testing.T) {
+	c := parse(t)
+	c.BaseScore = 900
+	r := c.Score(Values{"age": 30})
+	assert.Equal(t, 850, r.Score)
+	assert.Equal(t, 900+10-30-5, r.RawScore)
+
+	c.BaseScore = 100
+	assert.Equal(t, 300, c.Score(Values{"age": 30}).Score)
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, s := range []string{
+		``,
+		`{"baseScore":500}`,
+		`{"features":[{"feature":"nickname","bins":[{"points":1}]}]}`,
+		`{"features":[{"feature":"age","bins":[]}]}`,
+		`{"features":[{"feature":"age","bins":[{"points":1}]},{"feature":"age","bins":[{"points":1}]}]}`,
+		`{"features":[{"feature":"age","bins":[{"min":5,"max":5,"points":1}]}]}`,
+		`{"features":[{"feature":"age","bins":[{"max":30,"points":1},{"min":20,"points":2}]}]}`,
+		`{"features":[{"feature":"age","bins":[{"max":30,"points":1},{"max":40,"points":2}]}]}`,
+		`{"minScore":900,"maxScore":300,"features":[{"feature":"age","bins":[{"points":1}]}]}`,
+		`{"features":[{"feature":"age","bins":[{"points":1}]}],"extra":true}`,
+	} {
+		_, err := Parse(s)
+		assert.ErrorIs(t, err, ErrInvalidScorecard, s)
+	}
+}
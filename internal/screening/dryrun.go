@@ -0,0 +1,87 @@
+package screening
+
+import (
+	"math"
+	"sort"
+
+	"loan/internal/model"
+)
+
+// 历史申请单的人工初审结论(由 audit_status 推断)
+const (
+	ActualRejected = "REJECTED" // 已拒绝
+	ActualPassed   = "PASSED"   // 已通过初审
+	ActualPending  = "PENDING"  // 待审核
+)
+
+// Actual 历史申请单的人工初审结论
+func Actual(auditStatus int) string {
+	switch {
+	case auditStatus < 0:
+		return ActualRejected
+	case auditStatus > 0:
+		return ActualPassed
+	}
+	return ActualPending
+}
+
+// RuleHitCount 单条规则在试运行中的命中次数
+type RuleHitCount struct {
+	RuleID uint64 `json:"ruleID"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	Hits   int    `json:"hits"`
+}
+
+// Summary 规则试运行汇总
+type Summary struct {
+	Total    int                       `json:"total"`
+	Outcomes map[string]int            `json:"outcomes"` // 预审结论 -> 申请数
+	Matrix   map[string]map[string]int `json:"matrix"`   // 预审结论 -> 人工结论 -> 申请数
+	RuleHits []RuleHitCount            `json:"ruleHits"`
+	// Agreement 已有人工结论且预审给出自动结论(REJECT/PASS)的申请中，两者一致的比例(%，两位小数)
+	Agreement float64 `json:"agreement"`
+	// Conflicts 自动结论与人工结论相反的申请数(自动通过但人工拒绝，或自动拒绝但人工通过)
+	Conflicts int `json:"conflicts"`
+}
+
+// Summarize 汇总试运行结果，decisions 与 actuals 一一对应
+func Summarize(rules []*Rule, decisions []Decision, actuals []string) Summary {
+	s := Summary{
+		Total:    len(decisions),
+		Outcomes: map[string]int{model.ScreeningOutcomeReject: 0, model.ScreeningOutcomePass: 0, model.ScreeningOutcomeManual: 0},
+		Matrix:   map[string]map[string]int{},
+	}
+	hits := map[uint64]int{}
+	var compared, agreed int
+	for i, d := range decisions {
+		actual := actuals[i]
+		s.Outcomes[d.Outcome]++
+		if s.Matrix[d.Outcome] == nil {
+			s.Matrix[d.Outcome] = map[string]int{}
+		}
+		s.Matrix[d.Outcome][actual]++
+		for _, h := range d.Hits {
+			hits[h.RuleID]++
+		}
+
+		if d.Outcome == model.ScreeningOutcomeManual || actual == ActualPending {
+			continue
+		}
+		compared++
+		if (d.Outcome == model.ScreeningOutcomePass) == (actual == ActualPassed) {
+			agreed++
+		} else {
+			s.Conflicts++
+		}
+	}
+	if compared > 0 {
+		s.Agreement = math.Round(float64(agreed)*10000/float64(compared)) / 100
+	}
+
+	for _, r := range rules {
+		s.RuleHits = append(s.RuleHits, RuleHitCount{RuleID: r.ID, Name: r.Name, Action: r.Action, Hits: hits[r.ID]})
+	}
+	sort.SliceStable(s.RuleHits, func(i, j int) bool { return s.RuleHits[i].Hits > s.RuleHits[j].Hits })
+	return s
+}
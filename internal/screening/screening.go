@@ -0,0 +1,287 @@
+// Package screening 申请自动预审(决策引擎)：按数据库中配置的规则对申请单打分，
+// 给出自动拒绝、自动通过初审或转人工审核的结论。规则条件是针对申请单字段的 JSON 表达式：
+//
+//	{"all":[{"field":"age","op":"<","value":22},{"field":"has_house","op":"=","value":0}]}
+//	{"any":[...]}、{"not":{...}}、{"field":"loan_days","op":"in","values":[7,14]}
+package screening
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+
+	"loan/internal/model"
+	"loan/internal/types"
+)
+
+// ErrInvalidRule 规则条件或动作不合法
+var ErrInvalidRule = errors.New("invalid screening rule")
+
+// maxDepth 条件表达式最大嵌套层数
+const maxDepth = 8
+
+// Fields 规则可引用的字段及说明
+var Fields = map[string]string{
+	"age":                "年龄",
+	"salary":             "薪资",
+	"has_house":          "是否有房(1是 0否)",
+	"has_car":            "是否有车(1是 0否)",
+	"application_amount": "申请金额(分)",
+	"loan_days":          "借款天数",
+	"risk_list_status":   "同证件号/手机号的名单状态(0正常 1白名单 2黑名单)",
+	"contacts_count":     "通讯录条数",
+	"call_records_count": "通话记录条数",
+	"sms_records_count":  "短信条数",
+	"device_apps_count":  "已安装应用数",
+}
+
+// Facts 规则执行时的申请单数据，键为 Fields 中的字段
+type Facts map[string]float64
+
+// FactsOf 由申请单及名单、设备数据生成规则数据
+func FactsOf(b *model.LoanBaseinfo, extra *types.ScreeningExtraFacts) Facts {
+	f := Facts{
+		"age":                float64(b.Age),
+		"salary":             float64(b.Salary),
+		"has_house":          float64(b.HasHouse),
+		"has_car":            float64(b.HasCar),
+		"application_amount": float64(b.ApplicationAmount),
+		"loan_days":          float64(b.LoanDays),
+		"risk_list_status":   0,
+		"contacts_count":     0,
+		"call_records_count": 0,
+		"sms_records_count":  0,
+		"device_apps_count":  0,
+	}
+	if extra != nil {
+		f["risk_list_status"] = float64(extra.RiskListStatus)
+		f["contacts_count"] = float64(extra.Contacts)
+		f["call_records_count"] = float64(extra.CallRecords)
+		f["sms_records_count"] = float64(extra.SmsRecords)
+		f["device_apps_count"] = float64(extra.DeviceApps)
+	}
+	return f
+}
+
+// Condition 条件表达式：all/any/not 组合子条件，或 field+op+value(s) 比较单个字段
+type Condition struct {
+	All    []*Condition `json:"all,omitempty"`
+	Any    []*Condition `json:"any,omitempty"`
+	Not    *Condition   `json:"not,omitempty"`
+	Field  string       `json:"field,omitempty"`
+	Op     string       `json:"op,omitempty"`
+	Value  *float64     `json:"value,omitempty"`  // 比较运算的值
+	Values []float64    `json:"values,omitempty"` // in/not_in 的取值列表，between 的 [下限, 上限](含)
+}
+
+var compareOps = []string{"=", "!=", ">", ">=", "<", "<="}
+
+// ParseCondition 解析并校验条件表达式
+func ParseCondition(s string) (*Condition, error) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(s)))
+	dec.DisallowUnknownFields()
+	c := &Condition{}
+	if err := dec.Decode(c); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	if err := c.validate(1); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Condition) validate(depth int) error {
+	if c == nil {
+		return fmt.Errorf("%w: empty condition", ErrInvalidRule)
+	}
+	if depth > maxDepth {
+		return fmt.Errorf("%w: condition nested too deep", ErrInvalidRule)
+	}
+	kinds := 0
+	for _, set := range []bool{c.All != nil, c.Any != nil, c.Not != nil, c.Field != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return fmt.Errorf("%w: condition needs exactly one of all/any/not/field", ErrInvalidRule)
+	}
+
+	switch {
+	case c.All != nil || c.Any != nil:
+		children := c.All
+		if c.Any != nil {
+			children = c.Any
+		}
+		if len(children) == 0 {
+			return fmt.Errorf("%w: empty all/any", ErrInvalidRule)
+		}
+		for _, child := range children {
+			if err := child.validate(depth + 1); err != nil {
+				return err
+			}
+		}
+		return nil
+	case c.Not != nil:
+		return c.Not.validate(depth + 1)
+	}
+
+	if _, ok := Fields[c.Field]; !ok {
+		return fmt.Errorf("%w: unknown field %s", ErrInvalidRule, c.Field)
+	}
+	switch {
+	case slices.Contains(compareOps, c.Op):
+		if c.Value == nil {
+			return fmt.Errorf("%w: %s %s needs value", ErrInvalidRule, c.Field, c.Op)
+		}
+	case c.Op == "in" || c.Op == "not_in":
+		if len(c.Values) == 0 {
+			return fmt.Errorf("%w: %s %s needs values", ErrInvalidRule, c.Field, c.Op)
+		}
+	case c.Op == "between":
+		if len(c.Values) != 2 || c.Values[0] > c.Values[1] {
+			return fmt.Errorf("%w: %s between needs [min, max]", ErrInvalidRule, c.Field)
+		}
+	default:
+		return fmt.Errorf("%w: unknown op %q", ErrInvalidRule, c.Op)
+	}
+	return nil
+}
+
+// Match 条件是否成立
+func (c *Condition) Match(f Facts) bool {
+	switch {
+	case c.All != nil:
+		for _, child := range c.All {
+			if !child.Match(f) {
+				return false
+			}
+		}
+		return true
+	case c.Any != nil:
+		for _, child := range c.Any {
+			if child.Match(f) {
+				return true
+			}
+		}
+		return false
+	case c.Not != nil:
+		return !c.Not.Match(f)
+	}
+
+	v := f[c.Field]
+	switch c.Op {
+	case "=":
+		return v == *c.Value
+	case "!=":
+		return v != *c.Value
+	case ">":
+		return v > *c.Value
+	case ">=":
+		return v >= *c.Value
+	case "<":
+		return v < *c.Value
+	case "<=":
+		return v <= *c.Value
+	case "in":
+		return slices.Contains(c.Values, v)
+	case "not_in":
+		return !slices.Contains(c.Values, v)
+	case "between":
+		return v >= c.Values[0] && v <= c.Values[1]
+	}
+	return false
+}
+
+// Rule 已解析条件的启用规则
+type Rule struct {
+	ID       uint64
+	Name     string
+	Action   string
+	Score    int
+	Priority int
+	cond     *Condition
+}
+
+// ValidateRule 校验规则的动作与条件表达式
+func ValidateRule(r *model.LoanScreeningRules) error {
+	switch r.Action {
+	case model.ScreeningActionReject, model.ScreeningActionPass, model.ScreeningActionManual, model.ScreeningActionScore:
+	default:
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidRule, r.Action)
+	}
+	_, err := ParseCondition(r.Condition)
+	return err
+}
+
+// Compile 解析启用的规则，按 Priority、ID 升序
+func Compile(rules []*model.LoanScreeningRules) ([]*Rule, error) {
+	list := make([]*Rule, 0, len(rules))
+	for _, r := range rules {
+		if r.Status != 1 {
+			continue
+		}
+		if err := ValidateRule(r); err != nil {
+			return nil, fmt.Errorf("rule %d %s: %w", r.ID, r.Name, err)
+		}
+		cond, _ := ParseCondition(r.Condition)
+		list = append(list, &Rule{ID: r.ID, Name: r.Name, Action: r.Action, Score: r.Score, Priority: r.Priority, cond: cond})
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Priority != list[j].Priority {
+			return list[i].Priority < list[j].Priority
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+// Hit 命中的规则
+type Hit struct {
+	RuleID uint64 `json:"ruleID"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	Score  int    `json:"score"`
+}
+
+// Decision 预审结论
+type Decision struct {
+	Outcome string `json:"outcome"`
+	Score   int    `json:"score"`
+	Hits    []Hit  `json:"hits"`
+}
+
+// Evaluate 执行全部规则并给出结论，优先级：命中 REJECT 自动拒绝 > 命中 MANUAL 转人工 >
+// 命中 PASS 或分数合计达到 passScore(>0) 自动通过初审 > 其余转人工
+func Evaluate(rules []*Rule, f Facts, passScore int) Decision {
+	d := Decision{Outcome: model.ScreeningOutcomeManual, Hits: []Hit{}}
+	var reject, manual, pass bool
+	for _, r := range rules {
+		if !r.cond.Match(f) {
+			continue
+		}
+		d.Score += r.Score
+		d.Hits = append(d.Hits, Hit{RuleID: r.ID, Name: r.Name, Action: r.Action, Score: r.Score})
+		switch r.Action {
+		case model.ScreeningActionReject:
+			reject = true
+		case model.ScreeningActionManual:
+			manual = true
+		case model.ScreeningActionPass:
+			pass = true
+		}
+	}
+
+	switch {
+	case reject:
+		d.Outcome = model.ScreeningOutcomeReject
+	case manual:
+		d.Outcome = model.ScreeningOutcomeManual
+	case pass || (passScore > 0 && d.Score >= passScore):
+		d.Outcome = model.ScreeningOutcomePass
+	}
+	return d
+}
@@ -0,0 +1,145 @@
+package screening
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan/internal/model"
+	"loan/internal/types"
+)
+
+func rules() []*model.LoanScreeningRules {
+	return []*model.LoanScreeningRules{
+		{Name: "未成年", Condition: `{"field":"age","op":"<","value":18}`, Action: model.ScreeningActionReject, Score: -100, Status: 1},
+		{Name: "黑名单", Condition: `{"field":"risk_list_status","op":"=","value":2}`, Action: model.ScreeningActionReject, Status: 1},
+		{Name: "有房有车", Condition: `{"all":[{"field":"has_house","op":"=","value":1},{"field":"has_car","op":"=","value":1}]}`, Action: model.ScreeningActionScore, Score: 40, Status: 1},
+		{Name: "薪资", Condition: `{"field":"salary","op":"between","values":[8000,1000000]}`, Action: model.ScreeningActionScore, Score: 30, Status: 1},
+		{Name: "大额", Condition: `{"field":"application_amount","op":">=","value":5000000}`, Action: model.ScreeningActionManual, Priority: -1, Status: 1},
+		{Name: "停用", Condition: `{"field":"age","op":">","value":0}`, Action: model.ScreeningActionReject, Status: 0},
+	}
+}
+
+func compile(t *testing.T) []*Rule {
+	list := rules()
+	for i, r := range list {
+		r.ID = uint64(i + 1)
+	}
+	compiled, err := Compile(list)
+	require.NoError(t, err)
+	return compiled
+}
+
+func applicant(age, salary, house, car int, amount int64) *model.LoanBaseinfo {
+	return &model.LoanBaseinfo{Age: age, Salary: salary, HasHouse: house, HasCar: car, ApplicationAmount: amount, LoanDays: 14}
+}
+
+func TestCompile(t *testing.T) {
+	list := compile(t)
+	require.Len(t, list, 5)
+	// Priority 优先，其次按 ID
+	assert.Equal(t, "大额", list[0].Name)
+	assert.Equal(t, "未成年", list[1].Name)
+}
+
+func TestEvaluate(t *testing.T) {
+	list := compile(t)
+
+	d := Evaluate(list, FactsOf(applicant(30, 10000, 1, 1, 100000), nil), 60)
+	assert.Equal(t, model.ScreeningOutcomePass, d.Outcome)
+	assert.Equal(t, 70, d.Score)
+	assert.Len(t, d.Hits, 2)
+
+	// 分数不足转人工
+	d = Evaluate(list, FactsOf(applicant(30, 10000, 0, 0, 100000), nil), 60)
+	assert.Equal(t, model.ScreeningOutcomeManual, d.Outcome)
+	assert.Equal(t, 30, d.Score)
+
+	// passScore 为 0 时不按分数自动通过
+	d = Evaluate(list, FactsOf(applicant(30, 10000, 1, 1, 100000), nil), 0)
+	assert.Equal(t, model.ScreeningOutcomeManual, d.Outcome)
+
+	// REJECT 优先于 MANUAL
+	d = Evaluate(list, FactsOf(applicant(17, 10000, 1, 1, 6000000), nil), 60)
+	assert.Equal(t, model.ScreeningOutcomeReject, d.Outcome)
+	assert.Equal(t, -30, d.Score)
+
+	d = Evaluate(list, FactsOf(applicant(30, 10000, 1, 1, 6000000), nil), 60)
+	assert.Equal(t, model.ScreeningOutcomeManual, d.Outcome)
+
+	d = Evaluate(list, FactsOf(applicant(30, 10000, 1, 1, 100000), &types.ScreeningExtraFacts{RiskListStatus: 2}), 60)
+	assert.Equal(t, model.ScreeningOutcomeReject, d.Outcome)
+
+	assert.Equal(t, model.ScreeningOutcomeManual, Evaluate(nil, FactsOf(applicant(30, 0, 0, 0, 1), nil), 0).Outcome)
+}
+
+func TestMatchOperators(t *testing.T) {
+	f := Facts{"loan_days": 14, "contacts_count": 0}
+	match := func(s string) bool {
+		c, err := ParseCondition(s)
+		require.NoError(t, err, s)
+		return c.Match(f)
+	}
+	assert.True(t, match(`{"field":"loan_days","op":"in","values":[7,14]}`))
+	assert.False(t, match(`{"field":"loan_days","op":"not_in","values":[7,14]}`))
+	assert.True(t, match(`{"field":"loan_days","op":"!=","value":7}`))
+	assert.True(t, match(`{"not":{"field":"contacts_count","op":">","value":0}}`))
+	assert.True(t, match(`{"any":[{"field":"loan_days","op":"=","value":7},{"field":"contacts_count","op":"<=","value":0}]}`))
+}
+
+func TestParseConditionInvalid(t *testing.T) {
+	for _, s := range []string{
+		``,
+		`{}`,
+		`{"field":"nickname","op":"=","value":1}`,
+		`{"field":"age","op":"~","value":1}`,
+		`{"field":"age","op":"<"}`,
+		`{"field":"age","op":"between","values":[9,1]}`,
+		`{"all":[]}`,
+		`{"all":[{"field":"age","op":"<","value":1}],"field":"age"}`,
+		`{"field":"age","op":"<","value":1,"extra":true}`,
+	} {
+		_, err := ParseCondition(s)
+		assert.ErrorIs(t, err, ErrInvalidRule, s)
+	}
+
+	deep := `{"field":"age","op":"<","value":1}`
+	for i := 0; i < maxDepth; i++ {
+		deep = `{"not":` + deep + `}`
+	}
+	_, err := ParseCondition(deep)
+	assert.ErrorIs(t, err, ErrInvalidRule)
+
+	assert.ErrorIs(t, ValidateRule(&model.LoanScreeningRules{Action: "BLOCK", Condition: `{"field":"age","op":"<","value":1}`}), ErrInvalidRule)
+}
+
+func TestSummarize(t *testing.T) {
+	list := compile(t)
+	apps := []*model.LoanBaseinfo{
+		applicant(30, 10000, 1, 1, 100000), // PASS
+		applicant(17, 0, 0, 0, 100000),     // REJECT
+		applicant(30, 0, 0, 0, 100000),     // MANUAL
+		applicant(30, 9000, 1, 1, 100000),  // PASS
+	}
+	actuals := []string{ActualPassed, ActualPassed, ActualRejected, ActualPending}
+	decisions := make([]Decision, 0, len(apps))
+	for _, b := range apps {
+		decisions = append(decisions, Evaluate(list, FactsOf(b, nil), 60))
+	}
+
+	s := Summarize(list, decisions, actuals)
+	assert.Equal(t, 4, s.Total)
+	assert.Equal(t, 2, s.Outcomes[model.ScreeningOutcomePass])
+	assert.Equal(t, 1, s.Matrix[model.ScreeningOutcomeReject][ActualPassed])
+	assert.Equal(t, 1, s.Conflicts)
+	assert.Equal(t, 50.0, s.Agreement)
+	require.Len(t, s.RuleHits, 5)
+	assert.Equal(t, 2, s.RuleHits[0].Hits)
+}
+
+func TestActual(t *testing.T) {
+	assert.Equal(t, ActualRejected, Actual(-1))
+	assert.Equal(t, ActualPending, Actual(0))
+	assert.Equal(t, ActualPassed, Actual(2))
+}
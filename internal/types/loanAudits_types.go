@@ -48,7 +48,8 @@ type LoanAuditDetail struct {
 	AuditResult  int    `json:"auditResult"`  // 审核结果：1通过 -1拒绝
 	AuditComment string `json:"auditComment"` // 审核备注/原因
 	//AuditorUserID int64      `json:"auditorUserID"` // 审核人员(loan_users.id)
-	AuditType     int        `json:"auditType"`
+	AuditType     int        `json:"auditType"` // 审批节点序号
+	StageCode     string     `json:"stageCode"` // 审批节点代码
 	AuditUsername string     `gorm:"column:auditor_username" json:"auditUsername"`
 	CreatedAt     *time.Time `json:"createdAt"` // 审核时间(即审核通过/拒绝时间)
 }
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"loan/internal/model"
 	"time"
 
@@ -20,6 +21,12 @@ type AuditRequest struct {
 	PaymentChannelID uint64 `json:"paymentChannelID"`
 }
 
+// ReassignClaimRequest request params
+type ReassignClaimRequest struct {
+	UserID uint64 `json:"userID" binding:"required"` // 接手审批的人员 loan_users.id
+	Remark string `json:"remark"`                    // 改派原因
+}
+
 // CreateLoanBaseinfoRequest request params
 type CreateLoanBaseinfoRequest struct {
 	FirstName   string `json:"firstName" binding:""`   // 姓
@@ -52,6 +59,7 @@ type CreateLoanBaseinfoRequest struct {
 	ReferrerUserID    int64  `json:"referrerUserID" binding:""`    // 邀请人/分享人(loan_users.id)
 	RefCode           string `json:"refCode" binding:""`           // 访问时携带的ref(冗余存储便于排查)
 	LoanDays          int    `json:"loanDays" binding:""`          // 借款天数(单位：天)
+	ProductID         uint64 `json:"productID" binding:"required"` // 贷款产品 loan_products.id
 }
 
 // UpdateLoanBaseinfoByIDRequest request params
@@ -91,6 +99,7 @@ type LoanBaseinfoObjDetail struct {
 	RiskOperateID   uint64 ` json:"riskOperateID"`   // 风险记录操作人ID（loan_risk_customer.created_by）
 	RiskOperateName string ` json:"riskOperateName"` // 风险记录操作人用户名（loan_users.username）
 
+	CreditScoreBreakdown json.RawMessage `json:"scoreBreakdown"` // 信用分明细：评分卡版本及各特征的值、分箱与得分(未评分时为空)
 }
 
 type LoanBaseinfoSimpleObjDetail struct {
@@ -105,7 +114,9 @@ type LoanBaseinfoSimpleObjDetail struct {
 	ApplicationAmount *decimal.Decimal `json:"applicationAmount"` // 申請金額
 	AuditStatus       int              `json:"auditStatus"`       // 審核情況 0待審核 1審核通過 -1 審核拒絕
 	//ReferrerUserID    int64            `json:"referrerUserID"`    // 邀请人/分享人(loan_users.id)
-	LoanDays int `json:"loanDays"` // 借款天数(单位：天)
+	LoanDays       int        `json:"loanDays"`       // 借款天数(单位：天)
+	ClaimedBy      *uint64    `json:"claimedBy"`      // 领取审批的人员 loan_users.id
+	ClaimExpiresAt *time.Time `json:"claimExpiresAt"` // 领取租约到期时间
 }
 
 type LoanBaseinfoWithAuditRecords struct {
@@ -0,0 +1,32 @@
+package types
+
+// DashboardRequest request params
+type DashboardRequest struct {
+	Date   string `form:"date"`                                             // 统计日期 yyyy-mm-dd(为空表示当天)
+	Period string `form:"period" binding:"omitempty,oneof=week month year"` // 累计区间：week 本周 month 本月(默认) year 本年
+}
+
+// DashboardKPIs 一个时间段内的运营指标，金额单位为分
+type DashboardKPIs struct {
+	ApplicationsReceived  int64 `json:"applicationsReceived"`  // 新增借款申请
+	PreReviewPassed       int64 `json:"preReviewPassed"`       // 初审通过
+	PreReviewRejected     int64 `json:"preReviewRejected"`     // 初审拒绝
+	FinanceReviewPassed   int64 `json:"financeReviewPassed"`   // 放款审核通过
+	FinanceReviewRejected int64 `json:"financeReviewRejected"` // 放款审核拒绝
+	DisbursedCount        int64 `json:"disbursedCount"`        // 放款笔数
+	DisbursedAmount       int64 `json:"disbursedAmount"`       // 放款金额
+	DisbursedNetAmount    int64 `json:"disbursedNetAmount"`    // 实际到账金额
+	RepaymentCount        int64 `json:"repaymentCount"`        // 回款笔数(不含冲正流水)
+	RepaymentAmount       int64 `json:"repaymentAmount"`       // 回款金额(已扣冲正)
+	NewOverdueSchedules   int64 `json:"newOverdueSchedules"`   // 新增逾期期次(应还日次日落在区间内且未按时结清)
+	CollectionsCompleted  int64 `json:"collectionsCompleted"`  // 完成的催收任务
+}
+
+// Dashboard 运营看板：当日与区间累计指标
+type Dashboard struct {
+	Date         string         `json:"date"`
+	Period       string         `json:"period"`
+	PeriodFrom   string         `json:"periodFrom"` // 累计区间起始日期
+	Today        *DashboardKPIs `json:"today"`
+	PeriodToDate *DashboardKPIs `json:"periodToDate"`
+}
@@ -41,6 +41,18 @@ type UpdateLoanDisbursementsByIDRequest struct {
 	DisbursedAt          *time.Time `json:"disbursedAt" binding:""`          // 放款时间
 }
 
+// RetryLoanDisbursementRequest 放款失败后重试，可切换代付渠道
+type RetryLoanDisbursementRequest struct {
+	PayoutChannelID uint64 `json:"payoutChannelID" binding:""` // 新的代付渠道，0 表示沿用原渠道
+	MfaCode         string `json:"mfaCode" binding:"required"`
+}
+
+// CancelLoanDisbursementRequest 取消放款
+type CancelLoanDisbursementRequest struct {
+	Reason  string `json:"reason" binding:"required"` // 取消原因
+	MfaCode string `json:"mfaCode" binding:"required"`
+}
+
 // LoanDisbursementsObjDetail detail
 type LoanDisbursementsObjDetail struct {
 	ID uint64 `json:"id"` // convert to uint64 id
@@ -48,13 +60,20 @@ type LoanDisbursementsObjDetail struct {
 	BaseinfoID           int        `json:"baseinfoID"`           // 关联申请单 loan_baseinfo.id
 	DisburseAmount       int        `json:"disburseAmount"`       // 放款金额(单位按你的系统：元/分，建议统一)
 	NetAmount            int        `json:"netAmount"`            // 到账金额(扣除费用后实际到账)
-	Status               int        `json:"status"`               // 放款状态：0待放款 1已放款
+	PayoutFee            int        `json:"payoutFee"`            // 代付渠道手续费(分)
+	Status               int        `json:"status"`               // 放款状态：0待放款 1已放款 2已提交渠道 3放款失败 4待重试 5已取消
 	SourceReferrerUserID int64      `json:"sourceReferrerUserID"` // 用户来源(分享人 loan_users.id，冗余快照，便于查询)
 	AuditorUserID        int64      `json:"auditorUserID"`        // 审核人员(loan_users.id)
 	AuditedAt            *time.Time `json:"auditedAt"`            // 审核通过时间
 	PayoutChannelID      int64      `json:"payoutChannelID"`      // 放款渠道(代付) loan_payment_channels.id
 	PayoutOrderNo        string     `json:"payoutOrderNo"`        // 放款订单号/三方代付单号
 	DisbursedAt          *time.Time `json:"disbursedAt"`          // 放款时间
+	MerchantOrderNo      string     `json:"merchantOrderNo"`      // 平台代付商户单号
+	SubmittedAt          *time.Time `json:"submittedAt"`          // 提交渠道时间
+	FailReason           string     `json:"failReason"`           // 放款失败原因
+	AttemptCount         int        `json:"attemptCount"`         // 已提交渠道次数
+	CancelReason         string     `json:"cancelReason"`         // 取消原因
+	CancelledAt          *time.Time `json:"cancelledAt"`          // 取消时间
 	CreatedAt            *time.Time `json:"createdAt"`            // 创建时间(进入待放款时刻)
 	UpdatedAt            *time.Time `json:"updatedAt"`            // 更新时间
 }
@@ -0,0 +1,21 @@
+package types
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+)
+
+// LedgerTrialBalanceRequest request params
+type LedgerTrialBalanceRequest struct {
+	DateFrom string `form:"dateFrom"` // 开始日期 yyyy-mm-dd(为空表示不限)
+	DateTo   string `form:"dateTo"`   // 结束日期 yyyy-mm-dd(含当天，为空表示不限)
+}
+
+// LedgerAccountBalanceRequest request params
+type LedgerAccountBalanceRequest struct {
+	AsOf string `form:"asOf"` // 截止日期 yyyy-mm-dd(含当天，为空表示截至当前)
+}
+
+// ListLedgerEntriesRequest request params
+type ListLedgerEntriesRequest struct {
+	query.Params
+}
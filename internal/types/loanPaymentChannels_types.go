@@ -28,6 +28,10 @@ type CreateLoanPaymentChannelsRequest struct {
 	PayoutMaxAmount  int    `json:"payoutMaxAmount" binding:""`  // 最大代付金额(分)
 	SettlementCycle  string `json:"settlementCycle" binding:""`  // 结算周期(如 T0/T1/D1/W1/M1，可按你们渠道定义)
 	SettlementDesc   string `json:"settlementDesc" binding:""`   // 结算说明/备注
+	CallbackSecret   string `json:"callbackSecret" binding:""`   // 回调验签密钥(明文仅写入，加密存储，不回显，至少16位)
+	RoutePriority    int    `json:"routePriority" binding:""`    // 自动路由优先级(越小越优先)
+	RouteWeight      int    `json:"routeWeight" binding:""`      // 自动路由权重(同优先级内按权重随机)
+	PayoutDailyCap   int64  `json:"payoutDailyCap" binding:""`   // 当日代付额度(分)，0不限
 }
 
 // UpdateLoanPaymentChannelsByIDRequest request params
@@ -50,6 +54,10 @@ type UpdateLoanPaymentChannelsByIDRequest struct {
 	PayoutMaxAmount  int    `json:"payoutMaxAmount" binding:""`  // 最大代付金额(分)
 	SettlementCycle  string `json:"settlementCycle" binding:""`  // 结算周期(如 T0/T1/D1/W1/M1，可按你们渠道定义)
 	SettlementDesc   string `json:"settlementDesc" binding:""`   // 结算说明/备注
+	CallbackSecret   string `json:"callbackSecret" binding:""`   // 回调验签密钥(明文仅写入，加密存储，不回显，至少16位)
+	RoutePriority    int    `json:"routePriority" binding:""`    // 自动路由优先级(越小越优先)
+	RouteWeight      int    `json:"routeWeight" binding:""`      // 自动路由权重(同优先级内按权重随机)
+	PayoutDailyCap   int64  `json:"payoutDailyCap" binding:""`   // 当日代付额度(分)，0不限
 }
 
 // LoanPaymentChannelsObjDetail detail
@@ -72,6 +80,9 @@ type LoanPaymentChannelsObjDetail struct {
 	PayoutMaxAmount  int        `json:"payoutMaxAmount"`  // 最大代付金额(分)
 	SettlementCycle  string     `json:"settlementCycle"`  // 结算周期(如 T0/T1/D1/W1/M1，可按你们渠道定义)
 	SettlementDesc   string     `json:"settlementDesc"`   // 结算说明/备注
+	RoutePriority    int        `json:"routePriority"`    // 自动路由优先级(越小越优先)
+	RouteWeight      int        `json:"routeWeight"`      // 自动路由权重
+	PayoutDailyCap   int64      `json:"payoutDailyCap"`   // 当日代付额度(分)，0不限
 	CreatedAt        *time.Time `json:"createdAt"`        // 创建时间
 	UpdatedAt        *time.Time `json:"updatedAt"`        // 更新时间
 }
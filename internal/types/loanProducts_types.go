@@ -0,0 +1,138 @@
+package types
+
+import (
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+)
+
+var _ time.Time
+
+// Tip: suggested filling in the binding rules https://github.com/go-playground/validator in request struct fields tag.
+
+// CreateLoanProductsRequest request params
+type CreateLoanProductsRequest struct {
+	Code               string     `json:"code" binding:"required"`            // 产品编码(唯一)
+	Name               string     `json:"name" binding:"required"`            // 产品名称
+	Status             int        `json:"status" binding:""`                  // 状态：1上架 0下架
+	RepaymentMethod    string     `json:"repaymentMethod" binding:"required"` // 还款方式：BULLET/EQUAL_INSTALLMENT/EQUAL_PRINCIPAL
+	InstallmentCount   int        `json:"installmentCount" binding:""`        // 期数
+	InstallmentDays    int        `json:"installmentDays" binding:""`         // 每期天数，0表示按借款天数/期数平分
+	InterestRateBp     int        `json:"interestRateBp" binding:""`          // 日利率(万分比)
+	FeeModel           string     `json:"feeModel" binding:""`                // 服务费收取方式：NONE/UPFRONT/INSTALLMENT
+	ServiceFeeBp       int        `json:"serviceFeeBp" binding:""`            // 服务费率(占本金万分比)
+	ServiceFeeFixed    int64      `json:"serviceFeeFixed" binding:""`         // 固定服务费(分)
+	MinAmount          int64      `json:"minAmount" binding:""`               // 最低申请金额(分)
+	MaxAmount          int64      `json:"maxAmount" binding:""`               // 最高申请金额(分)
+	AllowedDays        string     `json:"allowedDays" binding:""`             // 可选借款天数，逗号分隔如 7,14,30
+	PenaltyDailyRateBp int        `json:"penaltyDailyRateBp" binding:""`      // 逾期日罚息率(万分比)
+	AllocationOrder    string     `json:"allocationOrder" binding:""`         // 还款冲销顺序，如 penalty,fee,interest,principal
+	PayoffFeeBp        int        `json:"payoffFeeBp" binding:""`             // 提前结清手续费率(占剩余本金万分比)
+	PayoffFeeFixed     int64      `json:"payoffFeeFixed" binding:""`          // 提前结清固定手续费(分)
+	PayoffDiscountBp   int        `json:"payoffDiscountBp" binding:""`        // 提前结清减免比例(占已计利息及费用万分比)
+	ActiveFrom         *time.Time `json:"activeFrom" binding:""`              // 生效开始时间
+	ActiveTo           *time.Time `json:"activeTo" binding:""`                // 生效结束时间
+	Remark             string     `json:"remark" binding:""`                  // 备注
+}
+
+// UpdateLoanProductsByIDRequest request params
+type UpdateLoanProductsByIDRequest struct {
+	ID uint64 `json:"id" binding:""` // uint64 id
+
+	Code               string     `json:"code" binding:""`
+	Name               string     `json:"name" binding:""`
+	Status             int        `json:"status" binding:""`
+	RepaymentMethod    string     `json:"repaymentMethod" binding:""`
+	InstallmentCount   int        `json:"installmentCount" binding:""`
+	InstallmentDays    int        `json:"installmentDays" binding:""`
+	InterestRateBp     int        `json:"interestRateBp" binding:""`
+	FeeModel           string     `json:"feeModel" binding:""`
+	ServiceFeeBp       int        `json:"serviceFeeBp" binding:""`
+	ServiceFeeFixed    int64      `json:"serviceFeeFixed" binding:""`
+	MinAmount          int64      `json:"minAmount" binding:""`
+	MaxAmount          int64      `json:"maxAmount" binding:""`
+	AllowedDays        string     `json:"allowedDays" binding:""`
+	PenaltyDailyRateBp int        `json:"penaltyDailyRateBp" binding:""`
+	AllocationOrder    string     `json:"allocationOrder" binding:""`
+	PayoffFeeBp        int        `json:"payoffFeeBp" binding:""`
+	PayoffFeeFixed     int64      `json:"payoffFeeFixed" binding:""`
+	PayoffDiscountBp   int        `json:"payoffDiscountBp" binding:""`
+	ActiveFrom         *time.Time `json:"activeFrom" binding:""`
+	ActiveTo           *time.Time `json:"activeTo" binding:""`
+	Remark             string     `json:"remark" binding:""`
+}
+
+// LoanProductsObjDetail detail
+type LoanProductsObjDetail struct {
+	ID uint64 `json:"id"` // convert to uint64 id
+
+	Code               string     `json:"code"`
+	Name               string     `json:"name"`
+	Status             int        `json:"status"`
+	RepaymentMethod    string     `json:"repaymentMethod"`
+	InstallmentCount   int        `json:"installmentCount"`
+	InstallmentDays    int        `json:"installmentDays"`
+	InterestRateBp     int        `json:"interestRateBp"`
+	FeeModel           string     `json:"feeModel"`
+	ServiceFeeBp       int        `json:"serviceFeeBp"`
+	ServiceFeeFixed    int64      `json:"serviceFeeFixed"`
+	MinAmount          int64      `json:"minAmount"`
+	MaxAmount          int64      `json:"maxAmount"`
+	AllowedDays        string     `json:"allowedDays"`
+	PenaltyDailyRateBp int        `json:"penaltyDailyRateBp"`
+	AllocationOrder    string     `json:"allocationOrder"`
+	PayoffFeeBp        int        `json:"payoffFeeBp"`
+	PayoffFeeFixed     int64      `json:"payoffFeeFixed"`
+	PayoffDiscountBp   int        `json:"payoffDiscountBp"`
+	ActiveFrom         *time.Time `json:"activeFrom"`
+	ActiveTo           *time.Time `json:"activeTo"`
+	Remark             string     `json:"remark"`
+	CreatedAt          *time.Time `json:"createdAt"`
+	UpdatedAt          *time.Time `json:"updatedAt"`
+}
+
+// CreateLoanProductsReply only for api docs
+type CreateLoanProductsReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		ID uint64 `json:"id"` // id
+	} `json:"data"` // return data
+}
+
+// DeleteLoanProductsByIDReply only for api docs
+type DeleteLoanProductsByIDReply struct {
+	Code int      `json:"code"` // return code
+	Msg  string   `json:"msg"`  // return information description
+	Data struct{} `json:"data"` // return data
+}
+
+// UpdateLoanProductsByIDReply only for api docs
+type UpdateLoanProductsByIDReply struct {
+	Code int      `json:"code"` // return code
+	Msg  string   `json:"msg"`  // return information description
+	Data struct{} `json:"data"` // return data
+}
+
+// GetLoanProductsByIDReply only for api docs
+type GetLoanProductsByIDReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		LoanProducts LoanProductsObjDetail `json:"loanProducts"`
+	} `json:"data"` // return data
+}
+
+// ListLoanProductssRequest request params
+type ListLoanProductssRequest struct {
+	query.Params
+}
+
+// ListLoanProductssReply only for api docs
+type ListLoanProductssReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		LoanProductss []LoanProductsObjDetail `json:"loanProductss"`
+	} `json:"data"` // return data
+}
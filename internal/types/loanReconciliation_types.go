@@ -0,0 +1,8 @@
+package types
+
+// ReconcileSettlementRequest 上传渠道结算文件对账(multipart/form-data，文件字段 file)
+type ReconcileSettlementRequest struct {
+	ChannelID uint64 `form:"channelID" binding:"required"` // 渠道 loan_payment_channels.id
+	DateFrom  string `form:"dateFrom" binding:"required"`  // 对账开始日期 yyyy-mm-dd
+	DateTo    string `form:"dateTo" binding:"required"`    // 对账结束日期 yyyy-mm-dd(含当天)
+}
@@ -179,3 +179,47 @@ type ListLoanRepaymentSchedulessByIDsReply struct {
 		LoanRepaymentScheduless []LoanRepaymentSchedulesObjDetail `json:"loanRepaymentScheduless"`
 	} `json:"data"` // return data
 }
+
+// PayoffQuoteRequest 提前结清报价请求参数
+type PayoffQuoteRequest struct {
+	DisbursementID uint64 `form:"disbursementID" binding:"required"` // 放款单 loan_disbursements.id
+}
+
+// PayoffAmounts 按科目拆分的金额(分)
+type PayoffAmounts struct {
+	Principal int64 `json:"principal"` // 本金
+	Interest  int64 `json:"interest"`  // 利息
+	Fee       int64 `json:"fee"`       // 费用
+	Penalty   int64 `json:"penalty"`   // 罚息
+	Total     int64 `json:"total"`     // 合计
+}
+
+// PayoffQuoteItem 单个未结清期次的结清金额
+type PayoffQuoteItem struct {
+	ScheduleID    uint64        `json:"scheduleID"`
+	InstallmentNo int           `json:"installmentNo"`
+	Due           PayoffAmounts `json:"due"`    // 本期结清需支付(最后一期含提前结清手续费)
+	Waived        PayoffAmounts `json:"waived"` // 本期免收的利息/费用
+}
+
+// PayoffQuoteDetail 提前结清报价
+type PayoffQuoteDetail struct {
+	DisbursementID uint64             `json:"disbursementID"`
+	QuoteDate      string             `json:"quoteDate"`   // 报价日期，利息按日计提，仅当天有效
+	Outstanding    PayoffAmounts      `json:"outstanding"` // 剩余本金+已计利息/费用+罚息(减免前)
+	Unaccrued      PayoffAmounts      `json:"unaccrued"`   // 未计提、免收的利息/费用
+	Discount       int64              `json:"discount"`    // 提前结清减免
+	Fee            int64              `json:"fee"`         // 提前结清手续费
+	Total          int64              `json:"total"`       // 结清应付总额
+	Items          []*PayoffQuoteItem `json:"items"`
+}
+
+// ExtendLoanRepaymentSchedulesRequest 展期请求参数，展期费按系统设置计算，需当场支付
+type ExtendLoanRepaymentSchedulesRequest struct {
+	Days             int    `json:"days" binding:"required,min=1"` // 顺延天数
+	CollectChannelID int64  `json:"collectChannelID" binding:""`   // 展期费回款渠道
+	PayRef           string `json:"payRef" binding:"max=128"`      // 展期费支付渠道流水号
+	VoucherFileName  string `json:"voucherFileName" binding:""`
+	MfaCode          string `json:"mfaCode" binding:"required"`
+	Remark           string `json:"remark" binding:"max=200"` // 备注
+}
@@ -14,12 +14,9 @@ var _ time.Time
 type CreateLoanRepaymentTransactionsRequest struct {
 	ScheduleID       uint64 `json:"scheduleID" binding:""` // 关联期次 loan_repayment_schedules.id(可空：先入账后分配/未分期)
 	CollectChannelID int64  `json:"collectChannelID" binding:""`
-	PayAmount        int    `json:"payAmount" binding:""`      // 本次回款金额(分)
-	PayMethod        string `json:"payMethod" binding:""`      // 回款方式(如 BANK_TRANSFER/WALLET)
-	AllocPrincipal   int    `json:"allocPrincipal" binding:""` // 本次分配到本金(分)
-	AllocInterest    int    `json:"allocInterest" binding:""`  // 本次分配到利息(分)
-	AllocFee         int    `json:"allocFee" binding:""`       // 本次分配到费用(分)
-	AllocPenalty     int    `json:"allocPenalty" binding:""`   // 本次分配到罚息(分)
+	PayRef           string `json:"payRef" binding:"max=128"` // 支付渠道流水号，同一渠道下重复提交返回原流水
+	PayAmount        int    `json:"payAmount" binding:""`     // 本次回款金额(分)
+	PayMethod        string `json:"payMethod" binding:""`     // 回款方式(如 BANK_TRANSFER/WALLET)
 	VoucherFileName  string `json:"voucherFileName" binding:""`
 	MfaCode          string `json:"mfaCode" binding:""`
 	Remark           string `json:"remark" binding:""` // 备注
@@ -50,22 +47,29 @@ type RepaymentScheduleDetail struct {
 }
 
 // UpdateLoanRepaymentTransactionsByIDRequest request params
+// 流水的金额、分配和状态不可修改(需走冲正)，只允许补充备注和凭证
 type UpdateLoanRepaymentTransactionsByIDRequest struct {
 	ID uint64 `json:"id" binding:""` // uint64 id
-	// 回款流水ID
-	ScheduleID       int64      `json:"scheduleID" binding:""`       // 关联期次 loan_repayment_schedules.id(可空：先入账后分配/未分期)
-	CollectChannelID int64      `json:"collectChannelID" binding:""` // 回款渠道(代收) loan_payment_channels.id
-	CollectOrderNo   string     `json:"collectOrderNo" binding:""`   // 回款订单号/三方代收单号(商户单号)
-	PayRef           string     `json:"payRef" binding:""`           // 支付渠道流水号/交易号(三方transaction id)
-	PayAmount        int        `json:"payAmount" binding:""`        // 本次回款金额(分)
-	PayMethod        string     `json:"payMethod" binding:""`        // 回款方式(如 BANK_TRANSFER/CARD/WALLET/CASH)
-	PaidAt           *time.Time `json:"paidAt" binding:""`           // 回款时间(交易成功时间)
-	AllocPrincipal   int        `json:"allocPrincipal" binding:""`   // 本次分配到本金(分)
-	AllocInterest    int        `json:"allocInterest" binding:""`    // 本次分配到利息(分)
-	AllocFee         int        `json:"allocFee" binding:""`         // 本次分配到费用(分)
-	AllocPenalty     int        `json:"allocPenalty" binding:""`     // 本次分配到罚息(分)
-	Status           int        `json:"status" binding:""`           // 流水状态：1成功 0失败 2冲正/撤销
-	Remark           string     `json:"remark" binding:""`           // 备注
+
+	VoucherFileName string `json:"voucherFileName" binding:""`
+	Remark          string `json:"remark" binding:""` // 备注
+}
+
+// ReverseLoanRepaymentTransactionRequest 冲正回款流水请求参数
+type ReverseLoanRepaymentTransactionRequest struct {
+	Reason  string `json:"reason" binding:"required"` // 冲正原因
+	MfaCode string `json:"mfaCode" binding:"required"`
+}
+
+// PayoffLoanRepaymentTransactionsRequest 提前结清回款请求参数，回款金额需与当日报价一致
+type PayoffLoanRepaymentTransactionsRequest struct {
+	DisbursementID   uint64 `json:"disbursementID" binding:"required"` // 放款单 loan_disbursements.id
+	CollectChannelID int64  `json:"collectChannelID" binding:""`
+	PayRef           string `json:"payRef" binding:"max=128"`     // 支付渠道流水号，同一渠道下重复提交返回原流水
+	PayAmount        int    `json:"payAmount" binding:"required"` // 回款总额(分)，需等于报价 total
+	VoucherFileName  string `json:"voucherFileName" binding:""`
+	MfaCode          string `json:"mfaCode" binding:"required"`
+	Remark           string `json:"remark" binding:""` // 备注
 }
 
 // LoanRepaymentTransactionsObjDetail detail
@@ -77,6 +81,7 @@ type LoanRepaymentTransactionsObjDetail struct {
 	CollectOrderNo   string     `json:"collectOrderNo"`   // 回款订单号/三方代收单号(商户单号)
 	PayRef           string     `json:"payRef"`           // 支付渠道流水号/交易号(三方transaction id)
 	PayAmount        int        `json:"payAmount"`        // 本次回款金额(分)
+	CollectFee       int        `json:"collectFee"`       // 代收渠道手续费(分)
 	PayMethod        string     `json:"payMethod"`        // 回款方式(如 BANK_TRANSFER/CARD/WALLET/CASH)
 	PaidAt           *time.Time `json:"paidAt"`           // 回款时间(交易成功时间)
 	AllocPrincipal   int        `json:"allocPrincipal"`   // 本次分配到本金(分)
@@ -85,6 +90,9 @@ type LoanRepaymentTransactionsObjDetail struct {
 	AllocPenalty     int        `json:"allocPenalty"`     // 本次分配到罚息(分)
 	Status           int        `json:"status"`           // 流水状态：1成功 0失败 2冲正/撤销
 	Remark           string     `json:"remark"`           // 备注
+	ReversalOfID     *uint64    `json:"reversalOfID"`     // 冲正流水指向的原流水id
+	ReverseReason    string     `json:"reverseReason"`    // 冲正原因
+	CreatedBy        uint64     `json:"createdBy"`        // 操作人
 	CreatedAt        *time.Time `json:"createdAt"`        // 创建时间
 	UpdatedAt        *time.Time `json:"updatedAt"`        // 更新时间
 }
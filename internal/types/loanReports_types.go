@@ -0,0 +1,36 @@
+package types
+
+import (
+	"time"
+)
+
+// AgingReportRequest request params
+type AgingReportRequest struct {
+	DisbursedFrom   string `json:"disbursedFrom"`                             // 放款开始日期 yyyy-mm-dd(为空表示不限)
+	DisbursedTo     string `json:"disbursedTo"`                               // 放款结束日期 yyyy-mm-dd(含当天，为空表示不限)
+	ChannelID       uint64 `json:"channelID"`                                 // 放款渠道 loan_payment_channels.id
+	ReferrerUserID  int64  `json:"referrerUserID"`                            // 用户来源(分享人 loan_users.id)
+	CollectorUserID uint64 `json:"collectorUserID"`                           // 催收人员 loan_users.id(任一期次分配给该催收人员)
+	AsOf            string `json:"asOf"`                                      // 统计日期 yyyy-mm-dd(为空表示当天)
+	Format          string `json:"format" binding:"omitempty,oneof=json csv"` // 输出格式：json(默认) csv
+}
+
+// AgingLoanRow 账龄统计的单笔借款：剩余未还本金及最早未结清期次的应还日期
+type AgingLoanRow struct {
+	DisbursementID       uint64     `gorm:"column:disbursement_id" json:"disbursementID"`
+	OutstandingPrincipal int64      `gorm:"column:outstanding_principal" json:"outstandingPrincipal"`
+	OldestDueDate        *time.Time `gorm:"column:oldest_due_date" json:"oldestDueDate"`
+}
+
+// VintageReportRequest request params
+type VintageReportRequest struct {
+	CohortFrom string `json:"cohortFrom"` // 起始放款月份 yyyy-mm(为空表示不限)
+	CohortTo   string `json:"cohortTo"`   // 截止放款月份 yyyy-mm(含，为空表示不限)
+}
+
+// ReviewerThroughputRequest request params
+type ReviewerThroughputRequest struct {
+	From          string `json:"from"`          // 开始日期 yyyy-mm-dd(为空表示当天)
+	To            string `json:"to"`            // 结束日期 yyyy-mm-dd(含当天，为空表示与开始日期相同)
+	AuditorUserID uint64 `json:"auditorUserID"` // 审批人员 loan_users.id(为空表示全部)
+}
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CreateLoanScorecardsRequest request params, 同名评分卡自动生成下一个版本号
+type CreateLoanScorecardsRequest struct {
+	Name       string          `json:"name" binding:"required,max=64"` // 评分卡名称
+	Definition json.RawMessage `json:"definition" binding:"required"`  // 评分卡定义，见 GET /api/v1/scorecards 返回的特征列表
+	Remark     string          `json:"remark" binding:"max=255"`       // 备注(版本变更说明)
+}
+
+// UpdateLoanScorecardStatusRequest request params
+type UpdateLoanScorecardStatusRequest struct {
+	Status int `json:"status" binding:"oneof=0 1"` // 1启用(同时停用其他版本) 0停用
+}
+
+// ScoringDeviceData 申请单的设备采集数据汇总，用于计算评分特征
+type ScoringDeviceData struct {
+	Contacts        int64    `json:"contacts"`        // 通讯录条数
+	CallRecords     int64    `json:"callRecords"`     // 通话记录条数
+	UnansweredCalls int64    `json:"unansweredCalls"` // 未接/拒接通话条数
+	SmsRecords      int64    `json:"smsRecords"`      // 短信条数
+	DeviceApps      int64    `json:"deviceApps"`      // 已安装应用数
+	InboxSms        []string `json:"-"`               // 最近收到的短信内容(识别工资短信)
+	Packages        []string `json:"-"`               // 已安装应用包名(识别借贷类应用)
+}
+
+// LoanScorecardObjDetail 评分卡详情，definition 以 JSON 对象返回
+type LoanScorecardObjDetail struct {
+	ID         uint64          `json:"id"`
+	Name       string          `json:"name"`
+	Version    int             `json:"version"`
+	Definition json.RawMessage `json:"definition"`
+	Status     int             `json:"status"`
+	Remark     string          `json:"remark"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CreateLoanScreeningRulesRequest request params
+type CreateLoanScreeningRulesRequest struct {
+	Name      string          `json:"name" binding:"required,max=64"`                           // 规则名称
+	Priority  int             `json:"priority"`                                                 // 执行顺序(升序)
+	Condition json.RawMessage `json:"condition" binding:"required"`                             // 条件表达式，如 {"field":"age","op":"<","value":18}
+	Action    string          `json:"action" binding:"required,oneof=REJECT PASS MANUAL SCORE"` // 命中后动作
+	Score     int             `json:"score"`                                                    // 命中后累加的分数(可为负)
+	Status    int             `json:"status" binding:"oneof=0 1"`                               // 状态：1启用 0停用
+	Remark    string          `json:"remark" binding:"max=255"`                                 // 备注
+}
+
+// UpdateLoanScreeningRulesByIDRequest request params, 所有字段整体覆盖
+type UpdateLoanScreeningRulesByIDRequest struct {
+	ID uint64 `json:"id" binding:""` // uint64 id
+
+	Name      string          `json:"name" binding:"required,max=64"`
+	Priority  int             `json:"priority"`
+	Condition json.RawMessage `json:"condition" binding:"required"`
+	Action    string          `json:"action" binding:"required,oneof=REJECT PASS MANUAL SCORE"`
+	Score     int             `json:"score"`
+	Status    int             `json:"status" binding:"oneof=0 1"`
+	Remark    string          `json:"remark" binding:"max=255"`
+}
+
+// ScreeningDryRunRequest request params
+type ScreeningDryRunRequest struct {
+	// Rules 待试运行的规则(整体替换现有规则)，为空表示试运行当前已保存的规则
+	Rules     []*CreateLoanScreeningRulesRequest `json:"rules" binding:"omitempty,dive"`
+	From      string                             `json:"from"`                                    // 申请提交开始日期 yyyy-mm-dd(为空表示30天前)
+	To        string                             `json:"to"`                                      // 申请提交结束日期 yyyy-mm-dd(含当天，为空表示当天)
+	PassScore *int                               `json:"passScore"`                               // 自动通过分数线(为空表示使用系统设置)
+	Limit     int                                `json:"limit" binding:"omitempty,gt=0,lte=5000"` // 最多评估的申请数(默认1000，按提交时间倒序)
+	Details   bool                               `json:"details"`                                 // 是否返回每个申请的结论(最多返回200条)
+}
+
+// ScreeningExtraFacts 申请单之外的预审数据：同一客户(证件号/手机号)的名单状态与设备数据条数
+type ScreeningExtraFacts struct {
+	RiskListStatus int   `json:"riskListStatus"` // 0正常 1白名单 2黑名单
+	Contacts       int64 `json:"contacts"`
+	CallRecords    int64 `json:"callRecords"`
+	SmsRecords     int64 `json:"smsRecords"`
+	DeviceApps     int64 `json:"deviceApps"`
+}
+
+// LoanScreeningDecisionDetail 预审决策日志
+type LoanScreeningDecisionDetail struct {
+	ID         uint64          `json:"id"`
+	BaseinfoID uint64          `json:"baseinfoID"`
+	Trigger    string          `json:"trigger"`
+	Outcome    string          `json:"outcome"`
+	Score      int             `json:"score"`
+	Hits       json.RawMessage `json:"hits"`
+	Facts      json.RawMessage `json:"facts"`
+	Note       string          `json:"note"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
@@ -0,0 +1,25 @@
+package types
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+)
+
+// CreateLoanWaiverRequestsRequest request params
+type CreateLoanWaiverRequestsRequest struct {
+	ScheduleID uint64 `json:"scheduleID" binding:"required"`                  // 关联期次 loan_repayment_schedules.id
+	CaseID     uint64 `json:"caseID" binding:""`                              // 关联催收任务 loan_collection_cases.id(可选)
+	Component  string `json:"component" binding:"required,oneof=penalty fee"` // 减免科目：penalty罚息 fee费用
+	Amount     int64  `json:"amount" binding:"required,min=1"`                // 申请减免金额(分)
+	Reason     string `json:"reason" binding:"required,max=255"`              // 减免原因
+}
+
+// ReviewLoanWaiverRequestsRequest request params
+type ReviewLoanWaiverRequestsRequest struct {
+	MfaCode string `json:"mfaCode" binding:"required"` // 审批人 MFA 动态码
+	Note    string `json:"note" binding:"max=255"`     // 审批备注
+}
+
+// ListLoanWaiverRequestssRequest request params
+type ListLoanWaiverRequestssRequest struct {
+	query.Params
+}
@@ -0,0 +1,29 @@
+package types
+
+// CreateLoanWorkflowStagesRequest request params
+type CreateLoanWorkflowStagesRequest struct {
+	Workflow   string `json:"workflow" binding:""`             // 流程代码(为空表示 loan_application)
+	StageNo    int    `json:"stageNo" binding:"required,gt=0"` // 节点序号(升序审批)
+	Code       string `json:"code" binding:"required,max=32"`  // 节点代码
+	Name       string `json:"name" binding:"required,max=64"`  // 节点名称
+	Permission string `json:"permission" binding:"max=64"`     // 审批所需权限码
+	MinAmount  int64  `json:"minAmount" binding:"gte=0"`       // 申请金额达到该值(分)才需要本节点，0 表示都需要
+	Status     int    `json:"status" binding:"oneof=0 1"`      // 状态：1启用 0停用
+}
+
+// UpdateLoanWorkflowStagesByIDRequest request params, 所有字段整体覆盖
+type UpdateLoanWorkflowStagesByIDRequest struct {
+	ID uint64 `json:"id" binding:""` // uint64 id
+
+	StageNo    int    `json:"stageNo" binding:"required,gt=0"`
+	Code       string `json:"code" binding:"required,max=32"`
+	Name       string `json:"name" binding:"required,max=64"`
+	Permission string `json:"permission" binding:"max=64"`
+	MinAmount  int64  `json:"minAmount" binding:"gte=0"`
+	Status     int    `json:"status" binding:"oneof=0 1"`
+}
+
+// ListLoanWorkflowStagesRequest request params
+type ListLoanWorkflowStagesRequest struct {
+	Workflow string `form:"workflow"` // 流程代码(为空表示 loan_application)
+}
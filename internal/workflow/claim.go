@@ -0,0 +1,75 @@
+package workflow
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"loan/internal/model"
+)
+
+// 审批工作队列：审批人领取(claim)待审申请单后在租约期内独占处理，租约过期后其他人员可重新领取
+const (
+	DefaultLeaseMinutes = 30  // 默认领取租约(分钟)
+	MaxLeaseMinutes     = 480 // 领取租约上限(分钟)
+)
+
+var (
+	// ErrClaimedByOther 申请单已被其他审批人领取且租约未过期
+	ErrClaimedByOther = errors.New("application is claimed by another reviewer")
+	// ErrNotClaimHolder 审批人未持有申请单的领取
+	ErrNotClaimHolder = errors.New("application is not claimed by the reviewer")
+	// ErrStageForbidden 审批人不具备当前节点的权限
+	ErrStageForbidden = errors.New("no permission for the current stage")
+)
+
+// LeaseDuration 领取租约时长，minutes<=0 使用默认值，超过上限按上限处理
+func LeaseDuration(minutes int64) time.Duration {
+	if minutes <= 0 {
+		minutes = DefaultLeaseMinutes
+	}
+	if minutes > MaxLeaseMinutes {
+		minutes = MaxLeaseMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// ClaimHolder 申请单当前有效的领取人，未领取或租约已过期时返回 false
+func ClaimHolder(b *model.LoanBaseinfo, now time.Time) (uint64, bool) {
+	if b.ClaimedBy == nil || *b.ClaimedBy == 0 || b.ClaimExpiresAt == nil || !now.Before(*b.ClaimExpiresAt) {
+		return 0, false
+	}
+	return *b.ClaimedBy, true
+}
+
+// CheckClaim 审批前校验申请单未被其他人员领取；未领取的申请单仍可直接审批
+func CheckClaim(b *model.LoanBaseinfo, reviewer uint64, now time.Time) error {
+	if holder, ok := ClaimHolder(b, now); ok && holder != reviewer {
+		return ErrClaimedByOther
+	}
+	return nil
+}
+
+// ReceivedAt 当前节点开始待审的时间，首个节点为申请提交时间
+func ReceivedAt(b *model.LoanBaseinfo) time.Time {
+	if b.StageReceivedAt != nil {
+		return *b.StageReceivedAt
+	}
+	return b.CreatedAt
+}
+
+// Eligible 审批人能否处理申请单的当前节点：流程未结束、具备节点权限且不违反职责分离
+func Eligible(stages []*model.LoanWorkflowStages, b *model.LoanBaseinfo, reviewer uint64, perms []string,
+	p SoDPolicy, priorApprovers []uint64) (Step, error) {
+	step, err := Next(stages, b.ApplicationAmount, b.AuditStatus)
+	if err != nil {
+		return Step{}, err
+	}
+	if step.Stage.Permission != "" && !slices.Contains(perms, step.Stage.Permission) {
+		return Step{}, ErrStageForbidden
+	}
+	if err = CheckSegregation(p, reviewer, b.ReferrerUserID, priorApprovers); err != nil {
+		return Step{}, err
+	}
+	return step, nil
+}
@@ -0,0 +1,66 @@
+package workflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan/internal/model"
+)
+
+func TestLeaseDuration(t *testing.T) {
+	assert.Equal(t, 30*time.Minute, LeaseDuration(0))
+	assert.Equal(t, 15*time.Minute, LeaseDuration(15))
+	assert.Equal(t, 8*time.Hour, LeaseDuration(10000))
+}
+
+func TestClaimHolder(t *testing.T) {
+	now := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	holder := uint64(5)
+	expires := now.Add(time.Minute)
+	b := &model.LoanBaseinfo{ClaimedBy: &holder, ClaimExpiresAt: &expires}
+
+	uid, ok := ClaimHolder(b, now)
+	assert.True(t, ok)
+	assert.Equal(t, holder, uid)
+	assert.NoError(t, CheckClaim(b, 5, now))
+	assert.ErrorIs(t, CheckClaim(b, 6, now), ErrClaimedByOther)
+
+	// 租约到期后视为未领取
+	_, ok = ClaimHolder(b, expires)
+	assert.False(t, ok)
+	assert.NoError(t, CheckClaim(b, 6, expires))
+
+	assert.NoError(t, CheckClaim(&model.LoanBaseinfo{}, 6, now))
+}
+
+func TestReceivedAt(t *testing.T) {
+	b := &model.LoanBaseinfo{}
+	b.CreatedAt = time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	assert.Equal(t, b.CreatedAt, ReceivedAt(b))
+
+	stage := b.CreatedAt.Add(2 * time.Hour)
+	b.StageReceivedAt = &stage
+	assert.Equal(t, stage, ReceivedAt(b))
+}
+
+func TestEligible(t *testing.T) {
+	strict := SoDPolicy{DistinctApprovers: true, BlockReferrer: true}
+	b := &model.LoanBaseinfo{ApplicationAmount: 500000, AuditStatus: 1}
+
+	step, err := Eligible(stages(), b, 3, []string{"loan:finance_review"}, strict, []uint64{2})
+	require.NoError(t, err)
+	assert.Equal(t, model.StageCodeFinanceReview, step.Stage.Code)
+
+	_, err = Eligible(stages(), b, 3, []string{"loan:pre_review"}, strict, []uint64{2})
+	assert.ErrorIs(t, err, ErrStageForbidden)
+
+	_, err = Eligible(stages(), b, 2, []string{"loan:finance_review"}, strict, []uint64{2})
+	assert.ErrorIs(t, err, ErrSameApprover)
+
+	b.AuditStatus = 2
+	_, err = Eligible(stages(), b, 3, []string{"loan:finance_review"}, strict, nil)
+	assert.ErrorIs(t, err, ErrCompleted)
+}
@@ -0,0 +1,31 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	// ErrSameApprover 审批人已审批过该申请单的其他节点
+	ErrSameApprover = errors.New("reviewer has already approved another stage of this application")
+	// ErrReviewerIsReferrer 审批人是该申请单的推荐人
+	ErrReviewerIsReferrer = errors.New("reviewer is the referrer of this application")
+)
+
+// SoDPolicy 职责分离(四眼原则)规则，来源于系统设置
+type SoDPolicy struct {
+	DistinctApprovers bool // 同一申请单的各节点须由不同人员审批
+	BlockReferrer     bool // 推荐人不得审批自己推荐的申请
+}
+
+// CheckSegregation 校验审批人能否审批当前节点，priorApprovers 为该申请单已通过节点的审批人
+func CheckSegregation(p SoDPolicy, reviewer uint64, referrer *int64, priorApprovers []uint64) error {
+	if p.BlockReferrer && referrer != nil && *referrer > 0 && uint64(*referrer) == reviewer {
+		return fmt.Errorf("%w: user %d", ErrReviewerIsReferrer, reviewer)
+	}
+	if p.DistinctApprovers && slices.Contains(priorApprovers, reviewer) {
+		return fmt.Errorf("%w: user %d", ErrSameApprover, reviewer)
+	}
+	return nil
+}
@@ -0,0 +1,21 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSegregation(t *testing.T) {
+	strict := SoDPolicy{DistinctApprovers: true, BlockReferrer: true}
+	referrer := int64(7)
+
+	assert.NoError(t, CheckSegregation(strict, 3, &referrer, []uint64{1, 2}))
+	assert.NoError(t, CheckSegregation(strict, 3, nil, nil))
+	assert.ErrorIs(t, CheckSegregation(strict, 2, &referrer, []uint64{1, 2}), ErrSameApprover)
+	assert.ErrorIs(t, CheckSegregation(strict, 7, &referrer, nil), ErrReviewerIsReferrer)
+
+	// 关闭后不再校验
+	assert.NoError(t, CheckSegregation(SoDPolicy{}, 2, &referrer, []uint64{2}))
+	assert.NoError(t, CheckSegregation(SoDPolicy{}, 7, &referrer, nil))
+}
@@ -0,0 +1,104 @@
+// Package workflow 多级审批流程：按数据库中的节点定义(loan_workflow_stages)逐级推进申请单，
+// 申请金额达到节点的 MinAmount 时才需要该节点(例如大额借款增加经理审批)。
+// 申请单的 audit_status 记录最后一个已通过节点的序号，-1 表示已拒绝。
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"loan/internal/model"
+)
+
+// AuditStatusRejected 申请单已被拒绝
+const AuditStatusRejected = -1
+
+var (
+	// ErrRejected 申请单已被拒绝，不能继续审批
+	ErrRejected = errors.New("application has been rejected")
+	// ErrCompleted 所有节点均已审批通过
+	ErrCompleted = errors.New("all workflow stages are approved")
+	// ErrStageMismatch 当前待审批节点与请求的节点不一致
+	ErrStageMismatch = errors.New("current stage does not match")
+	// ErrInvalidDefinition 流程定义不合法
+	ErrInvalidDefinition = errors.New("invalid workflow definition")
+)
+
+// DefaultStages 未配置流程时使用的内置两级审批(初审、放款审核)，与历史 audit_status 取值一致
+func DefaultStages() []*model.LoanWorkflowStages {
+	return []*model.LoanWorkflowStages{
+		{Workflow: model.WorkflowLoanApplication, StageNo: 1, Code: model.StageCodePreReview, Name: "初审", Permission: "loan:pre_review", Status: 1},
+		{Workflow: model.WorkflowLoanApplication, StageNo: 2, Code: model.StageCodeFinanceReview, Name: "放款审核", Permission: "loan:finance_review", Status: 1},
+	}
+}
+
+// Applicable 申请金额 amount(分)需要经过的启用节点，按 StageNo 升序
+func Applicable(stages []*model.LoanWorkflowStages, amount int64) []*model.LoanWorkflowStages {
+	list := make([]*model.LoanWorkflowStages, 0, len(stages))
+	for _, s := range stages {
+		if s.Status != 1 || (s.MinAmount > 0 && amount < s.MinAmount) {
+			continue
+		}
+		list = append(list, s)
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].StageNo < list[j].StageNo })
+	return list
+}
+
+// Step 申请单当前待审批的节点
+type Step struct {
+	Stage *model.LoanWorkflowStages
+	Final bool // 是否为最后一个节点，最终审批通过后生成放款单
+	Total int  // 该申请需要经过的节点数
+}
+
+// Next 根据申请单的 audit_status 计算下一个待审批节点
+func Next(stages []*model.LoanWorkflowStages, amount int64, auditStatus int) (Step, error) {
+	if auditStatus == AuditStatusRejected {
+		return Step{}, ErrRejected
+	}
+	list := Applicable(stages, amount)
+	for i, s := range list {
+		if s.StageNo > auditStatus {
+			return Step{Stage: s, Final: i == len(list)-1, Total: len(list)}, nil
+		}
+	}
+	return Step{}, ErrCompleted
+}
+
+// IsFinal 节点 stageNo 是否为申请金额 amount(分)需要经过的最后一个节点
+func IsFinal(stages []*model.LoanWorkflowStages, amount int64, stageNo int) bool {
+	list := Applicable(stages, amount)
+	return len(list) > 0 && list[len(list)-1].StageNo == stageNo
+}
+
+// Validate 校验流程定义：节点序号为正且不重复，节点代码不重复，至少有一个启用且对所有金额生效的节点
+func Validate(stages []*model.LoanWorkflowStages) error {
+	nos, codes := map[int]bool{}, map[string]bool{}
+	hasBase := false
+	for _, s := range stages {
+		code := strings.TrimSpace(s.Code)
+		if s.StageNo <= 0 || code == "" {
+			return fmt.Errorf("%w: stage no and code are required", ErrInvalidDefinition)
+		}
+		if nos[s.StageNo] {
+			return fmt.Errorf("%w: duplicate stage no %d", ErrInvalidDefinition, s.StageNo)
+		}
+		if codes[code] {
+			return fmt.Errorf("%w: duplicate stage code %s", ErrInvalidDefinition, code)
+		}
+		if s.MinAmount < 0 {
+			return fmt.Errorf("%w: negative min amount for %s", ErrInvalidDefinition, code)
+		}
+		nos[s.StageNo], codes[code] = true, true
+		if s.Status == 1 && s.MinAmount == 0 {
+			hasBase = true
+		}
+	}
+	if !hasBase {
+		return fmt.Errorf("%w: at least one enabled stage must apply to all amounts", ErrInvalidDefinition)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"loan/internal/model"
+)
+
+// stages 初审、放款审核，以及 100 万分以上需要的经理审批
+func stages() []*model.LoanWorkflowStages {
+	list := DefaultStages()
+	return append(list, &model.LoanWorkflowStages{
+		Workflow: model.WorkflowLoanApplication, StageNo: 3, Code: "MANAGER_REVIEW", Permission: "loan:manager_review",
+		MinAmount: 1000000, Status: 1,
+	})
+}
+
+func TestNextSmallLoan(t *testing.T) {
+	step, err := Next(stages(), 500000, 0)
+	require.NoError(t, err)
+	assert.Equal(t, model.StageCodePreReview, step.Stage.Code)
+	assert.False(t, step.Final)
+	assert.Equal(t, 2, step.Total)
+
+	step, err = Next(stages(), 500000, 1)
+	require.NoError(t, err)
+	assert.Equal(t, model.StageCodeFinanceReview, step.Stage.Code)
+	assert.True(t, step.Final)
+
+	_, err = Next(stages(), 500000, 2)
+	assert.ErrorIs(t, err, ErrCompleted)
+}
+
+func TestNextLargeLoan(t *testing.T) {
+	step, err := Next(stages(), 1000000, 1)
+	require.NoError(t, err)
+	assert.Equal(t, model.StageCodeFinanceReview, step.Stage.Code)
+	assert.False(t, step.Final)
+	assert.Equal(t, 3, step.Total)
+
+	step, err = Next(stages(), 1000000, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "MANAGER_REVIEW", step.Stage.Code)
+	assert.True(t, step.Final)
+}
+
+func TestNextSkipsDisabledAndRejected(t *testing.T) {
+	list := stages()
+	list[1].Status = 0
+	step, err := Next(list, 500000, 1)
+	assert.ErrorIs(t, err, ErrCompleted)
+	assert.Nil(t, step.Stage)
+
+	_, err = Next(list, 500000, AuditStatusRejected)
+	assert.ErrorIs(t, err, ErrRejected)
+}
+
+func TestNextUnorderedDefinition(t *testing.T) {
+	list := stages()
+	list[0], list[2] = list[2], list[0]
+	step, err := Next(list, 2000000, 0)
+	require.NoError(t, err)
+	assert.Equal(t, model.StageCodePreReview, step.Stage.Code)
+}
+
+func TestIsFinal(t *testing.T) {
+	assert.True(t, IsFinal(stages(), 500000, 2))
+	assert.False(t, IsFinal(stages(), 500000, 1))
+	assert.False(t, IsFinal(stages(), 2000000, 2))
+	assert.True(t, IsFinal(stages(), 2000000, 3))
+	assert.False(t, IsFinal(nil, 500000, 2))
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(stages()))
+
+	dupNo := stages()
+	dupNo[2].StageNo = 2
+	assert.ErrorIs(t, Validate(dupNo), ErrInvalidDefinition)
+
+	dupCode := stages()
+	dupCode[2].Code = model.StageCodePreReview
+	assert.ErrorIs(t, Validate(dupCode), ErrInvalidDefinition)
+
+	onlyLarge := stages()[2:]
+	assert.ErrorIs(t, Validate(onlyLarge), ErrInvalidDefinition)
+
+	assert.ErrorIs(t, Validate(nil), ErrInvalidDefinition)
+}
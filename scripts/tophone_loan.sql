@@ -27,7 +27,11 @@ CREATE TABLE `loan_audits` (
   `audit_result` tinyint NOT NULL COMMENT '审核结果：1通过 -1拒绝',
   `audit_comment` varchar(255) DEFAULT NULL COMMENT '审核备注/原因',
   `auditor_user_id` bigint NOT NULL COMMENT '审核人员(loan_users.id)',
-  `audit_type` varchar(255) DEFAULT NULL COMMENT '审核类型(初审1、放款审核2、回款审核3)',
+  `audit_type` varchar(255) DEFAULT NULL COMMENT '审批节点序号 loan_workflow_stages.stage_no(初审1、放款审核2)',
+  `stage_code` varchar(32) DEFAULT NULL COMMENT '审批节点代码 loan_workflow_stages.code(审批流程上线前的记录为空)',
+  `received_at` datetime DEFAULT NULL COMMENT '节点开始待审时间',
+  `claimed_at` datetime DEFAULT NULL COMMENT '审批人领取时间(未经工作队列领取时为空)',
+  `decided_at` datetime DEFAULT NULL COMMENT '审批决定时间',
   `updated_at` datetime DEFAULT NULL,
   `created_at` datetime NOT NULL COMMENT '审核时间(即审核通过/拒绝时间)',
   `deleted_at` datetime DEFAULT NULL COMMENT '软删除时间(NULL未删除)',
@@ -78,7 +82,7 @@ CREATE TABLE `loan_baseinfo` (
   `has_house` tinyint DEFAULT NULL COMMENT '是否有房',
   `has_car` tinyint DEFAULT NULL COMMENT '是否有車',
   `application_amount` bigint DEFAULT NULL COMMENT '申請金額',
-  `audit_status` tinyint DEFAULT '0' COMMENT '審核情況 0待審核 1初审通過 2财务审核通过 -1 審核拒絕',
+  `audit_status` tinyint DEFAULT '0' COMMENT '審核情況 0待審核 N已通過第N個審批節點(loan_workflow_stages.stage_no，默认1初审 2财务审核) -1 審核拒絕',
   `bank_no` varchar(255) DEFAULT NULL COMMENT '銀行卡號',
   `client_ip` varbinary(16) DEFAULT NULL COMMENT '客户端IP地址(IPv4/IPv6)',
   `created_at` datetime DEFAULT NULL,
@@ -87,10 +91,20 @@ CREATE TABLE `loan_baseinfo` (
   `referrer_user_id` bigint DEFAULT NULL COMMENT '邀请人/分享人(loan_users.id)',
   `ref_code` varchar(32) DEFAULT NULL COMMENT '访问时携带的ref(冗余存储便于排查)',
   `loan_days` smallint NOT NULL COMMENT '借款天数(单位：天)',
+  `product_id` bigint NOT NULL DEFAULT '0' COMMENT '贷款产品 loan_products.id(0表示未关联产品)',
+  `claimed_by` bigint DEFAULT NULL COMMENT '领取审批的人员 loan_users.id(审批工作队列)',
+  `claimed_at` datetime DEFAULT NULL COMMENT '领取时间',
+  `claim_expires_at` datetime DEFAULT NULL COMMENT '领取租约到期时间，过期后其他人员可重新领取',
+  `stage_received_at` datetime DEFAULT NULL COMMENT '当前审批节点开始待审的时间(为空表示申请提交时间)',
+  `credit_score` int DEFAULT NULL COMMENT '信用分(为空表示未评分)',
+  `scorecard_id` bigint DEFAULT '0' COMMENT '评分使用的评分卡版本 loan_scorecards.id(0表示未评分)',
+  `score_breakdown` text COMMENT '评分明细 JSON(各特征的值、分箱与得分)',
+  `scored_at` datetime DEFAULT NULL COMMENT '评分时间',
   `risk_list_status` tinyint NOT NULL DEFAULT '0' COMMENT '名单状态：0正常 1白名单 2黑名单',
   `risk_list_reason` varchar(255) DEFAULT NULL COMMENT '名单原因/来源说明',
   `risk_list_marked_at` datetime DEFAULT NULL COMMENT '名单标记时间',
   PRIMARY KEY (`id`),
+  KEY `idx_baseinfo_claimed_by` (`claimed_by`,`claim_expires_at`) COMMENT '审批工作队列按领取人查询',
   KEY `idx_baseinfo_referrer_user` (`referrer_user_id`) COMMENT '按邀请人查询申请记录',
   KEY `idx_baseinfo_ref_code` (`ref_code`) COMMENT '按ref查询',
   CONSTRAINT `fk_baseinfo_referrer_user` FOREIGN KEY (`referrer_user_id`) REFERENCES `loan_users` (`id`)
@@ -334,6 +348,38 @@ BEGIN;
 INSERT INTO `loan_departments` (`id`, `name`, `parent_id`, `status`, `created_at`, `updated_at`, `deleted_at`) VALUES (1, '管理员', NULL, 1, '2026-01-16 11:40:28', '2026-01-16 11:40:33', NULL);
 COMMIT;
 
+-- ----------------------------
+-- Table structure for loan_disbursement_attempts
+-- ----------------------------
+DROP TABLE IF EXISTS `loan_disbursement_attempts`;
+CREATE TABLE `loan_disbursement_attempts` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '提交记录ID',
+  `disbursement_id` bigint NOT NULL COMMENT '关联放款单 loan_disbursements.id',
+  `attempt_no` int NOT NULL COMMENT '第几次提交(从1开始)',
+  `payout_channel_id` bigint NOT NULL COMMENT '本次使用的代付渠道 loan_payment_channels.id',
+  `merchant_order_no` varchar(64) NOT NULL COMMENT '本次提交的商户单号',
+  `payout_order_no` varchar(128) DEFAULT NULL COMMENT '渠道代付单号',
+  `amount` bigint NOT NULL COMMENT '代付金额(分)',
+  `status` tinyint NOT NULL DEFAULT '0' COMMENT '0处理中 1成功 2失败 3已撤销',
+  `fail_reason` varchar(255) DEFAULT NULL COMMENT '失败/撤销原因',
+  `submitted_at` datetime DEFAULT NULL COMMENT '提交时间',
+  `finished_at` datetime DEFAULT NULL COMMENT '终态时间',
+  `operator_user_id` bigint DEFAULT '0' COMMENT '触发本次提交的操作人(首次为审核人，重试为重试操作人)',
+  `created_at` datetime NOT NULL COMMENT '创建时间',
+  `updated_at` datetime DEFAULT NULL COMMENT '更新时间',
+  `deleted_at` datetime DEFAULT NULL COMMENT '软删除时间(NULL未删除)',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_attempt_merchant_order_no` (`merchant_order_no`) COMMENT '同一商户单号只记录一次',
+  KEY `idx_attempt_disbursement` (`disbursement_id`,`attempt_no`) COMMENT '按放款单查提交记录',
+  CONSTRAINT `fk_attempt_disbursement` FOREIGN KEY (`disbursement_id`) REFERENCES `loan_disbursements` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='放款提交记录表(每次提交代付渠道一条)';
+
+-- ----------------------------
+-- Records of loan_disbursement_attempts
+-- ----------------------------
+BEGIN;
+COMMIT;
+
 -- ----------------------------
 -- Table structure for loan_disbursements
 -- ----------------------------
@@ -343,17 +389,29 @@ CREATE TABLE `loan_disbursements` (
   `baseinfo_id` int NOT NULL COMMENT '关联申请单 loan_baseinfo.id',
   `disburse_amount` bigint NOT NULL COMMENT '放款金额(单位按你的系统：元/分，建议统一)',
   `net_amount` bigint NOT NULL COMMENT '到账金额(扣除费用后实际到账)',
-  `status` tinyint NOT NULL DEFAULT '0' COMMENT '放款状态：0待放款 1已放款',
+  `payout_fee` bigint NOT NULL DEFAULT '0' COMMENT '代付渠道手续费(分，费率+固定费，已从到账金额中扣除)',
+  `status` tinyint NOT NULL DEFAULT '0' COMMENT '放款状态：0待放款 1已放款 2已提交渠道 3放款失败 4待重试 5已取消',
   `source_referrer_user_id` bigint DEFAULT NULL COMMENT '用户来源(分享人 loan_users.id，冗余快照，便于查询)',
   `auditor_user_id` bigint DEFAULT NULL COMMENT '审核人员(loan_users.id)',
   `audited_at` datetime DEFAULT NULL COMMENT '审核通过时间',
   `payout_channel_id` bigint DEFAULT NULL COMMENT '放款渠道(代付) loan_payment_channels.id',
   `payout_order_no` varchar(128) DEFAULT NULL COMMENT '放款订单号/三方代付单号',
+  `route_mode` varchar(16) DEFAULT NULL COMMENT '渠道选择方式 AUTO/MANUAL',
+  `route_reason` varchar(255) DEFAULT NULL COMMENT '选择渠道的原因',
   `disbursed_at` datetime DEFAULT NULL COMMENT '放款时间',
+  `merchant_order_no` varchar(64) DEFAULT NULL COMMENT '平台代付商户单号(提交/查询渠道用)',
+  `submitted_at` datetime DEFAULT NULL COMMENT '提交渠道时间',
+  `fail_reason` varchar(255) DEFAULT NULL COMMENT '放款失败原因',
+  `attempt_count` int NOT NULL DEFAULT '0' COMMENT '已提交渠道次数',
+  `retry_by` bigint DEFAULT NULL COMMENT '最近一次发起重试的操作人(loan_users.id)',
+  `cancel_reason` varchar(255) DEFAULT NULL COMMENT '取消原因',
+  `cancelled_by` bigint DEFAULT NULL COMMENT '取消人(loan_users.id)',
+  `cancelled_at` datetime DEFAULT NULL COMMENT '取消时间',
   `created_at` datetime DEFAULT NULL COMMENT '创建时间(进入待放款时刻)',
   `updated_at` datetime DEFAULT NULL COMMENT '更新时间',
   `deleted_at` datetime DEFAULT NULL COMMENT '软删除时间(NULL未删除)',
   PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_disburse_merchant_order_no` (`merchant_order_no`) COMMENT '代付商户单号唯一',
   UNIQUE KEY `uk_baseinfo_disbursement` (`baseinfo_id`) COMMENT '一个申请单只生成一个放款单(如允许多次放款可去掉)',
   KEY `idx_status` (`status`) COMMENT '按放款状态筛选',
   KEY `idx_auditor_time` (`auditor_user_id`,`audited_at`) COMMENT '按审核人/审核时间筛选',
@@ -364,7 +422,7 @@ CREATE TABLE `loan_disbursements` (
   CONSTRAINT `fk_disburse_auditor` FOREIGN KEY (`auditor_user_id`) REFERENCES `loan_users` (`id`),
   CONSTRAINT `fk_disburse_baseinfo` FOREIGN KEY (`baseinfo_id`) REFERENCES `loan_baseinfo` (`id`),
   CONSTRAINT `fk_disburse_payout_channel` FOREIGN KEY (`payout_channel_id`) REFERENCES `loan_payment_channels` (`id`)
-) ENGINE=InnoDB AUTO_INCREMENT=18 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='放款单/待放款任务表(审核通过后生成，状态待放款->已放款)';
+) ENGINE=InnoDB AUTO_INCREMENT=18 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='放款单/待放款任务表(审核通过后生成，状态待放款->已提交渠道->已放款/放款失败，失败可重试或取消)';
 
 -- ----------------------------
 -- Records of loan_disbursements
@@ -376,6 +434,67 @@ INSERT INTO `loan_disbursements` (`id`, `baseinfo_id`, `disburse_amount`, `net_a
 INSERT INTO `loan_disbursements` (`id`, `baseinfo_id`, `disburse_amount`, `net_amount`, `status`, `source_referrer_user_id`, `auditor_user_id`, `audited_at`, `payout_channel_id`, `payout_order_no`, `disbursed_at`, `created_at`, `updated_at`, `deleted_at`) VALUES (17, 7, 120000, 96000, 1, 0, 1, '2026-02-24 14:28:03', 1, 'PO20260224142802000', '2026-02-24 14:28:03', '2026-02-24 14:28:03', '2026-02-24 14:28:03', NULL);
 COMMIT;
 
+-- ----------------------------
+-- Table structure for loan_idempotency_keys
+-- ----------------------------
+DROP TABLE IF EXISTS `loan_idempotency_keys`;
+CREATE TABLE `loan_idempotency_keys` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '幂等键ID',
+  `user_id` bigint NOT NULL COMMENT '发起请求的用户 loan_users.id',
+  `scope` varchar(128) NOT NULL COMMENT '作用域: 请求方法+路由模板',
+  `idem_key` varchar(128) NOT NULL COMMENT '客户端传入的 Idempotency-Key',
+  `request_hash` char(64) NOT NULL COMMENT '请求体摘要(sha256)',
+  `status` tinyint NOT NULL DEFAULT '0' COMMENT '0处理中 1已完成',
+  `http_status` int NOT NULL DEFAULT '0' COMMENT '首次响应的http状态码',
+  `response_body` mediumtext COMMENT '首次响应体，用于回放',
+  `expires_at` datetime NOT NULL COMMENT '过期时间',
+  `created_at` datetime NOT NULL COMMENT '创建时间',
+  `updated_at` datetime DEFAULT NULL COMMENT '更新时间',
+  `deleted_at` datetime DEFAULT NULL COMMENT '软删除时间(NULL未删除)',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_user_scope_key` (`user_id`,`scope`,`idem_key`) COMMENT '同一用户同一接口同一个键只执行一次',
+  KEY `idx_idem_expires_at` (`expires_at`) COMMENT '清理过期键'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='接口幂等键表(重复提交回放首次响应)';
+
+-- ----------------------------
+-- Records of loan_idempotency_keys
+-- ----------------------------
+BEGIN;
+COMMIT;
+
+-- ----------------------------
+-- Table structure for loan_ledger_entries
+-- ----------------------------
+DROP TABLE IF EXISTS `loan_ledger_entries`;
+CREATE TABLE `loan_ledger_entries` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '分录ID',
+  `journal_no` varchar(40) NOT NULL COMMENT '凭证号(按业务类型+单据id生成，同一笔业务的分录共用)',
+  `line_no` int NOT NULL COMMENT '凭证内行号(从1开始)',
+  `event_type` varchar(32) NOT NULL COMMENT '业务类型：DISBURSEMENT放款 REPAYMENT回款 REVERSAL冲正 WAIVER减免 EXTENSION_WAIVER展期免罚息 PAYOFF_DISCOUNT提前结清减免',
+  `ref_type` varchar(64) NOT NULL COMMENT '业务单据表名',
+  `ref_id` bigint NOT NULL COMMENT '业务单据id',
+  `account` varchar(32) NOT NULL COMMENT '科目代码',
+  `debit` bigint NOT NULL DEFAULT '0' COMMENT '借方金额(分)',
+  `credit` bigint NOT NULL DEFAULT '0' COMMENT '贷方金额(分)',
+  `posted_at` datetime NOT NULL COMMENT '记账时间(业务发生时间)',
+  `memo` varchar(255) DEFAULT NULL COMMENT '摘要',
+  `created_at` datetime NOT NULL COMMENT '创建时间',
+  `updated_at` datetime DEFAULT NULL COMMENT '更新时间',
+  `deleted_at` datetime DEFAULT NULL COMMENT '软删除时间(NULL未删除)',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_journal_line` (`journal_no`,`line_no`) COMMENT '同一业务单据只能过账一次',
+  KEY `idx_ref` (`ref_type`,`ref_id`),
+  KEY `idx_account_posted_at` (`account`,`posted_at`),
+  KEY `idx_posted_at` (`posted_at`),
+  CONSTRAINT `chk_ledger_one_side` CHECK (((`debit` >= 0) and (`credit` >= 0) and ((`debit` = 0) <> (`credit` = 0))))
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='总账分录表(复式记账，只追加不修改，同一凭证借贷相等)';
+
+-- ----------------------------
+-- Records of loan_ledger_entries
+-- ----------------------------
+BEGIN;
+COMMIT;
+
 -- ----------------------------
 -- Table structure for loan_login_audit
 -- ----------------------------
@@ -471,6 +590,10 @@ CREATE TABLE `loan_payment_channels` (
   `payout_max_amount` int DEFAULT NULL COMMENT '最大代付金额(分)',
   `settlement_cycle` varchar(32) DEFAULT NULL COMMENT '结算周期(如 T0/T1/D1/W1/M1，可按你们渠道定义)',
   `settlement_desc` varchar(255) DEFAULT NULL COMMENT '结算说明/备注',
+  `callback_key_enc` varbinary(255) DEFAULT NULL COMMENT '回调验签密钥(AES-GCM加密存储)',
+  `route_priority` int DEFAULT '0' COMMENT '代付路由优先级，越小越优先',
+  `route_weight` int NOT NULL DEFAULT '1' COMMENT '同优先级内的路由权重',
+  `payout_daily_cap` bigint DEFAULT '0' COMMENT '代付当日额度(分)，0不限',
   `created_at` datetime DEFAULT NULL COMMENT '创建时间',
   `updated_at` datetime DEFAULT NULL COMMENT '更新时间',
   `deleted_at` datetime DEFAULT NULL COMMENT '软删除时间(NULL未删除)',
@@ -485,6 +608,35 @@ CREATE TABLE `loan_payment_channels` (
 BEGIN;
 INSERT INTO `loan_payment_channels` (`id`, `code`, `name`, `merchant_no`, `status`, `can_payout`, `can_collect`, `payout_fee_rate`, `payout_fee_fixed`, `collect_fee_rate`, `collect_fee_fixed`, `collect_min_amount`, `collect_max_amount`, `payout_min_amount`, `payout_max_amount`, `settlement_cycle`, `settlement_desc`, `created_at`, `updated_at`, `deleted_at`) VALUES (1, 'BANK_A', '银行A代付代收', '2020202020202', 1, 1, 1, 20, 0, 0.002, 0, 1000, 20000000, 1000, 50000000, 'T1', '默认T+1结算', '2026-01-14 19:38:20', '2026-01-14 19:38:20', NULL);
 INSERT INTO `loan_payment_channels` (`id`, `code`, `name`, `merchant_no`, `status`, `can_payout`, `can_collect`, `payout_fee_rate`, `payout_fee_fixed`, `collect_fee_rate`, `collect_fee_fixed`, `collect_min_amount`, `collect_max_amount`, `payout_min_amount`, `payout_max_amount`, `settlement_cycle`, `settlement_desc`, `created_at`, `updated_at`, `deleted_at`) VALUES (2, 'WALLET_X', '钱包X代付代收', '2020202020202', 1, 1, 1, 40, 100, 0.0025, 50, 1000, 10000000, 1000, 30000000, 'T0', '默认T+0结算', '2026-01-14 19:38:20', '2026-01-14 19:38:20', NULL);
+INSERT INTO `loan_payment_channels` (`id`, `code`, `name`, `merchant_no`, `status`, `can_payout`, `can_collect`, `payout_fee_rate`, `payout_fee_fixed`, `collect_fee_rate`, `collect_fee_fixed`, `collect_min_amount`, `collect_max_amount`, `payout_min_amount`, `payout_max_amount`, `settlement_cycle`, `settlement_desc`, `created_at`, `updated_at`, `deleted_at`) VALUES (3, 'MOCK', '模拟代付渠道(本地联调)', 'MOCK0001', 1, 1, 0, 0, 0, 0, 0, 100, 100000000, 100, 100000000, 'T0', '内存模拟代付网关，金额以13分结尾的代付会被拒绝', '2026-03-04 10:00:00', '2026-03-04 10:00:00', NULL);
+COMMIT;
+
+-- ----------------------------
+-- Table structure for loan_penalty_accruals
+-- ----------------------------
+DROP TABLE IF EXISTS `loan_penalty_accruals`;
+CREATE TABLE `loan_penalty_accruals` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '罚息计提流水ID',
+  `schedule_id` bigint NOT NULL COMMENT '关联期次 loan_repayment_schedules.id',
+  `accrual_date` date NOT NULL COMMENT '计提日期',
+  `overdue_days` int NOT NULL COMMENT '计提日的逾期天数',
+  `base_amount` bigint NOT NULL COMMENT '计提基数=剩余未还本金(分)',
+  `daily_rate_bp` int NOT NULL COMMENT '日罚息率(万分比)',
+  `amount` bigint NOT NULL COMMENT '本次计提罚息(分，已按上限截断)',
+  `penalty_before` bigint NOT NULL COMMENT '计提前期次应还罚息(分)',
+  `penalty_after` bigint NOT NULL COMMENT '计提后期次应还罚息(分)',
+  `created_at` datetime NOT NULL COMMENT '创建时间',
+  `updated_at` datetime DEFAULT NULL COMMENT '更新时间',
+  `deleted_at` datetime DEFAULT NULL COMMENT '软删除时间(NULL未删除)',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_schedule_accrual_date` (`schedule_id`,`accrual_date`) COMMENT '同一期次每天只计提一次',
+  CONSTRAINT `fk_accrual_schedule` FOREIGN KEY (`schedule_id`) REFERENCES `loan_repayment_schedules` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='罚息计提流水表(按期次按日计提，可审计/重算)';
+
+-- ----------------------------
+-- Records of loan_penalty_accruals
+-- ----------------------------
+BEGIN;
 COMMIT;
 
 -- ----------------------------
@@ -516,6 +668,46 @@ INSERT INTO `loan_permissions` (`id`, `code`, `name`, `type`, `resource`, `creat
 INSERT INTO `loan_permissions` (`id`, `code`, `name`, `type`, `resource`, `created_at`, `updated_at`, `deleted_at`) VALUES (6, 'repay:collect', '还款催收', NULL, NULL, '2026-01-14 18:21:50', '2026-01-14 18:21:50', NULL);
 COMMIT;
 
+-- ----------------------------
+-- Table structure for loan_products
+-- ----------------------------
+DROP TABLE IF EXISTS `loan_products`;
+CREATE TABLE `loan_products` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '产品ID',
+  `code` varchar(32) NOT NULL COMMENT '产品编码(唯一)',
+  `name` varchar(64) NOT NULL COMMENT '产品名称',
+  `status` tinyint NOT NULL DEFAULT '1' COMMENT '状态：1上架 0下架',
+  `repayment_method` varchar(32) NOT NULL COMMENT '还款方式：BULLET到期一次还本付息 EQUAL_INSTALLMENT等额本息 EQUAL_PRINCIPAL等额本金',
+  `installment_count` int NOT NULL DEFAULT '1' COMMENT '期数',
+  `installment_days` int NOT NULL DEFAULT '0' COMMENT '每期天数，0表示按借款天数/期数平分',
+  `interest_rate_bp` int NOT NULL DEFAULT '0' COMMENT '日利率(万分比)',
+  `fee_model` varchar(32) NOT NULL DEFAULT 'NONE' COMMENT '服务费收取方式：NONE不收 UPFRONT放款时扣除 INSTALLMENT按期收取',
+  `service_fee_bp` int NOT NULL DEFAULT '0' COMMENT '服务费率(占本金万分比)',
+  `service_fee_fixed` bigint NOT NULL DEFAULT '0' COMMENT '固定服务费(分)',
+  `min_amount` bigint NOT NULL DEFAULT '0' COMMENT '最低申请金额(分)，0不限',
+  `max_amount` bigint NOT NULL DEFAULT '0' COMMENT '最高申请金额(分)，0不限',
+  `allowed_days` varchar(64) DEFAULT NULL COMMENT '可选借款天数，逗号分隔如 7,14,30，空不限',
+  `penalty_daily_rate_bp` int NOT NULL DEFAULT '0' COMMENT '逾期日罚息率(万分比)，0使用系统设置',
+  `allocation_order` varchar(64) DEFAULT NULL COMMENT '还款冲销顺序，空使用系统设置',
+  `payoff_fee_bp` int NOT NULL DEFAULT '0' COMMENT '提前结清手续费率(占剩余本金万分比)',
+  `payoff_fee_fixed` bigint DEFAULT '0' COMMENT '提前结清固定手续费(分)',
+  `payoff_discount_bp` int DEFAULT '0' COMMENT '提前结清减免比例(占已计利息及费用万分比)',
+  `active_from` datetime DEFAULT NULL COMMENT '生效开始时间，空不限',
+  `active_to` datetime DEFAULT NULL COMMENT '生效结束时间，空不限',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `created_at` datetime NOT NULL COMMENT '创建时间',
+  `updated_at` datetime DEFAULT NULL COMMENT '更新时间',
+  `deleted_at` datetime DEFAULT NULL COMMENT '软删除时间(NULL未删除)',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_product_code` (`code`) COMMENT '产品编码唯一'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='贷款产品表(期限、期数、利率、费用模式及还款方式)';
+
+-- ----------------------------
+-- Records of loan_products
+-- ----------------------------
+BEGIN;
+COMMIT;
+
 -- ----------------------------
 -- Table structure for loan_referral_visits
 -- ----------------------------
@@ -600,6 +792,7 @@ CREATE TABLE `loan_repayment_transactions` (
   `collect_order_no` varchar(128) DEFAULT NULL COMMENT '回款订单号/三方代收单号(商户单号)',
   `pay_ref` varchar(128) DEFAULT NULL COMMENT '支付渠道流水号/交易号(三方transaction id)',
   `pay_amount` int NOT NULL COMMENT '本次回款金额(分)',
+  `collect_fee` int NOT NULL DEFAULT '0' COMMENT '代收渠道手续费(分，平台承担，不冲抵借款人应还)',
   `pay_method` varchar(32) DEFAULT NULL COMMENT '回款方式(如 BANK_TRANSFER/CARD/WALLET/CASH)',
   `paid_at` datetime DEFAULT NULL COMMENT '回款时间(交易成功时间)',
   `alloc_principal` int NOT NULL DEFAULT '0' COMMENT '本次分配到本金(分)',
@@ -609,11 +802,15 @@ CREATE TABLE `loan_repayment_transactions` (
   `status` tinyint NOT NULL DEFAULT '1' COMMENT '流水状态：1成功 0失败 2冲正/撤销',
   `remark` varchar(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_0900_ai_ci NOT NULL COMMENT '备注',
   `created_by` bigint NOT NULL,
+  `reversal_of_id` bigint DEFAULT NULL COMMENT '冲正流水指向被冲正的原流水id(唯一)，原流水为空',
+  `reverse_reason` varchar(255) DEFAULT NULL COMMENT '冲正原因',
   `voucher_file_name` varchar(64) NOT NULL,
   `created_at` datetime NOT NULL COMMENT '创建时间',
   `updated_at` datetime DEFAULT NULL COMMENT '更新时间',
   `deleted_at` datetime DEFAULT NULL COMMENT '软删除时间(NULL未删除)',
   PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_channel_pay_ref` (`collect_channel_id`,(if((`status` = 1),nullif(`pay_ref`,_utf8mb4''),NULL))) COMMENT '同一渠道成功流水的渠道流水号唯一(空值/冲正不参与)',
+  UNIQUE KEY `uk_reversal_of` (`reversal_of_id`) COMMENT '同一笔流水只能冲正一次',
   KEY `idx_tx_disburse_time` (`paid_at`) COMMENT '按放款单/时间查回款流水',
   KEY `idx_tx_schedule` (`schedule_id`) COMMENT '按期次查回款流水',
   KEY `idx_tx_collect_channel_time` (`collect_channel_id`,`paid_at`) COMMENT '按回款渠道/时间查流水',
@@ -737,6 +934,119 @@ INSERT INTO `loan_roles` (`id`, `code`, `name`, `data_scope`, `status`, `created
 INSERT INTO `loan_roles` (`id`, `code`, `name`, `data_scope`, `status`, `created_at`, `updated_at`, `deleted_at`) VALUES (3, 'auditor', '审核人员', 'DEPT', 1, '2026-01-14 18:21:42', '2026-01-14 18:21:42', NULL);
 COMMIT;
 
+-- ----------------------------
+-- Table structure for loan_schedule_extensions
+-- ----------------------------
+DROP TABLE IF EXISTS `loan_schedule_extensions`;
+CREATE TABLE `loan_schedule_extensions` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '展期记录ID',
+  `schedule_id` bigint NOT NULL COMMENT '关联期次 loan_repayment_schedules.id',
+  `disbursement_id` bigint NOT NULL COMMENT '关联放款单 loan_disbursements.id',
+  `extension_no` int NOT NULL COMMENT '该笔借款的第几次展期(从1开始)',
+  `days` int NOT NULL COMMENT '顺延天数',
+  `due_date_before` date NOT NULL COMMENT '展期前应还日期',
+  `due_date_after` date NOT NULL COMMENT '展期后应还日期',
+  `fee_amount` bigint NOT NULL DEFAULT '0' COMMENT '展期费(分)',
+  `transaction_id` bigint DEFAULT NULL COMMENT '展期费回款流水 loan_repayment_transactions.id，免费展期为0',
+  `penalty_mode` varchar(16) NOT NULL COMMENT '罚息处理方式：FREEZE冻结 WAIVE免除',
+  `penalty_waived` bigint DEFAULT '0' COMMENT '免除的罚息(分)',
+  `status_before` tinyint NOT NULL COMMENT '展期前期次状态',
+  `shifted_count` int DEFAULT '0' COMMENT '同时顺延的后续期次数',
+  `created_by` bigint NOT NULL COMMENT '操作人',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `created_at` datetime NOT NULL COMMENT '创建时间',
+  `updated_at` datetime DEFAULT NULL COMMENT '更新时间',
+  `deleted_at` datetime DEFAULT NULL COMMENT '软删除时间(NULL未删除)',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_disbursement_extension_no` (`disbursement_id`,`extension_no`) COMMENT '同一借款展期序号唯一，防止并发展期超限',
+  KEY `idx_schedule_id` (`schedule_id`),
+  CONSTRAINT `fk_extension_schedule` FOREIGN KEY (`schedule_id`) REFERENCES `loan_repayment_schedules` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='展期记录表(每次展期一条，记录顺延天数、展期费及罚息处理)';
+
+-- ----------------------------
+-- Records of loan_schedule_extensions
+-- ----------------------------
+BEGIN;
+COMMIT;
+
+-- ----------------------------
+-- Table structure for loan_scorecards
+-- ----------------------------
+DROP TABLE IF EXISTS `loan_scorecards`;
+CREATE TABLE `loan_scorecards` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `name` varchar(64) NOT NULL COMMENT '评分卡名称',
+  `version` int NOT NULL COMMENT '版本号，同名评分卡从 1 开始递增',
+  `definition` text NOT NULL COMMENT '评分卡定义 JSON：基础分、分数上下限、识别关键字、特征分箱与分值',
+  `status` tinyint NOT NULL DEFAULT '0' COMMENT '状态：1启用(提交申请时用于评分) 0停用，同一时间只启用一个版本',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注(版本变更说明)',
+  `created_at` datetime DEFAULT NULL,
+  `updated_at` datetime DEFAULT NULL,
+  `deleted_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_scorecards_name_version` (`name`,`version`) COMMENT '同名评分卡版本号唯一'
+) ENGINE=InnoDB AUTO_INCREMENT=2 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='信用评分卡(按版本保存，定义不可修改)';
+
+-- ----------------------------
+-- Records of loan_scorecards
+-- ----------------------------
+BEGIN;
+INSERT INTO `loan_scorecards` (`id`, `name`, `version`, `definition`, `status`, `remark`, `created_at`, `updated_at`, `deleted_at`) VALUES (1, '默认评分卡', 1, '{"baseScore":500,"minScore":300,"maxScore":850,"loanAppKeywords":["loan","credit","cash","lend"],"salaryKeywords":["salary","payroll","工资","薪资"],"features":[{"feature":"age","missingPoints":0,"bins":[{"max":22,"points":-20},{"min":22,"max":55,"points":15},{"min":55,"points":-10}]},{"feature":"has_house","missingPoints":0,"bins":[{"max":1,"points":0},{"min":1,"points":20}]},{"feature":"contacts_count","missingPoints":-30,"bins":[{"max":20,"points":-25},{"min":20,"max":100,"points":0},{"min":100,"points":20}]},{"feature":"unanswered_call_ratio","missingPoints":-10,"bins":[{"max":0.3,"points":15},{"min":0.3,"max":0.6,"points":0},{"min":0.6,"points":-30}]},{"feature":"loan_app_count","missingPoints":-10,"bins":[{"max":1,"points":20},{"min":1,"max":4,"points":-10},{"min":4,"points":-40}]},{"feature":"has_salary_sms","missingPoints":0,"bins":[{"max":1,"points":0},{"min":1,"points":30}]}]}', 0, '初始版本，启用前请按业务数据调整分箱与分值', '2026-03-01 00:00:00', '2026-03-01 00:00:00', NULL);
+COMMIT;
+
+-- ----------------------------
+-- Table structure for loan_screening_decisions
+-- ----------------------------
+DROP TABLE IF EXISTS `loan_screening_decisions`;
+CREATE TABLE `loan_screening_decisions` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `baseinfo_id` bigint NOT NULL COMMENT '申请单 loan_baseinfo.id',
+  `trigger_type` varchar(16) NOT NULL COMMENT '触发方式：CREATE 提交申请 RERUN 人工重新执行',
+  `outcome` varchar(16) NOT NULL COMMENT '预审结果：REJECT PASS MANUAL',
+  `score` int NOT NULL DEFAULT '0' COMMENT '命中规则的分数合计',
+  `hits` text COMMENT '命中规则 JSON 数组(规则id、名称、动作、分数)',
+  `facts` text COMMENT '执行时的申请单数据快照 JSON',
+  `note` varchar(255) DEFAULT NULL COMMENT '说明，如自动通过被降级为人工的原因',
+  `created_at` datetime DEFAULT NULL,
+  `updated_at` datetime DEFAULT NULL,
+  `deleted_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`),
+  KEY `idx_screening_baseinfo` (`baseinfo_id`) COMMENT '按申请单查询决策日志'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='申请自动预审决策日志';
+
+-- ----------------------------
+-- Records of loan_screening_decisions
+-- ----------------------------
+BEGIN;
+COMMIT;
+
+-- ----------------------------
+-- Table structure for loan_screening_rules
+-- ----------------------------
+DROP TABLE IF EXISTS `loan_screening_rules`;
+CREATE TABLE `loan_screening_rules` (
+  `id` bigint NOT NULL AUTO_INCREMENT,
+  `name` varchar(64) NOT NULL COMMENT '规则名称',
+  `priority` int NOT NULL DEFAULT '0' COMMENT '执行顺序(升序)',
+  `conditions` text NOT NULL COMMENT '条件表达式 JSON，如 {"field":"age","op":"<","value":18}',
+  `action` varchar(16) NOT NULL COMMENT '命中后动作：REJECT 自动拒绝 PASS 自动通过初审 MANUAL 转人工 SCORE 仅计分',
+  `score` int NOT NULL DEFAULT '0' COMMENT '命中后累加的分数(可为负)',
+  `status` tinyint NOT NULL DEFAULT '1' COMMENT '状态：1启用 0停用',
+  `remark` varchar(255) DEFAULT NULL COMMENT '备注',
+  `created_at` datetime DEFAULT NULL,
+  `updated_at` datetime DEFAULT NULL,
+  `deleted_at` datetime DEFAULT NULL,
+  PRIMARY KEY (`id`)
+) ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='申请自动预审(决策引擎)规则';
+
+-- ----------------------------
+-- Records of loan_screening_rules
+-- ----------------------------
+BEGIN;
+INSERT INTO `loan_screening_rules` (`id`, `name`, `priority`, `conditions`, `action`, `score`, `status`, `remark`, `created_at`, `updated_at`, `deleted_at`) VALUES (1, '未满18周岁', 0, '{"field":"age","op":"<","value":18}', 'REJECT', 0, 1, '未成年人不得借款', '2026-03-01 00:00:00', '2026-03-01 00:00:00', NULL);
+INSERT INTO `loan_screening_rules` (`id`, `name`, `priority`, `conditions`, `action`, `score`, `status`, `remark`, `created_at`, `updated_at`, `deleted_at`) VALUES (2, '黑名单客户', 0, '{"field":"risk_list_status","op":"=","value":2}', 'REJECT', 0, 1, '同证件号/手机号的申请被标记为黑名单', '2026-03-01 00:00:00', '2026-03-01 00:00:00', NULL);
+COMMIT;
+
 -- ----------------------------
 -- Table structure for loan_user_call_records
 -- ----------------------------
@@ -930,4 +1240,106 @@ INSERT INTO `loan_users` (`id`, `username`, `password_hash`, `department_id`, `m
 INSERT INTO `loan_users` (`id`, `username`, `password_hash`, `department_id`, `mfa_enabled`, `mfa_required`, `status`, `created_at`, `updated_at`, `deleted_at`, `share_code`) VALUES (3, 'referrer', '$2a$10$7EqJtq98hPqEX7fNZaFWoOhi5lWlP0r8kP7r9v8pJwD3h8m6cK4QK', 1, 0, 0, 1, '2026-01-14 19:36:30', '2026-01-14 19:36:30', NULL, 'REFSHARE01');
 COMMIT;
 
+-- ----------------------------
+-- Table structure for loan_vintage_snapshots
+-- ----------------------------
+DROP TABLE IF EXISTS `loan_vintage_snapshots`;
+CREATE TABLE `loan_vintage_snapshots` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '快照ID',
+  `cohort` char(7) NOT NULL COMMENT '放款月份 yyyy-mm',
+  `mob` int NOT NULL COMMENT '账龄月数(放款后第N个月的观察点)',
+  `cohort_loans` bigint NOT NULL DEFAULT '0' COMMENT '批次放款笔数',
+  `cohort_principal` bigint NOT NULL DEFAULT '0' COMMENT '批次放款本金(分)',
+  `observed_loans` bigint NOT NULL DEFAULT '0' COMMENT '已到达该观察点的借款笔数',
+  `observed_principal` bigint NOT NULL DEFAULT '0' COMMENT '已到达该观察点的放款本金(分)',
+  `dpd1_loans` bigint NOT NULL DEFAULT '0' COMMENT '截至观察点曾逾期1天以上的笔数',
+  `dpd1_principal` bigint NOT NULL DEFAULT '0' COMMENT '截至观察点曾逾期1天以上的放款本金(分)',
+  `dpd30_loans` bigint NOT NULL DEFAULT '0' COMMENT '曾逾期30天以上的笔数',
+  `dpd30_principal` bigint NOT NULL DEFAULT '0' COMMENT '曾逾期30天以上的放款本金(分)',
+  `dpd60_loans` bigint NOT NULL DEFAULT '0' COMMENT '曾逾期60天以上的笔数',
+  `dpd60_principal` bigint NOT NULL DEFAULT '0' COMMENT '曾逾期60天以上的放款本金(分)',
+  `dpd90_loans` bigint NOT NULL DEFAULT '0' COMMENT '曾逾期90天以上的笔数',
+  `dpd90_principal` bigint NOT NULL DEFAULT '0' COMMENT '曾逾期90天以上的放款本金(分)',
+  `collected_principal` bigint NOT NULL DEFAULT '0' COMMENT '截至观察点累计回收本金(分，已扣冲正)',
+  `collected_amount` bigint NOT NULL DEFAULT '0' COMMENT '截至观察点累计回款总额(分，已扣冲正)',
+  `snapshot_at` datetime NOT NULL COMMENT '快照计算时间',
+  `created_at` datetime NOT NULL COMMENT '创建时间',
+  `updated_at` datetime DEFAULT NULL COMMENT '更新时间',
+  `deleted_at` datetime DEFAULT NULL COMMENT '软删除时间(NULL未删除)',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_cohort_mob` (`cohort`,`mob`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='放款批次(vintage)表现快照表(每晚定时任务按放款月份重算)';
+
+-- ----------------------------
+-- Records of loan_vintage_snapshots
+-- ----------------------------
+BEGIN;
+COMMIT;
+
+-- ----------------------------
+-- Table structure for loan_waiver_requests
+-- ----------------------------
+DROP TABLE IF EXISTS `loan_waiver_requests`;
+CREATE TABLE `loan_waiver_requests` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '减免申请ID',
+  `schedule_id` bigint NOT NULL COMMENT '关联期次 loan_repayment_schedules.id',
+  `case_id` bigint DEFAULT NULL COMMENT '关联催收任务 loan_collection_cases.id(可选)',
+  `component` varchar(16) NOT NULL COMMENT '减免科目：penalty罚息 fee费用',
+  `amount` bigint NOT NULL COMMENT '申请减免金额(分)',
+  `reason` varchar(255) NOT NULL COMMENT '减免原因',
+  `requested_by` bigint NOT NULL COMMENT '申请人 loan_users.id',
+  `status` tinyint NOT NULL DEFAULT '0' COMMENT '状态：0待审批 1已通过 2已驳回',
+  `reviewed_by` bigint DEFAULT NULL COMMENT '审批人 loan_users.id',
+  `reviewed_at` datetime DEFAULT NULL COMMENT '审批时间',
+  `review_note` varchar(255) DEFAULT NULL COMMENT '审批备注',
+  `due_before` bigint DEFAULT '0' COMMENT '审批通过时该科目减免前应还(分)',
+  `due_after` bigint DEFAULT '0' COMMENT '审批通过时该科目减免后应还(分)',
+  `status_before` tinyint DEFAULT '0' COMMENT '审批通过时期次原状态',
+  `status_after` tinyint DEFAULT '0' COMMENT '审批通过后期次状态',
+  `created_at` datetime NOT NULL COMMENT '创建时间',
+  `updated_at` datetime DEFAULT NULL COMMENT '更新时间',
+  `deleted_at` datetime DEFAULT NULL COMMENT '软删除时间(NULL未删除)',
+  PRIMARY KEY (`id`),
+  KEY `idx_schedule_id` (`schedule_id`),
+  KEY `idx_case_id` (`case_id`),
+  KEY `idx_status` (`status`),
+  CONSTRAINT `fk_waiver_schedule` FOREIGN KEY (`schedule_id`) REFERENCES `loan_repayment_schedules` (`id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='罚息/费用减免申请表(催收发起，主管审批后调整期次应还)';
+
+-- ----------------------------
+-- Records of loan_waiver_requests
+-- ----------------------------
+BEGIN;
+COMMIT;
+
+-- ----------------------------
+-- Table structure for loan_workflow_stages
+-- ----------------------------
+DROP TABLE IF EXISTS `loan_workflow_stages`;
+CREATE TABLE `loan_workflow_stages` (
+  `id` bigint NOT NULL AUTO_INCREMENT COMMENT '节点ID',
+  `workflow` varchar(32) NOT NULL COMMENT '流程代码，借款申请为 loan_application',
+  `stage_no` int NOT NULL COMMENT '节点序号(升序审批)，审批通过后写入 loan_baseinfo.audit_status',
+  `code` varchar(32) NOT NULL COMMENT '节点代码，如 PRE_REVIEW FINANCE_REVIEW MANAGER_REVIEW',
+  `name` varchar(64) NOT NULL COMMENT '节点名称',
+  `permission` varchar(64) DEFAULT NULL COMMENT '审批该节点所需的权限码，为空表示不额外校验',
+  `min_amount` bigint NOT NULL DEFAULT '0' COMMENT '申请金额达到该值(分)才需要本节点，0 表示所有申请都需要',
+  `status` tinyint NOT NULL DEFAULT '1' COMMENT '状态：1启用 0停用',
+  `created_at` datetime NOT NULL COMMENT '创建时间',
+  `updated_at` datetime DEFAULT NULL COMMENT '更新时间',
+  `deleted_at` datetime DEFAULT NULL COMMENT '软删除时间(NULL未删除)',
+  PRIMARY KEY (`id`),
+  KEY `idx_workflow_stage_no` (`workflow`,`stage_no`),
+  KEY `idx_workflow_code` (`workflow`,`code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci COMMENT='审批流程节点定义表(申请单按节点序号逐级审批，大额申请可增加节点)';
+
+-- ----------------------------
+-- Records of loan_workflow_stages
+-- ----------------------------
+BEGIN;
+INSERT INTO `loan_workflow_stages` (`id`, `workflow`, `stage_no`, `code`, `name`, `permission`, `min_amount`, `status`, `created_at`, `updated_at`, `deleted_at`) VALUES (1, 'loan_application', 1, 'PRE_REVIEW', '初审', 'loan:pre_review', 0, 1, '2026-03-01 00:00:00', '2026-03-01 00:00:00', NULL);
+INSERT INTO `loan_workflow_stages` (`id`, `workflow`, `stage_no`, `code`, `name`, `permission`, `min_amount`, `status`, `created_at`, `updated_at`, `deleted_at`) VALUES (2, 'loan_application', 2, 'FINANCE_REVIEW', '放款审核', 'loan:finance_review', 0, 1, '2026-03-01 00:00:00', '2026-03-01 00:00:00', NULL);
+INSERT INTO `loan_workflow_stages` (`id`, `workflow`, `stage_no`, `code`, `name`, `permission`, `min_amount`, `status`, `created_at`, `updated_at`, `deleted_at`) VALUES (3, 'loan_application', 3, 'MANAGER_REVIEW', '经理审批', 'loan:manager_review', 5000000, 1, '2026-03-01 00:00:00', '2026-03-01 00:00:00', NULL);
+COMMIT;
+
 SET FOREIGN_KEY_CHECKS = 1;